@@ -4,7 +4,8 @@
  */
 
 import React, { useState, useEffect, useRef } from 'react';
-import { FileCode, AlertCircle, CheckCircle2, Copy, Download, Upload } from 'lucide-react';
+import { FileCode, AlertCircle, AlertTriangle, CheckCircle2, Copy, Download, Upload } from 'lucide-react';
+import type { ValidateTemplateResult } from '../../types';
 
 interface YamlEditorProps {
   value: string;
@@ -23,7 +24,7 @@ export const YamlEditor: React.FC<YamlEditorProps> = ({
 }) => {
   const [content, setContent] = useState(value || '');
   const [isValidating, setIsValidating] = useState(false);
-  const [validationResult, setValidationResult] = useState<{ valid: boolean; error?: string; message?: string } | null>(null);
+  const [validationResult, setValidationResult] = useState<ValidateTemplateResult | null>(null);
   const textareaRef = useRef<HTMLTextAreaElement>(null);
 
   useEffect(() => {
@@ -212,9 +213,29 @@ export const YamlEditor: React.FC<YamlEditorProps> = ({
             <span className={`text-sm ${
               validationResult.valid ? 'text-emerald-300' : 'text-red-300'
             }`}>
-              {validationResult.valid ? validationResult.message : validationResult.error}
+              {validationResult.valid
+                ? validationResult.message || (validationResult.nuclei_checked ? '模板校验通过（nuclei）' : '模板校验通过')
+                : validationResult.error}
             </span>
           </div>
+          {validationResult.diagnostics && validationResult.diagnostics.length > 0 && (
+            <ul className="mt-2 space-y-1">
+              {validationResult.diagnostics.map((d, i) => (
+                <li key={i} className="flex items-start gap-2 text-xs font-mono">
+                  {d.severity === 'error' ? (
+                    <AlertCircle size={12} className="mt-0.5 text-red-400 shrink-0" />
+                  ) : (
+                    <AlertTriangle size={12} className="mt-0.5 text-amber-400 shrink-0" />
+                  )}
+                  <span className={d.severity === 'error' ? 'text-red-300' : 'text-amber-300'}>
+                    {d.line > 0 && `${d.line}:${d.column > 0 ? d.column : 1} `}
+                    {d.message}
+                    {d.source === 'nuclei' && ' (nuclei)'}
+                  </span>
+                </li>
+              ))}
+            </ul>
+          )}
         </div>
       )}
 
@@ -7,6 +7,14 @@ import {
   Target,
   Vulnerability,
   ScanTask,
+  ScanOptions,
+  ScanEngine,
+  TemplateBulkToggleRequest,
+  TemplateManifestEntry,
+  TemplateManifestDiff,
+  ValidateTemplateResult,
+  TemplateTestRequest,
+  TemplateTestResult,
   CreateTargetRequest,
   UpdateTargetRequest,
   CreateScanRequest,
@@ -18,6 +26,12 @@ import {
   BruteResult,
   Report,
   CreateReportRequest,
+  PromoteTargetsRequest,
+  PromoteTargetsResult,
+  ScanSchedule,
+  ScanScheduleRequest,
+  AuthProfile,
+  AuthProfileRequest,
 } from '../types';
 
 // 导入 Wails 自动生成的绑定
@@ -156,12 +170,31 @@ class WailsServiceImpl {
     return safeWailsCall(
       async () => {
         // CreateScanTask 直接返回 ScanTask 对象，不是 id
-        const scanTask = await (WailsApp as any).CreateScanTask(
-          data.name || null,
-          data.target_id,
-          data.strategy,
-          data.templates || []
-        );
+        const multiTarget = (data.target_ids && data.target_ids.length > 0) || !!data.target_tag;
+        const scanTask = data.options || data.engine
+          ? await (WailsApp as any).CreateScanTaskWithOptions(
+              data.name || null,
+              multiTarget ? data.target_ids || [] : [data.target_id],
+              data.target_tag || '',
+              data.strategy,
+              data.templates || [],
+              data.options || null,
+              data.engine || ''
+            )
+          : multiTarget
+          ? await (WailsApp as any).CreateMultiTargetScanTask(
+              data.name || null,
+              data.target_ids || [],
+              data.target_tag || '',
+              data.strategy,
+              data.templates || []
+            )
+          : await (WailsApp as any).CreateScanTask(
+              data.name || null,
+              data.target_id,
+              data.strategy,
+              data.templates || []
+            );
         console.log('[WailsService] createScan returned:', JSON.stringify(scanTask), scanTask);
         if (!scanTask) {
           throw new Error('CreateScanTask returned null/undefined');
@@ -180,6 +213,26 @@ class WailsServiceImpl {
     );
   }
 
+  async getDefaultScanOptions(): Promise<ScanOptions> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).GetDefaultScanOptions();
+      },
+      {},
+      'getDefaultScanOptions'
+    );
+  }
+
+  async getScanEngines(): Promise<ScanEngine[]> {
+    return safeWailsCall(
+      async () => {
+        return (await (WailsApp as any).GetScanEngines()) || [];
+      },
+      [],
+      'getScanEngines'
+    );
+  }
+
   async startScan(id: number): Promise<void> {
     return safeWailsCall(
       async () => {
@@ -200,6 +253,59 @@ class WailsServiceImpl {
     );
   }
 
+  async stopScan(id: number): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        // 排队中的任务会直接移出队列
+        await (WailsApp as any).StopScan(id);
+      },
+      undefined,
+      'stopScan'
+    );
+  }
+
+  async pauseScan(id: number): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        await (WailsApp as any).PauseScan(id);
+      },
+      undefined,
+      'pauseScan'
+    );
+  }
+
+  async resumeScan(id: number): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        // 用于 paused 的任务，以及应用异常退出后被标记为 interrupted 的任务
+        await (WailsApp as any).ResumeScan(id);
+      },
+      undefined,
+      'resumeScan'
+    );
+  }
+
+  async getScanQueue(): Promise<ScanTask[]> {
+    return safeWailsCall(
+      async () => {
+        const queue = await (WailsApp as any).GetScanQueue();
+        return Array.isArray(queue) ? queue : [];
+      },
+      [],
+      'getScanQueue'
+    );
+  }
+
+  async reorderScanQueue(taskIds: number[]): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        await (WailsApp as any).ReorderScanQueue(taskIds);
+      },
+      undefined,
+      'reorderScanQueue'
+    );
+  }
+
   async deleteScan(id: number): Promise<void> {
     return safeWailsCall(
       async () => {
@@ -267,6 +373,27 @@ class WailsServiceImpl {
     );
   }
 
+  async getScanTemplateManifest(id: number): Promise<TemplateManifestEntry[]> {
+    return safeWailsCall(
+      async () => {
+        const entries = await (WailsApp as any).GetScanTemplateManifest(id);
+        return Array.isArray(entries) ? entries : [];
+      },
+      [],
+      'getScanTemplateManifest'
+    );
+  }
+
+  async compareScanTemplateManifests(id: number, baseId: number = 0): Promise<TemplateManifestDiff | null> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).CompareScanTemplateManifests(id, baseId);
+      },
+      null,
+      'compareScanTemplateManifests'
+    );
+  }
+
   // ==================== 漏洞管理 ====================
 
   async getAllVulnerabilities(): Promise<Vulnerability[]> {
@@ -544,16 +671,36 @@ class WailsServiceImpl {
     );
   }
 
-  async validateCustomTemplate(content: string): Promise<any> {
+  async bulkToggleBuiltinTemplates(request: TemplateBulkToggleRequest): Promise<number> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).BulkToggleBuiltinTemplates(request);
+      },
+      0,
+      'bulkToggleBuiltinTemplates'
+    );
+  }
+
+  async validateCustomTemplate(content: string): Promise<ValidateTemplateResult> {
     return safeWailsCall(
       async () => {
         return await (WailsApp as any).ValidateCustomTemplate(content);
       },
-      { valid: false, errors: [] },
+      { valid: false, diagnostics: [] },
       'validateCustomTemplate'
     );
   }
 
+  async testTemplate(req: TemplateTestRequest): Promise<TemplateTestResult | null> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).TestTemplate(req);
+      },
+      null,
+      'testTemplate'
+    );
+  }
+
   async getCustomTemplatesStats(): Promise<any> {
     return safeWailsCall(
       async () => {
@@ -582,6 +729,18 @@ class WailsServiceImpl {
     WailsRuntime.EventsOn('scan.failed', callback);
   }
 
+  onScanPaused(callback: Function): void {
+    WailsRuntime.EventsOn('scan.paused', callback);
+  }
+
+  onScanResumed(callback: Function): void {
+    WailsRuntime.EventsOn('scan.resumed', callback);
+  }
+
+  onScanTimedOut(callback: Function): void {
+    WailsRuntime.EventsOn('scan.timed_out', callback);
+  }
+
   onScanLog(callback: Function): void {
     WailsRuntime.EventsOn('scan.log', callback);
   }
@@ -740,11 +899,17 @@ class WailsServiceImpl {
   async createBruteTask(data: any): Promise<BruteTask> {
     return safeWailsCall(
       async () => {
-        const id = await (WailsApp as any).CreateBruteTask(
-          data.name,
-          data.request_id,
-          data.type
-        );
+        const id = await (WailsApp as any).CreateBruteTask({
+          name: data.name,
+          request_id: data.request_id,
+          type: data.type,
+          attack_mode: data.attack_mode || '',
+          payload_set_ids: data.payload_set_ids || [],
+          concurrency: data.concurrency || 0,
+          timeout: data.timeout || 0,
+          match_rules: data.match_rules || [],
+          match_mode: data.match_mode || '',
+        });
         const tasks = await (WailsApp as any).GetAllBruteTasks() || [];
         const task = tasks.find(t => t.id === Number(id));
         if (!task) {
@@ -770,7 +935,7 @@ class WailsServiceImpl {
   async cancelBruteTask(id: number): Promise<void> {
     return safeWailsCall(
       async () => {
-        // TODO: 实现取消逻辑
+        await (WailsApp as any).StopBruteTask(id);
       },
       undefined,
       'cancelBruteTask'
@@ -797,6 +962,16 @@ class WailsServiceImpl {
     );
   }
 
+  async getBruteTaskHits(id: number): Promise<BruteResult[]> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).GetBruteTaskHits(id) || [];
+      },
+      [],
+      'getBruteTaskHits'
+    );
+  }
+
   async getAllBrutePayloadSets(): Promise<BrutePayloadSet[]> {
     return safeWailsCall(
       async () => {
@@ -886,24 +1061,50 @@ class WailsServiceImpl {
   async scanPorts(options: {
     target: string;
     ports?: number[];
+    port_spec?: string;
     timeout?: number;
     batch_size?: number;
   }): Promise<any[]> {
     return safeWailsCall(
       async () => {
-        const taskId = await (WailsApp as any).CreatePortScanTask(
+        // 目标支持 CIDR、IP 范围与主机名列表，每个主机对应一个任务
+        const taskIds: number[] = await (WailsApp as any).CreatePortScanTasks(
           options.target,
-          options.ports || [],
+          options.port_spec || (options.ports || []).join(','),
           options.timeout || 2000,
           options.batch_size || 50
-        );
-        return await (WailsApp as any).GetPortScanResults(Number(taskId)) || [];
+        ) || [];
+
+        const results: any[] = [];
+        for (const taskId of taskIds) {
+          await (WailsApp as any).StartPortScanTask(Number(taskId));
+
+          // 等待扫描结束
+          let task = await (WailsApp as any).GetPortScanTask(Number(taskId));
+          while (task && (task.status === 'pending' || task.status === 'running')) {
+            await new Promise((resolve) => setTimeout(resolve, 500));
+            task = await (WailsApp as any).GetPortScanTask(Number(taskId));
+          }
+          const taskResults = await (WailsApp as any).GetPortScanResults(Number(taskId)) || [];
+          results.push(...taskResults.map((r: any) => ({ ...r, host: task?.target })));
+        }
+        return results;
       },
       [],
       'scanPorts'
     );
   }
 
+  async getPortProfiles(): Promise<Record<string, string>> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).GetPortProfiles() || {};
+      },
+      {},
+      'getPortProfiles'
+    );
+  }
+
   async getCommonPorts(): Promise<number[]> {
     return safeWailsCall(
       async () => {
@@ -919,6 +1120,7 @@ class WailsServiceImpl {
     wordlist?: string[];
     timeout?: number;
     batch_size?: number;
+    include_wildcard?: boolean;
   }): Promise<any[]> {
     return safeWailsCall(
       async () => {
@@ -928,13 +1130,189 @@ class WailsServiceImpl {
           options.timeout || 2000,
           options.batch_size || 50
         );
-        return await (WailsApp as any).GetDomainBruteResults(Number(taskId)) || [];
+        await (WailsApp as any).StartDomainBruteTask(Number(taskId));
+
+        // 等待解析结束
+        let task = await (WailsApp as any).GetDomainBruteTask(Number(taskId));
+        while (task && (task.status === 'pending' || task.status === 'running')) {
+          await new Promise((resolve) => setTimeout(resolve, 500));
+          task = await (WailsApp as any).GetDomainBruteTask(Number(taskId));
+        }
+        const results = await (WailsApp as any).GetDomainBruteResults(Number(taskId)) || [];
+        // 默认隐藏泛解析命中的结果
+        return options.include_wildcard ? results : results.filter((r: any) => !r.wildcard);
       },
       [],
       'bruteSubdomains'
     );
   }
 
+  async promoteTargets(req: PromoteTargetsRequest): Promise<PromoteTargetsResult> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).PromoteTargets({
+          source: req.source,
+          task_id: req.task_id,
+          result_ids: req.result_ids || [],
+          tags: req.tags || [],
+          scheme: req.scheme || '',
+          start_scan: req.start_scan || false,
+          scan_strategy: req.scan_strategy || '',
+          templates: req.templates || [],
+        });
+      },
+      { created: [], skipped: [] },
+      'promoteTargets'
+    );
+  }
+
+  // ==================== 扫描计划 ====================
+
+  async getScanSchedules(): Promise<ScanSchedule[]> {
+    return safeWailsCall(
+      async () => {
+        const schedules = await (WailsApp as any).GetAllScanSchedules();
+        return schedules || [];
+      },
+      [],
+      'getScanSchedules'
+    );
+  }
+
+  async createScanSchedule(req: ScanScheduleRequest): Promise<ScanSchedule> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).CreateScanSchedule({
+          name: req.name,
+          target_id: req.target_id || 0,
+          target_tag: req.target_tag || '',
+          strategy: req.strategy,
+          templates: req.templates || [],
+          cron: req.cron || '',
+          interval_seconds: req.interval_seconds || 0,
+          catch_up: req.catch_up || false,
+          enabled: req.enabled ?? true,
+        });
+      },
+      null as unknown as ScanSchedule,
+      'createScanSchedule'
+    );
+  }
+
+  async updateScanSchedule(id: number, req: ScanScheduleRequest): Promise<ScanSchedule> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).UpdateScanSchedule(id, {
+          name: req.name,
+          target_id: req.target_id || 0,
+          target_tag: req.target_tag || '',
+          strategy: req.strategy,
+          templates: req.templates || [],
+          cron: req.cron || '',
+          interval_seconds: req.interval_seconds || 0,
+          catch_up: req.catch_up || false,
+          enabled: req.enabled ?? true,
+        });
+      },
+      null as unknown as ScanSchedule,
+      'updateScanSchedule'
+    );
+  }
+
+  async setScanScheduleEnabled(id: number, enabled: boolean): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        await (WailsApp as any).SetScanScheduleEnabled(id, enabled);
+      },
+      undefined,
+      'setScanScheduleEnabled'
+    );
+  }
+
+  async deleteScanSchedule(id: number): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        await (WailsApp as any).DeleteScanSchedule(id);
+      },
+      undefined,
+      'deleteScanSchedule'
+    );
+  }
+
+  async runScanScheduleNow(id: number): Promise<ScanTask> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).RunScanScheduleNow(id);
+      },
+      null as unknown as ScanTask,
+      'runScanScheduleNow'
+    );
+  }
+
+  // ==================== 认证配置 ====================
+
+  async getAuthProfiles(targetId: number): Promise<AuthProfile[]> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).GetAuthProfiles(targetId);
+      },
+      [],
+      'getAuthProfiles'
+    );
+  }
+
+  async createAuthProfile(req: AuthProfileRequest): Promise<AuthProfile> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).CreateAuthProfile({
+          target_id: req.target_id,
+          name: req.name,
+          type: req.type,
+          enabled: req.enabled ?? true,
+          username: req.username || '',
+          login_request_id: req.login_request_id || 0,
+          headers: req.headers || {},
+          cookies: req.cookies || '',
+          password: req.password || '',
+          token: req.token || '',
+        });
+      },
+      null as unknown as AuthProfile,
+      'createAuthProfile'
+    );
+  }
+
+  async updateAuthProfile(id: number, req: AuthProfileRequest): Promise<AuthProfile> {
+    return safeWailsCall(
+      async () => {
+        return await (WailsApp as any).UpdateAuthProfile(id, {
+          target_id: req.target_id,
+          name: req.name,
+          type: req.type,
+          enabled: req.enabled ?? true,
+          username: req.username || '',
+          login_request_id: req.login_request_id || 0,
+          headers: req.headers || {},
+          cookies: req.cookies || '',
+          password: req.password || '',
+          token: req.token || '',
+        });
+      },
+      null as unknown as AuthProfile,
+      'updateAuthProfile'
+    );
+  }
+
+  async deleteAuthProfile(id: number): Promise<void> {
+    return safeWailsCall(
+      async () => {
+        await (WailsApp as any).DeleteAuthProfile(id);
+      },
+      undefined,
+      'deleteAuthProfile'
+    );
+  }
+
   async getDomainWordlist(): Promise<string[]> {
     return safeWailsCall(
       async () => {
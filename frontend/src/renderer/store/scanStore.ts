@@ -97,6 +97,15 @@ export const useScanStore = create<ScanState>()(
             strategy: strategy,
             templates: config.templates || [],
             scenarioGroupId: config.scenarioGroupId,
+            options: {
+              rate_limit: config.rateLimit,
+              concurrency: config.concurrency,
+              timeout: config.timeout,
+              retries: config.retries === 0 ? -1 : config.retries,
+              proxy: config.proxy,
+              headers: config.headers,
+              max_duration: config.maxDuration,
+            },
           };
 
           console.log('[scanStore] Creating scan with request:', createRequest);
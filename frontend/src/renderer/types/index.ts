@@ -51,8 +51,10 @@ export interface ScanTask {
   id: number;
   name?: string;
   target_id: number;
+  target_ids?: number[];  // 多目标任务的全部目标
+  target_tag?: string;    // 按标签选择目标时的标签
   target_name: string;
-  status: 'pending' | 'running' | 'completed' | 'failed' | 'cancelled';
+  status: 'pending' | 'queued' | 'running' | 'paused' | 'completed' | 'failed' | 'stopped' | 'interrupted' | 'timed_out' | 'cancelled';
   strategy?: string;
   templates_used?: string[];
   progress: number;
@@ -62,6 +64,9 @@ export interface ScanTask {
   started_at?: string;
   completed_at?: string;
   error?: string;
+  queue_position?: number;  // 排队顺序，仅 queued 状态有效
+  options?: ScanOptions;    // 任务级调优参数
+  engine?: string;          // 扫描引擎，默认为 nuclei
   config?: ScanConfigOptions;
   created_at?: string;
 }
@@ -69,9 +74,47 @@ export interface ScanTask {
 export interface CreateScanRequest {
   name?: string;
   target_id: number;
+  target_ids?: number[];  // 多目标扫描
+  target_tag?: string;    // 扫描带有该标签的全部目标
   strategy: string;
   templates?: string[];
   scenarioGroupId?: string;  // 场景分组 ID
+  options?: ScanOptions;     // 调优参数，未设置的字段使用全局默认值
+  engine?: string;           // 扫描引擎，为空时使用 nuclei
+}
+
+// 已注册的扫描引擎
+export interface ScanEngine {
+  name: string;
+  description: string;
+  available: boolean;
+}
+
+// 扫描运行的模板及其内容哈希
+export interface TemplateManifestEntry {
+  template_id: string;
+  source: 'builtin' | 'custom';
+  content_hash: string;
+}
+
+// 两次扫描运行模板的差异
+export interface TemplateManifestDiff {
+  scan_id: number;
+  base_scan_id: number;
+  added: TemplateManifestEntry[];
+  removed: TemplateManifestEntry[];
+  changed: TemplateManifestEntry[];
+}
+
+// nuclei 调优参数，数值为 0 或未设置时使用全局默认值
+export interface ScanOptions {
+  rate_limit?: number;   // 每秒最大请求数
+  concurrency?: number;  // 并行执行的模板数
+  timeout?: number;      // 单个请求超时（秒）
+  retries?: number;      // 失败重试次数，-1 表示不重试
+  proxy?: string;        // http/https/socks5 代理
+  headers?: string[];    // "Name: value"
+  max_duration?: number; // 扫描最长运行秒数，超时后终止，-1 表示不限制
 }
 
 export interface ScanConfigOptions {
@@ -82,7 +125,9 @@ export interface ScanConfigOptions {
   concurrency?: number;
   timeout?: number;
   retries?: number;
+  proxy?: string;
   headers?: string[];
+  maxDuration?: number;
 }
 
 // 扫描日志
@@ -136,12 +181,32 @@ export interface HttpResponse {
 }
 
 // 暴力破解相关
+export type BruteAttackMode = 'sniper' | 'battering_ram' | 'pitchfork' | 'cluster_bomb';
+
+export type BruteMatchMode = 'any' | 'all';
+
+export interface BruteMatchRule {
+  name?: string;
+  type: 'status' | 'length' | 'body' | 'header' | 'time';
+  codes?: number[];
+  min_delta?: number;
+  pattern?: string;
+  regex?: boolean;
+  header?: string;
+  min_time?: number;
+  max_time?: number;
+  negate?: boolean;
+}
+
 export interface BruteTask {
   id: number;
   name: string;
   request_id: number;
   request_name?: string;
   type: 'single' | 'multi-pitchfork' | 'multi-cluster';
+  attack_mode?: BruteAttackMode;
+  match_rules?: BruteMatchRule[];
+  match_mode?: BruteMatchMode;
   status: 'pending' | 'running' | 'paused' | 'completed' | 'failed' | 'cancelled';
   total_payloads: number;
   sent_payloads: number;
@@ -166,7 +231,7 @@ export interface BruteParameter {
 export interface BrutePayloadSet {
   id: number;
   name: string;
-  type: 'dictionary' | 'number' | 'charset' | 'date';
+  type: 'dictionary' | 'number' | 'charset' | 'date' | 'credentials';
   config?: string;
   created_at: string;
   payload_count?: number;
@@ -181,6 +246,7 @@ export interface BruteResult {
   status_code?: number;
   response_length?: number;
   response_time: number;
+  matched_rule?: string;
   body?: string;
   error?: string;
   created_at: string;
@@ -190,6 +256,10 @@ export interface CreateBruteTaskRequest {
   name: string;
   request_id: number;
   type: 'single' | 'multi-pitchfork' | 'multi-cluster';
+  attack_mode?: BruteAttackMode;
+  payload_set_ids?: number[];
+  match_rules?: BruteMatchRule[];
+  match_mode?: BruteMatchMode;
   parameters: {
     name: string;
     type: 'header' | 'query' | 'body' | 'path';
@@ -209,11 +279,100 @@ export interface CreateBruteTaskRequest {
 
 export interface CreatePayloadSetRequest {
   name: string;
-  type: 'dictionary' | 'number' | 'charset' | 'date';
+  type: 'dictionary' | 'number' | 'charset' | 'date' | 'credentials';
   config?: string;
   payloads?: string[];
 }
 
+// 扫描结果提升为目标
+export interface PromoteTargetsRequest {
+  source: 'port_scan' | 'domain_brute';
+  task_id: number;
+  result_ids?: number[];
+  tags?: string[];
+  scheme?: 'http' | 'https';
+  start_scan?: boolean;
+  scan_strategy?: string;
+  templates?: string[];
+}
+
+export interface PromotedTarget {
+  result_id: number;
+  target: Target;
+  scan_task_id?: number;
+  scan_error?: string;
+}
+
+export interface PromoteTargetsResult {
+  created: PromotedTarget[];
+  skipped: {
+    result_id: number;
+    url: string;
+    reason: string;
+  }[];
+}
+
+// 扫描计划
+export interface ScanSchedule {
+  id: number;
+  name: string;
+  target_id?: number;        // 与 target_tag 二选一
+  target_tag?: string;
+  strategy: string;          // scenario:<id> 时每次运行使用场景分组的最新模板
+  templates: string[];
+  cron?: string;             // 与 interval_seconds 二选一
+  interval_seconds?: number;
+  catch_up: boolean;         // 应用关闭期间错过的执行是否在启动后补跑一次
+  enabled: boolean;
+  last_run_at?: string;
+  next_run_at?: string;      // UTC 时间
+  last_task_id?: number;
+  last_error?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ScanScheduleRequest {
+  name: string;
+  target_id?: number;
+  target_tag?: string;
+  strategy: string;
+  templates?: string[];
+  cron?: string;
+  interval_seconds?: number;
+  catch_up?: boolean;
+  enabled?: boolean;
+}
+
+// 目标认证配置，凭据只写不读
+export type AuthType = 'header' | 'cookie' | 'basic' | 'bearer' | 'login';
+
+export interface AuthProfile {
+  id: number;
+  target_id: number;
+  name: string;
+  type: AuthType;
+  enabled: boolean;
+  username?: string;          // basic 认证用户名
+  login_request_id?: number;  // login 类型使用的 HTTP 请求
+  has_secret: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface AuthProfileRequest {
+  target_id: number;
+  name: string;
+  type: AuthType;
+  enabled?: boolean;
+  username?: string;
+  login_request_id?: number;
+  headers?: Record<string, string>;
+  cookies?: string;           // 格式为 "a=1; b=2"
+  password?: string;
+  token?: string;             // 更新时凭据字段全部为空表示保留原有凭据
+}
+
 // 自定义 POC 模板相关
 export interface CustomTemplate {
   id: number;
@@ -234,10 +393,55 @@ export interface UpdateCustomTemplateRequest {
   content: string;
 }
 
+// 按分类、标签或作者批量启用/禁用内置模板，多个条件同时满足才匹配
+export interface TemplateBulkToggleRequest {
+  category?: string;
+  tag?: string;
+  author?: string;
+  enabled: boolean;
+}
+
+// 模板校验发现的问题，line/column 为 0 时表示无法定位
+export interface TemplateDiagnostic {
+  line: number;
+  column: number;
+  severity: 'error' | 'warning';
+  message: string;
+  source: 'schema' | 'nuclei';
+}
+
 export interface ValidateTemplateResult {
   valid: boolean;
   error?: string;
   message?: string;
+  diagnostics?: TemplateDiagnostic[];
+  nuclei_checked?: boolean;
+}
+
+// 模板试运行：content 不为空时运行草稿内容，否则运行 id 对应的已保存模板
+export interface TemplateTestRequest {
+  id?: number;
+  content?: string;
+  url: string;
+}
+
+export interface TemplateTestEvent {
+  matched: boolean;
+  matcher_name?: string;
+  matched_at?: string;
+  extracted?: string[];
+  request?: string;
+  response?: string;
+}
+
+// 试运行结果只用于展示，不会保存为漏洞
+export interface TemplateTestResult {
+  template_id: string;
+  url: string;
+  matched: boolean;
+  events: TemplateTestEvent[];
+  diagnostics: TemplateDiagnostic[];
+  duration_ms: number;
 }
 
 export interface CustomTemplateStats {
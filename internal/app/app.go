@@ -13,7 +13,9 @@ import (
 	appEvent "github.com/holehunter/holehunter/internal/infrastructure/event"
 	"github.com/holehunter/holehunter/internal/infrastructure/logger"
 	"github.com/holehunter/holehunter/internal/infrastructure/resources"
+	"github.com/holehunter/holehunter/internal/infrastructure/secret"
 	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/scanner"
 	"github.com/holehunter/holehunter/internal/svc"
 	"github.com/holehunter/holehunter/internal/sync"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -44,6 +46,9 @@ type App struct {
 	domainBruteHandler *handler.DomainBruteHandler
 	bruteHandler       *handler.BruteHandler
 	reportHandler      *handler.ReportHandler
+	promotionHandler   *handler.PromotionHandler
+	scheduleHandler    *handler.ScheduleHandler
+	authHandler        *handler.AuthHandler
 }
 
 // AppOption 应用配置选项
@@ -152,9 +157,40 @@ func (a *App) startup(ctx context.Context) error {
 		// 不阻塞启动，只记录警告
 	}
 
+	// 自定义模板以数据库为准写入磁盘，扫描时使用其快照
+	if err := a.templateHandler.GetTemplateService().SyncCustomTemplates(ctx); err != nil {
+		a.logger.Warn("Failed to sync custom templates: %v", err)
+	}
+
 	// 设置事件转发
 	a.setupEventForwarding()
 
+	// 上次退出时仍在运行的扫描标记为中断，必须在启动队列之前完成
+	if n, err := a.scanHandler.RecoverInterrupted(ctx); err != nil {
+		a.logger.Warn("Failed to recover interrupted scans: %v", err)
+	} else if n > 0 {
+		a.logger.Info("Marked %d scan task(s) as interrupted", n)
+	}
+	for name, recoverTasks := range map[string]func(context.Context) (int, error){
+		"port scan":    a.portScanHandler.RecoverInterrupted,
+		"domain brute": a.domainBruteHandler.RecoverInterrupted,
+		"brute":        a.bruteHandler.RecoverInterrupted,
+	} {
+		if n, err := recoverTasks(ctx); err != nil {
+			a.logger.Warn("Failed to recover interrupted %s tasks: %v", name, err)
+		} else if n > 0 {
+			a.logger.Info("Marked %d %s task(s) as stopped", n, name)
+		}
+	}
+
+	// 恢复上次退出时仍在排队的扫描任务
+	if err := a.scanHandler.DispatchQueue(ctx); err != nil {
+		a.logger.Warn("Failed to resume scan queue: %v", err)
+	}
+
+	// 启动扫描计划，应用关闭期间错过的执行按各计划的补跑设置处理
+	a.scheduleHandler.Start()
+
 	// 通知前端应用已准备就绪
 	runtime.EventsEmit(ctx, "app.ready", map[string]interface{}{
 		"success": true,
@@ -180,6 +216,8 @@ func (a *App) initLayers() {
 	domainBruteRepo := repo.NewDomainBruteRepository(a.db)
 	bruteRepo := repo.NewBruteRepository(a.db)
 	reportRepo := repo.NewReportRepository(a.db)
+	scheduleRepo := repo.NewScheduleRepository(a.db)
+	authRepo := repo.NewAuthProfileRepository(a.db)
 
 	// 初始化 Service
 	targetSvc := svc.NewTargetService(targetRepo, a.eventBus)
@@ -187,12 +225,28 @@ func (a *App) initLayers() {
 	vulnSvc := svc.NewVulnerabilityService(vulnRepo, a.logger)
 	dashboardSvc := svc.NewDashboardService(dashboardRepo)
 	templateSvc := svc.NewTemplateService(templateRepo)
+	templateMaterializer := svc.NewTemplateMaterializer(templateRepo, a.config.CustomTemplatesDir, filepath.Join(a.config.DataDir, "template-snapshots"))
+	templateSvc.SetMaterializer(templateMaterializer)
+	templateNuclei := scanner.NewNucleiClientWithTemplates(a.config.DataDir, a.config.TemplatesDir)
+	templateSvc.SetValidator(templateNuclei)
+	templateSvc.SetRunner(templateNuclei)
 	scenarioSvc := svc.NewScenarioService(scenarioRepo)
 	httpSvc := svc.NewHTTPService(httpRequestRepo, httpResponseRepo)
-	portScanSvc := svc.NewPortScanService(portScanRepo)
-	domainBruteSvc := svc.NewDomainBruteService(domainBruteRepo)
-	bruteSvc := svc.NewBruteService(bruteRepo)
+	portScanSvc := svc.NewPortScanService(portScanRepo, a.eventBus, a.logger, a.config)
+	domainBruteSvc := svc.NewDomainBruteService(domainBruteRepo, a.eventBus, a.logger, a.config)
+	bruteSvc := svc.NewBruteService(bruteRepo, httpRequestRepo, a.eventBus, a.logger)
 	reportSvc := svc.NewReportService(reportRepo, scanRepo, vulnRepo, a.config.DataDir)
+	promotionSvc := svc.NewPromotionService(targetSvc, scanSvc, targetRepo, portScanRepo, domainBruteRepo, a.logger)
+	scheduleSvc := svc.NewScheduleService(scheduleRepo, targetRepo, scenarioRepo, scanSvc, a.eventBus, a.logger)
+
+	// 凭据加密密钥与数据库分开保存，加载失败时认证配置不可用
+	secretBox, err := secret.LoadOrCreate(filepath.Join(a.config.DataDir, "secret.key"))
+	if err != nil {
+		a.logger.Error("Failed to load secret key, auth profiles are unavailable: %v", err)
+	}
+	authSvc := svc.NewAuthService(authRepo, targetRepo, httpRequestRepo, secretBox, a.logger)
+	scanSvc.SetAuthService(authSvc)
+	scanSvc.SetTemplateMaterializer(templateMaterializer)
 
 	// 初始化 Handler
 	a.targetHandler = handler.NewTargetHandler(targetSvc)
@@ -206,6 +260,9 @@ func (a *App) initLayers() {
 	a.domainBruteHandler = handler.NewDomainBruteHandler(domainBruteSvc)
 	a.bruteHandler = handler.NewBruteHandler(bruteSvc)
 	a.reportHandler = handler.NewReportHandler(reportSvc)
+	a.promotionHandler = handler.NewPromotionHandler(promotionSvc)
+	a.scheduleHandler = handler.NewScheduleHandler(scheduleSvc)
+	a.authHandler = handler.NewAuthHandler(authSvc)
 
 	// 设置事件处理器（处理业务逻辑事件）
 	eventHandler := appEvent.NewEventHandler(vulnSvc, a.logger)
@@ -216,6 +273,10 @@ func (a *App) initLayers() {
 func (a *App) Shutdown(ctx context.Context) {
 	a.logger.Info("Shutting down HoleHunter...")
 
+	if a.scheduleHandler != nil {
+		a.scheduleHandler.Stop()
+	}
+
 	if a.logger != nil {
 		a.logger.Close()
 	}
@@ -286,6 +347,26 @@ func (a *App) setupEventForwarding() {
 		return nil
 	})
 
+	a.eventBus.Subscribe(appEvent.EventScanQueued, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "scan.queued", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventScanTimedOut, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "scan.timed_out", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventScanPaused, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "scan.paused", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventScanResumed, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "scan.resumed", e.Data)
+		return nil
+	})
+
 	// 目标事件
 	a.eventBus.Subscribe(appEvent.EventTargetCreated, func(ctx context.Context, e appEvent.Event) error {
 		runtime.EventsEmit(a.ctx, "target.created", e.Data)
@@ -317,6 +398,59 @@ func (a *App) setupEventForwarding() {
 		runtime.EventsEmit(a.ctx, "brute.failed", e.Data)
 		return nil
 	})
+
+	// PortScan 事件
+	a.eventBus.Subscribe(appEvent.EventPortScanStarted, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "portscan.started", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventPortScanProgress, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "portscan.progress", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventPortScanCompleted, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "portscan.completed", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventPortScanFailed, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "portscan.failed", e.Data)
+		return nil
+	})
+
+	// DomainBrute 事件
+	a.eventBus.Subscribe(appEvent.EventDomainBruteStarted, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "domainbrute.started", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventDomainBruteProgress, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "domainbrute.progress", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventDomainBruteCompleted, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "domainbrute.completed", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventDomainBruteFailed, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "domainbrute.failed", e.Data)
+		return nil
+	})
+
+	// Schedule 事件
+	a.eventBus.Subscribe(appEvent.EventScheduleTriggered, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "schedule.triggered", e.Data)
+		return nil
+	})
+
+	a.eventBus.Subscribe(appEvent.EventScheduleSkipped, func(ctx context.Context, e appEvent.Event) error {
+		runtime.EventsEmit(a.ctx, "schedule.skipped", e.Data)
+		return nil
+	})
 }
 
 // LogFromFrontend 前端日志
@@ -81,6 +81,38 @@ func (a *App) CreateScanTask(name string, targetID int, strategy string, templat
 	return a.scanHandler.Create(a.ctx, name, targetID, strategy, templates)
 }
 
+// CreateMultiTargetScanTask 创建覆盖多个目标或某一标签下全部目标的扫描任务
+func (a *App) CreateMultiTargetScanTask(name string, targetIDs []int, targetTag string, strategy string, templates []string) (*models.ScanTask, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.scanHandler.CreateMultiTarget(a.ctx, name, targetIDs, targetTag, strategy, templates)
+}
+
+// CreateScanTaskWithOptions 创建带调优参数（速率、并发、超时、重试、代理、请求头）的扫描任务，engine 选择扫描引擎，为空时使用 nuclei
+func (a *App) CreateScanTaskWithOptions(name string, targetIDs []int, targetTag string, strategy string, templates []string, options *models.ScanOptions, engine string) (*models.ScanTask, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.scanHandler.CreateWithOptions(a.ctx, name, targetIDs, targetTag, strategy, templates, options, engine)
+}
+
+// GetDefaultScanOptions 获取全局调优默认值
+func (a *App) GetDefaultScanOptions() (models.ScanOptions, error) {
+	if err := a.checkInitialized(); err != nil {
+		return models.ScanOptions{}, err
+	}
+	return a.scanHandler.DefaultOptions(), nil
+}
+
+// GetScanEngines 获取可选的扫描引擎
+func (a *App) GetScanEngines() ([]models.ScanEngine, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.scanHandler.Engines(), nil
+}
+
 // StartScan 启动扫描任务
 func (a *App) StartScan(taskID int) error {
 	if err := a.checkInitialized(); err != nil {
@@ -97,6 +129,38 @@ func (a *App) StopScan(taskID int) error {
 	return a.scanHandler.Stop(a.ctx, taskID)
 }
 
+// PauseScan 暂停扫描任务
+func (a *App) PauseScan(taskID int) error {
+	if err := a.checkInitialized(); err != nil {
+		return err
+	}
+	return a.scanHandler.Pause(a.ctx, taskID)
+}
+
+// ResumeScan 恢复暂停的扫描任务，或重新启动被中断的扫描任务
+func (a *App) ResumeScan(taskID int) error {
+	if err := a.checkInitialized(); err != nil {
+		return err
+	}
+	return a.scanHandler.Resume(a.ctx, taskID)
+}
+
+// GetScanQueue 获取排队中的扫描任务
+func (a *App) GetScanQueue() ([]*models.ScanTask, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.scanHandler.GetQueue(a.ctx)
+}
+
+// ReorderScanQueue 调整扫描队列顺序，taskIDs 中的任务依次排在队首
+func (a *App) ReorderScanQueue(taskIDs []int) error {
+	if err := a.checkInitialized(); err != nil {
+		return err
+	}
+	return a.scanHandler.ReorderQueue(a.ctx, taskIDs)
+}
+
 // UpdateScanTaskStatus 更新扫描任务状态
 func (a *App) UpdateScanTaskStatus(taskID int, status string) error {
 	if err := a.checkInitialized(); err != nil {
@@ -121,6 +185,22 @@ func (a *App) GetScanLogs(taskID int) ([]*models.ScanLog, error) {
 	return a.scanHandler.GetLogs(a.ctx, taskID)
 }
 
+// GetScanTemplateManifest 获取扫描任务运行的模板清单
+func (a *App) GetScanTemplateManifest(taskID int) ([]models.TemplateManifestEntry, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.scanHandler.GetTemplateManifest(a.ctx, taskID)
+}
+
+// CompareScanTemplateManifests 比较两次扫描运行的模板，baseTaskID 为 0 时与同一目标的上一次扫描比较
+func (a *App) CompareScanTemplateManifests(taskID, baseTaskID int) (*models.TemplateManifestDiff, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.scanHandler.CompareTemplateManifests(a.ctx, taskID, baseTaskID)
+}
+
 // DeleteScanTask 删除扫描任务
 func (a *App) DeleteScanTask(id int) error {
 	if err := a.checkInitialized(); err != nil {
@@ -351,6 +431,14 @@ func (a *App) ToggleCustomTemplate(id int, enabled bool) error {
 	return a.templateHandler.ToggleCustomTemplate(a.ctx, id, enabled)
 }
 
+// BulkToggleBuiltinTemplates 按分类、标签或作者批量启用或禁用内置模板
+func (a *App) BulkToggleBuiltinTemplates(req *models.TemplateBulkToggleRequest) (int, error) {
+	if err := a.checkInitialized(); err != nil {
+		return 0, err
+	}
+	return a.templateHandler.BulkToggleBuiltin(a.ctx, req)
+}
+
 // GetAllCustomTemplates 获取所有自定义模板
 func (a *App) GetAllCustomTemplates() ([]*models.Template, error) {
 	if err := a.checkInitialized(); err != nil {
@@ -368,13 +456,21 @@ func (a *App) GetCustomTemplateByID(id int) (*models.Template, error) {
 }
 
 // ValidateCustomTemplate 验证自定义模板
-func (a *App) ValidateCustomTemplate(content string) (bool, []string, error) {
+func (a *App) ValidateCustomTemplate(content string) (*models.TemplateValidationResult, error) {
 	if err := a.checkInitialized(); err != nil {
-		return false, nil, err
+		return nil, err
 	}
 	return a.templateHandler.ValidateCustomTemplate(a.ctx, content)
 }
 
+// TestTemplate 对指定 URL 试运行模板，结果不保存为漏洞
+func (a *App) TestTemplate(req *models.TemplateTestRequest) (*models.TemplateTestResult, error) {
+	if err := a.checkInitialized(); err != nil {
+		return nil, err
+	}
+	return a.templateHandler.TestTemplate(a.ctx, req)
+}
+
 // GetCustomTemplatesStats 获取自定义模板统计
 func (a *App) GetCustomTemplatesStats() (map[string]interface{}, error) {
 	if err := a.checkInitialized(); err != nil {
@@ -535,6 +631,46 @@ func (a *App) CreatePortScanTask(target string, ports []int, timeout, batchSize
 	return a.portScanHandler.CreateTask(a.ctx, target, ports, timeout, batchSize)
 }
 
+// CreatePortScanTasks 按目标表达式与端口表达式创建端口扫描任务，每个主机一个任务
+func (a *App) CreatePortScanTasks(targets, ports string, timeout, batchSize int) ([]int, error) {
+	if a.portScanHandler == nil {
+		return nil, errors.New("port scan handler not initialized")
+	}
+	return a.portScanHandler.CreateTasks(a.ctx, targets, ports, timeout, batchSize)
+}
+
+// GetPortProfiles 获取预置端口配置
+func (a *App) GetPortProfiles() (map[string]string, error) {
+	if a.portScanHandler == nil {
+		return nil, errors.New("port scan handler not initialized")
+	}
+	return a.portScanHandler.GetPortProfiles(), nil
+}
+
+// StartPortScanTask 启动端口扫描任务
+func (a *App) StartPortScanTask(taskID int) error {
+	if a.portScanHandler == nil {
+		return errors.New("port scan handler not initialized")
+	}
+	return a.portScanHandler.StartTask(a.ctx, taskID)
+}
+
+// StopPortScanTask 停止端口扫描任务
+func (a *App) StopPortScanTask(taskID int) error {
+	if a.portScanHandler == nil {
+		return errors.New("port scan handler not initialized")
+	}
+	return a.portScanHandler.StopTask(a.ctx, taskID)
+}
+
+// GetPortScanTask 获取端口扫描任务
+func (a *App) GetPortScanTask(taskID int) (*models.PortScanTask, error) {
+	if a.portScanHandler == nil {
+		return nil, errors.New("port scan handler not initialized")
+	}
+	return a.portScanHandler.GetTaskByID(a.ctx, taskID)
+}
+
 // GetPortScanResults 获取端口扫描结果
 func (a *App) GetPortScanResults(taskID int) ([]*models.PortScanResult, error) {
 	if a.portScanHandler == nil {
@@ -543,6 +679,108 @@ func (a *App) GetPortScanResults(taskID int) ([]*models.PortScanResult, error) {
 	return a.portScanHandler.GetResults(a.ctx, taskID)
 }
 
+// ==================== Promotion ====================
+
+// PromoteTargets 将端口扫描或子域名爆破结果提升为扫描目标
+func (a *App) PromoteTargets(req *models.PromoteTargetsRequest) (*models.PromoteTargetsResult, error) {
+	if a.promotionHandler == nil {
+		return nil, errors.New("promotion handler not initialized")
+	}
+	return a.promotionHandler.PromoteTargets(a.ctx, req)
+}
+
+// ==================== Auth Profile ====================
+
+// GetAuthProfiles 获取目标的认证配置，不返回凭据内容
+func (a *App) GetAuthProfiles(targetID int) ([]*models.AuthProfile, error) {
+	if a.authHandler == nil {
+		return nil, errors.New("auth handler not initialized")
+	}
+	return a.authHandler.GetByTargetID(a.ctx, targetID)
+}
+
+// CreateAuthProfile 创建认证配置，凭据加密保存
+func (a *App) CreateAuthProfile(req *models.AuthProfileRequest) (*models.AuthProfile, error) {
+	if a.authHandler == nil {
+		return nil, errors.New("auth handler not initialized")
+	}
+	return a.authHandler.Create(a.ctx, req)
+}
+
+// UpdateAuthProfile 更新认证配置，未提供凭据时保留原有凭据
+func (a *App) UpdateAuthProfile(id int, req *models.AuthProfileRequest) (*models.AuthProfile, error) {
+	if a.authHandler == nil {
+		return nil, errors.New("auth handler not initialized")
+	}
+	return a.authHandler.Update(a.ctx, id, req)
+}
+
+// DeleteAuthProfile 删除认证配置
+func (a *App) DeleteAuthProfile(id int) error {
+	if a.authHandler == nil {
+		return errors.New("auth handler not initialized")
+	}
+	return a.authHandler.Delete(a.ctx, id)
+}
+
+// ==================== Schedule ====================
+
+// GetAllScanSchedules 获取所有扫描计划
+func (a *App) GetAllScanSchedules() ([]*models.ScanSchedule, error) {
+	if a.scheduleHandler == nil {
+		return nil, errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.GetAll(a.ctx)
+}
+
+// GetScanScheduleByID 根据 ID 获取扫描计划
+func (a *App) GetScanScheduleByID(id int) (*models.ScanSchedule, error) {
+	if a.scheduleHandler == nil {
+		return nil, errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.GetByID(a.ctx, id)
+}
+
+// CreateScanSchedule 创建扫描计划
+func (a *App) CreateScanSchedule(req *models.ScanScheduleRequest) (*models.ScanSchedule, error) {
+	if a.scheduleHandler == nil {
+		return nil, errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.Create(a.ctx, req)
+}
+
+// UpdateScanSchedule 更新扫描计划
+func (a *App) UpdateScanSchedule(id int, req *models.ScanScheduleRequest) (*models.ScanSchedule, error) {
+	if a.scheduleHandler == nil {
+		return nil, errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.Update(a.ctx, id, req)
+}
+
+// SetScanScheduleEnabled 启用或停用扫描计划
+func (a *App) SetScanScheduleEnabled(id int, enabled bool) error {
+	if a.scheduleHandler == nil {
+		return errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.SetEnabled(a.ctx, id, enabled)
+}
+
+// DeleteScanSchedule 删除扫描计划
+func (a *App) DeleteScanSchedule(id int) error {
+	if a.scheduleHandler == nil {
+		return errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.Delete(a.ctx, id)
+}
+
+// RunScanScheduleNow 立即运行一次扫描计划
+func (a *App) RunScanScheduleNow(id int) (*models.ScanTask, error) {
+	if a.scheduleHandler == nil {
+		return nil, errors.New("schedule handler not initialized")
+	}
+	return a.scheduleHandler.RunNow(a.ctx, id)
+}
+
 // ==================== Domain Brute ====================
 
 // CreateDomainBruteTask 创建域名暴力破解任务
@@ -553,6 +791,30 @@ func (a *App) CreateDomainBruteTask(domain string, wordlist []string, timeout, b
 	return a.domainBruteHandler.CreateTask(a.ctx, domain, wordlist, timeout, batchSize)
 }
 
+// StartDomainBruteTask 启动域名暴力破解任务
+func (a *App) StartDomainBruteTask(taskID int) error {
+	if a.domainBruteHandler == nil {
+		return errors.New("domain brute handler not initialized")
+	}
+	return a.domainBruteHandler.StartTask(a.ctx, taskID)
+}
+
+// StopDomainBruteTask 停止域名暴力破解任务
+func (a *App) StopDomainBruteTask(taskID int) error {
+	if a.domainBruteHandler == nil {
+		return errors.New("domain brute handler not initialized")
+	}
+	return a.domainBruteHandler.StopTask(a.ctx, taskID)
+}
+
+// GetDomainBruteTask 获取域名暴力破解任务
+func (a *App) GetDomainBruteTask(taskID int) (*models.DomainBruteTask, error) {
+	if a.domainBruteHandler == nil {
+		return nil, errors.New("domain brute handler not initialized")
+	}
+	return a.domainBruteHandler.GetTaskByID(a.ctx, taskID)
+}
+
 // GetDomainBruteResults 获取域名暴力破解结果
 func (a *App) GetDomainBruteResults(taskID int) ([]*models.DomainBruteResult, error) {
 	if a.domainBruteHandler == nil {
@@ -572,11 +834,11 @@ func (a *App) GetAllBruteTasks() ([]*models.BruteTask, error) {
 }
 
 // CreateBruteTask 创建暴力破解任务
-func (a *App) CreateBruteTask(name string, requestID int, bruteType string) (int, error) {
+func (a *App) CreateBruteTask(req *models.CreateBruteTaskRequest) (int, error) {
 	if a.bruteHandler == nil {
 		return 0, errors.New("brute handler not initialized")
 	}
-	return a.bruteHandler.CreateTask(a.ctx, name, requestID, bruteType)
+	return a.bruteHandler.CreateTask(a.ctx, req)
 }
 
 // DeleteBruteTask 删除暴力破解任务
@@ -611,6 +873,14 @@ func (a *App) StartBruteTask(taskID int) error {
 	return a.bruteHandler.StartBruteTask(a.ctx, taskID)
 }
 
+// StopBruteTask 停止暴力破解任务
+func (a *App) StopBruteTask(taskID int) error {
+	if a.bruteHandler == nil {
+		return errors.New("brute handler not initialized")
+	}
+	return a.bruteHandler.StopBruteTask(a.ctx, taskID)
+}
+
 // GetBruteTaskResults 获取暴力破解任务结果
 func (a *App) GetBruteTaskResults(taskID int) ([]*models.BruteResult, error) {
 	if a.bruteHandler == nil {
@@ -619,6 +889,14 @@ func (a *App) GetBruteTaskResults(taskID int) ([]*models.BruteResult, error) {
 	return a.bruteHandler.GetBruteTaskResults(a.ctx, taskID)
 }
 
+// GetBruteTaskHits 获取命中规则的暴力破解结果
+func (a *App) GetBruteTaskHits(taskID int) ([]*models.BruteResult, error) {
+	if a.bruteHandler == nil {
+		return nil, errors.New("brute handler not initialized")
+	}
+	return a.bruteHandler.GetBruteTaskHits(a.ctx, taskID)
+}
+
 // ==================== Reports ====================
 
 // GetAllReports 获取所有报告
@@ -0,0 +1,422 @@
+package brute
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+)
+
+// 攻击模式，与 Burp Intruder 保持一致
+const (
+	// AttackSniper 单个载荷集，依次替换每个位置，其余位置保持默认值
+	AttackSniper = "sniper"
+	// AttackBatteringRam 单个载荷集，同一载荷同时填入全部位置
+	AttackBatteringRam = "battering_ram"
+	// AttackPitchfork 每个位置一个载荷集，按下标同步取值
+	AttackPitchfork = "pitchfork"
+	// AttackClusterBomb 每个位置一个载荷集，笛卡尔积组合
+	AttackClusterBomb = "cluster_bomb"
+)
+
+// MaxAttempts 单个任务允许的最大尝试次数
+const MaxAttempts = math.MaxInt32
+
+// IsValidAttackMode 检查攻击模式是否有效
+func IsValidAttackMode(mode string) bool {
+	switch mode {
+	case AttackSniper, AttackBatteringRam, AttackPitchfork, AttackClusterBomb:
+		return true
+	}
+	return false
+}
+
+// ValidatePayloadSetCount 检查攻击模式与载荷集数量是否匹配
+func ValidatePayloadSetCount(mode string, sets int) error {
+	switch mode {
+	case AttackSniper, AttackBatteringRam:
+		if sets != 1 {
+			return errors.InvalidInput(fmt.Sprintf("%s mode requires exactly one payload set, got %d", mode, sets))
+		}
+	case AttackPitchfork, AttackClusterBomb:
+		if sets < 1 {
+			return errors.InvalidInput(fmt.Sprintf("%s mode requires one payload set per position", mode))
+		}
+	default:
+		return errors.InvalidInput(fmt.Sprintf("invalid attack mode: %s", mode))
+	}
+	return nil
+}
+
+// ValidateAttack 检查攻击模式、位置数和载荷集数量是否匹配
+func ValidateAttack(mode string, positions, sets int) error {
+	if err := ValidatePayloadSetCount(mode, sets); err != nil {
+		return err
+	}
+	if positions < 1 {
+		return errors.InvalidInput("no payload positions marked in request")
+	}
+	if (mode == AttackPitchfork || mode == AttackClusterBomb) && sets != positions {
+		return errors.InvalidInput(fmt.Sprintf("%s mode requires one payload set per position: %d positions, %d payload sets", mode, positions, sets))
+	}
+	return nil
+}
+
+// NewSource 根据攻击模式创建尝试来源，每个载荷集对应一个生成器
+func NewSource(mode string, tmpl *RequestTemplate, sets []Generator) (Source, error) {
+	if tmpl == nil {
+		return nil, errors.InvalidInput("request template is required")
+	}
+	if err := ValidateAttack(mode, len(tmpl.Positions), len(sets)); err != nil {
+		return nil, err
+	}
+	for i, set := range sets {
+		if set.Count() == 0 {
+			return nil, errors.InvalidInput(fmt.Sprintf("payload set %d is empty", i+1))
+		}
+	}
+
+	names := make([]string, len(tmpl.Positions))
+	for i, p := range tmpl.Positions {
+		names[i] = p.Location
+	}
+
+	var src Source
+	switch mode {
+	case AttackSniper:
+		src = &sniperSource{gen: sets[0], defaults: tmpl.Defaults(), names: names}
+	case AttackBatteringRam:
+		src = &batteringRamSource{gen: sets[0], positions: len(names), paramName: strings.Join(names, ",")}
+	case AttackPitchfork:
+		src = &pitchforkSource{sets: sets, paramName: strings.Join(names, ",")}
+	case AttackClusterBomb:
+		src = &clusterBombSource{sets: sets, paramName: strings.Join(names, ",")}
+	}
+
+	if src.Total() < 0 || src.Total() > MaxAttempts {
+		return nil, errors.InvalidInput(fmt.Sprintf("too many payload combinations, limit is %d", MaxAttempts))
+	}
+	return src, nil
+}
+
+// iteratorState 记录迭代器错误，供各来源共用
+type iteratorState struct {
+	err error
+}
+
+func (s *iteratorState) Err() error { return s.err }
+
+// open 打开迭代器，失败时记录错误
+func (s *iteratorState) open(gen Generator) Iterator {
+	if s.err != nil {
+		return nil
+	}
+	it, err := gen.Open()
+	if err != nil {
+		s.err = err
+		return nil
+	}
+	return it
+}
+
+// exhausted 关闭已结束的迭代器并记录其错误
+func (s *iteratorState) exhausted(it Iterator) {
+	if err := it.Err(); err != nil && s.err == nil {
+		s.err = err
+	}
+	it.Close()
+}
+
+// sniperSource 依次在每个位置上遍历载荷
+type sniperSource struct {
+	iteratorState
+	gen      Generator
+	defaults []string
+	names    []string
+	position int
+	it       Iterator
+	next     int
+}
+
+func (s *sniperSource) Total() int {
+	if s.gen.Count() > MaxAttempts/len(s.names) {
+		return -1
+	}
+	return s.gen.Count() * len(s.names)
+}
+
+func (s *sniperSource) Next() (*Attempt, bool) {
+	for s.position < len(s.names) {
+		if s.it == nil {
+			if s.it = s.open(s.gen); s.it == nil {
+				return nil, false
+			}
+		}
+
+		payload, ok := s.it.Next()
+		if !ok {
+			s.exhausted(s.it)
+			s.it = nil
+			if s.err != nil {
+				return nil, false
+			}
+			s.position++
+			continue
+		}
+
+		values := make([]string, len(s.defaults))
+		copy(values, s.defaults)
+		values[s.position] = payload
+
+		attempt := &Attempt{
+			Seq:       s.next,
+			Values:    values,
+			Payload:   payload,
+			ParamName: s.names[s.position],
+		}
+		s.next++
+		return attempt, true
+	}
+	return nil, false
+}
+
+func (s *sniperSource) Close() error {
+	if s.it != nil {
+		s.it.Close()
+		s.it = nil
+	}
+	return nil
+}
+
+// batteringRamSource 将每个载荷同时填入全部位置
+type batteringRamSource struct {
+	iteratorState
+	gen       Generator
+	positions int
+	paramName string
+	it        Iterator
+	next      int
+	done      bool
+}
+
+func (s *batteringRamSource) Total() int {
+	return s.gen.Count()
+}
+
+func (s *batteringRamSource) Next() (*Attempt, bool) {
+	if s.done {
+		return nil, false
+	}
+	if s.it == nil {
+		if s.it = s.open(s.gen); s.it == nil {
+			return nil, false
+		}
+	}
+
+	payload, ok := s.it.Next()
+	if !ok {
+		s.exhausted(s.it)
+		s.it = nil
+		s.done = true
+		return nil, false
+	}
+
+	values := make([]string, s.positions)
+	for i := range values {
+		values[i] = payload
+	}
+	attempt := &Attempt{
+		Seq:       s.next,
+		Values:    values,
+		Payload:   payload,
+		ParamName: s.paramName,
+	}
+	s.next++
+	return attempt, true
+}
+
+func (s *batteringRamSource) Close() error {
+	if s.it != nil {
+		s.it.Close()
+		s.it = nil
+	}
+	return nil
+}
+
+// pitchforkSource 按下标同步遍历各载荷集，长度取最短的载荷集
+type pitchforkSource struct {
+	iteratorState
+	sets      []Generator
+	paramName string
+	its       []Iterator
+	next      int
+	done      bool
+}
+
+func (s *pitchforkSource) Total() int {
+	total := s.sets[0].Count()
+	for _, set := range s.sets[1:] {
+		if set.Count() < total {
+			total = set.Count()
+		}
+	}
+	return total
+}
+
+func (s *pitchforkSource) Next() (*Attempt, bool) {
+	if s.done {
+		return nil, false
+	}
+	if s.its == nil {
+		for _, set := range s.sets {
+			it := s.open(set)
+			if it == nil {
+				s.finish()
+				return nil, false
+			}
+			s.its = append(s.its, it)
+		}
+	}
+
+	values := make([]string, len(s.its))
+	for i, it := range s.its {
+		v, ok := it.Next()
+		if !ok {
+			s.finish()
+			return nil, false
+		}
+		values[i] = v
+	}
+
+	attempt := &Attempt{
+		Seq:       s.next,
+		Values:    values,
+		Payload:   strings.Join(values, ","),
+		ParamName: s.paramName,
+	}
+	s.next++
+	return attempt, true
+}
+
+// finish 关闭全部迭代器
+func (s *pitchforkSource) finish() {
+	for _, it := range s.its {
+		s.exhausted(it)
+	}
+	s.its = nil
+	s.done = true
+}
+
+func (s *pitchforkSource) Close() error {
+	for _, it := range s.its {
+		it.Close()
+	}
+	s.its = nil
+	return nil
+}
+
+// clusterBombSource 遍历各载荷集的笛卡尔积，第一个位置变化最快
+type clusterBombSource struct {
+	iteratorState
+	sets      []Generator
+	paramName string
+	its       []Iterator
+	values    []string
+	next      int
+	done      bool
+}
+
+func (s *clusterBombSource) Total() int {
+	total := 1
+	for _, set := range s.sets {
+		if total > MaxAttempts/set.Count() {
+			return -1
+		}
+		total *= set.Count()
+	}
+	return total
+}
+
+func (s *clusterBombSource) Next() (*Attempt, bool) {
+	if s.done {
+		return nil, false
+	}
+
+	if s.its == nil {
+		s.its = make([]Iterator, len(s.sets))
+		s.values = make([]string, len(s.sets))
+		for i := range s.sets {
+			if !s.reset(i) {
+				return nil, false
+			}
+		}
+	} else if !s.advance() {
+		return nil, false
+	}
+
+	values := make([]string, len(s.values))
+	copy(values, s.values)
+	attempt := &Attempt{
+		Seq:       s.next,
+		Values:    values,
+		Payload:   strings.Join(values, ","),
+		ParamName: s.paramName,
+	}
+	s.next++
+	return attempt, true
+}
+
+// advance 像里程表一样进位到下一个组合
+func (s *clusterBombSource) advance() bool {
+	for i := range s.its {
+		if v, ok := s.its[i].Next(); ok {
+			s.values[i] = v
+			return true
+		}
+		s.exhausted(s.its[i])
+		s.its[i] = nil
+		if s.err != nil || i == len(s.its)-1 {
+			s.done = true
+			s.Close()
+			return false
+		}
+		if !s.reset(i) {
+			return false
+		}
+	}
+	return false
+}
+
+// reset 重新打开第 i 个位置的迭代器并取第一个值
+func (s *clusterBombSource) reset(i int) bool {
+	it := s.open(s.sets[i])
+	if it == nil {
+		s.done = true
+		s.Close()
+		return false
+	}
+	v, ok := it.Next()
+	if !ok {
+		s.exhausted(it)
+		if s.err == nil {
+			s.err = errors.InvalidInput(fmt.Sprintf("payload set %d is empty", i+1))
+		}
+		s.done = true
+		s.Close()
+		return false
+	}
+	s.its[i] = it
+	s.values[i] = v
+	return true
+}
+
+func (s *clusterBombSource) Close() error {
+	for i, it := range s.its {
+		if it != nil {
+			it.Close()
+			s.its[i] = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package brute
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// lists 将载荷列表转换为生成器
+func lists(sets ...[]string) []Generator {
+	gens := make([]Generator, len(sets))
+	for i, set := range sets {
+		gens[i] = NewListGenerator(set)
+	}
+	return gens
+}
+
+// collect 读取来源中的全部尝试
+func collect(t *testing.T, src Source) []*Attempt {
+	t.Helper()
+	var attempts []*Attempt
+	for {
+		attempt, ok := src.Next()
+		if !ok {
+			break
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := src.Err(); err != nil {
+		t.Fatalf("source error: %v", err)
+	}
+	if len(attempts) != src.Total() {
+		t.Fatalf("got %d attempts, Total() = %d", len(attempts), src.Total())
+	}
+	for i, a := range attempts {
+		if a.Seq != i {
+			t.Fatalf("attempt %d has Seq %d", i, a.Seq)
+		}
+	}
+	return attempts
+}
+
+func twoPositionTemplate(t *testing.T) *RequestTemplate {
+	t.Helper()
+	tmpl, err := ParseRequest(&models.HttpRequest{
+		Method: "POST",
+		URL:    "http://example.com/login",
+		Body:   "user=§admin§&pass=§x§",
+	})
+	if err != nil {
+		t.Fatalf("ParseRequest() failed: %v", err)
+	}
+	return tmpl
+}
+
+// TestNewSource_Modes 测试各攻击模式的载荷组合
+func TestNewSource_Modes(t *testing.T) {
+	tmpl := twoPositionTemplate(t)
+
+	tests := []struct {
+		name string
+		mode string
+		sets [][]string
+		want [][]string
+	}{
+		{
+			name: "sniper",
+			mode: AttackSniper,
+			sets: [][]string{{"a", "b"}},
+			want: [][]string{{"a", "x"}, {"b", "x"}, {"admin", "a"}, {"admin", "b"}},
+		},
+		{
+			name: "battering ram",
+			mode: AttackBatteringRam,
+			sets: [][]string{{"a", "b"}},
+			want: [][]string{{"a", "a"}, {"b", "b"}},
+		},
+		{
+			name: "pitchfork",
+			mode: AttackPitchfork,
+			sets: [][]string{{"u1", "u2", "u3"}, {"p1", "p2"}},
+			want: [][]string{{"u1", "p1"}, {"u2", "p2"}},
+		},
+		{
+			name: "cluster bomb",
+			mode: AttackClusterBomb,
+			sets: [][]string{{"u1", "u2"}, {"p1", "p2", "p3"}},
+			want: [][]string{
+				{"u1", "p1"}, {"u2", "p1"},
+				{"u1", "p2"}, {"u2", "p2"},
+				{"u1", "p3"}, {"u2", "p3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewSource(tt.mode, tmpl, lists(tt.sets...))
+			if err != nil {
+				t.Fatalf("NewSource() failed: %v", err)
+			}
+			if src.Total() != len(tt.want) {
+				t.Fatalf("Total() = %d, want %d", src.Total(), len(tt.want))
+			}
+
+			attempts := collect(t, src)
+			for i, a := range attempts {
+				if !reflect.DeepEqual(a.Values, tt.want[i]) {
+					t.Errorf("attempt %d values = %v, want %v", i, a.Values, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestNewSource_Sniper_ParamName 测试 sniper 模式记录当前位置
+func TestNewSource_Sniper_ParamName(t *testing.T) {
+	tmpl, err := ParseRequest(&models.HttpRequest{
+		Method:  "GET",
+		URL:     "http://example.com/?q=§1§",
+		Headers: map[string]string{"X-Token": "§t§"},
+	})
+	if err != nil {
+		t.Fatalf("ParseRequest() failed: %v", err)
+	}
+
+	src, err := NewSource(AttackSniper, tmpl, lists([]string{"p"}))
+	if err != nil {
+		t.Fatalf("NewSource() failed: %v", err)
+	}
+	attempts := collect(t, src)
+	if attempts[0].ParamName != "url" || attempts[1].ParamName != "header:X-Token" {
+		t.Errorf("ParamNames = %s, %s", attempts[0].ParamName, attempts[1].ParamName)
+	}
+}
+
+// TestNewSource_Invalid 测试不匹配的模式与载荷集
+func TestNewSource_Invalid(t *testing.T) {
+	tmpl := twoPositionTemplate(t)
+
+	tests := []struct {
+		name string
+		mode string
+		sets [][]string
+	}{
+		{"未知模式", "unknown", [][]string{{"a"}}},
+		{"sniper 多个载荷集", AttackSniper, [][]string{{"a"}, {"b"}}},
+		{"battering ram 无载荷集", AttackBatteringRam, nil},
+		{"pitchfork 载荷集不足", AttackPitchfork, [][]string{{"a"}}},
+		{"cluster bomb 载荷集过多", AttackClusterBomb, [][]string{{"a"}, {"b"}, {"c"}}},
+		{"空载荷集", AttackClusterBomb, [][]string{{"a"}, {}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSource(tt.mode, tmpl, lists(tt.sets...)); err == nil {
+				t.Errorf("NewSource(%s) should return error", tt.mode)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package brute
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultConcurrency 默认并发数
+	DefaultConcurrency = 10
+	// DefaultTimeout 默认单个请求超时
+	DefaultTimeout = 10 * time.Second
+	// maxResponseBodySize 读取响应体的上限
+	maxResponseBodySize = 1 << 20 // 1MB
+)
+
+// Attempt 一次攻击尝试
+type Attempt struct {
+	Seq       int      // 序号，从 0 开始
+	Values    []string // 每个位置的取值
+	Payload   string   // 用于展示和持久化的载荷
+	ParamName string   // 被替换的位置
+}
+
+// Source 攻击尝试来源
+type Source interface {
+	// Total 尝试总数
+	Total() int
+	// Next 返回下一次尝试，结束或出错时返回 false
+	Next() (*Attempt, bool)
+	// Err 返回生成尝试时遇到的错误
+	Err() error
+	// Close 释放载荷迭代器
+	Close() error
+}
+
+// Result 一次尝试的结果
+type Result struct {
+	Attempt    *Attempt
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+	Length     int
+	Duration   time.Duration
+	Err        error
+}
+
+// Config 引擎配置
+type Config struct {
+	Concurrency int
+	Timeout     time.Duration
+}
+
+// Engine HTTP 暴力破解引擎
+type Engine struct {
+	client      *http.Client
+	concurrency int
+}
+
+// NewEngine 创建暴力破解引擎
+func NewEngine(cfg Config) *Engine {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	return &Engine{
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			// 暴力破解需要观察原始响应（如登录后的 302），不跟随重定向
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				TLSClientConfig:     &tls.Config{InsecureSkipVerify: true}, // 目标常为自签名证书
+				MaxIdleConnsPerHost: cfg.Concurrency,
+			},
+		},
+		concurrency: cfg.Concurrency,
+	}
+}
+
+// Run 执行暴力破解，onResult 在调用者的 goroutine 中串行调用
+// context 取消时返回 ctx.Err()，载荷读取失败时返回 src.Err()
+func (e *Engine) Run(ctx context.Context, tmpl *RequestTemplate, src Source, onResult func(*Result)) error {
+	jobs := make(chan *Attempt)
+	results := make(chan *Result)
+
+	// 生产者
+	go func() {
+		defer close(jobs)
+		defer src.Close()
+		for {
+			attempt, ok := src.Next()
+			if !ok {
+				return
+			}
+			select {
+			case jobs <- attempt:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// 工作池
+	var wg sync.WaitGroup
+	wg.Add(e.concurrency)
+	for i := 0; i < e.concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for attempt := range jobs {
+				result := e.send(ctx, tmpl, attempt)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		onResult(result)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return src.Err()
+}
+
+// Baseline 使用各位置的默认值发送一次基线请求
+func (e *Engine) Baseline(ctx context.Context, tmpl *RequestTemplate) *Result {
+	return e.send(ctx, tmpl, &Attempt{Seq: -1, Values: tmpl.Defaults()})
+}
+
+// send 发送单个请求
+func (e *Engine) send(ctx context.Context, tmpl *RequestTemplate, attempt *Attempt) *Result {
+	result := &Result{Attempt: attempt}
+
+	rendered, err := tmpl.Render(attempt.Values)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	u, err := url.Parse(rendered.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		result.Err = fmt.Errorf("invalid url: %s", rendered.URL)
+		return result
+	}
+
+	var body io.Reader
+	if rendered.Body != "" {
+		body = strings.NewReader(rendered.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, rendered.Method, u.String(), body)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	for name, value := range rendered.Headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	if err != nil {
+		result.Duration = time.Since(start)
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, io.LimitReader(resp.Body, maxResponseBodySize))
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.StatusCode = resp.StatusCode
+	result.Headers = resp.Header
+	result.Body = buf.Bytes()
+	result.Length = buf.Len()
+	return result
+}
@@ -0,0 +1,103 @@
+package brute
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// TestEngine_Run 测试引擎执行所有尝试
+func TestEngine_Run(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "pass=letmein" && r.Header.Get("X-User") == "letmein" {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("welcome"))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	tmpl, err := ParseRequest(&models.HttpRequest{
+		Method:  "POST",
+		URL:     server.URL + "/login",
+		Headers: map[string]string{"X-User": "§u§"},
+		Body:    "pass=§p§",
+	})
+	if err != nil {
+		t.Fatalf("ParseRequest() failed: %v", err)
+	}
+
+	payloads := []string{"a", "b", "letmein", "c"}
+	src, err := NewSource(AttackBatteringRam, tmpl, lists(payloads))
+	if err != nil {
+		t.Fatalf("NewSource() failed: %v", err)
+	}
+	if src.Total() != len(payloads) {
+		t.Fatalf("Total() = %d, want %d", src.Total(), len(payloads))
+	}
+
+	engine := NewEngine(Config{Concurrency: 3, Timeout: 5 * time.Second})
+	results := make(map[string]*Result)
+	err = engine.Run(context.Background(), tmpl, src, func(r *Result) {
+		results[r.Attempt.Payload] = r
+	})
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	if len(results) != len(payloads) {
+		t.Fatalf("got %d results, want %d", len(results), len(payloads))
+	}
+	hit := results["letmein"]
+	if hit.Err != nil || hit.StatusCode != http.StatusOK || string(hit.Body) != "welcome" {
+		t.Errorf("hit result = %+v", hit)
+	}
+	if results["a"].StatusCode != http.StatusForbidden {
+		t.Errorf("miss status = %d, want 403", results["a"].StatusCode)
+	}
+	if hit.Attempt.ParamName != "header:X-User,body" {
+		t.Errorf("ParamName = %s", hit.Attempt.ParamName)
+	}
+}
+
+// TestEngine_Run_Cancel 测试取消执行
+func TestEngine_Run_Cancel(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	tmpl, _ := ParseRequest(&models.HttpRequest{Method: "GET", URL: server.URL + "/?q=§x§"})
+	payloads := make([]string, 1000)
+	for i := range payloads {
+		payloads[i] = "p"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	src, _ := NewSource(AttackSniper, tmpl, lists(payloads))
+	engine := NewEngine(Config{Concurrency: 2})
+	count := 0
+	err := engine.Run(ctx, tmpl, src, func(r *Result) {
+		count++
+		if count == 2 {
+			cancel()
+		}
+	})
+
+	if err != context.Canceled {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if int(hits.Load()) >= len(payloads) {
+		t.Error("Run() should stop sending after cancel")
+	}
+}
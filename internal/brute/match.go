@@ -0,0 +1,224 @@
+package brute
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// 匹配规则类型
+const (
+	MatchStatus = "status" // 状态码
+	MatchLength = "length" // 与基线的长度差
+	MatchBody   = "body"   // 响应体内容
+	MatchHeader = "header" // 响应头内容
+	MatchTime   = "time"   // 响应时间
+)
+
+// 匹配规则组合方式
+const (
+	MatchAny = "any" // 任一规则命中即成功，返回第一条命中的规则
+	MatchAll = "all" // 全部规则命中才成功
+)
+
+// rule 编译后的匹配规则
+type rule struct {
+	models.BruteMatchRule
+	label string
+	re    *regexp.Regexp
+}
+
+// Matcher 根据规则判定结果是否命中
+type Matcher struct {
+	rules    []rule
+	mode     string
+	baseline *Result
+}
+
+// NewMatcher 编译匹配规则，mode 为空时按 any 组合
+func NewMatcher(rules []models.BruteMatchRule, mode string) (*Matcher, error) {
+	switch mode {
+	case "":
+		mode = MatchAny
+	case MatchAny, MatchAll:
+	default:
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid match mode: %s", mode))
+	}
+
+	m := &Matcher{mode: mode}
+	for i, r := range rules {
+		compiled := rule{BruteMatchRule: r}
+
+		switch r.Type {
+		case MatchStatus:
+			if len(r.Codes) == 0 {
+				return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: status codes are required", i+1))
+			}
+			codes := make([]string, len(r.Codes))
+			for j, code := range r.Codes {
+				codes[j] = strconv.Itoa(code)
+			}
+			compiled.label = "status=" + strings.Join(codes, ",")
+		case MatchLength:
+			if r.MinDelta <= 0 {
+				return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: min_delta must be positive", i+1))
+			}
+			compiled.label = fmt.Sprintf("length delta>=%d", r.MinDelta)
+		case MatchBody, MatchHeader:
+			if r.Pattern == "" {
+				return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: pattern is required", i+1))
+			}
+			if r.Regex {
+				re, err := regexp.Compile(r.Pattern)
+				if err != nil {
+					return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: invalid regex: %v", i+1, err))
+				}
+				compiled.re = re
+			}
+			target := r.Type
+			if r.Type == MatchHeader && r.Header != "" {
+				target = "header:" + r.Header
+			}
+			compiled.label = fmt.Sprintf("%s~%s", target, r.Pattern)
+		case MatchTime:
+			if r.MinTime <= 0 && r.MaxTime <= 0 {
+				return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: min_time or max_time is required", i+1))
+			}
+			if r.MaxTime > 0 && r.MinTime > r.MaxTime {
+				return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: min_time is greater than max_time", i+1))
+			}
+			compiled.label = fmt.Sprintf("time[%d,%d]ms", r.MinTime, r.MaxTime)
+		default:
+			return nil, errors.InvalidInput(fmt.Sprintf("match rule %d: invalid type: %s", i+1, r.Type))
+		}
+
+		if r.Negate {
+			compiled.label = "!" + compiled.label
+		}
+		if r.Name != "" {
+			compiled.label = r.Name
+		}
+		m.rules = append(m.rules, compiled)
+	}
+	return m, nil
+}
+
+// NeedsBaseline 是否存在依赖基线响应的规则
+func (m *Matcher) NeedsBaseline() bool {
+	for _, r := range m.rules {
+		if r.Type == MatchLength {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBaseline 设置基线响应，用于长度差规则
+// 基线请求失败时长度差规则无法判定，返回错误，由调用方终止任务
+func (m *Matcher) SetBaseline(baseline *Result) error {
+	if baseline == nil {
+		return errors.InvalidInput("baseline request returned no response")
+	}
+	if baseline.Err != nil {
+		return errors.Wrap(baseline.Err, "baseline request failed, length rules cannot be evaluated")
+	}
+	m.baseline = baseline
+	return nil
+}
+
+// Match 判定结果是否命中，出错的结果不会命中
+// any 模式返回第一条命中的规则名称，all 模式返回以 " & " 连接的全部规则名称
+func (m *Matcher) Match(r *Result) (string, bool) {
+	if r == nil || r.Err != nil || len(m.rules) == 0 {
+		return "", false
+	}
+	if m.mode == MatchAll {
+		labels := make([]string, 0, len(m.rules))
+		for _, rule := range m.rules {
+			if rule.matches(r, m.baseline) == rule.Negate {
+				return "", false
+			}
+			labels = append(labels, rule.label)
+		}
+		return strings.Join(labels, " & "), true
+	}
+	for _, rule := range m.rules {
+		if rule.matches(r, m.baseline) != rule.Negate {
+			return rule.label, true
+		}
+	}
+	return "", false
+}
+
+// matches 判断规则条件是否成立（未取反）
+func (r *rule) matches(res *Result, baseline *Result) bool {
+	switch r.Type {
+	case MatchStatus:
+		for _, code := range r.Codes {
+			if res.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	case MatchLength:
+		if baseline == nil {
+			return false
+		}
+		delta := res.Length - baseline.Length
+		if delta < 0 {
+			delta = -delta
+		}
+		return delta >= r.MinDelta
+	case MatchBody:
+		return r.contains(string(res.Body))
+	case MatchHeader:
+		return r.contains(headerText(res.Headers, r.Header))
+	case MatchTime:
+		if r.MinTime > 0 && res.Duration < time.Duration(r.MinTime)*time.Millisecond {
+			return false
+		}
+		if r.MaxTime > 0 && res.Duration > time.Duration(r.MaxTime)*time.Millisecond {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// contains 子串或正则匹配
+func (r *rule) contains(s string) bool {
+	if r.re != nil {
+		return r.re.MatchString(s)
+	}
+	return strings.Contains(s, r.Pattern)
+}
+
+// headerText 将响应头序列化为 "Name: value" 行，name 非空时只取该响应头
+func headerText(headers http.Header, name string) string {
+	if name != "" {
+		return strings.Join(headers.Values(name), "\n")
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, k := range names {
+		for _, v := range headers[k] {
+			b.WriteString(k)
+			b.WriteString(": ")
+			b.WriteString(v)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package brute
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// TestMatcher_Match 测试各类匹配规则
+func TestMatcher_Match(t *testing.T) {
+	result := &Result{
+		StatusCode: http.StatusFound,
+		Headers:    http.Header{"Location": {"/dashboard"}, "Set-Cookie": {"session=abc"}},
+		Body:       []byte("Welcome back, admin"),
+		Length:     19,
+		Duration:   1500 * time.Millisecond,
+	}
+	baseline := &Result{StatusCode: http.StatusOK, Length: 40}
+
+	tests := []struct {
+		name      string
+		rule      models.BruteMatchRule
+		wantLabel string
+		wantHit   bool
+	}{
+		{"状态码命中", models.BruteMatchRule{Type: MatchStatus, Codes: []int{301, 302}}, "status=301,302", true},
+		{"状态码未命中", models.BruteMatchRule{Type: MatchStatus, Codes: []int{200}}, "", false},
+		{"状态码取反", models.BruteMatchRule{Type: MatchStatus, Codes: []int{401}, Negate: true}, "!status=401", true},
+		{"长度差命中", models.BruteMatchRule{Type: MatchLength, MinDelta: 20}, "length delta>=20", true},
+		{"长度差未命中", models.BruteMatchRule{Type: MatchLength, MinDelta: 30}, "", false},
+		{"响应体子串", models.BruteMatchRule{Type: MatchBody, Pattern: "Welcome"}, "body~Welcome", true},
+		{"响应体正则", models.BruteMatchRule{Type: MatchBody, Pattern: `(?i)welcome\s+back`, Regex: true}, `body~(?i)welcome\s+back`, true},
+		{"响应体不包含", models.BruteMatchRule{Type: MatchBody, Pattern: "invalid", Negate: true}, "!body~invalid", true},
+		{"响应体不包含未命中", models.BruteMatchRule{Type: MatchBody, Pattern: "admin", Negate: true}, "", false},
+		{"指定响应头", models.BruteMatchRule{Type: MatchHeader, Header: "Location", Pattern: "dashboard"}, "header:Location~dashboard", true},
+		{"全部响应头", models.BruteMatchRule{Type: MatchHeader, Pattern: "Set-Cookie: session="}, "header~Set-Cookie: session=", true},
+		{"响应时间下限", models.BruteMatchRule{Type: MatchTime, MinTime: 1000}, "time[1000,0]ms", true},
+		{"响应时间上限", models.BruteMatchRule{Type: MatchTime, MaxTime: 1000}, "", false},
+		{"自定义名称", models.BruteMatchRule{Name: "login ok", Type: MatchStatus, Codes: []int{302}}, "login ok", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher([]models.BruteMatchRule{tt.rule}, MatchAny)
+			if err != nil {
+				t.Fatalf("NewMatcher() failed: %v", err)
+			}
+			if err := m.SetBaseline(baseline); err != nil {
+				t.Fatalf("SetBaseline() failed: %v", err)
+			}
+
+			label, hit := m.Match(result)
+			if hit != tt.wantHit || label != tt.wantLabel {
+				t.Errorf("Match() = (%q, %v), want (%q, %v)", label, hit, tt.wantLabel, tt.wantHit)
+			}
+		})
+	}
+}
+
+// TestMatcher_FirstMatch 测试返回第一条命中的规则，出错结果不命中
+func TestMatcher_FirstMatch(t *testing.T) {
+	m, err := NewMatcher([]models.BruteMatchRule{
+		{Name: "first", Type: MatchStatus, Codes: []int{500}},
+		{Name: "second", Type: MatchBody, Pattern: "ok"},
+		{Name: "third", Type: MatchStatus, Codes: []int{200}},
+	}, "")
+	if err != nil {
+		t.Fatalf("NewMatcher() failed: %v", err)
+	}
+	if m.NeedsBaseline() {
+		t.Error("NeedsBaseline() should be false without length rules")
+	}
+
+	if label, _ := m.Match(&Result{StatusCode: 200, Body: []byte("ok")}); label != "second" {
+		t.Errorf("Match() label = %q, want second", label)
+	}
+	if _, hit := m.Match(&Result{StatusCode: 500, Err: errors.New("timeout")}); hit {
+		t.Error("Match() should not hit a failed request")
+	}
+
+	empty, _ := NewMatcher(nil, MatchAll)
+	if _, hit := empty.Match(&Result{StatusCode: 200}); hit {
+		t.Error("Match() without rules should not hit")
+	}
+}
+
+// TestNewMatcher_Invalid 测试无效的匹配规则
+func TestNewMatcher_Invalid(t *testing.T) {
+	tests := []models.BruteMatchRule{
+		{Type: "unknown"},
+		{Type: MatchStatus},
+		{Type: MatchLength},
+		{Type: MatchBody},
+		{Type: MatchHeader, Pattern: "(", Regex: true},
+		{Type: MatchTime},
+		{Type: MatchTime, MinTime: 500, MaxTime: 100},
+	}
+
+	for _, rule := range tests {
+		if _, err := NewMatcher([]models.BruteMatchRule{rule}, MatchAny); err == nil {
+			t.Errorf("NewMatcher(%+v) should return error", rule)
+		}
+	}
+
+	valid := []models.BruteMatchRule{{Type: MatchStatus, Codes: []int{200}}}
+	if _, err := NewMatcher(valid, "either"); err == nil {
+		t.Error("NewMatcher() with unknown mode should return error")
+	}
+}
+
+// TestMatcher_MatchAll 测试 all 模式下全部规则命中才算命中
+func TestMatcher_MatchAll(t *testing.T) {
+	m, err := NewMatcher([]models.BruteMatchRule{
+		{Type: MatchStatus, Codes: []int{302}},
+		{Type: MatchBody, Pattern: "invalid", Negate: true},
+	}, MatchAll)
+	if err != nil {
+		t.Fatalf("NewMatcher() failed: %v", err)
+	}
+
+	label, hit := m.Match(&Result{StatusCode: 302, Body: []byte("welcome")})
+	if !hit || label != "status=302 & !body~invalid" {
+		t.Errorf("Match() = (%q, %v), want both rules", label, hit)
+	}
+	// 状态码命中但响应体含 invalid，整体不命中
+	if _, hit := m.Match(&Result{StatusCode: 302, Body: []byte("invalid password")}); hit {
+		t.Error("Match() should not hit when one rule fails")
+	}
+	if _, hit := m.Match(&Result{StatusCode: 200, Body: []byte("welcome")}); hit {
+		t.Error("Match() should not hit when the status rule fails")
+	}
+}
+
+// TestMatcher_SetBaseline 测试基线请求失败时返回错误
+func TestMatcher_SetBaseline(t *testing.T) {
+	m, err := NewMatcher([]models.BruteMatchRule{{Type: MatchLength, MinDelta: 10}}, MatchAny)
+	if err != nil {
+		t.Fatalf("NewMatcher() failed: %v", err)
+	}
+	if !m.NeedsBaseline() {
+		t.Fatal("NeedsBaseline() should be true with a length rule")
+	}
+	cause := errors.New("connection refused")
+	if err := m.SetBaseline(&Result{Err: cause}); err == nil || !errors.Is(err, cause) {
+		t.Errorf("SetBaseline() with a failed request = %v, want error wrapping the request error", err)
+	}
+	if err := m.SetBaseline(nil); err == nil {
+		t.Error("SetBaseline(nil) should return error")
+	}
+	if err := m.SetBaseline(&Result{StatusCode: 200, Length: 10}); err != nil {
+		t.Errorf("SetBaseline() failed: %v", err)
+	}
+}
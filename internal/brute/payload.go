@@ -0,0 +1,514 @@
+package brute
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// 载荷集类型
+const (
+	PayloadDictionary  = "dictionary"  // 字典：内联列表或文件
+	PayloadNumber      = "number"      // 数字范围
+	PayloadCharset     = "charset"     // 字符集穷举
+	PayloadDate        = "date"        // 日期范围
+	PayloadCredentials = "credentials" // 用户名 × 密码
+)
+
+// Generator 载荷生成器，按需流式产生载荷
+type Generator interface {
+	// Count 载荷总数，创建时即可确定
+	Count() int
+	// Open 打开一个新的迭代器，每次从头开始
+	Open() (Iterator, error)
+}
+
+// Iterator 载荷迭代器
+type Iterator interface {
+	// Next 返回下一个载荷，结束或出错时返回 false
+	Next() (string, bool)
+	// Err 返回迭代过程中的错误
+	Err() error
+	// Close 释放迭代器占用的资源
+	Close() error
+}
+
+// PayloadConfig 载荷集配置
+type PayloadConfig struct {
+	// dictionary
+	Payloads []string `json:"payloads,omitempty"`
+	File     string   `json:"file,omitempty"`
+
+	// number / date
+	From   json.RawMessage `json:"from,omitempty"`
+	To     json.RawMessage `json:"to,omitempty"`
+	Step   int             `json:"step,omitempty"`
+	Format string          `json:"format,omitempty"`
+
+	// charset
+	Charset   string `json:"charset,omitempty"`
+	MinLength int    `json:"min_length,omitempty"`
+	MaxLength int    `json:"max_length,omitempty"`
+
+	// credentials
+	Usernames     []string `json:"usernames,omitempty"`
+	UsernamesFile string   `json:"usernames_file,omitempty"`
+	Passwords     []string `json:"passwords,omitempty"`
+	PasswordsFile string   `json:"passwords_file,omitempty"`
+	Separator     *string  `json:"separator,omitempty"`
+
+	Processors []ProcessorConfig `json:"processors,omitempty"`
+}
+
+// NewPayloadGenerator 根据载荷集类型和配置创建生成器，并套上处理器链
+func NewPayloadGenerator(set *models.BrutePayloadSet) (Generator, error) {
+	if set == nil {
+		return nil, errors.InvalidInput("payload set is required")
+	}
+
+	var cfg PayloadConfig
+	raw, err := json.Marshal(set.Config)
+	if err != nil {
+		return nil, errors.InvalidInput("invalid payload set config")
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid payload set config: %v", err))
+	}
+
+	var gen Generator
+	switch set.Type {
+	case PayloadDictionary:
+		gen, err = newDictionaryGenerator(cfg.Payloads, cfg.File)
+	case PayloadNumber:
+		gen, err = newNumberGenerator(cfg)
+	case PayloadCharset:
+		gen, err = NewCharsetGenerator(cfg.Charset, cfg.MinLength, cfg.MaxLength)
+	case PayloadDate:
+		gen, err = newDateGenerator(cfg)
+	case PayloadCredentials:
+		gen, err = newCredentialsGenerator(cfg)
+	default:
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid payload set type: %s", set.Type))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if gen.Count() == 0 {
+		return nil, errors.InvalidInput("payload set has no payloads")
+	}
+
+	processors, err := NewProcessors(cfg.Processors)
+	if err != nil {
+		return nil, err
+	}
+	return WithProcessors(gen, processors), nil
+}
+
+func newDictionaryGenerator(payloads []string, file string) (Generator, error) {
+	if file != "" {
+		return NewFileGenerator(file)
+	}
+	return NewListGenerator(payloads), nil
+}
+
+// ==================== 列表 ====================
+
+// listGenerator 内存中的载荷列表
+type listGenerator struct {
+	payloads []string
+}
+
+// NewListGenerator 创建基于内联列表的生成器
+func NewListGenerator(payloads []string) Generator {
+	return &listGenerator{payloads: payloads}
+}
+
+func (g *listGenerator) Count() int { return len(g.payloads) }
+
+func (g *listGenerator) Open() (Iterator, error) {
+	return &funcIterator{next: func(i int) (string, bool) {
+		if i >= len(g.payloads) {
+			return "", false
+		}
+		return g.payloads[i], true
+	}}, nil
+}
+
+// funcIterator 按下标计算载荷的迭代器
+type funcIterator struct {
+	next func(i int) (string, bool)
+	i    int
+}
+
+func (it *funcIterator) Next() (string, bool) {
+	v, ok := it.next(it.i)
+	if ok {
+		it.i++
+	}
+	return v, ok
+}
+
+func (it *funcIterator) Err() error   { return nil }
+func (it *funcIterator) Close() error { return nil }
+
+// ==================== 文件 ====================
+
+// fileGenerator 逐行读取文件，忽略空行
+type fileGenerator struct {
+	path  string
+	count int
+}
+
+// NewFileGenerator 创建基于字典文件的生成器，创建时统计行数
+func NewFileGenerator(path string) (Generator, error) {
+	g := &fileGenerator{path: path}
+	it, err := g.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	for {
+		if _, ok := it.Next(); !ok {
+			break
+		}
+		g.count++
+	}
+	if err := it.Err(); err != nil {
+		return nil, errors.InvalidInput(fmt.Sprintf("failed to read payload file: %v", err))
+	}
+	return g, nil
+}
+
+func (g *fileGenerator) Count() int { return g.count }
+
+func (g *fileGenerator) Open() (Iterator, error) {
+	f, err := os.Open(g.path)
+	if err != nil {
+		return nil, errors.InvalidInput(fmt.Sprintf("failed to open payload file: %s", g.path))
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &fileIterator{file: f, scanner: scanner}, nil
+}
+
+type fileIterator struct {
+	file    *os.File
+	scanner *bufio.Scanner
+}
+
+func (it *fileIterator) Next() (string, bool) {
+	for it.scanner.Scan() {
+		line := strings.TrimRight(it.scanner.Text(), "\r")
+		if line != "" {
+			return line, true
+		}
+	}
+	return "", false
+}
+
+func (it *fileIterator) Err() error   { return it.scanner.Err() }
+func (it *fileIterator) Close() error { return it.file.Close() }
+
+// ==================== 数字 ====================
+
+// numberGenerator 数字范围，包含首尾
+type numberGenerator struct {
+	from, step int64
+	count      int
+	format     string
+}
+
+// NewNumberGenerator 创建数字范围生成器，step 可为负数，format 为 fmt 格式如 %04d
+func NewNumberGenerator(from, to, step int64, format string) (Generator, error) {
+	if step == 0 {
+		step = 1
+		if to < from {
+			step = -1
+		}
+	}
+	// 直接比较大小而不是相减，避免 to-from 溢出与整数除法向零截断
+	if (to > from && step < 0) || (to < from && step > 0) {
+		return nil, errors.InvalidInput("number range step points away from the end")
+	}
+	if format == "" {
+		format = "%d"
+	}
+	// 动词与参数不匹配时 fmt 输出 %!，如 %s、%d%d 或没有动词的格式
+	if strings.Contains(fmt.Sprintf(format, from), "%!") {
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid number format: %s", format))
+	}
+	// 跨度与步长在 uint64 中计算，int64 首尾相差可超过 MaxInt64；跨度为 MaxUint64 时 +1 回绕为 0
+	count := numberSpan(from, to)/absUint64(step) + 1
+	if count == 0 || count > MaxAttempts {
+		return nil, errors.InvalidInput(fmt.Sprintf("too many payloads, limit is %d", MaxAttempts))
+	}
+	return &numberGenerator{from: from, step: step, count: int(count), format: format}, nil
+}
+
+// numberSpan 返回 |to-from|，结果不会溢出
+func numberSpan(from, to int64) uint64 {
+	if to >= from {
+		return uint64(to) - uint64(from)
+	}
+	return uint64(from) - uint64(to)
+}
+
+// absUint64 返回 |n|，n 为 MinInt64 时同样正确
+func absUint64(n int64) uint64 {
+	if n < 0 {
+		return -uint64(n)
+	}
+	return uint64(n)
+}
+
+func newNumberGenerator(cfg PayloadConfig) (Generator, error) {
+	var from, to int64
+	if err := json.Unmarshal(cfg.From, &from); err != nil {
+		return nil, errors.InvalidInput("number payload set requires integer from")
+	}
+	if err := json.Unmarshal(cfg.To, &to); err != nil {
+		return nil, errors.InvalidInput("number payload set requires integer to")
+	}
+	return NewNumberGenerator(from, to, int64(cfg.Step), cfg.Format)
+}
+
+func (g *numberGenerator) Count() int { return g.count }
+
+func (g *numberGenerator) Open() (Iterator, error) {
+	return &funcIterator{next: func(i int) (string, bool) {
+		if i >= g.count {
+			return "", false
+		}
+		return fmt.Sprintf(g.format, g.from+int64(i)*g.step), true
+	}}, nil
+}
+
+// ==================== 字符集 ====================
+
+// charsetGenerator 字符集穷举，按长度从短到长
+type charsetGenerator struct {
+	charset  []rune
+	min, max int
+	count    int
+}
+
+// NewCharsetGenerator 创建字符集穷举生成器
+func NewCharsetGenerator(charset string, minLength, maxLength int) (Generator, error) {
+	runes := []rune(charset)
+	if len(runes) == 0 {
+		return nil, errors.InvalidInput("charset is required")
+	}
+	if minLength <= 0 {
+		minLength = 1
+	}
+	if maxLength < minLength {
+		maxLength = minLength
+	}
+
+	count := 0
+	for length := minLength; length <= maxLength; length++ {
+		n := 1
+		for i := 0; i < length; i++ {
+			if n > MaxAttempts/len(runes) {
+				return nil, errors.InvalidInput(fmt.Sprintf("too many payloads, limit is %d", MaxAttempts))
+			}
+			n *= len(runes)
+		}
+		if count > MaxAttempts-n {
+			return nil, errors.InvalidInput(fmt.Sprintf("too many payloads, limit is %d", MaxAttempts))
+		}
+		count += n
+	}
+
+	return &charsetGenerator{charset: runes, min: minLength, max: maxLength, count: count}, nil
+}
+
+func (g *charsetGenerator) Count() int { return g.count }
+
+func (g *charsetGenerator) Open() (Iterator, error) {
+	return &charsetIterator{gen: g, indexes: make([]int, g.min)}, nil
+}
+
+type charsetIterator struct {
+	gen     *charsetGenerator
+	indexes []int
+	done    bool
+}
+
+func (it *charsetIterator) Next() (string, bool) {
+	if it.done {
+		return "", false
+	}
+	buf := make([]rune, len(it.indexes))
+	for i, idx := range it.indexes {
+		buf[i] = it.gen.charset[idx]
+	}
+
+	// 末位变化最快，溢出时增加长度
+	i := len(it.indexes) - 1
+	for ; i >= 0; i-- {
+		it.indexes[i]++
+		if it.indexes[i] < len(it.gen.charset) {
+			break
+		}
+		it.indexes[i] = 0
+	}
+	if i < 0 {
+		if len(it.indexes) >= it.gen.max {
+			it.done = true
+		} else {
+			it.indexes = make([]int, len(it.indexes)+1)
+		}
+	}
+	return string(buf), true
+}
+
+func (it *charsetIterator) Err() error   { return nil }
+func (it *charsetIterator) Close() error { return nil }
+
+// ==================== 日期 ====================
+
+// dateLayout 日期配置的输入格式
+const dateLayout = "2006-01-02"
+
+// dateGenerator 日期范围，按天步进
+type dateGenerator struct {
+	from   time.Time
+	step   int
+	count  int
+	layout string
+}
+
+// NewDateGenerator 创建日期范围生成器，layout 为 Go 时间格式
+func NewDateGenerator(from, to time.Time, stepDays int, layout string) (Generator, error) {
+	if stepDays <= 0 {
+		stepDays = 1
+	}
+	if to.Before(from) {
+		return nil, errors.InvalidInput("date range end is before start")
+	}
+	if layout == "" {
+		layout = dateLayout
+	}
+	days := int(to.Sub(from).Hours() / 24)
+	return &dateGenerator{from: from, step: stepDays, count: days/stepDays + 1, layout: layout}, nil
+}
+
+func newDateGenerator(cfg PayloadConfig) (Generator, error) {
+	var fromStr, toStr string
+	_ = json.Unmarshal(cfg.From, &fromStr)
+	_ = json.Unmarshal(cfg.To, &toStr)
+
+	from, err := time.Parse(dateLayout, fromStr)
+	if err != nil {
+		return nil, errors.InvalidInput("date payload set requires from in YYYY-MM-DD")
+	}
+	to, err := time.Parse(dateLayout, toStr)
+	if err != nil {
+		return nil, errors.InvalidInput("date payload set requires to in YYYY-MM-DD")
+	}
+	return NewDateGenerator(from, to, cfg.Step, cfg.Format)
+}
+
+func (g *dateGenerator) Count() int { return g.count }
+
+func (g *dateGenerator) Open() (Iterator, error) {
+	return &funcIterator{next: func(i int) (string, bool) {
+		if i >= g.count {
+			return "", false
+		}
+		return g.from.AddDate(0, 0, i*g.step).Format(g.layout), true
+	}}, nil
+}
+
+// ==================== 凭据 ====================
+
+// credentialsGenerator 用户名与密码的笛卡尔积，用户名变化较慢
+type credentialsGenerator struct {
+	usernames Generator
+	passwords Generator
+	separator string
+}
+
+// NewCredentialsGenerator 创建用户名 × 密码生成器，输出 user<sep>pass
+func NewCredentialsGenerator(usernames, passwords Generator, separator string) (Generator, error) {
+	if usernames.Count() > 0 && passwords.Count() > MaxAttempts/usernames.Count() {
+		return nil, errors.InvalidInput(fmt.Sprintf("too many payloads, limit is %d", MaxAttempts))
+	}
+	return &credentialsGenerator{usernames: usernames, passwords: passwords, separator: separator}, nil
+}
+
+func newCredentialsGenerator(cfg PayloadConfig) (Generator, error) {
+	usernames, err := newDictionaryGenerator(cfg.Usernames, cfg.UsernamesFile)
+	if err != nil {
+		return nil, err
+	}
+	passwords, err := newDictionaryGenerator(cfg.Passwords, cfg.PasswordsFile)
+	if err != nil {
+		return nil, err
+	}
+	separator := ":"
+	if cfg.Separator != nil {
+		separator = *cfg.Separator
+	}
+	return NewCredentialsGenerator(usernames, passwords, separator)
+}
+
+func (g *credentialsGenerator) Count() int {
+	return g.usernames.Count() * g.passwords.Count()
+}
+
+func (g *credentialsGenerator) Open() (Iterator, error) {
+	users, err := g.usernames.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &credentialsIterator{gen: g, users: users}, nil
+}
+
+type credentialsIterator struct {
+	gen       *credentialsGenerator
+	users     Iterator
+	passwords Iterator
+	user      string
+	err       error
+}
+
+func (it *credentialsIterator) Next() (string, bool) {
+	for it.err == nil {
+		if it.passwords != nil {
+			if password, ok := it.passwords.Next(); ok {
+				return it.user + it.gen.separator + password, true
+			}
+			it.err = it.passwords.Err()
+			it.passwords.Close()
+			it.passwords = nil
+			continue
+		}
+
+		user, ok := it.users.Next()
+		if !ok {
+			it.err = it.users.Err()
+			return "", false
+		}
+		it.user = user
+		it.passwords, it.err = it.gen.passwords.Open()
+	}
+	return "", false
+}
+
+func (it *credentialsIterator) Err() error { return it.err }
+
+func (it *credentialsIterator) Close() error {
+	if it.passwords != nil {
+		it.passwords.Close()
+	}
+	return it.users.Close()
+}
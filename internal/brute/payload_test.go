@@ -0,0 +1,208 @@
+package brute
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// drain 读取生成器的全部载荷，并校验与 Count 一致
+func drain(t *testing.T, gen Generator) []string {
+	t.Helper()
+	it, err := gen.Open()
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer it.Close()
+
+	var payloads []string
+	for {
+		v, ok := it.Next()
+		if !ok {
+			break
+		}
+		payloads = append(payloads, v)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("iterator error: %v", err)
+	}
+	if len(payloads) != gen.Count() {
+		t.Fatalf("got %d payloads, Count() = %d", len(payloads), gen.Count())
+	}
+	return payloads
+}
+
+// TestNewPayloadGenerator 测试各类型载荷集的生成结果
+func TestNewPayloadGenerator(t *testing.T) {
+	dir := t.TempDir()
+	wordlist := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordlist, []byte("alpha\r\n\nbeta\ngamma\n"), 0644); err != nil {
+		t.Fatalf("failed to write wordlist: %v", err)
+	}
+	users := filepath.Join(dir, "users.txt")
+	if err := os.WriteFile(users, []byte("root\nadmin\n"), 0644); err != nil {
+		t.Fatalf("failed to write users: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		setType string
+		config  map[string]interface{}
+		want    []string
+	}{
+		{
+			name:    "内联字典",
+			setType: PayloadDictionary,
+			config:  map[string]interface{}{"payloads": []interface{}{"a", "b"}},
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "字典文件",
+			setType: PayloadDictionary,
+			config:  map[string]interface{}{"file": wordlist},
+			want:    []string{"alpha", "beta", "gamma"},
+		},
+		{
+			name:    "数字范围",
+			setType: PayloadNumber,
+			config:  map[string]interface{}{"from": 1, "to": 10, "step": 4, "format": "%03d"},
+			want:    []string{"001", "005", "009"},
+		},
+		{
+			name:    "数字倒序",
+			setType: PayloadNumber,
+			config:  map[string]interface{}{"from": 3, "to": 1},
+			want:    []string{"3", "2", "1"},
+		},
+		{
+			name:    "字符集",
+			setType: PayloadCharset,
+			config:  map[string]interface{}{"charset": "ab", "min_length": 1, "max_length": 2},
+			want:    []string{"a", "b", "aa", "ab", "ba", "bb"},
+		},
+		{
+			name:    "日期",
+			setType: PayloadDate,
+			config:  map[string]interface{}{"from": "2024-02-27", "to": "2024-03-02", "step": 2, "format": "20060102"},
+			want:    []string{"20240227", "20240229", "20240302"},
+		},
+		{
+			name:    "凭据",
+			setType: PayloadCredentials,
+			config: map[string]interface{}{
+				"usernames_file": users,
+				"passwords":      []interface{}{"123", "456"},
+			},
+			want: []string{"root:123", "root:456", "admin:123", "admin:456"},
+		},
+		{
+			name:    "处理器链",
+			setType: PayloadDictionary,
+			config: map[string]interface{}{
+				"payloads": []interface{}{"admin"},
+				"processors": []interface{}{
+					map[string]interface{}{"type": "case", "mode": "upper"},
+					map[string]interface{}{"type": "prefix", "value": "user:"},
+					map[string]interface{}{"type": "base64"},
+				},
+			},
+			want: []string{"dXNlcjpBRE1JTg=="},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := NewPayloadGenerator(&models.BrutePayloadSet{Type: tt.setType, Config: tt.config})
+			if err != nil {
+				t.Fatalf("NewPayloadGenerator() failed: %v", err)
+			}
+			got := drain(t, gen)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payloads = %v, want %v", got, tt.want)
+			}
+			// 迭代器可重复打开
+			if again := drain(t, gen); !reflect.DeepEqual(again, got) {
+				t.Errorf("second pass = %v, want %v", again, got)
+			}
+		})
+	}
+}
+
+// TestNewPayloadGenerator_Invalid 测试无效的载荷集配置
+func TestNewPayloadGenerator_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		setType string
+		config  map[string]interface{}
+	}{
+		{"未知类型", "unknown", nil},
+		{"空字典", PayloadDictionary, map[string]interface{}{"payloads": []interface{}{}}},
+		{"文件不存在", PayloadDictionary, map[string]interface{}{"file": "/nonexistent/words.txt"}},
+		{"数字缺少范围", PayloadNumber, map[string]interface{}{"from": 1}},
+		{"数字步长方向错误", PayloadNumber, map[string]interface{}{"from": 1, "to": 5, "step": -1}},
+		{"数字反向步长大于范围", PayloadNumber, map[string]interface{}{"from": 1, "to": 2, "step": -5}},
+		{"数字倒序正步长", PayloadNumber, map[string]interface{}{"from": 5, "to": 1, "step": 3}},
+		{"数字格式动词错误", PayloadNumber, map[string]interface{}{"from": 1, "to": 2, "format": "%s"}},
+		{"数字格式多个动词", PayloadNumber, map[string]interface{}{"from": 1, "to": 2, "format": "%d-%d"}},
+		{"数字格式缺少动词", PayloadNumber, map[string]interface{}{"from": 1, "to": 2, "format": "100%%"}},
+		{"字符集为空", PayloadCharset, map[string]interface{}{"max_length": 3}},
+		{"字符集过大", PayloadCharset, map[string]interface{}{"charset": "abcdefghijklmnopqrstuvwxyz", "min_length": 8}},
+		{"日期格式错误", PayloadDate, map[string]interface{}{"from": "2024/01/01", "to": "2024-01-02"}},
+		{"日期范围颠倒", PayloadDate, map[string]interface{}{"from": "2024-01-02", "to": "2024-01-01"}},
+		{"未知处理器", PayloadDictionary, map[string]interface{}{
+			"payloads":   []interface{}{"a"},
+			"processors": []interface{}{map[string]interface{}{"type": "rot13"}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPayloadGenerator(&models.BrutePayloadSet{Type: tt.setType, Config: tt.config}); err == nil {
+				t.Error("NewPayloadGenerator() should return error")
+			}
+		})
+	}
+}
+
+// TestNewNumberGenerator_Extremes 测试接近 int64 边界的范围不溢出
+func TestNewNumberGenerator_Extremes(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int64
+		step     int64
+		want     []string
+		wantErr  string
+	}{
+		{"从零到最大值", 0, math.MaxInt64, 1, nil, "too many payloads"},
+		{"跨度超过 MaxInt64", -5e18, 5e18, 1, nil, "too many payloads"},
+		{"完整 int64 范围", math.MinInt64, math.MaxInt64, 1, nil, "too many payloads"},
+		{"完整范围倒序", math.MaxInt64, math.MinInt64, 0, nil, "too many payloads"},
+		{"大跨度大步长", -5e18, 5e18, 5e18, []string{"-5000000000000000000", "0", "5000000000000000000"}, ""},
+		{"最小值步长", math.MaxInt64, math.MinInt64, math.MinInt64, []string{"9223372036854775807", "-1"}, ""},
+		{"最大值附近", math.MaxInt64 - 2, math.MaxInt64, 1, []string{"9223372036854775805", "9223372036854775806", "9223372036854775807"}, ""},
+		{"反向步长", -5e18, 5e18, -1, nil, "points away"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := NewNumberGenerator(tt.from, tt.to, tt.step, "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewNumberGenerator() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewNumberGenerator() failed: %v", err)
+			}
+			if got := drain(t, gen); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payloads = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package brute
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+)
+
+// 载荷处理器类型
+const (
+	ProcessURLEncode    = "url_encode"
+	ProcessBase64       = "base64"
+	ProcessHex          = "hex"
+	ProcessHash         = "hash"
+	ProcessPrefix       = "prefix"
+	ProcessSuffix       = "suffix"
+	ProcessCase         = "case"
+	ProcessRegexReplace = "regex_replace"
+)
+
+// ProcessorConfig 处理器配置
+type ProcessorConfig struct {
+	Type        string `json:"type"`
+	Value       string `json:"value,omitempty"`       // prefix / suffix
+	Algorithm   string `json:"algorithm,omitempty"`   // hash: md5 / sha1 / sha256 / sha512
+	Mode        string `json:"mode,omitempty"`        // case: upper / lower / capitalize / swap
+	Pattern     string `json:"pattern,omitempty"`     // regex_replace
+	Replacement string `json:"replacement,omitempty"` // regex_replace
+}
+
+// Processor 载荷处理器
+type Processor func(string) string
+
+// NewProcessors 按顺序构建处理器链
+func NewProcessors(configs []ProcessorConfig) ([]Processor, error) {
+	processors := make([]Processor, 0, len(configs))
+	for _, cfg := range configs {
+		p, err := NewProcessor(cfg)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, p)
+	}
+	return processors, nil
+}
+
+// NewProcessor 创建单个处理器
+func NewProcessor(cfg ProcessorConfig) (Processor, error) {
+	switch cfg.Type {
+	case ProcessURLEncode:
+		return url.QueryEscape, nil
+	case ProcessBase64:
+		return func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }, nil
+	case ProcessHex:
+		return func(s string) string { return hex.EncodeToString([]byte(s)) }, nil
+	case ProcessHash:
+		return newHashProcessor(cfg.Algorithm)
+	case ProcessPrefix:
+		return func(s string) string { return cfg.Value + s }, nil
+	case ProcessSuffix:
+		return func(s string) string { return s + cfg.Value }, nil
+	case ProcessCase:
+		return newCaseProcessor(cfg.Mode)
+	case ProcessRegexReplace:
+		re, err := regexp.Compile(cfg.Pattern)
+		if err != nil {
+			return nil, errors.InvalidInput(fmt.Sprintf("invalid regex pattern: %v", err))
+		}
+		return func(s string) string { return re.ReplaceAllString(s, cfg.Replacement) }, nil
+	default:
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid payload processor: %s", cfg.Type))
+	}
+}
+
+func newHashProcessor(algorithm string) (Processor, error) {
+	var newHash func() hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "md5":
+		newHash = md5.New
+	case "sha1":
+		newHash = sha1.New
+	case "", "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid hash algorithm: %s", algorithm))
+	}
+	return func(s string) string {
+		h := newHash()
+		h.Write([]byte(s))
+		return hex.EncodeToString(h.Sum(nil))
+	}, nil
+}
+
+func newCaseProcessor(mode string) (Processor, error) {
+	switch mode {
+	case "upper":
+		return strings.ToUpper, nil
+	case "lower":
+		return strings.ToLower, nil
+	case "capitalize":
+		return func(s string) string {
+			r, size := utf8.DecodeRuneInString(s)
+			if size == 0 {
+				return s
+			}
+			return string(unicode.ToUpper(r)) + strings.ToLower(s[size:])
+		}, nil
+	case "swap":
+		return func(s string) string {
+			return strings.Map(func(r rune) rune {
+				if unicode.IsUpper(r) {
+					return unicode.ToLower(r)
+				}
+				return unicode.ToUpper(r)
+			}, s)
+		}, nil
+	default:
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid case mode: %s", mode))
+	}
+}
+
+// processedGenerator 对生成器的输出依次应用处理器
+type processedGenerator struct {
+	Generator
+	processors []Processor
+}
+
+// WithProcessors 为生成器套上处理器链，不改变载荷数量
+func WithProcessors(gen Generator, processors []Processor) Generator {
+	if len(processors) == 0 {
+		return gen
+	}
+	return &processedGenerator{Generator: gen, processors: processors}
+}
+
+func (g *processedGenerator) Open() (Iterator, error) {
+	it, err := g.Generator.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &processedIterator{Iterator: it, processors: g.processors}, nil
+}
+
+type processedIterator struct {
+	Iterator
+	processors []Processor
+}
+
+func (it *processedIterator) Next() (string, bool) {
+	v, ok := it.Iterator.Next()
+	if !ok {
+		return "", false
+	}
+	for _, p := range it.processors {
+		v = p(v)
+	}
+	return v, true
+}
@@ -0,0 +1,54 @@
+package brute
+
+import "testing"
+
+// TestNewProcessor 测试单个处理器
+func TestNewProcessor(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   ProcessorConfig
+		input string
+		want  string
+	}{
+		{"URL 编码", ProcessorConfig{Type: ProcessURLEncode}, "a b&c", "a+b%26c"},
+		{"Base64", ProcessorConfig{Type: ProcessBase64}, "admin", "YWRtaW4="},
+		{"Hex", ProcessorConfig{Type: ProcessHex}, "ab", "6162"},
+		{"MD5", ProcessorConfig{Type: ProcessHash, Algorithm: "md5"}, "admin", "21232f297a57a5a743894a0e4a801fc3"},
+		{"SHA1", ProcessorConfig{Type: ProcessHash, Algorithm: "sha1"}, "admin", "d033e22ae348aeb5660fc2140aec35850c4da997"},
+		{"前缀", ProcessorConfig{Type: ProcessPrefix, Value: "pre-"}, "x", "pre-x"},
+		{"后缀", ProcessorConfig{Type: ProcessSuffix, Value: "123"}, "x", "x123"},
+		{"大写", ProcessorConfig{Type: ProcessCase, Mode: "upper"}, "Admin", "ADMIN"},
+		{"小写", ProcessorConfig{Type: ProcessCase, Mode: "lower"}, "Admin", "admin"},
+		{"首字母大写", ProcessorConfig{Type: ProcessCase, Mode: "capitalize"}, "aDMIN", "Admin"},
+		{"大小写互换", ProcessorConfig{Type: ProcessCase, Mode: "swap"}, "aDmIn", "AdMiN"},
+		{"正则替换", ProcessorConfig{Type: ProcessRegexReplace, Pattern: `[aeiou]`, Replacement: "*"}, "admin", "*dm*n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewProcessor(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewProcessor() failed: %v", err)
+			}
+			if got := p(tt.input); got != tt.want {
+				t.Errorf("process(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewProcessor_Invalid 测试无效的处理器配置
+func TestNewProcessor_Invalid(t *testing.T) {
+	tests := []ProcessorConfig{
+		{Type: "unknown"},
+		{Type: ProcessHash, Algorithm: "crc32"},
+		{Type: ProcessCase, Mode: "title"},
+		{Type: ProcessRegexReplace, Pattern: "("},
+	}
+
+	for _, cfg := range tests {
+		if _, err := NewProcessor(cfg); err == nil {
+			t.Errorf("NewProcessor(%+v) should return error", cfg)
+		}
+	}
+}
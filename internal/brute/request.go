@@ -0,0 +1,161 @@
+package brute
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// PositionMarker 载荷位置标记符，与 Burp Intruder 保持一致
+// 例如: /login?user=§admin§ 中 admin 为该位置的默认值
+const PositionMarker = "§"
+
+// Position 请求中的一个载荷位置
+type Position struct {
+	Index    int    `json:"index"`
+	Location string `json:"location"` // url / header:<Name> / body
+	Default  string `json:"default"`
+}
+
+// field 含有载荷位置的字段，literals 比 positions 多一个元素
+type field struct {
+	literals  []string
+	positions []int
+}
+
+// render 用载荷值渲染字段
+func (f *field) render(values []string) string {
+	if len(f.positions) == 0 {
+		return f.literals[0]
+	}
+	var b strings.Builder
+	for i, pos := range f.positions {
+		b.WriteString(f.literals[i])
+		b.WriteString(values[pos])
+	}
+	b.WriteString(f.literals[len(f.literals)-1])
+	return b.String()
+}
+
+type headerField struct {
+	name  string
+	value field
+}
+
+// RequestTemplate 带有载荷位置标记的请求模板
+type RequestTemplate struct {
+	Method      string
+	ContentType string
+	Positions   []Position
+
+	url     field
+	headers []headerField
+	body    field
+}
+
+// RenderedRequest 填充载荷后的请求
+type RenderedRequest struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+}
+
+// ParseRequest 解析 HTTP 请求中的载荷位置
+// 位置按 URL、请求头（按名称排序）、请求体的顺序编号
+func ParseRequest(req *models.HttpRequest) (*RequestTemplate, error) {
+	if req == nil {
+		return nil, errors.InvalidInput("http request is required")
+	}
+
+	tmpl := &RequestTemplate{
+		Method:      strings.ToUpper(req.Method),
+		ContentType: req.ContentType,
+	}
+
+	var err error
+	if tmpl.url, err = tmpl.parseField(req.URL, "url"); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, err := tmpl.parseField(req.Headers[name], "header:"+name)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.headers = append(tmpl.headers, headerField{name: name, value: value})
+	}
+
+	if tmpl.body, err = tmpl.parseField(req.Body, "body"); err != nil {
+		return nil, err
+	}
+
+	if len(tmpl.Positions) == 0 {
+		return nil, errors.InvalidInput("no payload positions marked in request, wrap values with " + PositionMarker)
+	}
+
+	return tmpl, nil
+}
+
+// parseField 解析单个字段中的标记
+func (t *RequestTemplate) parseField(s, location string) (field, error) {
+	parts := strings.Split(s, PositionMarker)
+	if len(parts)%2 == 0 {
+		return field{}, errors.InvalidInput(fmt.Sprintf("unbalanced payload marker in %s", location))
+	}
+
+	f := field{}
+	for i, part := range parts {
+		if i%2 == 0 {
+			f.literals = append(f.literals, part)
+			continue
+		}
+		index := len(t.Positions)
+		t.Positions = append(t.Positions, Position{
+			Index:    index,
+			Location: location,
+			Default:  part,
+		})
+		f.positions = append(f.positions, index)
+	}
+	return f, nil
+}
+
+// Defaults 返回所有位置的默认值
+func (t *RequestTemplate) Defaults() []string {
+	values := make([]string, len(t.Positions))
+	for i, p := range t.Positions {
+		values[i] = p.Default
+	}
+	return values
+}
+
+// Render 使用每个位置的值渲染请求
+func (t *RequestTemplate) Render(values []string) (*RenderedRequest, error) {
+	if len(values) != len(t.Positions) {
+		return nil, errors.InvalidInput(fmt.Sprintf("expected %d payload values, got %d", len(t.Positions), len(values)))
+	}
+
+	rendered := &RenderedRequest{
+		Method:  t.Method,
+		URL:     t.url.render(values),
+		Headers: make(map[string]string, len(t.headers)+1),
+		Body:    t.body.render(values),
+	}
+	for _, h := range t.headers {
+		rendered.Headers[h.name] = h.value.render(values)
+	}
+	if t.ContentType != "" {
+		rendered.Headers["Content-Type"] = t.ContentType
+	}
+	return rendered, nil
+}
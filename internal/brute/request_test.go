@@ -0,0 +1,87 @@
+package brute
+
+import (
+	"testing"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// TestParseRequest 测试载荷位置解析
+func TestParseRequest(t *testing.T) {
+	req := &models.HttpRequest{
+		Method: "post",
+		URL:    "https://example.com/login?lang=§en§",
+		Headers: map[string]string{
+			"X-Token": "§abc§",
+			"Accept":  "*/*",
+		},
+		Body:        "user=§admin§&pass=§123§",
+		ContentType: "application/x-www-form-urlencoded",
+	}
+
+	tmpl, err := ParseRequest(req)
+	if err != nil {
+		t.Fatalf("ParseRequest() failed: %v", err)
+	}
+
+	if tmpl.Method != "POST" {
+		t.Errorf("Method = %s, want POST", tmpl.Method)
+	}
+
+	want := []Position{
+		{Index: 0, Location: "url", Default: "en"},
+		{Index: 1, Location: "header:X-Token", Default: "abc"},
+		{Index: 2, Location: "body", Default: "admin"},
+		{Index: 3, Location: "body", Default: "123"},
+	}
+	if len(tmpl.Positions) != len(want) {
+		t.Fatalf("got %d positions, want %d", len(tmpl.Positions), len(want))
+	}
+	for i, p := range want {
+		if tmpl.Positions[i] != p {
+			t.Errorf("Positions[%d] = %+v, want %+v", i, tmpl.Positions[i], p)
+		}
+	}
+
+	rendered, err := tmpl.Render([]string{"zh", "tok", "root", "toor"})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	if rendered.URL != "https://example.com/login?lang=zh" {
+		t.Errorf("URL = %s", rendered.URL)
+	}
+	if rendered.Headers["X-Token"] != "tok" || rendered.Headers["Accept"] != "*/*" {
+		t.Errorf("Headers = %v", rendered.Headers)
+	}
+	if rendered.Headers["Content-Type"] != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %s", rendered.Headers["Content-Type"])
+	}
+	if rendered.Body != "user=root&pass=toor" {
+		t.Errorf("Body = %s", rendered.Body)
+	}
+
+	defaults, _ := tmpl.Render(tmpl.Defaults())
+	if defaults.Body != "user=admin&pass=123" {
+		t.Errorf("Render(Defaults()) body = %s", defaults.Body)
+	}
+}
+
+// TestParseRequest_Invalid 测试非法标记
+func TestParseRequest_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *models.HttpRequest
+	}{
+		{"无位置", &models.HttpRequest{Method: "GET", URL: "https://example.com/"}},
+		{"标记不成对", &models.HttpRequest{Method: "GET", URL: "https://example.com/?a=§1"}},
+		{"空请求", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRequest(tt.req); err == nil {
+				t.Error("ParseRequest() should return error")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParse 测试解析 cron 表达式
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"*/15 * * * *", false},
+		{"0 2 * * mon-fri", false},
+		{"30 4 1,15 * *", false},
+		{"0 0 * JAN,jul 7", false},
+		{"@daily", false},
+		{"@Hourly", false},
+		{"* * * *", true},
+		{"60 * * * *", true},
+		{"0 24 * * *", true},
+		{"0 0 0 * *", true},
+		{"*/0 * * * *", true},
+		{"5-1 * * * *", true},
+		{"0 0 * foo *", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSchedule_Next 测试计算下一次触发时间
+func TestSchedule_Next(t *testing.T) {
+	// 2025-01-15 是周三
+	base := time.Date(2025, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", base, time.Date(2025, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", base, time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", base, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 2 * * mon-fri", time.Date(2025, 1, 17, 3, 0, 0, 0, time.UTC), time.Date(2025, 1, 20, 2, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", base, time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", base, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// 日与周同时受限时任一匹配即可
+		{"0 0 20 * fri", base, time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		// 恰好处于触发时刻时返回下一次
+		{"7 10 * * *", time.Date(2025, 1, 15, 10, 7, 0, 0, time.UTC), time.Date(2025, 1, 16, 10, 7, 0, 0, time.UTC)},
+		{"0 0 30 2 *", base, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.expr, err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/svc"
+)
+
+// AuthHandler 认证配置处理器
+type AuthHandler struct {
+	service *svc.AuthService
+}
+
+// NewAuthHandler 创建认证配置处理器
+func NewAuthHandler(service *svc.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// GetByTargetID 获取目标的认证配置
+func (h *AuthHandler) GetByTargetID(ctx context.Context, targetID int) ([]*models.AuthProfile, error) {
+	return h.service.GetByTargetID(ctx, targetID)
+}
+
+// Create 创建认证配置
+func (h *AuthHandler) Create(ctx context.Context, req *models.AuthProfileRequest) (*models.AuthProfile, error) {
+	return h.service.Create(ctx, req)
+}
+
+// Update 更新认证配置
+func (h *AuthHandler) Update(ctx context.Context, id int, req *models.AuthProfileRequest) (*models.AuthProfile, error) {
+	return h.service.Update(ctx, id, req)
+}
+
+// Delete 删除认证配置
+func (h *AuthHandler) Delete(ctx context.Context, id int) error {
+	return h.service.Delete(ctx, id)
+}
@@ -18,8 +18,8 @@ func NewBruteHandler(service *svc.BruteService) *BruteHandler {
 }
 
 // CreateTask 创建任务
-func (h *BruteHandler) CreateTask(ctx context.Context, name string, requestID int, bruteType string) (int, error) {
-	return h.service.CreateTask(ctx, name, requestID, bruteType)
+func (h *BruteHandler) CreateTask(ctx context.Context, req *models.CreateBruteTaskRequest) (int, error) {
+	return h.service.CreateTask(ctx, req)
 }
 
 // GetTaskByID 获取任务
@@ -52,7 +52,22 @@ func (h *BruteHandler) StartBruteTask(ctx context.Context, taskID int) error {
 	return h.service.StartBruteTask(ctx, taskID)
 }
 
+// StopBruteTask 停止暴力破解任务
+func (h *BruteHandler) StopBruteTask(ctx context.Context, taskID int) error {
+	return h.service.StopBruteTask(ctx, taskID)
+}
+
 // GetBruteTaskResults 获取暴力破解任务结果
 func (h *BruteHandler) GetBruteTaskResults(ctx context.Context, taskID int) ([]*models.BruteResult, error) {
 	return h.service.GetBruteTaskResults(ctx, taskID)
 }
+
+// GetBruteTaskHits 获取命中规则的暴力破解结果
+func (h *BruteHandler) GetBruteTaskHits(ctx context.Context, taskID int) ([]*models.BruteResult, error) {
+	return h.service.GetBruteTaskHits(ctx, taskID)
+}
+
+// RecoverInterrupted 将上次退出时仍在运行的任务标记为 stopped
+func (h *BruteHandler) RecoverInterrupted(ctx context.Context) (int, error) {
+	return h.service.RecoverInterrupted(ctx)
+}
@@ -37,7 +37,22 @@ func (h *DomainBruteHandler) GetResults(ctx context.Context, taskID int) ([]*mod
 	return h.service.GetResults(ctx, taskID)
 }
 
+// StartTask 启动任务
+func (h *DomainBruteHandler) StartTask(ctx context.Context, taskID int) error {
+	return h.service.StartTask(ctx, taskID)
+}
+
+// StopTask 停止任务
+func (h *DomainBruteHandler) StopTask(ctx context.Context, taskID int) error {
+	return h.service.StopTask(ctx, taskID)
+}
+
 // CreateResult 创建结果
 func (h *DomainBruteHandler) CreateResult(ctx context.Context, result *models.DomainBruteResult) error {
 	return h.service.CreateResult(ctx, result)
 }
+
+// RecoverInterrupted 将上次退出时仍在运行的任务标记为 stopped
+func (h *DomainBruteHandler) RecoverInterrupted(ctx context.Context) (int, error) {
+	return h.service.RecoverInterrupted(ctx)
+}
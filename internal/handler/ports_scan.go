@@ -22,6 +22,16 @@ func (h *PortScanHandler) CreateTask(ctx context.Context, target string, ports [
 	return h.service.CreateTask(ctx, target, ports, timeout, batchSize)
 }
 
+// CreateTasks 按目标表达式与端口表达式创建扫描任务
+func (h *PortScanHandler) CreateTasks(ctx context.Context, targets, ports string, timeout, batchSize int) ([]int, error) {
+	return h.service.CreateTasks(ctx, targets, ports, timeout, batchSize)
+}
+
+// GetPortProfiles 获取预置端口配置
+func (h *PortScanHandler) GetPortProfiles() map[string]string {
+	return h.service.GetPortProfiles()
+}
+
 // GetTaskByID 获取任务
 func (h *PortScanHandler) GetTaskByID(ctx context.Context, id int) (*models.PortScanTask, error) {
 	return h.service.GetTaskByID(ctx, id)
@@ -37,7 +47,22 @@ func (h *PortScanHandler) GetResults(ctx context.Context, taskID int) ([]*models
 	return h.service.GetResults(ctx, taskID)
 }
 
+// StartTask 启动扫描任务
+func (h *PortScanHandler) StartTask(ctx context.Context, taskID int) error {
+	return h.service.StartTask(ctx, taskID)
+}
+
+// StopTask 停止扫描任务
+func (h *PortScanHandler) StopTask(ctx context.Context, taskID int) error {
+	return h.service.StopTask(ctx, taskID)
+}
+
 // CreateResult 创建扫描结果
 func (h *PortScanHandler) CreateResult(ctx context.Context, result *models.PortScanResult) error {
 	return h.service.CreateResult(ctx, result)
 }
+
+// RecoverInterrupted 将上次退出时仍在运行的任务标记为 stopped
+func (h *PortScanHandler) RecoverInterrupted(ctx context.Context) (int, error) {
+	return h.service.RecoverInterrupted(ctx)
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/svc"
+)
+
+// PromotionHandler 结果提升处理器
+type PromotionHandler struct {
+	service *svc.PromotionService
+}
+
+// NewPromotionHandler 创建结果提升处理器
+func NewPromotionHandler(service *svc.PromotionService) *PromotionHandler {
+	return &PromotionHandler{service: service}
+}
+
+// PromoteTargets 将扫描结果提升为目标
+func (h *PromotionHandler) PromoteTargets(ctx context.Context, req *models.PromoteTargetsRequest) (*models.PromoteTargetsResult, error) {
+	return h.service.PromoteTargets(ctx, req)
+}
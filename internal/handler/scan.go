@@ -42,6 +42,30 @@ func (h *ScanHandler) Create(ctx context.Context, name string, targetID int, str
 	})
 }
 
+// CreateMultiTarget 创建多目标扫描任务，targetTag 非空时包含带有该标签的全部目标
+func (h *ScanHandler) CreateMultiTarget(ctx context.Context, name string, targetIDs []int, targetTag string, strategy string, templates []string) (*models.ScanTask, error) {
+	return h.service.Create(ctx, &svc.CreateScanRequest{
+		Name:      name,
+		TargetIDs: targetIDs,
+		TargetTag: targetTag,
+		Strategy:  strategy,
+		Templates: templates,
+	})
+}
+
+// CreateWithOptions 创建带调优参数的扫描任务，targetIDs 与 targetTag 的含义同 CreateMultiTarget，engine 为空时使用 nuclei
+func (h *ScanHandler) CreateWithOptions(ctx context.Context, name string, targetIDs []int, targetTag string, strategy string, templates []string, options *models.ScanOptions, engine string) (*models.ScanTask, error) {
+	return h.service.Create(ctx, &svc.CreateScanRequest{
+		Name:      name,
+		TargetIDs: targetIDs,
+		TargetTag: targetTag,
+		Strategy:  strategy,
+		Templates: templates,
+		Options:   options,
+		Engine:    engine,
+	})
+}
+
 // Start 启动扫描任务
 func (h *ScanHandler) Start(ctx context.Context, taskID int) error {
 	return h.service.Start(ctx, taskID)
@@ -52,6 +76,36 @@ func (h *ScanHandler) Stop(ctx context.Context, taskID int) error {
 	return h.service.Stop(ctx, taskID)
 }
 
+// Pause 暂停扫描任务
+func (h *ScanHandler) Pause(ctx context.Context, taskID int) error {
+	return h.service.Pause(ctx, taskID)
+}
+
+// Resume 恢复暂停的扫描任务，或重新启动被中断的扫描任务
+func (h *ScanHandler) Resume(ctx context.Context, taskID int) error {
+	return h.service.Resume(ctx, taskID)
+}
+
+// RecoverInterrupted 标记上次退出时中断的扫描任务
+func (h *ScanHandler) RecoverInterrupted(ctx context.Context) (int, error) {
+	return h.service.RecoverInterrupted(ctx)
+}
+
+// GetQueue 获取扫描队列
+func (h *ScanHandler) GetQueue(ctx context.Context) ([]*models.ScanTask, error) {
+	return h.service.GetQueue(ctx)
+}
+
+// ReorderQueue 调整扫描队列顺序
+func (h *ScanHandler) ReorderQueue(ctx context.Context, taskIDs []int) error {
+	return h.service.ReorderQueue(ctx, taskIDs)
+}
+
+// DispatchQueue 启动队列中可运行的任务
+func (h *ScanHandler) DispatchQueue(ctx context.Context) error {
+	return h.service.DispatchQueue(ctx)
+}
+
 // GetProgress 获取扫描进度
 func (h *ScanHandler) GetProgress(ctx context.Context, taskID int) (*models.ScanProgress, error) {
 	return h.service.GetProgress(ctx, taskID)
@@ -72,6 +126,16 @@ func (h *ScanHandler) GetNucleiStatus() *models.NucleiStatus {
 	return h.service.GetNucleiStatus()
 }
 
+// DefaultOptions 获取全局调优默认值
+func (h *ScanHandler) DefaultOptions() models.ScanOptions {
+	return h.service.DefaultOptions()
+}
+
+// Engines 列出可选的扫描引擎
+func (h *ScanHandler) Engines() []models.ScanEngine {
+	return h.service.Engines()
+}
+
 // UpdateStatus 更新扫描任务状态
 func (h *ScanHandler) UpdateStatus(ctx context.Context, taskID int, status string) error {
 	return h.service.UpdateStatus(ctx, taskID, status)
@@ -81,3 +145,13 @@ func (h *ScanHandler) UpdateStatus(ctx context.Context, taskID int, status strin
 func (h *ScanHandler) GetLogs(ctx context.Context, taskID int) ([]*models.ScanLog, error) {
 	return h.service.GetLogs(ctx, taskID)
 }
+
+// GetTemplateManifest 获取扫描任务运行的模板清单
+func (h *ScanHandler) GetTemplateManifest(ctx context.Context, taskID int) ([]models.TemplateManifestEntry, error) {
+	return h.service.GetTemplateManifest(ctx, taskID)
+}
+
+// CompareTemplateManifests 比较两次扫描运行的模板
+func (h *ScanHandler) CompareTemplateManifests(ctx context.Context, taskID, baseTaskID int) (*models.TemplateManifestDiff, error) {
+	return h.service.CompareTemplateManifests(ctx, taskID, baseTaskID)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/svc"
+)
+
+// ScheduleHandler 扫描计划处理器
+type ScheduleHandler struct {
+	service *svc.ScheduleService
+}
+
+// NewScheduleHandler 创建扫描计划处理器
+func NewScheduleHandler(service *svc.ScheduleService) *ScheduleHandler {
+	return &ScheduleHandler{service: service}
+}
+
+// GetAll 获取所有扫描计划
+func (h *ScheduleHandler) GetAll(ctx context.Context) ([]*models.ScanSchedule, error) {
+	return h.service.GetAll(ctx)
+}
+
+// GetByID 根据 ID 获取扫描计划
+func (h *ScheduleHandler) GetByID(ctx context.Context, id int) (*models.ScanSchedule, error) {
+	return h.service.GetByID(ctx, id)
+}
+
+// Create 创建扫描计划
+func (h *ScheduleHandler) Create(ctx context.Context, req *models.ScanScheduleRequest) (*models.ScanSchedule, error) {
+	return h.service.Create(ctx, req)
+}
+
+// Update 更新扫描计划
+func (h *ScheduleHandler) Update(ctx context.Context, id int, req *models.ScanScheduleRequest) (*models.ScanSchedule, error) {
+	return h.service.Update(ctx, id, req)
+}
+
+// SetEnabled 启用或停用扫描计划
+func (h *ScheduleHandler) SetEnabled(ctx context.Context, id int, enabled bool) error {
+	return h.service.SetEnabled(ctx, id, enabled)
+}
+
+// Delete 删除扫描计划
+func (h *ScheduleHandler) Delete(ctx context.Context, id int) error {
+	return h.service.Delete(ctx, id)
+}
+
+// RunNow 立即运行扫描计划
+func (h *ScheduleHandler) RunNow(ctx context.Context, id int) (*models.ScanTask, error) {
+	return h.service.RunNow(ctx, id)
+}
+
+// Start 启动后台调度
+func (h *ScheduleHandler) Start() {
+	h.service.Start()
+}
+
+// Stop 停止后台调度
+func (h *ScheduleHandler) Stop() {
+	h.service.Stop()
+}
@@ -77,6 +77,11 @@ func (h *TemplateHandler) ToggleCustomTemplate(ctx context.Context, id int, enab
 	return h.service.ToggleCustomTemplate(ctx, id, enabled)
 }
 
+// BulkToggleBuiltin 按分类、标签或作者批量启用或禁用内置模板
+func (h *TemplateHandler) BulkToggleBuiltin(ctx context.Context, req *models.TemplateBulkToggleRequest) (int, error) {
+	return h.service.BulkToggleBuiltin(ctx, req)
+}
+
 // SyncBuiltinTemplates 同步内置模板
 func (h *TemplateHandler) SyncBuiltinTemplates(ctx context.Context, templates []*models.Template) (*models.SyncStats, error) {
 	return h.service.SyncBuiltinTemplates(ctx, templates)
@@ -93,10 +98,15 @@ func (h *TemplateHandler) GetCustomByID(ctx context.Context, id int) (*models.Te
 }
 
 // ValidateCustomTemplate 验证自定义模板
-func (h *TemplateHandler) ValidateCustomTemplate(ctx context.Context, content string) (bool, []string, error) {
+func (h *TemplateHandler) ValidateCustomTemplate(ctx context.Context, content string) (*models.TemplateValidationResult, error) {
 	return h.service.ValidateCustomTemplate(ctx, content)
 }
 
+// TestTemplate 对指定 URL 试运行模板，结果不保存为漏洞
+func (h *TemplateHandler) TestTemplate(ctx context.Context, req *models.TemplateTestRequest) (*models.TemplateTestResult, error) {
+	return h.service.TestTemplate(ctx, req)
+}
+
 // GetCustomStats 获取自定义模板统计
 func (h *TemplateHandler) GetCustomStats(ctx context.Context) (map[string]interface{}, error) {
 	return h.service.GetCustomStats(ctx)
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 )
 
 // Config 应用配置
@@ -21,8 +22,25 @@ type Config struct {
 	NucleiPath         string
 	TemplatesDir       string
 	CustomTemplatesDir string
+	EnginesDir         string // 外部扫描引擎定义目录，每个 *.yaml 文件声明一个引擎
 	MaxConcurrent      int
-	ScanTimeout        int // 秒
+	ScanTimeout        int // 单次扫描最长运行秒数，扫描任务可单独覆盖，0 表示不限制
+
+	// 暂停的扫描是否让出并发槽位给队列中的任务，默认继续占用
+	PauseReleasesSlot bool
+
+	// nuclei 调优默认值，扫描任务可单独覆盖
+	ScanRateLimit      int    // 每秒最大请求数
+	ScanConcurrency    int    // 并行执行的模板数
+	ScanRequestTimeout int    // 单个请求超时（秒）
+	ScanRetries        int    // 请求失败重试次数
+	ScanProxy          string // HTTP 或 SOCKS5 代理地址，为空时直连
+
+	// 端口扫描配置
+	PortSignaturesFile string // 自定义服务指纹库，存在时与内置指纹库合并
+
+	// 子域名爆破配置
+	DNSResolver string // DNS 服务器地址（host:port），为空时使用系统解析器
 
 	// 日志配置
 	LogLevel string
@@ -47,8 +65,21 @@ func Load() *Config {
 		NucleiPath:         getNucleiPath(),
 		TemplatesDir:       templatesDir,
 		CustomTemplatesDir: filepath.Join(dataDir, "custom-templates"),
+		EnginesDir:         filepath.Join(dataDir, "engines"),
 		MaxConcurrent:      3,
-		ScanTimeout:        300, // 5 分钟
+		ScanTimeout:        getScanTimeout(),
+
+		PauseReleasesSlot: os.Getenv("HH_PAUSE_RELEASES_SLOT") == "true",
+
+		ScanRateLimit:      150,
+		ScanConcurrency:    25,
+		ScanRequestTimeout: 10,
+		ScanRetries:        1,
+		ScanProxy:          os.Getenv("HH_SCAN_PROXY"),
+
+		PortSignaturesFile: filepath.Join(dataDir, "port-signatures.yaml"),
+
+		DNSResolver: os.Getenv("HH_DNS_RESOLVER"),
 
 		LogLevel: getLogLevel(),
 		LogFile:  filepath.Join(dataDir, "app.log"),
@@ -141,6 +172,14 @@ func getTemplatesDir(dataDir string) string {
 	return userTemplates
 }
 
+// getScanTimeout 单次扫描最长运行秒数，默认不限制，可通过 HH_SCAN_TIMEOUT 开启
+func getScanTimeout() int {
+	if seconds, err := strconv.Atoi(os.Getenv("HH_SCAN_TIMEOUT")); err == nil && seconds > 0 {
+		return seconds
+	}
+	return 0
+}
+
 func getLogLevel() string {
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
 		return level
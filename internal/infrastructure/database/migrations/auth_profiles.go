@@ -0,0 +1,41 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Auth_001_Profiles{})
+}
+
+type Auth_001_Profiles struct{}
+
+func (m *Auth_001_Profiles) Version() int        { return 2025020112 }
+func (m *Auth_001_Profiles) Description() string { return "Auth: Add auth_profiles table" }
+func (m *Auth_001_Profiles) Module() string      { return "core" }
+
+func (m *Auth_001_Profiles) Up(tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS auth_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		enabled BOOLEAN DEFAULT 1,
+		username TEXT,
+		login_request_id INTEGER,
+		secret TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (target_id) REFERENCES targets(id) ON DELETE CASCADE,
+		FOREIGN KEY (login_request_id) REFERENCES http_requests(id) ON DELETE SET NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_auth_profiles_target ON auth_profiles(target_id);
+	`
+	_, err := tx.Exec(query)
+	return err
+}
+
+func (m *Auth_001_Profiles) Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS auth_profiles`)
+	return err
+}
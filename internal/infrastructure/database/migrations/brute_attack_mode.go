@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Brute_003_AttackMode{})
+}
+
+type Brute_003_AttackMode struct{}
+
+func (m *Brute_003_AttackMode) Version() int        { return 2025020102 }
+func (m *Brute_003_AttackMode) Description() string { return "Brute: Add attack mode" }
+func (m *Brute_003_AttackMode) Module() string      { return "brute" }
+
+func (m *Brute_003_AttackMode) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE brute_tasks ADD COLUMN attack_mode TEXT DEFAULT 'sniper'")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *Brute_003_AttackMode) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Brute_005_MatchMode{})
+}
+
+type Brute_005_MatchMode struct{}
+
+func (m *Brute_005_MatchMode) Version() int        { return 2025020115 }
+func (m *Brute_005_MatchMode) Description() string { return "Brute: Add match rule combination mode" }
+func (m *Brute_005_MatchMode) Module() string      { return "brute" }
+
+func (m *Brute_005_MatchMode) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE brute_tasks ADD COLUMN match_mode TEXT DEFAULT 'any'")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *Brute_005_MatchMode) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Brute_004_MatchRules{})
+}
+
+type Brute_004_MatchRules struct{}
+
+func (m *Brute_004_MatchRules) Version() int        { return 2025020103 }
+func (m *Brute_004_MatchRules) Description() string { return "Brute: Add match rules" }
+func (m *Brute_004_MatchRules) Module() string      { return "brute" }
+
+func (m *Brute_004_MatchRules) Up(tx *sql.Tx) error {
+	columns := []string{
+		"ALTER TABLE brute_tasks ADD COLUMN match_rules TEXT DEFAULT '[]'",
+		"ALTER TABLE brute_results ADD COLUMN matched_rule TEXT",
+	}
+	for _, query := range columns {
+		if _, err := tx.Exec(query); err != nil && !isDuplicateColumnError(err.Error()) {
+			return err
+		}
+	}
+	if _, err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_brute_results_status ON brute_results(task_id, status)"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (m *Brute_004_MatchRules) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP INDEX IF EXISTS idx_brute_results_status")
+	return err
+}
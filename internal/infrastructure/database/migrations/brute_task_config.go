@@ -0,0 +1,32 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Brute_002_TaskConfig{})
+}
+
+type Brute_002_TaskConfig struct{}
+
+func (m *Brute_002_TaskConfig) Version() int        { return 2025020101 }
+func (m *Brute_002_TaskConfig) Description() string { return "Brute: Add task execution config" }
+func (m *Brute_002_TaskConfig) Module() string      { return "brute" }
+
+func (m *Brute_002_TaskConfig) Up(tx *sql.Tx) error {
+	columns := []string{
+		"ALTER TABLE brute_tasks ADD COLUMN payload_set_ids TEXT DEFAULT '[]'",
+		"ALTER TABLE brute_tasks ADD COLUMN concurrency INTEGER DEFAULT 10",
+		"ALTER TABLE brute_tasks ADD COLUMN timeout INTEGER DEFAULT 10000",
+	}
+	for _, query := range columns {
+		if _, err := tx.Exec(query); err != nil && !isDuplicateColumnError(err.Error()) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Brute_002_TaskConfig) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
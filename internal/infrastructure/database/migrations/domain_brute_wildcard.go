@@ -0,0 +1,32 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&DomainBrute_002_Wildcard{})
+}
+
+type DomainBrute_002_Wildcard struct{}
+
+func (m *DomainBrute_002_Wildcard) Version() int        { return 2025020105 }
+func (m *DomainBrute_002_Wildcard) Description() string { return "DomainBrute: Add wildcard DNS" }
+func (m *DomainBrute_002_Wildcard) Module() string      { return "domain_brute" }
+
+func (m *DomainBrute_002_Wildcard) Up(tx *sql.Tx) error {
+	columns := []string{
+		"ALTER TABLE domain_brute_tasks ADD COLUMN wildcard TEXT",
+		"ALTER TABLE domain_brute_results ADD COLUMN cname TEXT",
+		"ALTER TABLE domain_brute_results ADD COLUMN wildcard INTEGER DEFAULT 0",
+	}
+	for _, query := range columns {
+		if _, err := tx.Exec(query); err != nil && !isDuplicateColumnError(err.Error()) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *DomainBrute_002_Wildcard) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
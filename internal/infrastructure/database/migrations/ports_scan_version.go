@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&PortScan_002_ServiceVersion{})
+}
+
+type PortScan_002_ServiceVersion struct{}
+
+func (m *PortScan_002_ServiceVersion) Version() int        { return 2025020104 }
+func (m *PortScan_002_ServiceVersion) Description() string { return "PortScan: Add service version" }
+func (m *PortScan_002_ServiceVersion) Module() string      { return "port_scan" }
+
+func (m *PortScan_002_ServiceVersion) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE port_scan_results ADD COLUMN version TEXT")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *PortScan_002_ServiceVersion) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_009_Engine{})
+}
+
+type Scan_009_Engine struct{}
+
+func (m *Scan_009_Engine) Version() int        { return 2025020113 }
+func (m *Scan_009_Engine) Description() string { return "Scan: Add scan engine selection" }
+func (m *Scan_009_Engine) Module() string      { return "core" }
+
+func (m *Scan_009_Engine) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE scan_tasks ADD COLUMN engine TEXT NOT NULL DEFAULT 'nuclei'")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *Scan_009_Engine) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_006_MultiTarget{})
+}
+
+type Scan_006_MultiTarget struct{}
+
+func (m *Scan_006_MultiTarget) Version() int        { return 2025020109 }
+func (m *Scan_006_MultiTarget) Description() string { return "Scan: Add multi-target scan tasks" }
+func (m *Scan_006_MultiTarget) Module() string      { return "core" }
+
+func (m *Scan_006_MultiTarget) Up(tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS scan_task_targets (
+		scan_id INTEGER NOT NULL,
+		target_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (scan_id, target_id),
+		FOREIGN KEY (scan_id) REFERENCES scan_tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY (target_id) REFERENCES targets(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_scan_task_targets_target_id ON scan_task_targets(target_id);
+	`
+	if _, err := tx.Exec(query); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec("ALTER TABLE scan_tasks ADD COLUMN target_tag TEXT")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+
+	_, err = tx.Exec("ALTER TABLE vulnerabilities ADD COLUMN target_id INTEGER")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+
+	// 已有漏洞归属到所属任务的目标
+	_, err = tx.Exec(`
+	UPDATE vulnerabilities
+	SET target_id = (SELECT target_id FROM scan_tasks WHERE scan_tasks.id = vulnerabilities.task_id)
+	WHERE target_id IS NULL`)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX IF NOT EXISTS idx_vulnerabilities_target_id ON vulnerabilities(target_id)")
+	return err
+}
+
+func (m *Scan_006_MultiTarget) Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	DROP INDEX IF EXISTS idx_vulnerabilities_target_id;
+	DROP TABLE IF EXISTS scan_task_targets;
+	`)
+	return err
+}
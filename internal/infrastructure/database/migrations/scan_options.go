@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_008_Options{})
+}
+
+type Scan_008_Options struct{}
+
+func (m *Scan_008_Options) Version() int        { return 2025020111 }
+func (m *Scan_008_Options) Description() string { return "Scan: Add per-task nuclei tuning options" }
+func (m *Scan_008_Options) Module() string      { return "core" }
+
+func (m *Scan_008_Options) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE scan_tasks ADD COLUMN options TEXT")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *Scan_008_Options) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
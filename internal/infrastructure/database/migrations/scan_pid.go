@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_004_ProcessID{})
+}
+
+type Scan_004_ProcessID struct{}
+
+func (m *Scan_004_ProcessID) Version() int        { return 2025020107 }
+func (m *Scan_004_ProcessID) Description() string { return "Scan: Add nuclei process id" }
+func (m *Scan_004_ProcessID) Module() string      { return "core" }
+
+func (m *Scan_004_ProcessID) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE scan_tasks ADD COLUMN pid INTEGER")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *Scan_004_ProcessID) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_003_Queue{})
+}
+
+type Scan_003_Queue struct{}
+
+func (m *Scan_003_Queue) Version() int        { return 2025020106 }
+func (m *Scan_003_Queue) Description() string { return "Scan: Add queue position" }
+func (m *Scan_003_Queue) Module() string      { return "core" }
+
+func (m *Scan_003_Queue) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE scan_tasks ADD COLUMN queue_position INTEGER")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+
+	_, err = tx.Exec("CREATE INDEX IF NOT EXISTS idx_scan_tasks_queue ON scan_tasks(status, queue_position)")
+	return err
+}
+
+func (m *Scan_003_Queue) Down(tx *sql.Tx) error {
+	_, err := tx.Exec("DROP INDEX IF EXISTS idx_scan_tasks_queue")
+	return err
+}
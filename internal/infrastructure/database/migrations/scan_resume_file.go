@@ -0,0 +1,26 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_005_ResumeFile{})
+}
+
+type Scan_005_ResumeFile struct{}
+
+func (m *Scan_005_ResumeFile) Version() int        { return 2025020108 }
+func (m *Scan_005_ResumeFile) Description() string { return "Scan: Add nuclei resume file" }
+func (m *Scan_005_ResumeFile) Module() string      { return "core" }
+
+func (m *Scan_005_ResumeFile) Up(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE scan_tasks ADD COLUMN resume_file TEXT")
+	if err != nil && !isDuplicateColumnError(err.Error()) {
+		return err
+	}
+	return nil
+}
+
+func (m *Scan_005_ResumeFile) Down(tx *sql.Tx) error {
+	// SQLite 不支持 DROP COLUMN
+	return nil
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_007_Schedules{})
+}
+
+type Scan_007_Schedules struct{}
+
+func (m *Scan_007_Schedules) Version() int        { return 2025020110 }
+func (m *Scan_007_Schedules) Description() string { return "Scan: Add scan_schedules table" }
+func (m *Scan_007_Schedules) Module() string      { return "core" }
+
+func (m *Scan_007_Schedules) Up(tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS scan_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		target_id INTEGER,
+		target_tag TEXT,
+		strategy TEXT NOT NULL,
+		templates TEXT,
+		cron TEXT,
+		interval_seconds INTEGER DEFAULT 0,
+		catch_up BOOLEAN DEFAULT 0,
+		enabled BOOLEAN DEFAULT 1,
+		last_run_at DATETIME,
+		next_run_at DATETIME,
+		last_task_id INTEGER,
+		last_error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (target_id) REFERENCES targets(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_scan_schedules_next_run ON scan_schedules(enabled, next_run_at);
+	`
+	_, err := tx.Exec(query)
+	return err
+}
+
+func (m *Scan_007_Schedules) Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS scan_schedules`)
+	return err
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import "database/sql"
+
+func init() {
+	Register(&Scan_010_TemplateManifest{})
+}
+
+type Scan_010_TemplateManifest struct{}
+
+func (m *Scan_010_TemplateManifest) Version() int { return 2025020114 }
+func (m *Scan_010_TemplateManifest) Description() string {
+	return "Scan: Add per-scan template manifest"
+}
+func (m *Scan_010_TemplateManifest) Module() string { return "core" }
+
+func (m *Scan_010_TemplateManifest) Up(tx *sql.Tx) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS scan_template_manifests (
+		scan_id INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		template_id TEXT NOT NULL,
+		content_hash TEXT NOT NULL,
+		PRIMARY KEY (scan_id, source, template_id),
+		FOREIGN KEY (scan_id) REFERENCES scan_tasks(id) ON DELETE CASCADE
+	);
+	`
+	_, err := tx.Exec(query)
+	return err
+}
+
+func (m *Scan_010_TemplateManifest) Down(tx *sql.Tx) error {
+	_, err := tx.Exec(`DROP TABLE IF EXISTS scan_template_manifests`)
+	return err
+}
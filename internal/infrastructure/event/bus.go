@@ -66,6 +66,10 @@ const (
 	EventScanCompleted = "scan.completed"
 	EventScanFailed    = "scan.failed"
 	EventScanStopped   = "scan.stopped"
+	EventScanQueued    = "scan.queued"
+	EventScanTimedOut  = "scan.timed_out"
+	EventScanPaused    = "scan.paused"
+	EventScanResumed   = "scan.resumed"
 	EventVulnFound     = "vulnerability.found"
 	EventTargetCreated = "target.created"
 	EventTargetDeleted = "target.deleted"
@@ -75,4 +79,20 @@ const (
 	EventBruteProgress  = "brute.progress"
 	EventBruteCompleted = "brute.completed"
 	EventBruteFailed    = "brute.failed"
+
+	// PortScan 事件
+	EventPortScanStarted   = "portscan.started"
+	EventPortScanProgress  = "portscan.progress"
+	EventPortScanCompleted = "portscan.completed"
+	EventPortScanFailed    = "portscan.failed"
+
+	// DomainBrute 事件
+	EventDomainBruteStarted   = "domainbrute.started"
+	EventDomainBruteProgress  = "domainbrute.progress"
+	EventDomainBruteCompleted = "domainbrute.completed"
+	EventDomainBruteFailed    = "domainbrute.failed"
+
+	// Schedule 事件
+	EventScheduleTriggered = "schedule.triggered"
+	EventScheduleSkipped   = "schedule.skipped"
 )
@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestBox_SealOpen 测试加密与解密
+func TestBox_SealOpen(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "keys", "secret.key")
+	box, err := LoadOrCreate(keyFile)
+	if err != nil {
+		t.Fatalf("LoadOrCreate() failed: %v", err)
+	}
+
+	sealed, err := box.Seal([]byte("Bearer abc123"))
+	if err != nil {
+		t.Fatalf("Seal() failed: %v", err)
+	}
+	if sealed == "" || sealed == "Bearer abc123" {
+		t.Fatalf("Seal() = %q, want ciphertext", sealed)
+	}
+
+	// 再次加载使用同一密钥
+	reloaded, err := LoadOrCreate(keyFile)
+	if err != nil {
+		t.Fatalf("LoadOrCreate() reload failed: %v", err)
+	}
+	plain, err := reloaded.Open(sealed)
+	if err != nil || string(plain) != "Bearer abc123" {
+		t.Fatalf("Open() = %q, %v", plain, err)
+	}
+
+	if info, err := os.Stat(keyFile); err != nil {
+		t.Errorf("key file not written: %v", err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	other, _ := NewBox(make([]byte, keySize))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Open() with wrong key should fail")
+	}
+	if _, err := NewBox([]byte("short")); err == nil {
+		t.Error("NewBox() with short key should fail")
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+// 认证配置类型
+const (
+	AuthTypeHeader = "header" // 自定义请求头
+	AuthTypeCookie = "cookie" // 静态 Cookie
+	AuthTypeBasic  = "basic"  // HTTP Basic 认证
+	AuthTypeBearer = "bearer" // Bearer Token
+	AuthTypeLogin  = "login"  // 扫描前发送 HTTP 模块中保存的登录请求，使用返回的 Cookie
+)
+
+// AuthProfile represents authentication material used when scanning a target
+// 凭据加密存储，接口只返回是否已设置
+type AuthProfile struct {
+	ID             int    `json:"id"`
+	TargetID       int    `json:"target_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Enabled        bool   `json:"enabled"`
+	Username       string `json:"username,omitempty"`         // basic 认证用户名
+	LoginRequestID *int   `json:"login_request_id,omitempty"` // login 类型使用的 HTTP 请求
+	HasSecret      bool   `json:"has_secret"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+
+	// EncryptedSecret 加密后的 AuthSecret，不返回给前端
+	EncryptedSecret string `json:"-"`
+}
+
+// AuthSecret represents the sensitive part of an auth profile
+type AuthSecret struct {
+	Headers  map[string]string `json:"headers,omitempty"`  // header 类型
+	Cookies  string            `json:"cookies,omitempty"`  // cookie 类型，格式为 "a=1; b=2"
+	Password string            `json:"password,omitempty"` // basic 类型
+	Token    string            `json:"token,omitempty"`    // bearer 类型
+}
+
+// AuthProfileRequest represents a request to create or update an auth profile
+// 更新时凭据字段全部为空表示保留原有凭据
+type AuthProfileRequest struct {
+	TargetID       int               `json:"target_id"`
+	Name           string            `json:"name"`
+	Type           string            `json:"type"`
+	Enabled        bool              `json:"enabled"`
+	Username       string            `json:"username"`
+	LoginRequestID int               `json:"login_request_id"`
+	Headers        map[string]string `json:"headers"`
+	Cookies        string            `json:"cookies"`
+	Password       string            `json:"password"`
+	Token          string            `json:"token"`
+}
@@ -2,19 +2,52 @@ package models
 
 // BruteTask represents a brute force task
 type BruteTask struct {
-	ID            int    `json:"id"`
-	Name          string `json:"name"`
-	RequestID     int    `json:"request_id"`
-	Type          string `json:"type"`
-	Status        string `json:"status"`
-	TotalPayloads int    `json:"total_payloads"`
-	SentPayloads  int    `json:"sent_payloads"`
-	SuccessCount  int    `json:"success_count"`
-	FailureCount  int    `json:"failure_count"`
-	StartedAt     string `json:"started_at"`
-	CompletedAt   string `json:"completed_at"`
-	CreatedAt     string `json:"created_at"`
-	UpdatedAt     string `json:"updated_at"`
+	ID            int              `json:"id"`
+	Name          string           `json:"name"`
+	RequestID     int              `json:"request_id"`
+	Type          string           `json:"type"`
+	AttackMode    string           `json:"attack_mode"` // sniper / battering_ram / pitchfork / cluster_bomb
+	PayloadSetIDs []int            `json:"payload_set_ids"`
+	Concurrency   int              `json:"concurrency"`
+	Timeout       int              `json:"timeout"` // 毫秒
+	MatchRules    []BruteMatchRule `json:"match_rules"`
+	MatchMode     string           `json:"match_mode"` // any: 任一规则命中 / all: 全部规则命中
+	Status        string           `json:"status"`
+	TotalPayloads int              `json:"total_payloads"`
+	SentPayloads  int              `json:"sent_payloads"`
+	SuccessCount  int              `json:"success_count"`
+	FailureCount  int              `json:"failure_count"`
+	StartedAt     string           `json:"started_at"`
+	CompletedAt   string           `json:"completed_at"`
+	CreatedAt     string           `json:"created_at"`
+	UpdatedAt     string           `json:"updated_at"`
+}
+
+// CreateBruteTaskRequest represents the request to create a brute force task
+type CreateBruteTaskRequest struct {
+	Name          string           `json:"name"`
+	RequestID     int              `json:"request_id"`
+	Type          string           `json:"type"`
+	AttackMode    string           `json:"attack_mode"`
+	PayloadSetIDs []int            `json:"payload_set_ids"`
+	Concurrency   int              `json:"concurrency"`
+	Timeout       int              `json:"timeout"`
+	MatchRules    []BruteMatchRule `json:"match_rules"`
+	MatchMode     string           `json:"match_mode"` // any / all，默认 any
+}
+
+// BruteMatchRule represents a success criterion, rules are combined by the task's match mode
+type BruteMatchRule struct {
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type"`                // status / length / body / header / time
+	Codes    []int  `json:"codes,omitempty"`     // status: 状态码集合
+	MinDelta int    `json:"min_delta,omitempty"` // length: 与基线响应长度的最小差值（绝对值）
+	Pattern  string `json:"pattern,omitempty"`   // body / header: 子串或正则
+	Regex    bool   `json:"regex,omitempty"`     // body / header: Pattern 为正则
+	Header   string `json:"header,omitempty"`    // header: 指定响应头，为空时匹配全部响应头
+	MinTime  int    `json:"min_time,omitempty"`  // time: 响应时间下限（毫秒）
+	MaxTime  int    `json:"max_time,omitempty"`  // time: 响应时间上限（毫秒）
+	Negate   bool   `json:"negate,omitempty"`    // 取反，如响应体不包含 Pattern 时命中
 }
 
 // BrutePayloadSet represents a payload set
@@ -28,12 +61,23 @@ type BrutePayloadSet struct {
 
 // BruteResult represents a single brute force attempt result
 type BruteResult struct {
-	TaskID       int                    `json:"task_id"`
-	Payload      string                 `json:"payload"`
-	Result       string                 `json:"result"`
-	Success      bool                   `json:"success"`
-	ResponseTime int64                  `json:"response_time"`
-	Error        string                 `json:"error,omitempty"`
-	Timestamp    string                 `json:"timestamp"`
-	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	ID             int    `json:"id"`
+	TaskID         int    `json:"task_id"`
+	ParamName      string `json:"param_name"`
+	Payload        string `json:"payload"`
+	Status         string `json:"status"`
+	Success        bool   `json:"success"`
+	StatusCode     int    `json:"status_code"`
+	ResponseLength int    `json:"response_length"`
+	ResponseTime   int64  `json:"response_time"`
+	MatchedRule    string `json:"matched_rule,omitempty"`
+	Body           string `json:"body,omitempty"`
+	Error          string `json:"error,omitempty"`
+	CreatedAt      string `json:"created_at"`
 }
+
+// Brute result statuses
+const (
+	BruteResultSuccess = "success"
+	BruteResultFailed  = "failed"
+)
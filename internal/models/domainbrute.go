@@ -2,15 +2,16 @@ package models
 
 // DomainBruteTask represents a domain brute force task
 type DomainBruteTask struct {
-	ID          int      `json:"id"`
-	Domain      string   `json:"domain"`
-	Wordlist    []string `json:"wordlist"`
-	Timeout     int      `json:"timeout"`
-	BatchSize   int      `json:"batch_size"`
-	Status      string   `json:"status"`
-	StartedAt   string   `json:"started_at"`
-	CompletedAt string   `json:"completed_at"`
-	CreatedAt   string   `json:"created_at"`
+	ID          int             `json:"id"`
+	Domain      string          `json:"domain"`
+	Wordlist    []string        `json:"wordlist"`
+	Timeout     int             `json:"timeout"`
+	BatchSize   int             `json:"batch_size"`
+	Status      string          `json:"status"`
+	Wildcard    *DomainWildcard `json:"wildcard,omitempty"` // 泛解析签名，未检测到泛解析时为空
+	StartedAt   string          `json:"started_at"`
+	CompletedAt string          `json:"completed_at"`
+	CreatedAt   string          `json:"created_at"`
 }
 
 // DomainBruteResult represents a domain brute force result
@@ -20,6 +21,14 @@ type DomainBruteResult struct {
 	Subdomain string   `json:"subdomain"`
 	Resolved  bool     `json:"resolved"`
 	IPs       []string `json:"ips"`
+	CNAME     string   `json:"cname"`
 	Latency   int      `json:"latency"`
+	Wildcard  bool     `json:"wildcard"` // 解析结果与泛解析签名一致
 	CreatedAt string   `json:"created_at"`
 }
+
+// DomainWildcard represents the wildcard DNS signature of a domain
+type DomainWildcard struct {
+	IPs    []string `json:"ips"`
+	CNAMEs []string `json:"cnames"`
+}
@@ -20,6 +20,7 @@ type PortScanResult struct {
 	Port      int    `json:"port"`
 	Status    string `json:"status"`
 	Service   string `json:"service"`
+	Version   string `json:"version"`
 	Banner    string `json:"banner"`
 	Latency   int    `json:"latency"`
 	CreatedAt string `json:"created_at"`
@@ -0,0 +1,34 @@
+package models
+
+// PromoteTargetsRequest represents a request to promote recon results into scan targets
+type PromoteTargetsRequest struct {
+	Source       string   `json:"source"`     // 结果来源：port_scan 或 domain_brute
+	TaskID       int      `json:"task_id"`    // 来源任务 ID
+	ResultIDs    []int    `json:"result_ids"` // 选中的结果，为空时提升任务的全部结果
+	Tags         []string `json:"tags"`       // 附加到新目标的标签
+	Scheme       string   `json:"scheme"`     // 子域名使用的协议，默认 https
+	StartScan    bool     `json:"start_scan"` // 是否为新目标创建并启动扫描任务
+	ScanStrategy string   `json:"scan_strategy"`
+	Templates    []string `json:"templates"`
+}
+
+// PromotedTarget represents a target created from a recon result
+type PromotedTarget struct {
+	ResultID   int     `json:"result_id"`
+	Target     *Target `json:"target"`
+	ScanTaskID int     `json:"scan_task_id,omitempty"`
+	ScanError  string  `json:"scan_error,omitempty"` // 扫描任务创建或启动失败的原因
+}
+
+// PromoteSkipped represents a recon result that was not promoted
+type PromoteSkipped struct {
+	ResultID int    `json:"result_id"`
+	URL      string `json:"url"`
+	Reason   string `json:"reason"`
+}
+
+// PromoteTargetsResult represents the outcome of a promotion
+type PromoteTargetsResult struct {
+	Created []*PromotedTarget `json:"created"`
+	Skipped []*PromoteSkipped `json:"skipped"`
+}
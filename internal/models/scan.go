@@ -2,23 +2,52 @@ package models
 
 // ScanTask represents a scan task
 type ScanTask struct {
-	ID                int      `json:"id"`
-	Name              *string  `json:"name,omitempty"`
-	TargetID          int      `json:"target_id"`
-	Status            string   `json:"status"`
-	Strategy          string   `json:"strategy"`
-	TemplatesUsed     []string `json:"templates_used"`
-	StartedAt         *string  `json:"started_at,omitempty"`
-	CompletedAt       *string  `json:"completed_at,omitempty"`
-	TotalTemplates    *int     `json:"total_templates,omitempty"`
-	ExecutedTemplates *int     `json:"executed_templates,omitempty"`
-	Progress          int      `json:"progress"`
-	CurrentTemplate   *string  `json:"current_template,omitempty"`
-	Error             *string  `json:"error,omitempty"`
-	FindingsCount     *int     `json:"findings_count,omitempty"`
-	CreatedAt         string   `json:"created_at"`
+	ID                int          `json:"id"`
+	Name              *string      `json:"name,omitempty"`
+	TargetID          int          `json:"target_id"`            // 主目标，多目标任务为第一个目标
+	TargetIDs         []int        `json:"target_ids,omitempty"` // 多目标任务的全部目标
+	TargetTag         *string      `json:"target_tag,omitempty"` // 按标签选择目标时的标签
+	Status            string       `json:"status"`
+	Strategy          string       `json:"strategy"`
+	TemplatesUsed     []string     `json:"templates_used"`
+	StartedAt         *string      `json:"started_at,omitempty"`
+	CompletedAt       *string      `json:"completed_at,omitempty"`
+	TotalTemplates    *int         `json:"total_templates,omitempty"`
+	ExecutedTemplates *int         `json:"executed_templates,omitempty"`
+	Progress          int          `json:"progress"`
+	CurrentTemplate   *string      `json:"current_template,omitempty"`
+	Error             *string      `json:"error,omitempty"`
+	FindingsCount     *int         `json:"findings_count,omitempty"`
+	QueuePosition     *int         `json:"queue_position,omitempty"` // 排队顺序，仅 queued 状态有效
+	PID               *int         `json:"pid,omitempty"`            // nuclei 进程 ID，仅 running 状态有效
+	ResumeFile        *string      `json:"resume_file,omitempty"`    // nuclei 断点文件，扫描完成后清除
+	Options           *ScanOptions `json:"options,omitempty"`        // 任务级调优参数，未设置的字段使用全局默认值
+	Engine            string       `json:"engine"`                   // 扫描引擎，默认为 nuclei
+	CreatedAt         string       `json:"created_at"`
 }
 
+// ScanOptions represents nuclei tuning options for a scan
+// 数值字段为 0 时使用全局默认值
+type ScanOptions struct {
+	RateLimit   int      `json:"rate_limit,omitempty"`   // 每秒最大请求数 (-rl)
+	Concurrency int      `json:"concurrency,omitempty"`  // 并行执行的模板数 (-c)
+	Timeout     int      `json:"timeout,omitempty"`      // 单个请求超时秒数 (-timeout)
+	Retries     int      `json:"retries,omitempty"`      // 请求失败重试次数 (-retries)，-1 表示不重试
+	Proxy       string   `json:"proxy,omitempty"`        // HTTP 或 SOCKS5 代理地址 (-proxy)
+	Headers     []string `json:"headers,omitempty"`      // 自定义请求头，格式为 "Name: value" (-H)
+	MaxDuration int      `json:"max_duration,omitempty"` // 扫描最长运行秒数，超时后终止，-1 表示不限制
+}
+
+// ScanEngine represents a registered scanning engine
+type ScanEngine struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Available   bool   `json:"available"`
+}
+
+// DefaultScanEngine 未指定扫描引擎时使用 nuclei
+const DefaultScanEngine = "nuclei"
+
 // ScanProgress represents the progress of a scan
 type ScanProgress struct {
 	TaskID          int    `json:"task_id"`
@@ -29,6 +58,24 @@ type ScanProgress struct {
 	CurrentTemplate string `json:"current_template"`
 	VulnCount       int    `json:"vuln_count"`
 	Error           string `json:"error,omitempty"`
+	// TargetVulns 多目标任务中各目标的漏洞数量
+	TargetVulns map[int]int `json:"target_vulns,omitempty"`
+}
+
+// TemplateManifestEntry represents a template resolved for a scan
+type TemplateManifestEntry struct {
+	TemplateID  string `json:"template_id"`
+	Source      string `json:"source"`       // "builtin" | "custom"
+	ContentHash string `json:"content_hash"` // 解析时模板内容的 SHA-256
+}
+
+// TemplateManifestDiff represents template changes between two scans
+type TemplateManifestDiff struct {
+	ScanID     int                     `json:"scan_id"`
+	BaseScanID int                     `json:"base_scan_id"`
+	Added      []TemplateManifestEntry `json:"added"`   // 本次新增的模板
+	Removed    []TemplateManifestEntry `json:"removed"` // 本次不再运行的模板
+	Changed    []TemplateManifestEntry `json:"changed"` // 内容变化的模板，为本次的版本
 }
 
 // ScanLog represents a log entry for a scan
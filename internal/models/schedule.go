@@ -0,0 +1,34 @@
+package models
+
+// ScanSchedule represents a scheduled or recurring scan
+type ScanSchedule struct {
+	ID              int      `json:"id"`
+	Name            string   `json:"name"`
+	TargetID        *int     `json:"target_id,omitempty"`  // 扫描目标，与 TargetTag 二选一
+	TargetTag       *string  `json:"target_tag,omitempty"` // 扫描带有该标签的全部目标
+	Strategy        string   `json:"strategy"`             // 扫描策略，scenario:<id> 时每次运行使用场景分组的最新模板
+	Templates       []string `json:"templates"`
+	Cron            string   `json:"cron,omitempty"`             // cron 表达式，与 IntervalSeconds 二选一
+	IntervalSeconds int      `json:"interval_seconds,omitempty"` // 固定间隔（秒）
+	CatchUp         bool     `json:"catch_up"`                   // 应用关闭期间错过的执行是否在启动后补跑一次
+	Enabled         bool     `json:"enabled"`
+	LastRunAt       *string  `json:"last_run_at,omitempty"`
+	NextRunAt       *string  `json:"next_run_at,omitempty"`
+	LastTaskID      *int     `json:"last_task_id,omitempty"` // 最近一次创建的扫描任务
+	LastError       *string  `json:"last_error,omitempty"`   // 最近一次运行失败或跳过的原因
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+// ScanScheduleRequest represents a request to create or update a scan schedule
+type ScanScheduleRequest struct {
+	Name            string   `json:"name"`
+	TargetID        int      `json:"target_id"`
+	TargetTag       string   `json:"target_tag"`
+	Strategy        string   `json:"strategy"`
+	Templates       []string `json:"templates"`
+	Cron            string   `json:"cron"`
+	IntervalSeconds int      `json:"interval_seconds"`
+	CatchUp         bool     `json:"catch_up"`
+	Enabled         bool     `json:"enabled"`
+}
@@ -232,6 +232,58 @@ type UpdateTemplateRequest struct {
 	Enabled     *bool    `json:"enabled,omitempty"`
 }
 
+// TemplateBulkToggleRequest 按分类、标签或作者批量启用或禁用内置模板，多个条件同时满足才匹配
+type TemplateBulkToggleRequest struct {
+	Category string `json:"category"` // 分类，包含其子分类
+	Tag      string `json:"tag"`
+	Author   string `json:"author"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// TemplateDiagnostic 模板校验发现的问题，Line/Column 从 1 开始，为 0 时无法定位
+type TemplateDiagnostic struct {
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"` // "error" | "warning"
+	Message  string `json:"message"`
+	Source   string `json:"source"` // "schema" | "nuclei"
+}
+
+// TemplateValidationResult 模板校验结果，没有 error 级别的问题时为有效
+type TemplateValidationResult struct {
+	Valid         bool                 `json:"valid"`
+	Error         string               `json:"error,omitempty"` // 第一个 error 级别问题，便于简单展示
+	Diagnostics   []TemplateDiagnostic `json:"diagnostics"`
+	NucleiChecked bool                 `json:"nuclei_checked"` // 是否已通过 nuclei -validate 校验
+}
+
+// TemplateTestRequest 模板试运行请求，Content 不为空时运行草稿内容，否则运行 ID 对应的已保存模板
+type TemplateTestRequest struct {
+	ID      int    `json:"id,omitempty"`
+	Content string `json:"content,omitempty"`
+	URL     string `json:"url"`
+}
+
+// TemplateTestEvent 试运行中 nuclei 报告的一次请求结果
+type TemplateTestEvent struct {
+	Matched     bool     `json:"matched"`
+	MatcherName string   `json:"matcher_name,omitempty"`
+	MatchedAt   string   `json:"matched_at,omitempty"`
+	Extracted   []string `json:"extracted,omitempty"`
+	Request     string   `json:"request,omitempty"`
+	Response    string   `json:"response,omitempty"`
+}
+
+// TemplateTestResult 模板试运行结果，只返回给调用方，不保存为漏洞
+type TemplateTestResult struct {
+	TemplateID  string               `json:"template_id"`
+	URL         string               `json:"url"`
+	Matched     bool                 `json:"matched"`
+	Events      []TemplateTestEvent  `json:"events"`
+	Diagnostics []TemplateDiagnostic `json:"diagnostics"` // nuclei 运行中输出的错误与警告
+	DurationMs  int64                `json:"duration_ms"`
+}
+
 // SyncStats 同步统计
 type SyncStats struct {
 	Inserted int `json:"inserted"`
@@ -4,6 +4,7 @@ package models
 type Vulnerability struct {
 	ID              int      `json:"id"`
 	TaskID          int      `json:"task_id"`
+	TargetID        *int     `json:"target_id,omitempty"` // 漏洞所属目标
 	TemplateID      string   `json:"template_id"`
 	Severity        string   `json:"severity"`
 	Name            string   `json:"name"`
@@ -28,9 +28,9 @@ func (f *VulnerabilityFilter) Match(vuln *Vulnerability) bool {
 		}
 	}
 
-	// 目标 ID 过滤（通过 TaskID 过滤）
+	// 目标 ID 过滤
 	if f.TargetID != nil {
-		if vuln.TaskID != *f.TargetID {
+		if vuln.TargetID == nil || *vuln.TargetID != *f.TargetID {
 			return false
 		}
 	}
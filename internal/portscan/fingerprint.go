@@ -0,0 +1,361 @@
+package portscan
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed signatures.yaml
+var defaultSignatures []byte
+
+const (
+	// maxBannerSize 读取 banner 的上限
+	maxBannerSize = 4096
+	// maxStoredBannerSize 保存的 banner 长度上限
+	maxStoredBannerSize = 512
+	// DefaultBannerTimeout 默认等待 banner 的时间
+	DefaultBannerTimeout = 2 * time.Second
+)
+
+// Probe 协议探针
+type Probe struct {
+	Name     string `yaml:"name"`
+	Payload  string `yaml:"payload"`
+	Ports    []int  `yaml:"ports"`
+	TLS      bool   `yaml:"tls"`
+	Fallback bool   `yaml:"fallback"`
+	Followup bool   `yaml:"followup"`
+}
+
+// Signature 服务签名
+type Signature struct {
+	Service string `yaml:"service"`
+	Probe   string `yaml:"probe"`
+	Pattern string `yaml:"pattern"`
+	Version string `yaml:"version"`
+
+	re *regexp.Regexp
+}
+
+// SignatureDB 探针与签名库
+type SignatureDB struct {
+	Probes     []Probe        `yaml:"probes"`
+	Signatures []Signature    `yaml:"signatures"`
+	Services   map[int]string `yaml:"services"`
+}
+
+// ParseSignatureDB 解析 YAML 格式的签名库
+func ParseSignatureDB(data []byte) (*SignatureDB, error) {
+	var db SignatureDB
+	if err := yaml.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("invalid signature database: %w", err)
+	}
+
+	for i := range db.Probes {
+		if db.Probes[i].Name == "" {
+			return nil, fmt.Errorf("probe %d: name is required", i+1)
+		}
+	}
+	for i := range db.Signatures {
+		sig := &db.Signatures[i]
+		if sig.Service == "" {
+			return nil, fmt.Errorf("signature %d: service is required", i+1)
+		}
+		re, err := regexp.Compile(sig.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d (%s): invalid pattern: %w", i+1, sig.Service, err)
+		}
+		sig.re = re
+	}
+	return &db, nil
+}
+
+// LoadSignatureDB 从文件加载签名库
+func LoadSignatureDB(path string) (*SignatureDB, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSignatureDB(data)
+}
+
+// DefaultSignatureDB 返回内置签名库
+func DefaultSignatureDB() *SignatureDB {
+	db, err := ParseSignatureDB(defaultSignatures)
+	if err != nil {
+		panic(err) // 内置签名库由测试保证有效
+	}
+	return db
+}
+
+// Merge 合并签名库，other 中的探针和签名优先
+func (db *SignatureDB) Merge(other *SignatureDB) *SignatureDB {
+	merged := &SignatureDB{
+		Probes:     append(append([]Probe{}, other.Probes...), db.Probes...),
+		Signatures: append(append([]Signature{}, other.Signatures...), db.Signatures...),
+		Services:   make(map[int]string, len(db.Services)+len(other.Services)),
+	}
+	for port, name := range db.Services {
+		merged.Services[port] = name
+	}
+	for port, name := range other.Services {
+		merged.Services[port] = name
+	}
+	return merged
+}
+
+// Match 使用签名匹配响应，返回服务名称与版本
+func (db *SignatureDB) Match(probe string, banner []byte) (service, version string, ok bool) {
+	for _, sig := range db.Signatures {
+		if sig.Probe != "" && sig.Probe != probe {
+			continue
+		}
+		match := sig.re.FindSubmatchIndex(banner)
+		if match == nil {
+			continue
+		}
+		if sig.Version != "" {
+			version = strings.TrimSpace(string(sig.re.Expand(nil, []byte(sig.Version), banner, match)))
+		}
+		return sig.Service, version, true
+	}
+	return "", "", false
+}
+
+// Fingerprint 服务识别结果
+type Fingerprint struct {
+	Service string
+	Version string
+	Banner  string
+}
+
+// Fingerprinter 抓取 banner 并识别服务
+type Fingerprinter struct {
+	db      *SignatureDB
+	timeout time.Duration
+	dialer  *net.Dialer
+}
+
+// NewFingerprinter 创建服务识别器，timeout 同时用于连接和读取
+func NewFingerprinter(db *SignatureDB, timeout time.Duration) *Fingerprinter {
+	if db == nil {
+		db = DefaultSignatureDB()
+	}
+	if timeout <= 0 {
+		timeout = DefaultBannerTimeout
+	}
+	return &Fingerprinter{
+		db:      db,
+		timeout: timeout,
+		dialer:  &net.Dialer{Timeout: timeout},
+	}
+}
+
+// Identify 识别端口上运行的服务
+// 先被动等待服务端主动发送的 banner，没有时再按端口依次发送探针
+func (f *Fingerprinter) Identify(ctx context.Context, host string, port int) *Fingerprint {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+
+	if banner := f.passive(ctx, addr, port); len(banner) > 0 {
+		return f.result("", banner, false, port)
+	}
+
+	for _, probe := range f.probesFor(port) {
+		if ctx.Err() != nil {
+			break
+		}
+		banner, tlsOK := f.active(ctx, addr, host, probe)
+		if len(banner) > 0 || tlsOK {
+			return f.result(probe.Name, banner, tlsOK, port)
+		}
+	}
+
+	return &Fingerprint{Service: f.guess(port)}
+}
+
+// passive 读取服务端主动发送的 banner，必要时在同一连接上发送后续探针
+func (f *Fingerprinter) passive(ctx context.Context, addr string, port int) []byte {
+	conn, err := f.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	banner := f.read(conn)
+	if len(banner) == 0 {
+		return nil
+	}
+
+	for _, probe := range f.db.Probes {
+		if !probe.Followup || !containsPort(probe.Ports, port) {
+			continue
+		}
+		if f.write(conn, probe.Payload) == nil {
+			banner = append(banner, f.read(conn)...)
+		}
+	}
+	return banner
+}
+
+// active 使用探针建立新连接并读取响应
+func (f *Fingerprinter) active(ctx context.Context, addr, host string, probe Probe) ([]byte, bool) {
+	conn, err := f.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, false
+	}
+	defer conn.Close()
+
+	var tlsOK bool
+	var prefix []byte
+	if probe.TLS {
+		tlsConn := tls.Client(conn, &tls.Config{
+			InsecureSkipVerify: true, // 仅用于识别服务
+			ServerName:         serverName(host),
+		})
+		_ = tlsConn.SetDeadline(time.Now().Add(f.timeout))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, false
+		}
+		tlsOK = true
+		prefix = []byte(tlsSummary(tlsConn.ConnectionState()))
+		conn = tlsConn
+	}
+
+	var banner []byte
+	if probe.Payload != "" && f.write(conn, probe.Payload) == nil {
+		banner = f.read(conn)
+	}
+	if tlsOK {
+		banner = append(append(prefix, '\n'), banner...)
+	}
+	return banner, tlsOK
+}
+
+// probesFor 返回端口适用的探针：先是指定了该端口的探针，再是兜底探针
+func (f *Fingerprinter) probesFor(port int) []Probe {
+	var matched, fallback []Probe
+	for _, probe := range f.db.Probes {
+		if probe.Followup {
+			continue
+		}
+		if containsPort(probe.Ports, port) {
+			matched = append(matched, probe)
+		} else if probe.Fallback {
+			fallback = append(fallback, probe)
+		}
+	}
+	return append(matched, fallback...)
+}
+
+// result 根据 banner 生成识别结果
+func (f *Fingerprinter) result(probe string, banner []byte, tlsOK bool, port int) *Fingerprint {
+	fp := &Fingerprint{Banner: SanitizeBanner(banner)}
+
+	// TLS 探针的响应以握手摘要开头，签名匹配解密后的内容
+	content := banner
+	if tlsOK {
+		if i := bytes.IndexByte(banner, '\n'); i >= 0 {
+			content = banner[i+1:]
+		}
+	}
+
+	service, version, ok := f.db.Match(probe, content)
+	switch {
+	case ok && tlsOK && service == "http":
+		fp.Service, fp.Version = "https", version
+	case ok && tlsOK:
+		fp.Service, fp.Version = "ssl/"+service, version
+	case ok:
+		fp.Service, fp.Version = service, version
+	case tlsOK:
+		fp.Service = "ssl"
+	default:
+		fp.Service = f.guess(port)
+	}
+	return fp
+}
+
+// guess 按端口推测服务名称，带 ? 表示未经确认
+func (f *Fingerprinter) guess(port int) string {
+	if name, ok := f.db.Services[port]; ok {
+		return name + "?"
+	}
+	return ""
+}
+
+func (f *Fingerprinter) read(conn net.Conn) []byte {
+	_ = conn.SetReadDeadline(time.Now().Add(f.timeout))
+	buf := make([]byte, maxBannerSize)
+	var n int
+	for n < len(buf) {
+		m, err := conn.Read(buf[n:])
+		n += m
+		if err != nil {
+			break
+		}
+		// 已收到数据后只再短暂等待剩余部分
+		_ = conn.SetReadDeadline(time.Now().Add(f.timeout / 4))
+	}
+	return buf[:n]
+}
+
+func (f *Fingerprinter) write(conn net.Conn, payload string) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(f.timeout))
+	_, err := io.WriteString(conn, payload)
+	return err
+}
+
+// SanitizeBanner 将 banner 转换为可显示的文本，不可打印字符以 \xNN 表示
+func SanitizeBanner(banner []byte) string {
+	var b strings.Builder
+	for _, c := range banner {
+		if b.Len() >= maxStoredBannerSize {
+			break
+		}
+		switch {
+		case c == '\r':
+			continue
+		case c == '\n' || c == '\t' || (c >= 0x20 && c < 0x7f):
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\x%02x", c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func tlsSummary(state tls.ConnectionState) string {
+	summary := "TLS " + tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		summary += " CN=" + state.PeerCertificates[0].Subject.CommonName
+	}
+	return summary
+}
+
+func serverName(host string) string {
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+	return host
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
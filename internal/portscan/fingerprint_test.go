@@ -0,0 +1,196 @@
+package portscan
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve 在本地启动 TCP 服务，handler 处理每个连接
+func serve(t *testing.T, handler func(net.Conn)) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// serverPort 返回 httptest 服务监听的端口
+func serverPort(t *testing.T, rawURL string) int {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("invalid server url: %v", err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		t.Fatalf("invalid server port: %v", err)
+	}
+	return port
+}
+
+// TestFingerprinter_Identify 测试识别常见服务
+func TestFingerprinter_Identify(t *testing.T) {
+	sshPort := serve(t, func(conn net.Conn) {
+		conn.Write([]byte("SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.6\r\n"))
+	})
+
+	smtpPort := serve(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP\r\n"))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		if strings.HasPrefix(line, "EHLO") {
+			conn.Write([]byte("250-mail.example.com\r\n250 STARTTLS\r\n"))
+		}
+	})
+
+	redisPort := serve(t, func(conn net.Conn) {
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		if line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.25.3")
+	}))
+	defer httpServer.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Apache/2.4.58")
+	}))
+	defer tlsServer.Close()
+
+	db := DefaultSignatureDB()
+	db.Probes = append(db.Probes,
+		Probe{Name: "redis", Payload: "PING\r\n", Ports: []int{redisPort}},
+		Probe{Name: "smtp-ehlo", Payload: "EHLO holehunter\r\n", Ports: []int{smtpPort}, Followup: true},
+	)
+	fp := NewFingerprinter(db, 300*time.Millisecond)
+
+	tests := []struct {
+		name        string
+		port        int
+		wantService string
+		wantVersion string
+		wantBanner  string
+	}{
+		{"SSH", sshPort, "ssh", "OpenSSH 8.9p1", "SSH-2.0-OpenSSH_8.9p1"},
+		{"SMTP EHLO", smtpPort, "smtp", "", "250 STARTTLS"},
+		{"Redis", redisPort, "redis", "", "+PONG"},
+		{"HTTP", serverPort(t, httpServer.URL), "http", "nginx 1.25.3", "Server: nginx/1.25.3"},
+		{"HTTPS", serverPort(t, tlsServer.URL), "https", "Apache httpd 2.4.58", "TLS "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fp.Identify(context.Background(), "127.0.0.1", tt.port)
+			if got.Service != tt.wantService || got.Version != tt.wantVersion {
+				t.Errorf("Identify() = %s/%s, want %s/%s (banner %q)", got.Service, got.Version, tt.wantService, tt.wantVersion, got.Banner)
+			}
+			if !strings.Contains(got.Banner, tt.wantBanner) {
+				t.Errorf("banner %q should contain %q", got.Banner, tt.wantBanner)
+			}
+		})
+	}
+}
+
+// TestSignatureDB_Match 测试内置签名库
+func TestSignatureDB_Match(t *testing.T) {
+	db := DefaultSignatureDB()
+
+	tests := []struct {
+		name        string
+		probe       string
+		banner      string
+		wantService string
+		wantVersion string
+	}{
+		{"vsftpd", "", "220 (vsFTPd 3.0.5)\r\n", "ftp", "vsftpd 3.0.5"},
+		{"Postfix", "", "220 mx.example.com ESMTP Postfix (Ubuntu)\r\n", "smtp", "Postfix"},
+		{"MySQL", "", "J\x00\x00\x00\x0a8.0.36\x00\x08\x00\x00\x00", "mysql", "MySQL 8.0.36"},
+		{"MariaDB", "", "Y\x00\x00\x00\x0a5.5.5-10.11.6-MariaDB-0+deb12u1\x00", "mysql", "MariaDB 5.5.5-10.11.6"},
+		{"Redis 需要认证", "redis", "-NOAUTH Authentication required.\r\n", "redis", "authentication required"},
+		{"Redis 签名限定探针", "", "+PONG\r\n", "", ""},
+		{"memcached", "memcached", "VERSION 1.6.21\r\n", "memcached", "memcached 1.6.21"},
+		{"VNC", "", "RFB 003.008\n", "vnc", "RFB 003.008"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, version, _ := db.Match(tt.probe, []byte(tt.banner))
+			if service != tt.wantService || version != tt.wantVersion {
+				t.Errorf("Match() = %s/%s, want %s/%s", service, version, tt.wantService, tt.wantVersion)
+			}
+		})
+	}
+}
+
+// TestLoadSignatureDB 测试从文件加载并合并签名库
+func TestLoadSignatureDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.yaml")
+	custom := `
+signatures:
+  - service: acme
+    pattern: '^ACME/(\d+)'
+    version: 'ACME $1'
+  - service: custom-ssh
+    pattern: '^SSH-2\.0-Custom'
+services:
+  22: secure-shell
+`
+	if err := os.WriteFile(path, []byte(custom), 0644); err != nil {
+		t.Fatalf("failed to write signatures: %v", err)
+	}
+
+	loaded, err := LoadSignatureDB(path)
+	if err != nil {
+		t.Fatalf("LoadSignatureDB() failed: %v", err)
+	}
+	db := DefaultSignatureDB().Merge(loaded)
+
+	if service, version, _ := db.Match("", []byte("ACME/7 ready")); service != "acme" || version != "ACME 7" {
+		t.Errorf("custom signature = %s/%s", service, version)
+	}
+	// 自定义签名优先于内置签名
+	if service, _, _ := db.Match("", []byte("SSH-2.0-Custom_1.0")); service != "custom-ssh" {
+		t.Errorf("custom signature should take precedence, got %s", service)
+	}
+	if db.Services[22] != "secure-shell" || db.Services[3306] != "mysql" {
+		t.Errorf("services = %v", db.Services)
+	}
+
+	if _, err := ParseSignatureDB([]byte("signatures:\n  - service: bad\n    pattern: '('\n")); err == nil {
+		t.Error("ParseSignatureDB() should reject invalid pattern")
+	}
+}
+
+// TestSanitizeBanner 测试 banner 转义
+func TestSanitizeBanner(t *testing.T) {
+	got := SanitizeBanner([]byte("J\x00\x00\x00\x0a8.0\r\n"))
+	if got != `J\x00\x00\x00`+"\n8.0" {
+		t.Errorf("SanitizeBanner() = %q", got)
+	}
+}
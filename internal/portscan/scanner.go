@@ -0,0 +1,157 @@
+package portscan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultTimeout 默认连接超时
+	DefaultTimeout = 2 * time.Second
+	// DefaultBatchSize 默认并发连接数
+	DefaultBatchSize = 50
+)
+
+// 端口状态
+const (
+	StatusOpen     = "open"
+	StatusClosed   = "closed"
+	StatusFiltered = "filtered"
+)
+
+// Result 单个端口的探测结果
+type Result struct {
+	Host    string
+	Port    int
+	Status  string
+	Latency time.Duration
+	Service string
+	Version string
+	Banner  string
+	Err     error
+}
+
+// Open 端口是否开放
+func (r *Result) Open() bool {
+	return r.Status == StatusOpen
+}
+
+// Config 扫描器配置
+type Config struct {
+	Timeout   time.Duration
+	BatchSize int
+	// Fingerprinter 非空时识别开放端口上的服务
+	Fingerprinter *Fingerprinter
+}
+
+// Scanner TCP connect 端口扫描器
+type Scanner struct {
+	timeout       time.Duration
+	batchSize     int
+	dialer        *net.Dialer
+	fingerprinter *Fingerprinter
+}
+
+// NewScanner 创建端口扫描器
+func NewScanner(cfg Config) *Scanner {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	return &Scanner{
+		timeout:       cfg.Timeout,
+		batchSize:     cfg.BatchSize,
+		dialer:        &net.Dialer{Timeout: cfg.Timeout},
+		fingerprinter: cfg.Fingerprinter,
+	}
+}
+
+// Scan 扫描主机的端口，onResult 在调用者的 goroutine 中串行调用
+// context 取消时返回 ctx.Err()
+func (s *Scanner) Scan(ctx context.Context, host string, ports []int, onResult func(*Result)) error {
+	jobs := make(chan int)
+	results := make(chan *Result)
+
+	// 生产者
+	go func() {
+		defer close(jobs)
+		for _, port := range ports {
+			select {
+			case jobs <- port:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// 工作池
+	workers := s.batchSize
+	if workers > len(ports) {
+		workers = len(ports)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for port := range jobs {
+				result := s.probe(ctx, host, port)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		onResult(result)
+	}
+
+	return ctx.Err()
+}
+
+// probe 探测单个端口
+func (s *Scanner) probe(ctx context.Context, host string, port int) *Result {
+	result := &Result{Host: host, Port: port}
+
+	start := time.Now()
+	conn, err := s.dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Err = err
+		result.Status = classify(err)
+		return result
+	}
+	conn.Close()
+
+	result.Status = StatusOpen
+	if s.fingerprinter != nil {
+		fp := s.fingerprinter.Identify(ctx, host, port)
+		result.Service = fp.Service
+		result.Version = fp.Version
+		result.Banner = fp.Banner
+	}
+	return result
+}
+
+// classify 根据连接错误区分关闭与被过滤
+func classify(err error) string {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return StatusClosed
+	}
+	return StatusFiltered
+}
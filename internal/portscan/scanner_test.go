@@ -0,0 +1,94 @@
+package portscan
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// listen 在本地监听随机端口
+func listen(t *testing.T) (net.Listener, int) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln, ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort 获取一个当前未监听的端口
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, port := listen(t)
+	ln.Close()
+	return port
+}
+
+// TestScanner_Scan 测试扫描开放与关闭的端口
+func TestScanner_Scan(t *testing.T) {
+	ln1, open1 := listen(t)
+	defer ln1.Close()
+	ln2, open2 := listen(t)
+	defer ln2.Close()
+	closed := closedPort(t)
+
+	scanner := NewScanner(Config{Timeout: time.Second, BatchSize: 2})
+	results := make(map[int]*Result)
+	err := scanner.Scan(context.Background(), "127.0.0.1", []int{open1, closed, open2}, func(r *Result) {
+		results[r.Port] = r
+	})
+	if err != nil {
+		t.Fatalf("Scan() failed: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if !results[open1].Open() || !results[open2].Open() {
+		t.Errorf("listening ports should be open: %+v, %+v", results[open1], results[open2])
+	}
+	if results[closed].Open() {
+		t.Errorf("port %d should not be open", closed)
+	}
+	if results[closed].Status != StatusClosed {
+		t.Errorf("refused port status = %s, want %s", results[closed].Status, StatusClosed)
+	}
+}
+
+// TestScanner_Scan_Cancel 测试取消扫描
+func TestScanner_Scan_Cancel(t *testing.T) {
+	ln, port := listen(t)
+	defer ln.Close()
+
+	ports := make([]int, 5000)
+	for i := range ports {
+		ports[i] = port
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	scanner := NewScanner(Config{BatchSize: 1})
+	count := 0
+	err := scanner.Scan(ctx, "127.0.0.1", ports, func(r *Result) {
+		count++
+		if count == 3 {
+			cancel()
+		}
+	})
+
+	if err != context.Canceled {
+		t.Errorf("Scan() error = %v, want context.Canceled", err)
+	}
+	if count >= len(ports) {
+		t.Error("Scan() should stop after cancel")
+	}
+}
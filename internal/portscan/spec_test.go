@@ -0,0 +1,60 @@
+package portscan
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParsePorts 测试端口表达式解析
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{"单个端口", "80", []int{80}, false},
+		{"列表去重排序", "443, 80,80,22", []int{22, 80, 443}, false},
+		{"范围", "8000-8003", []int{8000, 8001, 8002, 8003}, false},
+		{"排除", "1-5,!2,!4-5", []int{1, 3}, false},
+		{"排除与顺序无关", "!3,1-4", []int{1, 2, 4}, false},
+		{"全部排除", "db,!db", nil, true},
+		{"预置配置大小写", "DB,!1521-65535", []int{1433}, false},
+		{"空表达式", "", nil, true},
+		{"非法端口", "http", nil, true},
+		{"端口越界", "0-80", nil, true},
+		{"反向范围", "100-1", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePorts(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePorts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProfiles 测试预置端口配置
+func TestProfiles(t *testing.T) {
+	sizes := map[string]int{"top100": 100, "top1000": 1000, "all": MaxPort}
+	for name, spec := range Profiles() {
+		ports, err := ParsePorts(spec)
+		if err != nil {
+			t.Errorf("profile %s is invalid: %v", name, err)
+			continue
+		}
+		if want, ok := sizes[name]; ok && len(ports) != want {
+			t.Errorf("profile %s has %d ports, want %d", name, len(ports), want)
+		}
+	}
+
+	ports, _ := ParsePorts("top1000,!top100")
+	if len(ports) != 900 {
+		t.Errorf("top1000 should contain top100, got %d ports after exclusion", len(ports))
+	}
+}
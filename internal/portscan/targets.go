@@ -0,0 +1,160 @@
+package portscan
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// MaxTargets 单次展开的最大主机数量
+const MaxTargets = 65536
+
+// MaxProbes 单次创建任务的主机数与端口数乘积上限
+const MaxProbes = 1 << 20
+
+// ExpandTargets 展开目标表达式，返回去重后的主机列表（保持书写顺序）
+//
+// 表达式由逗号、空白或换行分隔，每一项可以是：
+//   - 单个 IP 或主机名：192.168.1.1、example.com
+//   - CIDR：192.168.1.0/24（前缀长度小于 31 时不包含网络地址与广播地址）
+//   - IP 范围：192.168.1.10-192.168.1.20，或简写为 192.168.1.10-20
+func ExpandTargets(spec string) ([]string, error) {
+	var hosts []string
+	seen := make(map[string]bool)
+	add := func(host string) error {
+		if seen[host] {
+			return nil
+		}
+		if len(hosts) >= MaxTargets {
+			return fmt.Errorf("too many targets, at most %d hosts are allowed", MaxTargets)
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+		return nil
+	}
+
+	for _, item := range strings.FieldsFunc(spec, isTargetSeparator) {
+		var err error
+		switch {
+		case strings.Contains(item, "/"):
+			err = expandCIDR(item, add)
+		case strings.Contains(item, "-") && isIPRange(item):
+			err = expandRange(item, add)
+		default:
+			err = addHost(item, add)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no targets specified")
+	}
+	return hosts, nil
+}
+
+func isTargetSeparator(r rune) bool {
+	return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// expandCIDR 展开 CIDR
+func expandCIDR(item string, add func(string) error) error {
+	prefix, err := netip.ParsePrefix(item)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR: %s", item)
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 16 {
+		return fmt.Errorf("CIDR %s is too large", item)
+	}
+
+	first := prefix.Addr()
+	last := first
+	for i := 0; i < 1<<hostBits-1; i++ {
+		last = last.Next()
+	}
+	// IPv4 排除网络地址与广播地址
+	if first.Is4() && hostBits >= 2 {
+		first, last = first.Next(), last.Prev()
+	}
+
+	for addr := first; ; addr = addr.Next() {
+		if err := add(addr.String()); err != nil {
+			return err
+		}
+		if addr == last {
+			return nil
+		}
+	}
+}
+
+// isIPRange 判断是否为 IP 范围（主机名中也可能包含 -）
+func isIPRange(item string) bool {
+	from, _, _ := strings.Cut(item, "-")
+	_, err := netip.ParseAddr(from)
+	return err == nil
+}
+
+// expandRange 展开 IP 范围
+func expandRange(item string, add func(string) error) error {
+	from, to, _ := strings.Cut(item, "-")
+	start, err := netip.ParseAddr(from)
+	if err != nil {
+		return fmt.Errorf("invalid IP range: %s", item)
+	}
+
+	end, err := netip.ParseAddr(to)
+	if err != nil && start.Is4() {
+		// 简写形式，只给出最后一段
+		dot := strings.LastIndex(from, ".")
+		end, err = netip.ParseAddr(from[:dot+1] + to)
+	}
+	if err != nil || end.BitLen() != start.BitLen() || end.Less(start) {
+		return fmt.Errorf("invalid IP range: %s", item)
+	}
+
+	for addr, n := start, 0; ; addr, n = addr.Next(), n+1 {
+		if n >= MaxTargets {
+			return fmt.Errorf("IP range %s is too large", item)
+		}
+		if err := add(addr.String()); err != nil {
+			return err
+		}
+		if addr == end {
+			return nil
+		}
+	}
+}
+
+// addHost 添加单个 IP 或主机名
+func addHost(item string, add func(string) error) error {
+	if addr, err := netip.ParseAddr(strings.Trim(item, "[]")); err == nil {
+		return add(addr.String())
+	}
+	if !isValidHostname(item) {
+		return fmt.Errorf("invalid target: %s", item)
+	}
+	return add(strings.ToLower(strings.TrimSuffix(item, ".")))
+}
+
+// isValidHostname 校验主机名
+func isValidHostname(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
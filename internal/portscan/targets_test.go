@@ -0,0 +1,47 @@
+package portscan
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestExpandTargets 测试目标表达式展开
+func TestExpandTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"单个 IP", "10.0.0.1", []string{"10.0.0.1"}, false},
+		{"主机名列表", "Example.com, api.example.com\nexample.com", []string{"example.com", "api.example.com"}, false},
+		{"CIDR 排除网络与广播地址", "10.0.0.0/30", []string{"10.0.0.1", "10.0.0.2"}, false},
+		{"CIDR /31", "10.0.0.5/31", []string{"10.0.0.4", "10.0.0.5"}, false},
+		{"CIDR /32", "10.0.0.5/32", []string{"10.0.0.5"}, false},
+		{"IP 范围", "10.0.0.254-10.0.1.1", []string{"10.0.0.254", "10.0.0.255", "10.0.1.0", "10.0.1.1"}, false},
+		{"IP 范围简写", "192.168.1.8-10", []string{"192.168.1.8", "192.168.1.9", "192.168.1.10"}, false},
+		{"带连字符的主机名", "my-host.local", []string{"my-host.local"}, false},
+		{"IPv6", "[::1]", []string{"::1"}, false},
+		{"空", " , ", nil, true},
+		{"反向范围", "10.0.0.9-1", nil, true},
+		{"CIDR 过大", "10.0.0.0/8", nil, true},
+		{"非法主机名", "bad_host!", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandTargets(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	hosts, err := ExpandTargets("172.16.0.0/16")
+	if err != nil || len(hosts) != 65534 {
+		t.Errorf("ExpandTargets(/16) = %d hosts, err %v", len(hosts), err)
+	}
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// AuthProfileRepository 认证配置仓储
+type AuthProfileRepository struct {
+	db *sql.DB
+}
+
+// NewAuthProfileRepository 创建认证配置仓储
+func NewAuthProfileRepository(db *sql.DB) *AuthProfileRepository {
+	return &AuthProfileRepository{db: db}
+}
+
+// authProfileColumns 认证配置查询列，顺序与 scanAuthProfile 一致
+const authProfileColumns = `id, target_id, name, type, enabled, username, login_request_id, secret, created_at, updated_at`
+
+// GetByTargetID 获取目标的全部认证配置
+func (r *AuthProfileRepository) GetByTargetID(ctx context.Context, targetID int) ([]*models.AuthProfile, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+authProfileColumns+` FROM auth_profiles WHERE target_id = ? ORDER BY id`, targetID)
+	if err != nil {
+		return nil, errors.DBError("failed to query auth profiles", err)
+	}
+	defer rows.Close()
+
+	profiles := []*models.AuthProfile{}
+	for rows.Next() {
+		p, err := r.scanAuthProfile(rows)
+		if err != nil {
+			return nil, errors.DBError("failed to scan auth profile", err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating auth profiles", err)
+	}
+
+	return profiles, nil
+}
+
+// GetByID 根据 ID 获取认证配置
+func (r *AuthProfileRepository) GetByID(ctx context.Context, id int) (*models.AuthProfile, error) {
+	p, err := r.scanAuthProfile(r.db.QueryRowContext(ctx,
+		`SELECT `+authProfileColumns+` FROM auth_profiles WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("auth profile not found")
+	}
+	if err != nil {
+		return nil, errors.DBError("failed to query auth profile", err)
+	}
+	return p, nil
+}
+
+// Create 创建认证配置，secret 字段需已加密
+func (r *AuthProfileRepository) Create(ctx context.Context, p *models.AuthProfile) error {
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO auth_profiles (target_id, name, type, enabled, username, login_request_id, secret, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))`,
+		p.TargetID, p.Name, p.Type, p.Enabled, p.Username, p.LoginRequestID, sql.NullString{String: p.EncryptedSecret, Valid: p.EncryptedSecret != ""})
+	if err != nil {
+		return errors.DBError("failed to create auth profile", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.DBError("failed to get last insert id", err)
+	}
+
+	p.ID = int(id)
+	return nil
+}
+
+// Update 更新认证配置，secret 字段需已加密
+func (r *AuthProfileRepository) Update(ctx context.Context, p *models.AuthProfile) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE auth_profiles
+		 SET name = ?, type = ?, enabled = ?, username = ?, login_request_id = ?, secret = ?, updated_at = datetime('now')
+		 WHERE id = ?`,
+		p.Name, p.Type, p.Enabled, p.Username, p.LoginRequestID, sql.NullString{String: p.EncryptedSecret, Valid: p.EncryptedSecret != ""}, p.ID)
+	if err != nil {
+		return errors.DBError("failed to update auth profile", err)
+	}
+	return nil
+}
+
+// Delete 删除认证配置
+func (r *AuthProfileRepository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM auth_profiles WHERE id = ?", id)
+	if err != nil {
+		return errors.DBError("failed to delete auth profile", err)
+	}
+	return nil
+}
+
+// scanAuthProfile 扫描一行认证配置数据
+func (r *AuthProfileRepository) scanAuthProfile(row interface{ Scan(dest ...any) error }) (*models.AuthProfile, error) {
+	var p models.AuthProfile
+	var username, secret sql.NullString
+	var loginRequestID sql.NullInt64
+
+	err := row.Scan(&p.ID, &p.TargetID, &p.Name, &p.Type, &p.Enabled, &username, &loginRequestID, &secret,
+		&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Username = username.String
+	if loginRequestID.Valid {
+		val := int(loginRequestID.Int64)
+		p.LoginRequestID = &val
+	}
+	p.EncryptedSecret = secret.String
+	p.HasSecret = secret.String != ""
+
+	return &p, nil
+}
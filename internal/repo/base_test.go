@@ -68,6 +68,7 @@ func initSchemaDB(db *sql.DB) error {
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		name TEXT,
 		target_id INTEGER NOT NULL,
+		target_tag TEXT,
 		status TEXT NOT NULL,
 		strategy TEXT,
 		templates_used TEXT,
@@ -78,12 +79,34 @@ func initSchemaDB(db *sql.DB) error {
 		progress INTEGER DEFAULT 0,
 		current_template TEXT,
 		error TEXT,
+		findings_count INTEGER DEFAULT 0,
+		queue_position INTEGER,
+		pid INTEGER,
+		resume_file TEXT,
+		options TEXT,
+		engine TEXT NOT NULL DEFAULT 'nuclei',
 		created_at TEXT
 	);
 
+	CREATE TABLE scan_task_targets (
+		scan_id INTEGER NOT NULL,
+		target_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (scan_id, target_id)
+	);
+
+	CREATE TABLE scan_template_manifests (
+		scan_id INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		template_id TEXT NOT NULL,
+		content_hash TEXT NOT NULL,
+		PRIMARY KEY (scan_id, source, template_id)
+	);
+
 	CREATE TABLE vulnerabilities (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		task_id INTEGER NOT NULL,
+		target_id INTEGER,
 		template_id TEXT NOT NULL,
 		severity TEXT,
 		name TEXT,
@@ -19,15 +19,30 @@ func NewBruteRepository(db *sql.DB) *BruteRepository {
 	return &BruteRepository{db: db}
 }
 
+const bruteTaskColumns = `
+	id, name, request_id, type, attack_mode, payload_set_ids, concurrency, timeout, match_rules, match_mode, status,
+	total_payloads, sent_payloads, success_count, failure_count,
+	started_at, completed_at, created_at, updated_at
+`
+
+const bruteResultColumns = `
+	id, task_id, param_name, payload, status, status_code, response_length,
+	response_time, matched_rule, body, error, created_at
+`
+
 // CreateTask 创建暴力破解任务
 func (r *BruteRepository) CreateTask(ctx context.Context, task *models.BruteTask) error {
+	payloadSetIDsJSON, _ := json.Marshal(task.PayloadSetIDs)
+	matchRulesJSON, _ := json.Marshal(task.MatchRules)
+
 	query := `
-		INSERT INTO brute_tasks (name, request_id, type, status)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO brute_tasks (name, request_id, type, attack_mode, payload_set_ids, concurrency, timeout, match_rules, match_mode, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
-		task.Name, task.RequestID, task.Type, task.Status,
+		task.Name, task.RequestID, task.Type, task.AttackMode, string(payloadSetIDsJSON),
+		task.Concurrency, task.Timeout, string(matchRulesJSON), task.MatchMode, task.Status,
 	)
 	if err != nil {
 		return err
@@ -44,21 +59,9 @@ func (r *BruteRepository) CreateTask(ctx context.Context, task *models.BruteTask
 
 // GetTaskByID 根据ID获取任务
 func (r *BruteRepository) GetTaskByID(ctx context.Context, id int) (*models.BruteTask, error) {
-	query := `
-		SELECT id, name, request_id, type, status, total_payloads, sent_payloads,
-		       success_count, failure_count, started_at, completed_at, created_at, updated_at
-		FROM brute_tasks
-		WHERE id = ?
-	`
-
-	var task models.BruteTask
-	var startedAt, completedAt, createdAt, updatedAt sql.NullString
+	query := `SELECT ` + bruteTaskColumns + ` FROM brute_tasks WHERE id = ?`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID, &task.Name, &task.RequestID, &task.Type, &task.Status,
-		&task.TotalPayloads, &task.SentPayloads, &task.SuccessCount, &task.FailureCount,
-		&startedAt, &completedAt, &createdAt, &updatedAt,
-	)
+	task, err := scanBruteTask(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NotFound("brute task not found")
@@ -66,30 +69,12 @@ func (r *BruteRepository) GetTaskByID(ctx context.Context, id int) (*models.Brut
 		return nil, err
 	}
 
-	if startedAt.Valid {
-		task.StartedAt = startedAt.String
-	}
-	if completedAt.Valid {
-		task.CompletedAt = completedAt.String
-	}
-	if createdAt.Valid {
-		task.CreatedAt = createdAt.String
-	}
-	if updatedAt.Valid {
-		task.UpdatedAt = updatedAt.String
-	}
-
-	return &task, nil
+	return task, nil
 }
 
 // GetAllTasks 获取所有任务
 func (r *BruteRepository) GetAllTasks(ctx context.Context) ([]*models.BruteTask, error) {
-	query := `
-		SELECT id, name, request_id, type, status, total_payloads, sent_payloads,
-		       success_count, failure_count, started_at, completed_at, created_at, updated_at
-		FROM brute_tasks
-		ORDER BY created_at DESC
-	`
+	query := `SELECT ` + bruteTaskColumns + ` FROM brute_tasks ORDER BY created_at DESC`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -99,22 +84,60 @@ func (r *BruteRepository) GetAllTasks(ctx context.Context) ([]*models.BruteTask,
 
 	var tasks []*models.BruteTask
 	for rows.Next() {
-		var task models.BruteTask
-
-		if err := rows.Scan(
-			&task.ID, &task.Name, &task.RequestID, &task.Type, &task.Status,
-			&task.TotalPayloads, &task.SentPayloads, &task.SuccessCount, &task.FailureCount,
-			&task.StartedAt, &task.CompletedAt, &task.CreatedAt, &task.UpdatedAt,
-		); err != nil {
+		task, err := scanBruteTask(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		tasks = append(tasks, &task)
+		tasks = append(tasks, task)
 	}
 
 	return tasks, rows.Err()
 }
 
+// scanBruteTask 扫描一行任务数据
+func scanBruteTask(row interface{ Scan(dest ...any) error }) (*models.BruteTask, error) {
+	var task models.BruteTask
+	var requestID sql.NullInt64
+	var attackMode, payloadSetIDs, matchRules, matchMode, startedAt, completedAt, createdAt, updatedAt sql.NullString
+
+	err := row.Scan(
+		&task.ID, &task.Name, &requestID, &task.Type, &attackMode, &payloadSetIDs,
+		&task.Concurrency, &task.Timeout, &matchRules, &matchMode, &task.Status,
+		&task.TotalPayloads, &task.SentPayloads, &task.SuccessCount, &task.FailureCount,
+		&startedAt, &completedAt, &createdAt, &updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.RequestID = int(requestID.Int64)
+	task.AttackMode = attackMode.String
+	if payloadSetIDs.Valid {
+		_ = json.Unmarshal([]byte(payloadSetIDs.String), &task.PayloadSetIDs)
+	}
+	if matchRules.Valid {
+		_ = json.Unmarshal([]byte(matchRules.String), &task.MatchRules)
+	}
+	task.MatchMode = matchMode.String
+	if task.MatchMode == "" {
+		task.MatchMode = "any"
+	}
+	if startedAt.Valid {
+		task.StartedAt = startedAt.String
+	}
+	if completedAt.Valid {
+		task.CompletedAt = completedAt.String
+	}
+	if createdAt.Valid {
+		task.CreatedAt = createdAt.String
+	}
+	if updatedAt.Valid {
+		task.UpdatedAt = updatedAt.String
+	}
+
+	return &task, nil
+}
+
 // DeleteTask 删除任务
 func (r *BruteRepository) DeleteTask(ctx context.Context, id int) error {
 	query := `DELETE FROM brute_tasks WHERE id = ?`
@@ -155,6 +178,51 @@ func (r *BruteRepository) UpdateTaskStatus(ctx context.Context, id int, status s
 	return nil
 }
 
+// MarkTaskStarted 标记任务开始执行，重置计数器
+func (r *BruteRepository) MarkTaskStarted(ctx context.Context, id int, totalPayloads int) error {
+	query := `
+		UPDATE brute_tasks
+		SET status = 'running', total_payloads = ?, sent_payloads = 0, success_count = 0, failure_count = 0,
+		    started_at = CURRENT_TIMESTAMP, completed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, totalPayloads, id)
+	return err
+}
+
+// UpdateTaskCounters 更新任务计数
+func (r *BruteRepository) UpdateTaskCounters(ctx context.Context, id int, sent, success, failure int) error {
+	query := `
+		UPDATE brute_tasks
+		SET sent_payloads = ?, success_count = ?, failure_count = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, sent, success, failure, id)
+	return err
+}
+
+// MarkTaskFinished 标记任务结束
+func (r *BruteRepository) MarkTaskFinished(ctx context.Context, id int, status string) error {
+	query := `
+		UPDATE brute_tasks
+		SET status = ?, completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, status, id)
+	return err
+}
+
+// MarkRunningTasksStopped 将仍处于 running 状态的任务标记为 stopped，返回受影响的任务数
+func (r *BruteRepository) MarkRunningTasksStopped(ctx context.Context) (int, error) {
+	query := `UPDATE brute_tasks SET status = 'stopped', completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE status = 'running'`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
 // CreatePayloadSet 创建载荷集
 func (r *BruteRepository) CreatePayloadSet(ctx context.Context, set *models.BrutePayloadSet) error {
 	configJSON, _ := json.Marshal(set.Config)
@@ -178,6 +246,33 @@ func (r *BruteRepository) CreatePayloadSet(ctx context.Context, set *models.Brut
 	return nil
 }
 
+// GetPayloadSetByID 根据ID获取载荷集
+func (r *BruteRepository) GetPayloadSetByID(ctx context.Context, id int) (*models.BrutePayloadSet, error) {
+	query := `
+		SELECT id, name, type, config, created_at
+		FROM brute_payload_sets
+		WHERE id = ?
+	`
+
+	var set models.BrutePayloadSet
+	var configJSON, createdAt sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&set.ID, &set.Name, &set.Type, &configJSON, &createdAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("brute payload set not found")
+		}
+		return nil, err
+	}
+
+	if configJSON.Valid {
+		_ = json.Unmarshal([]byte(configJSON.String), &set.Config)
+	}
+	set.CreatedAt = createdAt.String
+
+	return &set, nil
+}
+
 // GetAllPayloadSets 获取所有载荷集
 func (r *BruteRepository) GetAllPayloadSets(ctx context.Context) ([]*models.BrutePayloadSet, error) {
 	query := `
@@ -207,3 +302,80 @@ func (r *BruteRepository) GetAllPayloadSets(ctx context.Context) ([]*models.Brut
 
 	return sets, rows.Err()
 }
+
+// CreateResult 创建暴力破解结果
+func (r *BruteRepository) CreateResult(ctx context.Context, result *models.BruteResult) error {
+	query := `
+		INSERT INTO brute_results (task_id, param_name, payload, status, status_code, response_length, response_time, matched_rule, body, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, query,
+		result.TaskID, result.ParamName, result.Payload, result.Status, result.StatusCode,
+		result.ResponseLength, result.ResponseTime, result.MatchedRule, result.Body, result.Error,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	result.ID = int(id)
+	return nil
+}
+
+// GetResultsByTaskID 获取任务结果
+func (r *BruteRepository) GetResultsByTaskID(ctx context.Context, taskID int) ([]*models.BruteResult, error) {
+	query := `SELECT ` + bruteResultColumns + ` FROM brute_results WHERE task_id = ? ORDER BY id ASC`
+	return r.queryResults(ctx, query, taskID)
+}
+
+// GetSuccessResultsByTaskID 获取任务中命中规则的结果
+func (r *BruteRepository) GetSuccessResultsByTaskID(ctx context.Context, taskID int) ([]*models.BruteResult, error) {
+	query := `SELECT ` + bruteResultColumns + ` FROM brute_results WHERE task_id = ? AND status = ? ORDER BY id ASC`
+	return r.queryResults(ctx, query, taskID, models.BruteResultSuccess)
+}
+
+// queryResults 查询结果列表
+func (r *BruteRepository) queryResults(ctx context.Context, query string, args ...interface{}) ([]*models.BruteResult, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*models.BruteResult{}
+	for rows.Next() {
+		var result models.BruteResult
+		var statusCode, responseLength sql.NullInt64
+		var matchedRule, body, errStr, createdAt sql.NullString
+
+		if err := rows.Scan(
+			&result.ID, &result.TaskID, &result.ParamName, &result.Payload, &result.Status,
+			&statusCode, &responseLength, &result.ResponseTime, &matchedRule, &body, &errStr, &createdAt,
+		); err != nil {
+			return nil, err
+		}
+
+		result.Success = result.Status == models.BruteResultSuccess
+		result.StatusCode = int(statusCode.Int64)
+		result.ResponseLength = int(responseLength.Int64)
+		result.MatchedRule = matchedRule.String
+		result.Body = body.String
+		result.Error = errStr.String
+		result.CreatedAt = createdAt.String
+
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
+
+// DeleteResultsByTaskID 删除任务的所有结果
+func (r *BruteRepository) DeleteResultsByTaskID(ctx context.Context, taskID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM brute_results WHERE task_id = ?`, taskID)
+	return err
+}
@@ -44,21 +44,13 @@ func (r *DomainBruteRepository) CreateTask(ctx context.Context, task *models.Dom
 	return nil
 }
 
+const domainBruteTaskColumns = `id, domain, wordlist, timeout, batch_size, status, wildcard, started_at, completed_at, created_at`
+
 // GetTaskByID 根据ID获取任务
 func (r *DomainBruteRepository) GetTaskByID(ctx context.Context, id int) (*models.DomainBruteTask, error) {
-	query := `
-		SELECT id, domain, wordlist, timeout, batch_size, status, started_at, completed_at, created_at
-		FROM domain_brute_tasks
-		WHERE id = ?
-	`
+	query := `SELECT ` + domainBruteTaskColumns + ` FROM domain_brute_tasks WHERE id = ?`
 
-	var task models.DomainBruteTask
-	var wordlistJSON string
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID, &task.Domain, &wordlistJSON, &task.Timeout, &task.BatchSize,
-		&task.Status, &task.StartedAt, &task.CompletedAt, &task.CreatedAt,
-	)
+	task, err := scanDomainBruteTask(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NotFound("domain brute task not found")
@@ -66,17 +58,12 @@ func (r *DomainBruteRepository) GetTaskByID(ctx context.Context, id int) (*model
 		return nil, err
 	}
 
-	_ = json.Unmarshal([]byte(wordlistJSON), &task.Wordlist)
-	return &task, nil
+	return task, nil
 }
 
 // GetAllTasks 获取所有任务
 func (r *DomainBruteRepository) GetAllTasks(ctx context.Context) ([]*models.DomainBruteTask, error) {
-	query := `
-		SELECT id, domain, wordlist, timeout, batch_size, status, started_at, completed_at, created_at
-		FROM domain_brute_tasks
-		ORDER BY created_at DESC
-	`
+	query := `SELECT ` + domainBruteTaskColumns + ` FROM domain_brute_tasks ORDER BY created_at DESC`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -86,23 +73,39 @@ func (r *DomainBruteRepository) GetAllTasks(ctx context.Context) ([]*models.Doma
 
 	var tasks []*models.DomainBruteTask
 	for rows.Next() {
-		var task models.DomainBruteTask
-		var wordlistJSON string
-
-		if err := rows.Scan(
-			&task.ID, &task.Domain, &wordlistJSON, &task.Timeout, &task.BatchSize,
-			&task.Status, &task.StartedAt, &task.CompletedAt, &task.CreatedAt,
-		); err != nil {
+		task, err := scanDomainBruteTask(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		_ = json.Unmarshal([]byte(wordlistJSON), &task.Wordlist)
-		tasks = append(tasks, &task)
+		tasks = append(tasks, task)
 	}
 
 	return tasks, rows.Err()
 }
 
+// scanDomainBruteTask 扫描一行任务数据
+func scanDomainBruteTask(row interface{ Scan(dest ...any) error }) (*models.DomainBruteTask, error) {
+	var task models.DomainBruteTask
+	var wordlistJSON, wildcardJSON, startedAt, completedAt, createdAt sql.NullString
+
+	err := row.Scan(
+		&task.ID, &task.Domain, &wordlistJSON, &task.Timeout, &task.BatchSize,
+		&task.Status, &wildcardJSON, &startedAt, &completedAt, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = json.Unmarshal([]byte(wordlistJSON.String), &task.Wordlist)
+	if wildcardJSON.String != "" {
+		_ = json.Unmarshal([]byte(wildcardJSON.String), &task.Wildcard)
+	}
+	task.StartedAt = startedAt.String
+	task.CompletedAt = completedAt.String
+	task.CreatedAt = createdAt.String
+	return &task, nil
+}
+
 // UpdateTaskStatus 更新任务状态
 func (r *DomainBruteRepository) UpdateTaskStatus(ctx context.Context, id int, status string) error {
 	query := `UPDATE domain_brute_tasks SET status = ? WHERE id = ?`
@@ -123,25 +126,74 @@ func (r *DomainBruteRepository) UpdateTaskStatus(ctx context.Context, id int, st
 	return nil
 }
 
+// MarkTaskStarted 标记任务开始执行
+func (r *DomainBruteRepository) MarkTaskStarted(ctx context.Context, id int) error {
+	query := `UPDATE domain_brute_tasks SET status = 'running', wildcard = NULL, started_at = CURRENT_TIMESTAMP, completed_at = NULL WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// UpdateTaskWildcard 保存检测到的泛解析签名
+func (r *DomainBruteRepository) UpdateTaskWildcard(ctx context.Context, id int, wildcard *models.DomainWildcard) error {
+	var value interface{}
+	if wildcard != nil {
+		data, err := json.Marshal(wildcard)
+		if err != nil {
+			return err
+		}
+		value = string(data)
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE domain_brute_tasks SET wildcard = ? WHERE id = ?`, value, id)
+	return err
+}
+
+// MarkTaskFinished 标记任务结束
+func (r *DomainBruteRepository) MarkTaskFinished(ctx context.Context, id int, status string) error {
+	query := `UPDATE domain_brute_tasks SET status = ?, completed_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, status, id)
+	return err
+}
+
+// MarkRunningTasksStopped 将仍处于 running 状态的任务标记为 stopped，返回受影响的任务数
+func (r *DomainBruteRepository) MarkRunningTasksStopped(ctx context.Context) (int, error) {
+	query := `UPDATE domain_brute_tasks SET status = 'stopped', completed_at = CURRENT_TIMESTAMP WHERE status = 'running'`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
 // CreateResult 创建扫描结果
 func (r *DomainBruteRepository) CreateResult(ctx context.Context, result *models.DomainBruteResult) error {
 	ipsJSON, _ := json.Marshal(result.IPs)
 
 	query := `
-		INSERT INTO domain_brute_results (task_id, subdomain, resolved, ips, latency)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO domain_brute_results (task_id, subdomain, resolved, ips, cname, latency, wildcard)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		result.TaskID, result.Subdomain, result.Resolved, ipsJSON, result.Latency,
+	res, err := r.db.ExecContext(ctx, query,
+		result.TaskID, result.Subdomain, result.Resolved, ipsJSON, result.CNAME, result.Latency, result.Wildcard,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	result.ID = int(id)
+	return nil
 }
 
 // GetResultsByTaskID 获取任务结果
 func (r *DomainBruteRepository) GetResultsByTaskID(ctx context.Context, taskID int) ([]*models.DomainBruteResult, error) {
 	query := `
-		SELECT id, task_id, subdomain, resolved, ips, latency, created_at
+		SELECT id, task_id, subdomain, resolved, ips, cname, latency, wildcard, created_at
 		FROM domain_brute_results
 		WHERE task_id = ?
 		ORDER BY subdomain ASC
@@ -153,21 +205,31 @@ func (r *DomainBruteRepository) GetResultsByTaskID(ctx context.Context, taskID i
 	}
 	defer rows.Close()
 
-	var results []*models.DomainBruteResult
+	results := []*models.DomainBruteResult{}
 	for rows.Next() {
 		var result models.DomainBruteResult
-		var ipsJSON string
+		var ipsJSON, cname, createdAt sql.NullString
+		var wildcard sql.NullBool
 
 		if err := rows.Scan(
 			&result.ID, &result.TaskID, &result.Subdomain, &result.Resolved,
-			&ipsJSON, &result.Latency, &result.CreatedAt,
+			&ipsJSON, &cname, &result.Latency, &wildcard, &createdAt,
 		); err != nil {
 			return nil, err
 		}
 
-		json.Unmarshal([]byte(ipsJSON), &result.IPs)
+		_ = json.Unmarshal([]byte(ipsJSON.String), &result.IPs)
+		result.CNAME = cname.String
+		result.Wildcard = wildcard.Bool
+		result.CreatedAt = createdAt.String
 		results = append(results, &result)
 	}
 
 	return results, rows.Err()
 }
+
+// DeleteResultsByTaskID 删除任务的所有结果
+func (r *DomainBruteRepository) DeleteResultsByTaskID(ctx context.Context, taskID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM domain_brute_results WHERE task_id = ?`, taskID)
+	return err
+}
@@ -44,21 +44,53 @@ func (r *PortScanRepository) CreateTask(ctx context.Context, task *models.PortSc
 	return nil
 }
 
+// CreateTasks 在同一事务中创建多个端口扫描任务，任一失败时全部回滚
+func (r *PortScanRepository) CreateTasks(ctx context.Context, tasks []*models.PortScanTask) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DBError("failed to begin transaction", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO port_scan_tasks (target, ports, timeout, batch_size, status)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return errors.DBError("failed to prepare port scan task insert", err)
+	}
+	defer stmt.Close()
+
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		portsJSON, _ := json.Marshal(task.Ports)
+		result, err := stmt.ExecContext(ctx, task.Target, portsJSON, task.Timeout, task.BatchSize, task.Status)
+		if err != nil {
+			return errors.DBError("failed to create port scan task", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return errors.DBError("failed to get port scan task id", err)
+		}
+		ids[i] = int(id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DBError("failed to commit port scan tasks", err)
+	}
+	for i, task := range tasks {
+		task.ID = ids[i]
+	}
+	return nil
+}
+
+const portScanTaskColumns = `id, target, ports, timeout, batch_size, status, started_at, completed_at, created_at`
+
 // GetTaskByID 根据ID获取任务
 func (r *PortScanRepository) GetTaskByID(ctx context.Context, id int) (*models.PortScanTask, error) {
-	query := `
-		SELECT id, target, ports, timeout, batch_size, status, started_at, completed_at, created_at
-		FROM port_scan_tasks
-		WHERE id = ?
-	`
-
-	var task models.PortScanTask
-	var portsJSON string
+	query := `SELECT ` + portScanTaskColumns + ` FROM port_scan_tasks WHERE id = ?`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID, &task.Target, &portsJSON, &task.Timeout, &task.BatchSize,
-		&task.Status, &task.StartedAt, &task.CompletedAt, &task.CreatedAt,
-	)
+	task, err := scanPortScanTask(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NotFound("port scan task not found")
@@ -66,17 +98,12 @@ func (r *PortScanRepository) GetTaskByID(ctx context.Context, id int) (*models.P
 		return nil, err
 	}
 
-	json.Unmarshal([]byte(portsJSON), &task.Ports)
-	return &task, nil
+	return task, nil
 }
 
 // GetAllTasks 获取所有任务
 func (r *PortScanRepository) GetAllTasks(ctx context.Context) ([]*models.PortScanTask, error) {
-	query := `
-		SELECT id, target, ports, timeout, batch_size, status, started_at, completed_at, created_at
-		FROM port_scan_tasks
-		ORDER BY created_at DESC
-	`
+	query := `SELECT ` + portScanTaskColumns + ` FROM port_scan_tasks ORDER BY created_at DESC`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -86,23 +113,37 @@ func (r *PortScanRepository) GetAllTasks(ctx context.Context) ([]*models.PortSca
 
 	var tasks []*models.PortScanTask
 	for rows.Next() {
-		var task models.PortScanTask
-		var portsJSON string
-
-		if err := rows.Scan(
-			&task.ID, &task.Target, &portsJSON, &task.Timeout, &task.BatchSize,
-			&task.Status, &task.StartedAt, &task.CompletedAt, &task.CreatedAt,
-		); err != nil {
+		task, err := scanPortScanTask(rows)
+		if err != nil {
 			return nil, err
 		}
-
-		json.Unmarshal([]byte(portsJSON), &task.Ports)
-		tasks = append(tasks, &task)
+		tasks = append(tasks, task)
 	}
 
 	return tasks, rows.Err()
 }
 
+// scanPortScanTask 扫描一行任务数据
+func scanPortScanTask(row interface{ Scan(dest ...any) error }) (*models.PortScanTask, error) {
+	var task models.PortScanTask
+	var portsJSON string
+	var startedAt, completedAt, createdAt sql.NullString
+
+	err := row.Scan(
+		&task.ID, &task.Target, &portsJSON, &task.Timeout, &task.BatchSize,
+		&task.Status, &startedAt, &completedAt, &createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal([]byte(portsJSON), &task.Ports)
+	task.StartedAt = startedAt.String
+	task.CompletedAt = completedAt.String
+	task.CreatedAt = createdAt.String
+	return &task, nil
+}
+
 // UpdateTaskStatus 更新任务状态
 func (r *PortScanRepository) UpdateTaskStatus(ctx context.Context, id int, status string) error {
 	query := `UPDATE port_scan_tasks SET status = ? WHERE id = ?`
@@ -123,23 +164,58 @@ func (r *PortScanRepository) UpdateTaskStatus(ctx context.Context, id int, statu
 	return nil
 }
 
+// MarkTaskStarted 标记任务开始执行
+func (r *PortScanRepository) MarkTaskStarted(ctx context.Context, id int) error {
+	query := `UPDATE port_scan_tasks SET status = 'running', started_at = CURRENT_TIMESTAMP, completed_at = NULL WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// MarkTaskFinished 标记任务结束
+func (r *PortScanRepository) MarkTaskFinished(ctx context.Context, id int, status string) error {
+	query := `UPDATE port_scan_tasks SET status = ?, completed_at = CURRENT_TIMESTAMP WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, status, id)
+	return err
+}
+
+// MarkRunningTasksStopped 将仍处于 running 状态的任务标记为 stopped，返回受影响的任务数
+func (r *PortScanRepository) MarkRunningTasksStopped(ctx context.Context) (int, error) {
+	query := `UPDATE port_scan_tasks SET status = 'stopped', completed_at = CURRENT_TIMESTAMP WHERE status = 'running'`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
 // CreateResult 创建扫描结果
 func (r *PortScanRepository) CreateResult(ctx context.Context, result *models.PortScanResult) error {
 	query := `
-		INSERT INTO port_scan_results (task_id, port, status, service, banner, latency)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO port_scan_results (task_id, port, status, service, version, banner, latency)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
-		result.TaskID, result.Port, result.Status, result.Service, result.Banner, result.Latency,
+	res, err := r.db.ExecContext(ctx, query,
+		result.TaskID, result.Port, result.Status, result.Service, result.Version, result.Banner, result.Latency,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	result.ID = int(id)
+	return nil
 }
 
 // GetResultsByTaskID 获取任务结果
 func (r *PortScanRepository) GetResultsByTaskID(ctx context.Context, taskID int) ([]*models.PortScanResult, error) {
 	query := `
-		SELECT id, task_id, port, status, service, banner, latency, created_at
+		SELECT id, task_id, port, status, service, version, banner, latency, created_at
 		FROM port_scan_results
 		WHERE task_id = ?
 		ORDER BY port ASC
@@ -151,19 +227,30 @@ func (r *PortScanRepository) GetResultsByTaskID(ctx context.Context, taskID int)
 	}
 	defer rows.Close()
 
-	var results []*models.PortScanResult
+	results := []*models.PortScanResult{}
 	for rows.Next() {
 		var result models.PortScanResult
+		var service, version, banner, createdAt sql.NullString
 
 		if err := rows.Scan(
 			&result.ID, &result.TaskID, &result.Port, &result.Status,
-			&result.Service, &result.Banner, &result.Latency, &result.CreatedAt,
+			&service, &version, &banner, &result.Latency, &createdAt,
 		); err != nil {
 			return nil, err
 		}
 
+		result.Service = service.String
+		result.Version = version.String
+		result.Banner = banner.String
+		result.CreatedAt = createdAt.String
 		results = append(results, &result)
 	}
 
 	return results, rows.Err()
 }
+
+// DeleteResultsByTaskID 删除任务的所有结果
+func (r *PortScanRepository) DeleteResultsByTaskID(ctx context.Context, taskID int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM port_scan_results WHERE task_id = ?`, taskID)
+	return err
+}
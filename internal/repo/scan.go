@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"strings"
 
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
 	"github.com/holehunter/holehunter/internal/infrastructure/logger"
@@ -21,6 +22,11 @@ func NewScanRepository(db *sql.DB, logger *logger.Logger) *ScanRepository {
 	return &ScanRepository{db: db, logger: logger}
 }
 
+// scanTaskColumns 扫描任务查询列，顺序与 scanTask 一致
+const scanTaskColumns = `id, name, target_id, target_tag, status, strategy, templates_used,
+	started_at, completed_at, total_templates, executed_templates,
+	progress, current_template, error, findings_count, queue_position, pid, resume_file, options, engine, created_at`
+
 // GetAll 获取所有扫描任务
 func (r *ScanRepository) GetAll(ctx context.Context) ([]*models.ScanTask, error) {
 	return r.GetAllPaged(ctx, 0, 0)
@@ -28,12 +34,7 @@ func (r *ScanRepository) GetAll(ctx context.Context) ([]*models.ScanTask, error)
 
 // GetAllPaged 分页获取扫描任务
 func (r *ScanRepository) GetAllPaged(ctx context.Context, offset, limit int) ([]*models.ScanTask, error) {
-	query := `
-		SELECT id, name, target_id, status, strategy, templates_used,
-		       started_at, completed_at, total_templates, executed_templates,
-		       progress, current_template, error, findings_count, created_at
-		FROM scan_tasks ORDER BY created_at DESC
-	`
+	query := `SELECT ` + scanTaskColumns + ` FROM scan_tasks ORDER BY created_at DESC`
 
 	// 添加分页
 	if limit > 0 {
@@ -59,23 +60,16 @@ func (r *ScanRepository) GetAllPaged(ctx context.Context, offset, limit int) ([]
 		return nil, errors.DBError("error iterating scan tasks", err)
 	}
 
+	if err := r.attachTargetIDs(ctx, tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
 // GetByID 根据 ID 获取扫描任务
 func (r *ScanRepository) GetByID(ctx context.Context, id int) (*models.ScanTask, error) {
-	var t models.ScanTask
-	var name, startedAt, completedAt, templatesUsed, currentTemplate, errStr sql.NullString
-	var totalTemplates, executedTemplates, findingsCount sql.NullInt64
-
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, name, target_id, status, strategy, templates_used,
-		         started_at, completed_at, total_templates, executed_templates,
-		         progress, current_template, error, findings_count, created_at
-		  FROM scan_tasks WHERE id = ?`, id).
-		Scan(&t.ID, &name, &t.TargetID, &t.Status, &t.Strategy, &templatesUsed,
-			&startedAt, &completedAt, &totalTemplates, &executedTemplates,
-			&t.Progress, &currentTemplate, &errStr, &findingsCount, &t.CreatedAt)
+	t, err := r.scanTask(r.db.QueryRowContext(ctx,
+		`SELECT `+scanTaskColumns+` FROM scan_tasks WHERE id = ?`, id))
 
 	if err == sql.ErrNoRows {
 		return nil, errors.NotFound("scan task not found")
@@ -84,18 +78,18 @@ func (r *ScanRepository) GetByID(ctx context.Context, id int) (*models.ScanTask,
 		return nil, errors.DBError("failed to query scan task", err)
 	}
 
-	r.mapScanTaskFields(&t, name, startedAt, completedAt, templatesUsed, currentTemplate, errStr, totalTemplates, executedTemplates, findingsCount)
-
-	return &t, nil
+	if err := r.attachTargetIDs(ctx, []*models.ScanTask{t}); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
-// GetByTargetID 根据目标 ID 获取扫描任务
+// GetByTargetID 根据目标 ID 获取扫描任务，包括覆盖该目标的多目标任务
 func (r *ScanRepository) GetByTargetID(ctx context.Context, targetID int) ([]*models.ScanTask, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, name, target_id, status, strategy, templates_used,
-		         started_at, completed_at, total_templates, executed_templates,
-		         progress, current_template, error, created_at
-		  FROM scan_tasks WHERE target_id = ? ORDER BY created_at DESC`, targetID)
+		`SELECT `+scanTaskColumns+` FROM scan_tasks
+		 WHERE target_id = ? OR id IN (SELECT scan_id FROM scan_task_targets WHERE target_id = ?)
+		 ORDER BY created_at DESC`, targetID, targetID)
 	if err != nil {
 		return nil, errors.DBError("failed to query scan tasks by target", err)
 	}
@@ -110,20 +104,46 @@ func (r *ScanRepository) GetByTargetID(ctx context.Context, targetID int) ([]*mo
 		tasks = append(tasks, t)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating scan tasks", err)
+	}
+
+	if err := r.attachTargetIDs(ctx, tasks); err != nil {
+		return nil, err
+	}
 	return tasks, nil
 }
 
-// Create 创建扫描任务
+// Create 创建扫描任务，多目标任务同时保存目标列表
 func (r *ScanRepository) Create(ctx context.Context, t *models.ScanTask) error {
 	templatesJSON, err := json.Marshal(t.TemplatesUsed)
 	if err != nil {
 		return errors.Internal("failed to marshal templates", err)
 	}
 
-	result, err := r.db.ExecContext(ctx,
-		`INSERT INTO scan_tasks (name, target_id, status, strategy, templates_used, created_at)
-		 VALUES (?, ?, ?, ?, ?, datetime('now'))`,
-		t.Name, t.TargetID, t.Status, t.Strategy, string(templatesJSON))
+	var optionsJSON interface{}
+	if t.Options != nil {
+		data, err := json.Marshal(t.Options)
+		if err != nil {
+			return errors.Internal("failed to marshal scan options", err)
+		}
+		optionsJSON = string(data)
+	}
+
+	if t.Engine == "" {
+		t.Engine = models.DefaultScanEngine
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DBError("failed to begin transaction", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO scan_tasks (name, target_id, target_tag, status, strategy, templates_used, options, engine, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
+		t.Name, t.TargetID, t.TargetTag, t.Status, t.Strategy, string(templatesJSON), optionsJSON, t.Engine)
 	if err != nil {
 		return errors.DBError("failed to create scan task", err)
 	}
@@ -133,6 +153,20 @@ func (r *ScanRepository) Create(ctx context.Context, t *models.ScanTask) error {
 		return errors.DBError("failed to get last insert id", err)
 	}
 
+	if len(t.TargetIDs) > 1 {
+		for i, targetID := range t.TargetIDs {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO scan_task_targets (scan_id, target_id, position) VALUES (?, ?, ?)",
+				id, targetID, i); err != nil {
+				return errors.DBError("failed to save scan task targets", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DBError("failed to commit scan task", err)
+	}
+
 	t.ID = int(id)
 	return nil
 }
@@ -146,10 +180,14 @@ func (r *ScanRepository) UpdateStatus(ctx context.Context, id int, status string
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	// 更新状态
+	// 更新状态，离开队列时清除排队顺序，结束运行时清除进程 ID
+	// 暂停的进程仍然存在，保留进程 ID 以便异常退出后终止遗留的挂起进程
 	_, err = tx.ExecContext(ctx,
-		"UPDATE scan_tasks SET status = ? WHERE id = ?",
-		status, id)
+		`UPDATE scan_tasks
+		 SET status = ?, queue_position = NULL,
+		     pid = CASE WHEN ? IN ('running', 'paused') THEN pid ELSE NULL END
+		 WHERE id = ?`,
+		status, status, id)
 	if err != nil {
 		return errors.DBError("failed to update scan status", err)
 	}
@@ -164,7 +202,7 @@ func (r *ScanRepository) UpdateStatus(ctx context.Context, id int, status string
 	}
 
 	// 如果扫描完成或失败，更新 completed_at
-	if status == "completed" || status == "failed" || status == "stopped" {
+	if status == "completed" || status == "failed" || status == "stopped" || status == "interrupted" || status == "timed_out" {
 		_, err = tx.ExecContext(ctx,
 			"UPDATE scan_tasks SET completed_at = datetime('now') WHERE id = ?", id)
 		if err != nil {
@@ -189,6 +227,109 @@ func (r *ScanRepository) UpdateProgress(ctx context.Context, id int, progress mo
 	return nil
 }
 
+// UpdatePID 记录扫描任务的 nuclei 进程 ID
+func (r *ScanRepository) UpdatePID(ctx context.Context, id, pid int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE scan_tasks SET pid = ? WHERE id = ?", pid, id)
+	if err != nil {
+		return errors.DBError("failed to update scan pid", err)
+	}
+	return nil
+}
+
+// UpdateResumeFile 记录扫描任务的断点文件，path 为空时清除
+func (r *ScanRepository) UpdateResumeFile(ctx context.Context, id int, path string) error {
+	var value interface{}
+	if path != "" {
+		value = path
+	}
+	_, err := r.db.ExecContext(ctx, "UPDATE scan_tasks SET resume_file = ? WHERE id = ?", value, id)
+	if err != nil {
+		return errors.DBError("failed to update scan resume file", err)
+	}
+	return nil
+}
+
+// SaveTemplateManifest 保存扫描任务的模板清单并以清单大小作为模板总数，已有清单被替换
+func (r *ScanRepository) SaveTemplateManifest(ctx context.Context, scanID int, entries []models.TemplateManifestEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DBError("failed to begin transaction", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM scan_template_manifests WHERE scan_id = ?", scanID); err != nil {
+		return errors.DBError("failed to clear template manifest", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO scan_template_manifests (scan_id, source, template_id, content_hash) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return errors.DBError("failed to prepare template manifest insert", err)
+	}
+	defer stmt.Close()
+	for _, e := range entries {
+		if _, err := stmt.ExecContext(ctx, scanID, e.Source, e.TemplateID, e.ContentHash); err != nil {
+			return errors.DBError("failed to save template manifest", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE scan_tasks SET total_templates = ? WHERE id = ?", len(entries), scanID); err != nil {
+		return errors.DBError("failed to update total templates", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DBError("failed to commit template manifest", err)
+	}
+	return nil
+}
+
+// GetTemplateManifest 获取扫描任务的模板清单，按来源与模板 ID 排序
+func (r *ScanRepository) GetTemplateManifest(ctx context.Context, scanID int) ([]models.TemplateManifestEntry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT template_id, source, content_hash
+		 FROM scan_template_manifests
+		 WHERE scan_id = ?
+		 ORDER BY source, template_id`, scanID)
+	if err != nil {
+		return nil, errors.DBError("failed to query template manifest", err)
+	}
+	defer rows.Close()
+
+	var entries []models.TemplateManifestEntry
+	for rows.Next() {
+		var e models.TemplateManifestEntry
+		if err := rows.Scan(&e.TemplateID, &e.Source, &e.ContentHash); err != nil {
+			return nil, errors.DBError("failed to scan template manifest", err)
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating template manifest", err)
+	}
+	return entries, nil
+}
+
+// GetPreviousManifestScanID 获取同一目标上一个带模板清单的扫描任务 ID，不存在时返回 0
+func (r *ScanRepository) GetPreviousManifestScanID(ctx context.Context, scanID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT s.id FROM scan_tasks s
+		 WHERE s.target_id = (SELECT target_id FROM scan_tasks WHERE id = ?)
+		   AND s.id < ?
+		   AND EXISTS (SELECT 1 FROM scan_template_manifests m WHERE m.scan_id = s.id)
+		 ORDER BY s.id DESC
+		 LIMIT 1`, scanID, scanID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.DBError("failed to query previous scan", err)
+	}
+	return id, nil
+}
+
 // UpdateFindingsCount 更新扫描任务的漏洞数量
 func (r *ScanRepository) UpdateFindingsCount(ctx context.Context, scanID int, count int) error {
 	_, err := r.db.ExecContext(ctx,
@@ -220,6 +361,110 @@ func (r *ScanRepository) Update(ctx context.Context, t *models.ScanTask) error {
 	return nil
 }
 
+// GetByStatus 获取指定状态的扫描任务
+func (r *ScanRepository) GetByStatus(ctx context.Context, status string) ([]*models.ScanTask, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+scanTaskColumns+` FROM scan_tasks WHERE status = ? ORDER BY id`, status)
+	if err != nil {
+		return nil, errors.DBError("failed to query scan tasks by status", err)
+	}
+	defer rows.Close()
+
+	tasks := []*models.ScanTask{}
+	for rows.Next() {
+		t, err := r.scanTask(rows)
+		if err != nil {
+			return nil, errors.DBError("failed to scan scan task", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating scan tasks", err)
+	}
+
+	if err := r.attachTargetIDs(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// Enqueue 将扫描任务加入队列末尾
+func (r *ScanRepository) Enqueue(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE scan_tasks
+		 SET status = 'queued',
+		     queue_position = (SELECT COALESCE(MAX(queue_position), 0) + 1 FROM scan_tasks WHERE status = 'queued')
+		 WHERE id = ?`, id)
+	if err != nil {
+		return errors.DBError("failed to enqueue scan task", err)
+	}
+	return nil
+}
+
+// GetQueued 按排队顺序获取排队中的扫描任务
+func (r *ScanRepository) GetQueued(ctx context.Context) ([]*models.ScanTask, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+scanTaskColumns+` FROM scan_tasks WHERE status = 'queued' ORDER BY queue_position, id`)
+	if err != nil {
+		return nil, errors.DBError("failed to query queued scan tasks", err)
+	}
+	defer rows.Close()
+
+	tasks := []*models.ScanTask{}
+	for rows.Next() {
+		t, err := r.scanTask(rows)
+		if err != nil {
+			return nil, errors.DBError("failed to scan scan task", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating queued scan tasks", err)
+	}
+
+	if err := r.attachTargetIDs(ctx, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// NextQueued 获取队首的扫描任务，队列为空时返回 NotFound
+func (r *ScanRepository) NextQueued(ctx context.Context) (*models.ScanTask, error) {
+	t, err := r.scanTask(r.db.QueryRowContext(ctx,
+		`SELECT `+scanTaskColumns+` FROM scan_tasks WHERE status = 'queued' ORDER BY queue_position, id LIMIT 1`))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("scan queue is empty")
+	}
+	if err != nil {
+		return nil, errors.DBError("failed to query next queued scan task", err)
+	}
+	if err := r.attachTargetIDs(ctx, []*models.ScanTask{t}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetQueuePositions 按给定顺序重排队列
+func (r *ScanRepository) SetQueuePositions(ctx context.Context, ids []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DBError("failed to begin transaction", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE scan_tasks SET queue_position = ? WHERE id = ? AND status = 'queued'",
+			i+1, id); err != nil {
+			return errors.DBError("failed to update queue position", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // Delete 删除扫描任务
 func (r *ScanRepository) Delete(ctx context.Context, id int) error {
 	_, err := r.db.ExecContext(ctx, "DELETE FROM scan_tasks WHERE id = ?", id)
@@ -291,23 +536,104 @@ func (r *ScanRepository) mapScanTaskFields(t *models.ScanTask,
 }
 
 // scanTask 扫描一行扫描任务数据
-func (r *ScanRepository) scanTask(rows *sql.Rows) (*models.ScanTask, error) {
+func (r *ScanRepository) scanTask(row interface{ Scan(dest ...any) error }) (*models.ScanTask, error) {
 	var t models.ScanTask
-	var name, startedAt, completedAt, templatesUsed, currentTemplate, errStr sql.NullString
-	var totalTemplates, executedTemplates, findingsCount sql.NullInt64
+	var name, targetTag, startedAt, completedAt, templatesUsed, currentTemplate, errStr, resumeFile, options, engine sql.NullString
+	var totalTemplates, executedTemplates, findingsCount, queuePosition, pid sql.NullInt64
 
-	err := rows.Scan(&t.ID, &name, &t.TargetID, &t.Status, &t.Strategy, &templatesUsed,
+	err := row.Scan(&t.ID, &name, &t.TargetID, &targetTag, &t.Status, &t.Strategy, &templatesUsed,
 		&startedAt, &completedAt, &totalTemplates, &executedTemplates,
-		&t.Progress, &currentTemplate, &errStr, &findingsCount, &t.CreatedAt)
+		&t.Progress, &currentTemplate, &errStr, &findingsCount, &queuePosition, &pid, &resumeFile, &options, &engine, &t.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
 	r.mapScanTaskFields(&t, name, startedAt, completedAt, templatesUsed, currentTemplate, errStr, totalTemplates, executedTemplates, findingsCount)
+	if targetTag.Valid {
+		t.TargetTag = &targetTag.String
+	}
+	if queuePosition.Valid {
+		val := int(queuePosition.Int64)
+		t.QueuePosition = &val
+	}
+	if pid.Valid {
+		val := int(pid.Int64)
+		t.PID = &val
+	}
+	if resumeFile.Valid {
+		t.ResumeFile = &resumeFile.String
+	}
+	if options.Valid && options.String != "" {
+		var opts models.ScanOptions
+		if err := json.Unmarshal([]byte(options.String), &opts); err != nil {
+			r.logger.Warn("Failed to unmarshal options for scan task %d: %v", t.ID, err)
+		} else {
+			t.Options = &opts
+		}
+	}
+	t.Engine = models.DefaultScanEngine
+	if engine.Valid && engine.String != "" {
+		t.Engine = engine.String
+	}
 
 	return &t, nil
 }
 
+// attachTargetIDsBatch 单次查询的任务数，避免超出 SQLite 参数数量限制
+const attachTargetIDsBatch = 500
+
+// attachTargetIDs 为多目标任务填充目标列表
+func (r *ScanRepository) attachTargetIDs(ctx context.Context, tasks []*models.ScanTask) error {
+	for start := 0; start < len(tasks); start += attachTargetIDsBatch {
+		end := start + attachTargetIDsBatch
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+
+		batch := tasks[start:end]
+		byID := make(map[int]*models.ScanTask, len(batch))
+		placeholders := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch))
+		for _, t := range batch {
+			byID[t.ID] = t
+			placeholders = append(placeholders, "?")
+			args = append(args, t.ID)
+		}
+
+		if err := r.loadTargetIDs(ctx, byID, placeholders, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTargetIDs 查询一批任务的目标列表
+func (r *ScanRepository) loadTargetIDs(ctx context.Context, byID map[int]*models.ScanTask, placeholders []string, args []interface{}) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT scan_id, target_id FROM scan_task_targets
+		 WHERE scan_id IN (`+strings.Join(placeholders, ",")+`)
+		 ORDER BY scan_id, position`, args...)
+	if err != nil {
+		return errors.DBError("failed to query scan task targets", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var scanID, targetID int
+		if err := rows.Scan(&scanID, &targetID); err != nil {
+			return errors.DBError("failed to scan scan task target", err)
+		}
+		if t, ok := byID[scanID]; ok {
+			t.TargetIDs = append(t.TargetIDs, targetID)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.DBError("error iterating scan task targets", err)
+	}
+	return nil
+}
+
 // AddLog 添加扫描日志
 func (r *ScanRepository) AddLog(ctx context.Context, scanID int, level, message string) error {
 	_, err := r.db.ExecContext(ctx,
@@ -338,6 +338,61 @@ func TestScanRepository_GetByTargetID(t *testing.T) {
 	}
 }
 
+// TestScanRepository_MultiTarget 测试多目标扫描任务的目标列表
+func TestScanRepository_MultiTarget(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	log := logger.New("info", "")
+	repo := NewScanRepository(db, log)
+	ctx := context.Background()
+
+	multi := &models.ScanTask{
+		Name:      strPtr("Multi Scan"),
+		TargetID:  7,
+		TargetIDs: []int{7, 3, 5},
+		TargetTag: strPtr("prod"),
+		Status:    "pending",
+		Strategy:  "quick",
+	}
+	if err := repo.Create(ctx, multi); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	single := &models.ScanTask{Name: strPtr("Single Scan"), TargetID: 3, Status: "pending", Strategy: "quick"}
+	if err := repo.Create(ctx, single); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	fetched, err := repo.GetByID(ctx, multi.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if len(fetched.TargetIDs) != 3 || fetched.TargetIDs[0] != 7 || fetched.TargetIDs[1] != 3 || fetched.TargetIDs[2] != 5 {
+		t.Errorf("TargetIDs = %v, want [7 3 5]", fetched.TargetIDs)
+	}
+	if fetched.TargetTag == nil || *fetched.TargetTag != "prod" {
+		t.Errorf("TargetTag = %v, want prod", fetched.TargetTag)
+	}
+
+	// 目标 3 的历史包含单目标任务与多目标任务
+	tasks, err := repo.GetByTargetID(ctx, 3)
+	if err != nil {
+		t.Fatalf("GetByTargetID() failed: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Errorf("GetByTargetID(3) returned %d tasks, want 2", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.ID == single.ID && task.TargetIDs != nil {
+			t.Errorf("single target task TargetIDs = %v, want nil", task.TargetIDs)
+		}
+	}
+
+	if tasks, _ := repo.GetByTargetID(ctx, 5); len(tasks) != 1 || tasks[0].ID != multi.ID {
+		t.Errorf("GetByTargetID(5) = %d tasks, want multi-target task", len(tasks))
+	}
+}
+
 // TestScanRepository_GetAllPaged 测试分页获取扫描任务
 func TestScanRepository_GetAllPaged(t *testing.T) {
 	db := setupTestDB(t)
@@ -430,6 +485,61 @@ func TestScanRepository_Update(t *testing.T) {
 	}
 }
 
+// TestScanRepository_Queue 测试扫描队列的入队、重排与出队
+func TestScanRepository_Queue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	log := logger.New("info", "")
+	repo := NewScanRepository(db, log)
+	ctx := context.Background()
+
+	var ids []int
+	for i := 0; i < 3; i++ {
+		task := &models.ScanTask{TargetID: 1, Status: "pending", Strategy: "fast"}
+		if err := repo.Create(ctx, task); err != nil {
+			t.Fatalf("setup Create() failed: %v", err)
+		}
+		if err := repo.Enqueue(ctx, task.ID); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+
+	fetched, _ := repo.GetByID(ctx, ids[2])
+	if fetched.Status != "queued" || fetched.QueuePosition == nil || *fetched.QueuePosition != 3 {
+		t.Errorf("Enqueue() status = %s, position = %v, want queued at 3", fetched.Status, fetched.QueuePosition)
+	}
+
+	if err := repo.SetQueuePositions(ctx, []int{ids[2], ids[0], ids[1]}); err != nil {
+		t.Fatalf("SetQueuePositions() failed: %v", err)
+	}
+	next, err := repo.NextQueued(ctx)
+	if err != nil {
+		t.Fatalf("NextQueued() failed: %v", err)
+	}
+	if next.ID != ids[2] {
+		t.Errorf("NextQueued() = %d, want %d", next.ID, ids[2])
+	}
+
+	// 离开队列后清除排队顺序
+	if err := repo.UpdateStatus(ctx, ids[2], "running"); err != nil {
+		t.Fatalf("UpdateStatus() failed: %v", err)
+	}
+	fetched, _ = repo.GetByID(ctx, ids[2])
+	if fetched.QueuePosition != nil {
+		t.Errorf("UpdateStatus() QueuePosition = %d, want nil", *fetched.QueuePosition)
+	}
+
+	queued, err := repo.GetQueued(ctx)
+	if err != nil {
+		t.Fatalf("GetQueued() failed: %v", err)
+	}
+	if len(queued) != 2 || queued[0].ID != ids[0] || queued[1].ID != ids[1] {
+		t.Errorf("GetQueued() returned %d tasks in wrong order", len(queued))
+	}
+}
+
 // 辅助函数
 func strPtr(s string) *string {
 	return &s
@@ -0,0 +1,223 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// ScheduleTimeLayout 计划时间的存储格式，统一使用 UTC
+const ScheduleTimeLayout = "2006-01-02 15:04:05"
+
+// ScheduleRepository 扫描计划仓储
+type ScheduleRepository struct {
+	db *sql.DB
+}
+
+// NewScheduleRepository 创建扫描计划仓储
+func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
+	return &ScheduleRepository{db: db}
+}
+
+// scheduleColumns 扫描计划查询列，顺序与 scanSchedule 一致
+const scheduleColumns = `id, name, target_id, target_tag, strategy, templates, cron, interval_seconds,
+	catch_up, enabled, last_run_at, next_run_at, last_task_id, last_error, created_at, updated_at`
+
+// GetAll 获取所有扫描计划
+func (r *ScheduleRepository) GetAll(ctx context.Context) ([]*models.ScanSchedule, error) {
+	return r.query(ctx, `SELECT `+scheduleColumns+` FROM scan_schedules ORDER BY id`)
+}
+
+// GetEnabled 获取已启用的扫描计划
+func (r *ScheduleRepository) GetEnabled(ctx context.Context) ([]*models.ScanSchedule, error) {
+	return r.query(ctx, `SELECT `+scheduleColumns+` FROM scan_schedules WHERE enabled = 1 ORDER BY next_run_at, id`)
+}
+
+// GetByID 根据 ID 获取扫描计划
+func (r *ScheduleRepository) GetByID(ctx context.Context, id int) (*models.ScanSchedule, error) {
+	s, err := r.scanSchedule(r.db.QueryRowContext(ctx,
+		`SELECT `+scheduleColumns+` FROM scan_schedules WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("scan schedule not found")
+	}
+	if err != nil {
+		return nil, errors.DBError("failed to query scan schedule", err)
+	}
+	return s, nil
+}
+
+// Create 创建扫描计划
+func (r *ScheduleRepository) Create(ctx context.Context, s *models.ScanSchedule) error {
+	templatesJSON, err := json.Marshal(s.Templates)
+	if err != nil {
+		return errors.Internal("failed to marshal templates", err)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO scan_schedules
+		 (name, target_id, target_tag, strategy, templates, cron, interval_seconds, catch_up, enabled, next_run_at, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))`,
+		s.Name, s.TargetID, s.TargetTag, s.Strategy, string(templatesJSON), s.Cron, s.IntervalSeconds,
+		s.CatchUp, s.Enabled, s.NextRunAt)
+	if err != nil {
+		return errors.DBError("failed to create scan schedule", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return errors.DBError("failed to get last insert id", err)
+	}
+
+	s.ID = int(id)
+	return nil
+}
+
+// Update 更新扫描计划的定义与下次运行时间
+func (r *ScheduleRepository) Update(ctx context.Context, s *models.ScanSchedule) error {
+	templatesJSON, err := json.Marshal(s.Templates)
+	if err != nil {
+		return errors.Internal("failed to marshal templates", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE scan_schedules
+		 SET name = ?, target_id = ?, target_tag = ?, strategy = ?, templates = ?, cron = ?, interval_seconds = ?,
+		     catch_up = ?, enabled = ?, next_run_at = ?, updated_at = datetime('now')
+		 WHERE id = ?`,
+		s.Name, s.TargetID, s.TargetTag, s.Strategy, string(templatesJSON), s.Cron, s.IntervalSeconds,
+		s.CatchUp, s.Enabled, s.NextRunAt, s.ID)
+	if err != nil {
+		return errors.DBError("failed to update scan schedule", err)
+	}
+	return nil
+}
+
+// RecordRun 记录一次运行，taskID 为 0 表示未创建任务，reason 为失败或跳过的原因
+func (r *ScheduleRepository) RecordRun(ctx context.Context, id int, runAt time.Time, taskID int, reason string, next time.Time) error {
+	var lastTaskID, lastError interface{}
+	if taskID > 0 {
+		lastTaskID = taskID
+	}
+	if reason != "" {
+		lastError = reason
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE scan_schedules
+		 SET last_run_at = ?, last_task_id = COALESCE(?, last_task_id), last_error = ?, next_run_at = ?
+		 WHERE id = ?`,
+		formatScheduleTime(runAt), lastTaskID, lastError, formatScheduleTime(next), id)
+	if err != nil {
+		return errors.DBError("failed to record scan schedule run", err)
+	}
+	return nil
+}
+
+// SetNextRun 更新下次运行时间，next 为零值时清除
+func (r *ScheduleRepository) SetNextRun(ctx context.Context, id int, next time.Time, reason string) error {
+	var lastError interface{}
+	if reason != "" {
+		lastError = reason
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE scan_schedules SET next_run_at = ?, last_error = COALESCE(?, last_error) WHERE id = ?",
+		formatScheduleTime(next), lastError, id)
+	if err != nil {
+		return errors.DBError("failed to update scan schedule next run", err)
+	}
+	return nil
+}
+
+// Delete 删除扫描计划
+func (r *ScheduleRepository) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM scan_schedules WHERE id = ?", id)
+	if err != nil {
+		return errors.DBError("failed to delete scan schedule", err)
+	}
+	return nil
+}
+
+// query 执行查询并扫描所有计划
+func (r *ScheduleRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.ScanSchedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DBError("failed to query scan schedules", err)
+	}
+	defer rows.Close()
+
+	schedules := []*models.ScanSchedule{}
+	for rows.Next() {
+		s, err := r.scanSchedule(rows)
+		if err != nil {
+			return nil, errors.DBError("failed to scan scan schedule", err)
+		}
+		schedules = append(schedules, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating scan schedules", err)
+	}
+
+	return schedules, nil
+}
+
+// scanSchedule 扫描一行扫描计划数据
+func (r *ScheduleRepository) scanSchedule(row interface{ Scan(dest ...any) error }) (*models.ScanSchedule, error) {
+	var s models.ScanSchedule
+	var targetTag, templates, cronExpr, lastError sql.NullString
+	var targetID, intervalSeconds, lastTaskID sql.NullInt64
+	// 驱动会将 DATETIME 列解析为 time.Time，统一转回存储格式
+	var lastRunAt, nextRunAt sql.NullTime
+
+	err := row.Scan(&s.ID, &s.Name, &targetID, &targetTag, &s.Strategy, &templates, &cronExpr, &intervalSeconds,
+		&s.CatchUp, &s.Enabled, &lastRunAt, &nextRunAt, &lastTaskID, &lastError, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetID.Valid {
+		val := int(targetID.Int64)
+		s.TargetID = &val
+	}
+	if targetTag.Valid {
+		s.TargetTag = &targetTag.String
+	}
+	s.Templates = []string{}
+	if templates.Valid && templates.String != "" {
+		if err := json.Unmarshal([]byte(templates.String), &s.Templates); err != nil || s.Templates == nil {
+			s.Templates = []string{}
+		}
+	}
+	s.Cron = cronExpr.String
+	s.IntervalSeconds = int(intervalSeconds.Int64)
+	if lastRunAt.Valid {
+		val := lastRunAt.Time.UTC().Format(ScheduleTimeLayout)
+		s.LastRunAt = &val
+	}
+	if nextRunAt.Valid {
+		val := nextRunAt.Time.UTC().Format(ScheduleTimeLayout)
+		s.NextRunAt = &val
+	}
+	if lastTaskID.Valid {
+		val := int(lastTaskID.Int64)
+		s.LastTaskID = &val
+	}
+	if lastError.Valid {
+		s.LastError = &lastError.String
+	}
+
+	return &s, nil
+}
+
+// formatScheduleTime 格式化计划时间，零值返回 nil
+func formatScheduleTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(ScheduleTimeLayout)
+}
@@ -69,6 +69,38 @@ func (r *TargetRepository) GetByID(ctx context.Context, id int) (*models.Target,
 	return &t, nil
 }
 
+// GetByTag 获取带有指定标签的目标，按 ID 排序
+func (r *TargetRepository) GetByTag(ctx context.Context, tag string) ([]*models.Target, error) {
+	// LIKE 仅做初筛，标签需精确匹配
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, name, url, description, tags, created_at, updated_at FROM targets WHERE tags LIKE ? ORDER BY id",
+		"%\""+tag+"\"%")
+	if err != nil {
+		return nil, errors.DBError("failed to query targets by tag", err)
+	}
+	defer rows.Close()
+
+	targets := []*models.Target{}
+	for rows.Next() {
+		t, err := r.scanTarget(rows)
+		if err != nil {
+			return nil, errors.DBError("failed to scan target", err)
+		}
+		for _, existing := range t.Tags {
+			if existing == tag {
+				targets = append(targets, t)
+				break
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DBError("error iterating targets", err)
+	}
+
+	return targets, nil
+}
+
 // Create 创建目标
 func (r *TargetRepository) Create(ctx context.Context, t *models.Target) error {
 	tagsJSON, err := json.Marshal(t.Tags)
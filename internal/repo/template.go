@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/holehunter/holehunter/internal/models"
 )
@@ -60,6 +61,27 @@ func (r *TemplateRepository) GetAllCustom(ctx context.Context) ([]*models.Templa
 	return r.scanTemplates(rows)
 }
 
+// GetEnabled 获取所有启用的模板
+func (r *TemplateRepository) GetEnabled(ctx context.Context) ([]*models.Template, error) {
+	query := `
+		SELECT id, source, template_id, name, severity, category, author,
+		       path, content, enabled, description, impact, remediation,
+		       tags, reference, metadata, nuclei_version, official_path,
+		       created_at, updated_at
+		FROM templates
+		WHERE enabled = 1
+		ORDER BY source, template_id, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query enabled templates: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanTemplates(rows)
+}
+
 // GetByID 根据 ID 获取模板
 func (r *TemplateRepository) GetByID(ctx context.Context, id int) (*models.Template, error) {
 	query := `
@@ -286,6 +308,117 @@ func (r *TemplateRepository) ToggleEnabled(ctx context.Context, id int, enabled
 	return nil
 }
 
+// GetDisabledBuiltinIDs 获取被禁用的内置模板 ID，用于从扫描中排除
+func (r *TemplateRepository) GetDisabledBuiltinIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT template_id
+		FROM templates
+		WHERE source = 'builtin' AND enabled = 0
+		ORDER BY template_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query disabled templates: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetBuiltinEnabled 按分类、标签或作者批量设置内置模板启用状态，返回匹配的模板数
+func (r *TemplateRepository) SetBuiltinEnabled(ctx context.Context, req *models.TemplateBulkToggleRequest) (int, error) {
+	// SQL 仅做初筛，标签与作者需精确匹配
+	conditions := []string{"source = 'builtin'"}
+	var args []interface{}
+	if req.Category != "" {
+		conditions = append(conditions, "(category = ? OR category LIKE ?)")
+		args = append(args, req.Category, strings.TrimSuffix(req.Category, "/")+"/%")
+	}
+	if req.Tag != "" {
+		conditions = append(conditions, "tags LIKE ?")
+		args = append(args, "%\""+req.Tag+"\"%")
+	}
+	if req.Author != "" {
+		conditions = append(conditions, "author LIKE ?")
+		args = append(args, "%"+req.Author+"%")
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, tags, author FROM templates WHERE "+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query templates: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		var tags, author sql.NullString
+		if err := rows.Scan(&id, &tags, &author); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if req.Tag != "" && !containsString(parseJSONStrings(tags.String), req.Tag) {
+			continue
+		}
+		if req.Author != "" && !containsString(splitAuthors(author.String), req.Author) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE templates SET enabled = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?", req.Enabled, id); err != nil {
+			return 0, fmt.Errorf("failed to update template: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(ids), nil
+}
+
+// parseJSONStrings 解析 JSON 字符串数组，格式错误时返回空
+func parseJSONStrings(s string) []string {
+	var values []string
+	_ = json.Unmarshal([]byte(s), &values)
+	return values
+}
+
+// splitAuthors 拆分逗号分隔的作者
+func splitAuthors(s string) []string {
+	var authors []string
+	for _, author := range strings.Split(s, ",") {
+		if author = strings.TrimSpace(author); author != "" {
+			authors = append(authors, author)
+		}
+	}
+	return authors
+}
+
+// containsString 检查列表中是否包含指定字符串
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
 // GetStats 获取模板统计信息
 func (r *TemplateRepository) GetStats(ctx context.Context) (map[string]int, error) {
 	stats := make(map[string]int)
@@ -370,16 +503,16 @@ func (r *TemplateRepository) SyncBuiltin(ctx context.Context, templates []*model
 			}
 			stats.Inserted++
 		} else if err == nil {
-			// 更新现有模板 - 包括 enabled 字段以确保模板保持启用状态
+			// 更新现有模板 - 保留 enabled 字段，用户禁用的内置模板在重新同步后仍保持禁用
 			_, err := r.db.ExecContext(ctx, `
 				UPDATE templates SET
 					name = ?, severity = ?, category = ?, author = ?,
-					path = ?, enabled = ?, description = ?, impact = ?, remediation = ?,
+					path = ?, description = ?, impact = ?, remediation = ?,
 					tags = ?, reference = ?, metadata = ?,
 					nuclei_version = ?, official_path = ?, updated_at = CURRENT_TIMESTAMP
 				WHERE source = 'builtin' AND template_id = ?
 			`, tmpl.Name, tmpl.Severity, tmpl.Category, tmpl.Author,
-				tmpl.Path, tmpl.Enabled, tmpl.Description, tmpl.Impact, tmpl.Remediation,
+				tmpl.Path, tmpl.Description, tmpl.Impact, tmpl.Remediation,
 				stringSliceToJSON(tmpl.Tags), stringSliceToJSON(tmpl.Reference),
 				mapToJSON(tmpl.Metadata), tmpl.NucleiVersion, tmpl.OfficialPath,
 				tmpl.TemplateID,
@@ -397,6 +397,82 @@ func TestTemplateRepository_SyncBuiltin(t *testing.T) {
 	}
 }
 
+func TestTemplateRepository_SetBuiltinEnabled(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repo := NewTemplateRepository(db)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO templates (source, template_id, name, severity, category, author, tags, enabled)
+		VALUES
+			('builtin', 'cve-1', 'CVE 1', 'high', 'http/cves', 'alice, bob', '["cve","rce"]', 1),
+			('builtin', 'cve-2', 'CVE 2', 'high', 'http/cves/2024', 'carol', '["cve"]', 1),
+			('builtin', 'panel-1', 'Panel 1', 'info', 'http/exposed-panels', 'alice', '["panel"]', 1),
+			('builtin', 'rce-like', 'RCE Like', 'info', 'http/misc', 'alicex', '["rce-like"]', 1),
+			('custom', 'custom-1', 'Custom 1', 'high', 'http/cves', 'alice', '["cve"]', 1)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		req      *models.TemplateBulkToggleRequest
+		expected int
+	}{
+		{"category includes subcategories", &models.TemplateBulkToggleRequest{Category: "http/cves", Enabled: true}, 2},
+		{"exact tag", &models.TemplateBulkToggleRequest{Tag: "rce", Enabled: true}, 1},
+		{"exact author", &models.TemplateBulkToggleRequest{Author: "alice", Enabled: true}, 2},
+		{"combined filters", &models.TemplateBulkToggleRequest{Category: "http/cves", Author: "alice", Enabled: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := repo.SetBuiltinEnabled(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("SetBuiltinEnabled failed: %v", err)
+			}
+			if count != tt.expected {
+				t.Errorf("Expected %d templates, got %d", tt.expected, count)
+			}
+		})
+	}
+
+	disabled, err := repo.GetDisabledBuiltinIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetDisabledBuiltinIDs failed: %v", err)
+	}
+	if len(disabled) != 0 {
+		t.Errorf("Expected no disabled templates, got %v", disabled)
+	}
+
+	if _, err := repo.SetBuiltinEnabled(ctx, &models.TemplateBulkToggleRequest{Category: "http/cves", Enabled: false}); err != nil {
+		t.Fatalf("SetBuiltinEnabled failed: %v", err)
+	}
+	disabled, err = repo.GetDisabledBuiltinIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetDisabledBuiltinIDs failed: %v", err)
+	}
+	if fmt.Sprint(disabled) != "[cve-1 cve-2]" {
+		t.Errorf("Expected [cve-1 cve-2] disabled, got %v", disabled)
+	}
+
+	// 重新同步内置模板不应恢复用户禁用的模板
+	if _, err := repo.SyncBuiltin(ctx, []*models.Template{
+		{TemplateID: "cve-1", Name: "CVE 1", Severity: "critical", Category: "http/cves", Enabled: true},
+		{TemplateID: "cve-2", Name: "CVE 2", Severity: "high", Category: "http/cves/2024", Enabled: true},
+	}); err != nil {
+		t.Fatalf("SyncBuiltin failed: %v", err)
+	}
+	disabled, err = repo.GetDisabledBuiltinIDs(ctx)
+	if err != nil {
+		t.Fatalf("GetDisabledBuiltinIDs failed: %v", err)
+	}
+	if fmt.Sprint(disabled) != "[cve-1 cve-2]" {
+		t.Errorf("SyncBuiltin should keep templates disabled, got %v", disabled)
+	}
+}
+
 // Helper function
 func boolPtr(b bool) *bool {
 	return &b
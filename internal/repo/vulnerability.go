@@ -23,7 +23,7 @@ func NewVulnerabilityRepository(db *sql.DB) *VulnerabilityRepository {
 // GetAll 获取所有漏洞
 func (r *VulnerabilityRepository) GetAll(ctx context.Context) ([]*models.Vulnerability, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, task_id, template_id, severity, name, description, url,
+		`SELECT id, task_id, target_id, template_id, severity, name, description, url,
 		         matched_at, tags, reference, request_response, false_positive, notes, cve, cvss, created_at
 		  FROM vulnerabilities ORDER BY created_at DESC`)
 	if err != nil {
@@ -50,7 +50,7 @@ func (r *VulnerabilityRepository) GetAll(ctx context.Context) ([]*models.Vulnera
 // GetByTaskID 根据任务 ID 获取漏洞列表
 func (r *VulnerabilityRepository) GetByTaskID(ctx context.Context, taskID int) ([]*models.Vulnerability, error) {
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, task_id, template_id, severity, name, description, url,
+		`SELECT id, task_id, target_id, template_id, severity, name, description, url,
 		         matched_at, tags, reference, request_response, false_positive, notes, cve, cvss, created_at
 		  FROM vulnerabilities WHERE task_id = ? ORDER BY created_at DESC`, taskID)
 	if err != nil {
@@ -79,12 +79,13 @@ func (r *VulnerabilityRepository) GetByID(ctx context.Context, id int) (*models.
 	var v models.Vulnerability
 	var notes, cve, tags, reference sql.NullString
 	var cvss sql.NullFloat64
+	var targetID sql.NullInt64
 
 	err := r.db.QueryRowContext(ctx,
-		`SELECT id, task_id, template_id, severity, name, description, url,
+		`SELECT id, task_id, target_id, template_id, severity, name, description, url,
 		         matched_at, tags, reference, false_positive, notes, cve, cvss, created_at
 		  FROM vulnerabilities WHERE id = ?`, id).
-		Scan(&v.ID, &v.TaskID, &v.TemplateID, &v.Severity, &v.Name, &v.Description,
+		Scan(&v.ID, &v.TaskID, &targetID, &v.TemplateID, &v.Severity, &v.Name, &v.Description,
 			&v.URL, &v.MatchedAt, &tags, &reference, &v.FalsePositive, &notes, &cve, &cvss, &v.CreatedAt)
 
 	if err == sql.ErrNoRows {
@@ -94,6 +95,10 @@ func (r *VulnerabilityRepository) GetByID(ctx context.Context, id int) (*models.
 		return nil, errors.DBError("failed to query vulnerability", err)
 	}
 
+	if targetID.Valid {
+		val := int(targetID.Int64)
+		v.TargetID = &val
+	}
 	if notes.Valid {
 		v.Notes = &notes.String
 	}
@@ -121,10 +126,10 @@ func (r *VulnerabilityRepository) Create(ctx context.Context, v *models.Vulnerab
 
 	result, err := r.db.ExecContext(ctx,
 		`INSERT INTO vulnerabilities
-		 (task_id, template_id, severity, name, description, url, matched_at,
+		 (task_id, target_id, template_id, severity, name, description, url, matched_at,
 		  tags, reference, request_response, false_positive, cve, cvss, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
-		v.TaskID, v.TemplateID, v.Severity, v.Name, v.Description,
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
+		v.TaskID, v.TargetID, v.TemplateID, v.Severity, v.Name, v.Description,
 		v.URL, v.MatchedAt, tagsJSON, referenceJSON, v.RequestResponse,
 		v.FalsePositive, v.CVE, v.CVSS)
 	if err != nil {
@@ -235,7 +240,7 @@ func (r *VulnerabilityRepository) GetPage(ctx context.Context, page, pageSize in
 
 	// 查询分页数据
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, task_id, template_id, severity, name, description, url,
+		`SELECT id, task_id, target_id, template_id, severity, name, description, url,
 		         matched_at, tags, reference, request_response, false_positive, notes, cve, cvss, created_at
 		  FROM vulnerabilities ORDER BY created_at DESC LIMIT ? OFFSET ?`,
 		pageSize, offset)
@@ -282,7 +287,7 @@ func (r *VulnerabilityRepository) GetPageByFilter(ctx context.Context, filter *m
 	}
 
 	// 查询分页数据
-	query := `SELECT id, task_id, template_id, severity, name, description, url,
+	query := `SELECT id, task_id, target_id, template_id, severity, name, description, url,
 		         matched_at, tags, reference, request_response, false_positive, notes, cve, cvss, created_at
 		  FROM vulnerabilities`
 	if whereClause != "" {
@@ -333,8 +338,8 @@ func (r *VulnerabilityRepository) buildFilterQuery(filter *models.VulnerabilityF
 
 	// 目标 ID 过滤
 	if filter.TargetID != nil {
-		conditions = append(conditions, "(task_id = ? OR target_id = ?)")
-		args = append(args, *filter.TargetID, *filter.TargetID)
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, *filter.TargetID)
 	}
 
 	// 扫描任务 ID 过滤
@@ -377,13 +382,18 @@ func (r *VulnerabilityRepository) scanVulnerability(rows *sql.Rows) (*models.Vul
 	var v models.Vulnerability
 	var notes, cve, tags, reference sql.NullString
 	var cvss sql.NullFloat64
+	var targetID sql.NullInt64
 
-	err := rows.Scan(&v.ID, &v.TaskID, &v.TemplateID, &v.Severity, &v.Name, &v.Description,
+	err := rows.Scan(&v.ID, &v.TaskID, &targetID, &v.TemplateID, &v.Severity, &v.Name, &v.Description,
 		&v.URL, &v.MatchedAt, &tags, &reference, &v.RequestResponse, &v.FalsePositive, &notes, &cve, &cvss, &v.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
+	if targetID.Valid {
+		val := int(targetID.Int64)
+		v.TargetID = &val
+	}
 	if notes.Valid {
 		v.Notes = &notes.String
 	}
@@ -0,0 +1,165 @@
+package scanner
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// 内置扫描引擎名称
+const (
+	EngineNuclei    = models.DefaultScanEngine
+	EngineHTTPCheck = "httpcheck"
+)
+
+// Engine 扫描引擎
+// 引擎只负责把扫描请求转换为执行体，并发槽位、超时、暂停、日志与结果入库由 Orchestrator 统一处理
+type Engine interface {
+	// Name 引擎名称，对应扫描任务的 engine 字段
+	Name() string
+	// Description 引擎说明，用于前端展示
+	Description() string
+	// Available 检查引擎是否可用
+	Available() bool
+	// NewRunner 为扫描请求创建执行体，发现与进度通过 sink 回调
+	NewRunner(req ScanRequest, sink FindingSink) (Runner, error)
+}
+
+// Runner 一次扫描的执行体
+type Runner interface {
+	// Run 执行扫描直到结束，ctx 取消时终止
+	Run(ctx context.Context) error
+	// Stop 优雅停止扫描，返回时扫描已结束
+	Stop() error
+	// Suspend 挂起扫描，不支持时返回 errSuspendUnsupported
+	Suspend() error
+	// Continue 恢复挂起的扫描
+	Continue() error
+}
+
+// FindingSink 引擎输出的回调
+type FindingSink struct {
+	OnFinding  func(*NucleiOutput)
+	OnProgress func(ScanProgress)
+}
+
+// Finding 引擎无关的扫描发现
+// 非 nuclei 引擎的发现先转换为 NucleiOutput，再与 nuclei 结果走同一条入库流程
+type Finding struct {
+	TemplateID  string
+	Name        string
+	Severity    string
+	Description string
+	Host        string
+	URL         string
+	MatchedAt   string
+	Tags        []string
+	Reference   []string
+	Request     string
+	Response    string
+}
+
+// Output 将发现转换为统一的 NucleiOutput 结构
+func (f *Finding) Output() *NucleiOutput {
+	severity := normalizeSeverity(f.Severity)
+	name := f.Name
+	if name == "" {
+		name = f.TemplateID
+	}
+
+	info := map[string]interface{}{
+		"name":     name,
+		"severity": severity,
+	}
+	if f.Description != "" {
+		info["description"] = f.Description
+	}
+	if len(f.Tags) > 0 {
+		info["tags"] = f.Tags
+	}
+	if len(f.Reference) > 0 {
+		info["reference"] = f.Reference
+	}
+
+	return &NucleiOutput{
+		TemplateID: f.TemplateID,
+		Info:       info,
+		Severity:   severity,
+		Name:       name,
+		Host:       f.Host,
+		URL:        f.URL,
+		MatchedAt:  f.MatchedAt,
+		Request:    f.Request,
+		Response:   f.Response,
+		Timestamp:  time.Now(),
+	}
+}
+
+// normalizeSeverity 规范化严重程度，无法识别时视为 info
+func normalizeSeverity(severity string) string {
+	switch s := strings.ToLower(strings.TrimSpace(severity)); s {
+	case "critical", "high", "medium", "low", "info":
+		return s
+	case "moderate":
+		return "medium"
+	default:
+		return "info"
+	}
+}
+
+// EngineRegistry 扫描引擎注册表
+type EngineRegistry struct {
+	mu      sync.RWMutex
+	engines map[string]Engine
+}
+
+// NewEngineRegistry 创建扫描引擎注册表
+func NewEngineRegistry() *EngineRegistry {
+	return &EngineRegistry{
+		engines: make(map[string]Engine),
+	}
+}
+
+// Register 注册扫描引擎，同名引擎会被替换
+func (r *EngineRegistry) Register(engine Engine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.engines[engine.Name()] = engine
+}
+
+// Get 获取扫描引擎，名称为空时返回 nuclei
+func (r *EngineRegistry) Get(name string) (Engine, bool) {
+	if name == "" {
+		name = EngineNuclei
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	engine, ok := r.engines[name]
+	return engine, ok
+}
+
+// List 按名称列出已注册的扫描引擎，nuclei 排在首位
+func (r *EngineRegistry) List() []models.ScanEngine {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]models.ScanEngine, 0, len(r.engines))
+	for _, engine := range r.engines {
+		list = append(list, models.ScanEngine{
+			Name:        engine.Name(),
+			Description: engine.Description(),
+			Available:   engine.Available(),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Name == EngineNuclei) != (list[j].Name == EngineNuclei) {
+			return list[i].Name == EngineNuclei
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
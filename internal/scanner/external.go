@@ -0,0 +1,355 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+)
+
+// 外部引擎参数中的占位符
+const (
+	placeholderTarget     = "{{target}}"
+	placeholderTargetList = "{{target_list}}"
+)
+
+// externalEngineNamePattern 外部引擎名称格式
+var externalEngineNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ExternalEngineSpec 外部扫描工具的声明式定义
+//
+//	name: mytool
+//	description: 自定义扫描工具
+//	command: ./mytool            # 相对路径相对于引擎定义所在目录
+//	args: ["-u", "{{target}}", "-json"]
+//	output:
+//	  format: jsonl
+//	  fields:
+//	    template_id: rule.id
+//	    name: rule.name
+//	    severity: level
+//	    matched_at: location
+//	  severity_map:
+//	    warning: medium
+//	  default_severity: info
+type ExternalEngineSpec struct {
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description"`
+	Command     string             `yaml:"command"`
+	Args        []string           `yaml:"args"`
+	Output      ExternalOutputSpec `yaml:"output"`
+}
+
+// ExternalOutputSpec 外部工具输出到发现字段的映射
+type ExternalOutputSpec struct {
+	// Format 输出格式，目前只支持 jsonl（每行一个 JSON 对象）
+	Format string `yaml:"format"`
+	// Fields 发现字段到 JSON 路径的映射，路径以点分隔，如 info.severity
+	Fields ExternalFieldMap `yaml:"fields"`
+	// SeverityMap 工具自身的严重程度到 critical/high/medium/low/info 的映射
+	SeverityMap map[string]string `yaml:"severity_map"`
+	// DefaultSeverity 未输出严重程度时使用的值
+	DefaultSeverity string `yaml:"default_severity"`
+}
+
+// ExternalFieldMap 发现字段对应的 JSON 路径
+type ExternalFieldMap struct {
+	TemplateID  string `yaml:"template_id"`
+	Name        string `yaml:"name"`
+	Severity    string `yaml:"severity"`
+	Description string `yaml:"description"`
+	MatchedAt   string `yaml:"matched_at"`
+	Host        string `yaml:"host"`
+	URL         string `yaml:"url"`
+	Tags        string `yaml:"tags"`
+	Reference   string `yaml:"reference"`
+}
+
+// ExternalEngine 通过声明式定义包装的外部扫描工具
+type ExternalEngine struct {
+	spec    ExternalEngineSpec
+	command string
+}
+
+// NewExternalEngine 校验定义并创建外部扫描引擎，baseDir 用于解析相对路径的命令
+func NewExternalEngine(spec ExternalEngineSpec, baseDir string) (*ExternalEngine, error) {
+	if !externalEngineNamePattern.MatchString(spec.Name) {
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid engine name: %q", spec.Name))
+	}
+	if spec.Name == EngineNuclei || spec.Name == EngineHTTPCheck {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine name %s is reserved", spec.Name))
+	}
+	if spec.Command == "" {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine %s: command is required", spec.Name))
+	}
+	if !argsContain(spec.Args, placeholderTarget) && !argsContain(spec.Args, placeholderTargetList) {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine %s: args must contain %s or %s", spec.Name, placeholderTarget, placeholderTargetList))
+	}
+	if spec.Output.Format == "" {
+		spec.Output.Format = "jsonl"
+	}
+	if spec.Output.Format != "jsonl" {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine %s: unsupported output format %s", spec.Name, spec.Output.Format))
+	}
+	if spec.Output.Fields.TemplateID == "" && spec.Output.Fields.Name == "" {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine %s: output.fields must map template_id or name", spec.Name))
+	}
+
+	command := spec.Command
+	if strings.ContainsRune(command, '/') || strings.ContainsRune(command, filepath.Separator) {
+		if !filepath.IsAbs(command) {
+			command = filepath.Join(baseDir, command)
+		}
+	}
+
+	return &ExternalEngine{spec: spec, command: command}, nil
+}
+
+// LoadExternalEngines 加载目录下的外部引擎定义，目录不存在时返回空
+// 单个定义无效不影响其他定义的加载，错误合并返回
+func LoadExternalEngines(dir string) ([]*ExternalEngine, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	ymlFiles, _ := filepath.Glob(filepath.Join(dir, "*.yml"))
+	files = append(files, ymlFiles...)
+
+	var engines []*ExternalEngine
+	var failures []string
+	seen := make(map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", filepath.Base(file), err))
+			continue
+		}
+		var spec ExternalEngineSpec
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", filepath.Base(file), err))
+			continue
+		}
+		engine, err := NewExternalEngine(spec, dir)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", filepath.Base(file), err))
+			continue
+		}
+		if prev, ok := seen[engine.Name()]; ok {
+			failures = append(failures, fmt.Sprintf("%s: engine %s already defined in %s", filepath.Base(file), engine.Name(), prev))
+			continue
+		}
+		seen[engine.Name()] = filepath.Base(file)
+		engines = append(engines, engine)
+	}
+
+	if len(failures) > 0 {
+		return engines, errors.InvalidInput("invalid engine definitions: " + strings.Join(failures, "; "))
+	}
+	return engines, nil
+}
+
+// Name 引擎名称
+func (e *ExternalEngine) Name() string {
+	return e.spec.Name
+}
+
+// Description 引擎说明
+func (e *ExternalEngine) Description() string {
+	if e.spec.Description != "" {
+		return e.spec.Description
+	}
+	return "外部扫描工具: " + e.spec.Command
+}
+
+// Available 检查外部工具是否可执行
+func (e *ExternalEngine) Available() bool {
+	_, err := exec.LookPath(e.command)
+	return err == nil
+}
+
+// NewRunner 替换参数占位符并构建外部工具命令
+func (e *ExternalEngine) NewRunner(req ScanRequest, sink FindingSink) (Runner, error) {
+	multiTarget := req.TargetList != ""
+	if multiTarget && !argsContain(e.spec.Args, placeholderTargetList) {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine %s does not support multi-target scans", e.spec.Name))
+	}
+	if !multiTarget && !argsContain(e.spec.Args, placeholderTarget) {
+		return nil, errors.InvalidInput(fmt.Sprintf("engine %s requires a target list", e.spec.Name))
+	}
+
+	args := make([]string, len(e.spec.Args))
+	for i, arg := range e.spec.Args {
+		arg = strings.ReplaceAll(arg, placeholderTarget, req.TargetURL)
+		args[i] = strings.ReplaceAll(arg, placeholderTargetList, req.TargetList)
+	}
+
+	cmd := exec.Command(e.command, args...)
+	cmd.Env = os.Environ()
+	return NewScanProcess(req.TaskID, cmd, &mappedOutputParser{taskID: req.TaskID, engine: e.spec.Name, output: e.spec.Output, sink: sink}), nil
+}
+
+// argsContain 检查参数中是否包含占位符
+func argsContain(args []string, placeholder string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// mappedOutputParser 按字段映射解析外部工具的 JSONL 输出
+type mappedOutputParser struct {
+	taskID int
+	engine string
+	output ExternalOutputSpec
+	sink   FindingSink
+}
+
+// ParseStdout 解析每行 JSON 为发现，非 JSON 行忽略
+// 外部工具没有统一的进度输出，开始读取时报告开始，输出正常结束时报告完成
+func (p *mappedOutputParser) ParseStdout(reader io.Reader) {
+	p.progress("running", 0)
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue
+		}
+		finding, ok := p.finding(record)
+		if ok && p.sink.OnFinding != nil {
+			p.sink.OnFinding(finding.Output())
+		}
+	}
+
+	if scanner.Err() == nil {
+		p.progress("completed", 1)
+	}
+}
+
+// progress 以整个外部工具为一个执行单元报告进度
+func (p *mappedOutputParser) progress(status string, executed int) {
+	if p.sink.OnProgress == nil {
+		return
+	}
+	p.sink.OnProgress(ScanProgress{
+		TaskID:          p.taskID,
+		Status:          status,
+		TotalTemplates:  1,
+		Executed:        executed,
+		Progress:        executed * 100,
+		CurrentTemplate: p.engine,
+	})
+}
+
+// ParseStderr 外部工具的标准错误不参与解析，读取完毕以免进程阻塞
+func (p *mappedOutputParser) ParseStderr(reader io.Reader) {
+	_, _ = io.Copy(io.Discard, reader)
+}
+
+// finding 按映射从记录中取出发现字段，缺少模板 ID 与名称时忽略该记录
+func (p *mappedOutputParser) finding(record map[string]interface{}) (*Finding, bool) {
+	fields := p.output.Fields
+	finding := &Finding{
+		TemplateID:  lookupString(record, fields.TemplateID),
+		Name:        lookupString(record, fields.Name),
+		Description: lookupString(record, fields.Description),
+		MatchedAt:   lookupString(record, fields.MatchedAt),
+		Host:        lookupString(record, fields.Host),
+		URL:         lookupString(record, fields.URL),
+		Tags:        lookupStrings(record, fields.Tags),
+		Reference:   lookupStrings(record, fields.Reference),
+	}
+	if finding.TemplateID == "" && finding.Name == "" {
+		return nil, false
+	}
+	if finding.TemplateID == "" {
+		finding.TemplateID = finding.Name
+	}
+
+	severity := lookupString(record, fields.Severity)
+	if mapped, ok := p.output.SeverityMap[strings.ToLower(severity)]; ok {
+		severity = mapped
+	}
+	if severity == "" {
+		severity = p.output.DefaultSeverity
+	}
+	finding.Severity = severity
+
+	return finding, true
+}
+
+// lookup 按点分隔的路径取出 JSON 值
+func lookup(record map[string]interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var current interface{} = record
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// lookupString 取出字符串值，数字与布尔值转为字符串
+func lookupString(record map[string]interface{}, path string) string {
+	value, ok := lookup(record, path)
+	if !ok || value == nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64, bool:
+		return fmt.Sprint(v)
+	default:
+		return ""
+	}
+}
+
+// lookupStrings 取出字符串列表，逗号分隔的字符串会被拆分
+func lookupStrings(record map[string]interface{}, path string) []string {
+	value, ok := lookup(record, path)
+	if !ok || value == nil {
+		return nil
+	}
+
+	var result []string
+	switch v := value.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				result = append(result, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadExternalEngines 测试外部引擎定义的加载与校验
+func TestLoadExternalEngines(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok.yaml": `name: ok-tool
+command: ./ok
+args: ["-l", "{{target_list}}", "-u", "{{target}}"]
+output:
+  fields:
+    template_id: id`,
+		"reserved.yaml":   "name: nuclei\ncommand: nuclei\nargs: ['{{target}}']\noutput:\n  fields:\n    name: n",
+		"no-target.yaml":  "name: no-target\ncommand: tool\nargs: ['-v']\noutput:\n  fields:\n    name: n",
+		"no-fields.yaml":  "name: no-fields\ncommand: tool\nargs: ['{{target}}']",
+		"bad-format.yaml": "name: bad-format\ncommand: tool\nargs: ['{{target}}']\noutput:\n  format: xml\n  fields:\n    name: n",
+		"bad-name.yaml":   "name: Bad Name\ncommand: tool\nargs: ['{{target}}']\noutput:\n  fields:\n    name: n",
+		"duplicate.yml":   "name: ok-tool\ncommand: tool\nargs: ['{{target}}']\noutput:\n  fields:\n    name: n",
+		"ignored.txt":     "not an engine",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	engines, err := LoadExternalEngines(dir)
+	if err == nil {
+		t.Error("LoadExternalEngines() should report invalid definitions")
+	}
+	for _, name := range []string{"reserved.yaml", "no-target.yaml", "no-fields.yaml", "bad-format.yaml", "bad-name.yaml", "duplicate.yml"} {
+		if err != nil && !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q should mention %s", err, name)
+		}
+	}
+	if len(engines) != 1 || engines[0].Name() != "ok-tool" || engines[0].command != filepath.Join(dir, "ok") {
+		t.Fatalf("engines = %+v, want ok-tool", engines)
+	}
+	if engines[0].Available() {
+		t.Error("engine with missing command should not be available")
+	}
+
+	if engines, err := LoadExternalEngines(filepath.Join(dir, "missing")); err != nil || len(engines) != 0 {
+		t.Errorf("LoadExternalEngines(missing) = %v, %v, want empty", engines, err)
+	}
+}
+
+// TestMappedOutputParser 测试按字段映射解析外部工具输出
+func TestMappedOutputParser(t *testing.T) {
+	var findings []*NucleiOutput
+	var progress []ScanProgress
+	parser := &mappedOutputParser{
+		taskID: 7,
+		engine: "lint",
+		output: ExternalOutputSpec{
+			Fields: ExternalFieldMap{
+				TemplateID: "check",
+				Name:       "meta.title",
+				Severity:   "meta.risk",
+				MatchedAt:  "target",
+				Tags:       "meta.tags",
+				Reference:  "refs",
+			},
+			SeverityMap:     map[string]string{"severe": "critical"},
+			DefaultSeverity: "low",
+		},
+		sink: FindingSink{
+			OnFinding:  func(output *NucleiOutput) { findings = append(findings, output) },
+			OnProgress: func(p ScanProgress) { progress = append(progress, p) },
+		},
+	}
+
+	parser.ParseStdout(strings.NewReader(strings.Join([]string{
+		`starting scan`,
+		`{"check":"sqli","meta":{"title":"SQL Injection","risk":"SEVERE","tags":"sqli, injection"},"target":"https://a.example.com/?id=1","refs":["https://owasp.org"]}`,
+		`{"check":"banner","meta":{"title":"Banner"},"target":"https://a.example.com"}`,
+		`{"check":"odd","meta":{"risk":"bogus"}}`,
+		`{"other":"record"}`,
+		`{broken`,
+	}, "\n")))
+
+	if len(findings) != 3 {
+		t.Fatalf("findings = %d, want 3", len(findings))
+	}
+	sqli := findings[0]
+	if sqli.TemplateID != "sqli" || sqli.Name != "SQL Injection" || sqli.Severity != "critical" || sqli.MatchedAt != "https://a.example.com/?id=1" {
+		t.Errorf("sqli finding = %+v", sqli)
+	}
+	if tags, _ := sqli.Info["tags"].([]string); len(tags) != 2 || tags[1] != "injection" {
+		t.Errorf("sqli tags = %v", sqli.Info["tags"])
+	}
+	if refs, _ := sqli.Info["reference"].([]string); len(refs) != 1 {
+		t.Errorf("sqli reference = %v", sqli.Info["reference"])
+	}
+	if findings[1].Severity != "low" {
+		t.Errorf("default severity = %s, want low", findings[1].Severity)
+	}
+	if findings[2].Severity != "info" || findings[2].Name != "odd" {
+		t.Errorf("unknown severity finding = %+v, want info named by template id", findings[2])
+	}
+
+	// 开始与结束各报告一次进度
+	if len(progress) != 2 {
+		t.Fatalf("progress updates = %+v, want started and completed", progress)
+	}
+	if progress[0].Status != "running" || progress[0].Progress != 0 || progress[0].TaskID != 7 {
+		t.Errorf("first progress = %+v, want running at 0%%", progress[0])
+	}
+	if progress[1].Status != "completed" || progress[1].Progress != 100 || progress[1].Executed != 1 || progress[1].CurrentTemplate != "lint" {
+		t.Errorf("last progress = %+v, want completed at 100%%", progress[1])
+	}
+}
@@ -0,0 +1,478 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+const (
+	// httpCheckDefaultTimeout 单个请求的默认超时
+	httpCheckDefaultTimeout = 10 * time.Second
+	// httpCheckMaxBody 读取响应体的上限
+	httpCheckMaxBody = 64 * 1024
+)
+
+// httpCheck 内置 HTTP 检查项
+type httpCheck struct {
+	ID   string
+	Name string
+	// Run 对单个目标执行检查，返回发现的问题
+	Run func(ctx context.Context, c *httpCheckClient, target string) ([]Finding, error)
+}
+
+// httpChecks 内置检查项，按执行顺序排列
+var httpChecks = []httpCheck{
+	{ID: "http-missing-security-headers", Name: "Missing Security Headers", Run: checkSecurityHeaders},
+	{ID: "http-version-disclosure", Name: "Server Version Disclosure", Run: checkVersionDisclosure},
+	{ID: "http-git-config-exposure", Name: "Git Config Exposure", Run: checkGitConfig},
+	{ID: "http-env-file-exposure", Name: "Environment File Exposure", Run: checkEnvFile},
+	{ID: "http-directory-listing", Name: "Directory Listing", Run: checkDirectoryListing},
+}
+
+// HTTPCheckEngine 内置的 Go HTTP 检查引擎，不依赖外部程序
+type HTTPCheckEngine struct{}
+
+// NewHTTPCheckEngine 创建内置 HTTP 检查引擎
+func NewHTTPCheckEngine() *HTTPCheckEngine {
+	return &HTTPCheckEngine{}
+}
+
+// Name 引擎名称
+func (e *HTTPCheckEngine) Name() string {
+	return EngineHTTPCheck
+}
+
+// Description 引擎说明
+func (e *HTTPCheckEngine) Description() string {
+	return "内置 HTTP 检查：安全响应头、版本泄露、敏感文件暴露与目录列表"
+}
+
+// Available 内置引擎始终可用
+func (e *HTTPCheckEngine) Available() bool {
+	return true
+}
+
+// NewRunner 创建 HTTP 检查执行体，req.Templates 非空时只执行指定的检查项
+func (e *HTTPCheckEngine) NewRunner(req ScanRequest, sink FindingSink) (Runner, error) {
+	checks := httpChecks
+	if len(req.Templates) > 0 {
+		selected := make(map[string]bool, len(req.Templates))
+		for _, id := range req.Templates {
+			selected[id] = true
+		}
+		checks = nil
+		for _, check := range httpChecks {
+			if selected[check.ID] {
+				checks = append(checks, check)
+			}
+		}
+		if len(checks) == 0 {
+			return nil, errors.InvalidInput("no matching http checks selected")
+		}
+	}
+
+	targets := make([]string, 0, len(req.Targets))
+	for _, target := range req.Targets {
+		targets = append(targets, target.URL)
+	}
+	if len(targets) == 0 && req.TargetURL != "" {
+		targets = append(targets, req.TargetURL)
+	}
+	if len(targets) == 0 {
+		return nil, errors.InvalidInput("no targets to check")
+	}
+
+	client, err := newHTTPCheckClient(req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &httpCheckRunner{
+		taskID:  req.TaskID,
+		targets: targets,
+		checks:  checks,
+		client:  client,
+		sink:    sink,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// httpCheckRunner HTTP 检查执行体
+type httpCheckRunner struct {
+	taskID  int
+	targets []string
+	checks  []httpCheck
+	client  *httpCheckClient
+	sink    FindingSink
+
+	// ctx 由 Stop 取消
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	startOnce sync.Once
+	started   bool
+	done      chan struct{}
+}
+
+// Run 依次对每个目标执行检查
+func (r *httpCheckRunner) Run(ctx context.Context) error {
+	r.startOnce.Do(func() { r.started = true })
+	defer close(r.done)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(r.ctx, cancel)
+	defer stop()
+
+	total := len(r.targets) * len(r.checks)
+	executed := 0
+	for _, target := range r.targets {
+		for _, check := range r.checks {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			findings, err := check.Run(ctx, r.client, target)
+			if err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// 单项检查失败（如连接被拒绝）不影响其他检查
+			for i := range findings {
+				if findings[i].TemplateID == "" {
+					findings[i].TemplateID = check.ID
+				}
+				if findings[i].Name == "" {
+					findings[i].Name = check.Name
+				}
+				if findings[i].Host == "" {
+					findings[i].Host = target
+				}
+				if r.sink.OnFinding != nil {
+					r.sink.OnFinding(findings[i].Output())
+				}
+			}
+
+			executed++
+			if r.sink.OnProgress != nil {
+				r.sink.OnProgress(ScanProgress{
+					TaskID:          r.taskID,
+					Status:          "running",
+					TotalTemplates:  total,
+					Executed:        executed,
+					Progress:        executed * 100 / total,
+					CurrentTemplate: check.ID,
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// Stop 取消检查并等待结束
+func (r *httpCheckRunner) Stop() error {
+	r.cancel()
+	// 尚未运行时阻止之后的 Run 等待，已运行时等待其结束
+	r.startOnce.Do(func() {})
+	if r.started {
+		<-r.done
+	}
+	return nil
+}
+
+// Suspend 检查在进程内执行，不支持挂起
+func (r *httpCheckRunner) Suspend() error {
+	return errSuspendUnsupported
+}
+
+// Continue 无需恢复
+func (r *httpCheckRunner) Continue() error {
+	return nil
+}
+
+// httpCheckClient 按扫描调优参数配置的 HTTP 客户端
+type httpCheckClient struct {
+	client   *http.Client
+	headers  http.Header
+	interval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// newHTTPCheckClient 根据调优参数创建客户端
+func newHTTPCheckClient(options *models.ScanOptions) (*httpCheckClient, error) {
+	timeout := httpCheckDefaultTimeout
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // 扫描目标常使用自签名证书
+	}
+	headers := make(http.Header)
+	var interval time.Duration
+
+	if options != nil {
+		if options.Timeout > 0 {
+			timeout = time.Duration(options.Timeout) * time.Second
+		}
+		if options.Proxy != "" {
+			proxyURL, err := url.Parse(options.Proxy)
+			if err != nil {
+				return nil, errors.InvalidInput(fmt.Sprintf("invalid proxy: %s", options.Proxy))
+			}
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		for _, header := range options.Headers {
+			name, value, ok := strings.Cut(header, ":")
+			if !ok {
+				continue
+			}
+			headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+		}
+		if options.RateLimit > 0 {
+			interval = time.Second / time.Duration(options.RateLimit)
+		}
+	}
+
+	return &httpCheckClient{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// 不跟随重定向，避免把跳转后的页面误判为目标文件
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		headers:  headers,
+		interval: interval,
+	}, nil
+}
+
+// httpCheckResponse 检查用的响应摘要
+type httpCheckResponse struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       string
+}
+
+// get 请求目标下的路径，遵守速率限制
+func (c *httpCheckClient) get(ctx context.Context, target, path string) (*httpCheckResponse, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	reqURL, err := joinURLPath(target, path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, httpCheckMaxBody))
+	if err != nil {
+		return nil, err
+	}
+	return &httpCheckResponse{
+		URL:        reqURL,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+	}, nil
+}
+
+// wait 等待到下一个请求允许发出的时间
+func (c *httpCheckClient) wait(ctx context.Context) error {
+	if c.interval <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	next := c.last.Add(c.interval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	c.last = next
+	c.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// joinURLPath 把路径拼接到目标地址，path 为空时返回目标本身
+func joinURLPath(target, path string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "", errors.InvalidInput(fmt.Sprintf("invalid target url: %s", target))
+	}
+	if path == "" {
+		return u.String(), nil
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// checkSecurityHeaders 检查缺失的安全响应头
+func checkSecurityHeaders(ctx context.Context, c *httpCheckClient, target string) ([]Finding, error) {
+	resp, err := c.get(ctx, target, "")
+	if err != nil {
+		return nil, err
+	}
+
+	required := []string{"Content-Security-Policy", "X-Frame-Options", "X-Content-Type-Options"}
+	if strings.HasPrefix(resp.URL, "https://") {
+		required = append(required, "Strict-Transport-Security")
+	}
+	var missing []string
+	for _, name := range required {
+		if resp.Header.Get(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	return []Finding{{
+		Severity:    "info",
+		Description: "Response is missing security headers: " + strings.Join(missing, ", "),
+		URL:         resp.URL,
+		MatchedAt:   resp.URL,
+		Tags:        []string{"misconfig", "headers"},
+	}}, nil
+}
+
+// checkVersionDisclosure 检查 Server 与 X-Powered-By 中泄露的版本号
+func checkVersionDisclosure(ctx context.Context, c *httpCheckClient, target string) ([]Finding, error) {
+	resp, err := c.get(ctx, target, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var disclosed []string
+	for _, name := range []string{"Server", "X-Powered-By"} {
+		value := resp.Header.Get(name)
+		if value != "" && strings.ContainsAny(value, "0123456789") {
+			disclosed = append(disclosed, name+": "+value)
+		}
+	}
+	if len(disclosed) == 0 {
+		return nil, nil
+	}
+
+	return []Finding{{
+		Severity:    "low",
+		Description: "Response headers disclose software versions: " + strings.Join(disclosed, "; "),
+		URL:         resp.URL,
+		MatchedAt:   resp.URL,
+		Tags:        []string{"exposure", "tech"},
+	}}, nil
+}
+
+// checkGitConfig 检查 .git/config 是否可访问
+func checkGitConfig(ctx context.Context, c *httpCheckClient, target string) ([]Finding, error) {
+	resp, err := c.get(ctx, target, "/.git/config")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Body, "[core]") {
+		return nil, nil
+	}
+
+	return []Finding{{
+		Severity:    "medium",
+		Description: "Git repository configuration is publicly accessible, source code may be downloadable",
+		URL:         resp.URL,
+		MatchedAt:   resp.URL,
+		Tags:        []string{"exposure", "git", "config"},
+	}}, nil
+}
+
+// checkEnvFile 检查 .env 文件是否可访问
+func checkEnvFile(ctx context.Context, c *httpCheckClient, target string) ([]Finding, error) {
+	resp, err := c.get(ctx, target, "/.env")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || !looksLikeEnvFile(resp.Body) {
+		return nil, nil
+	}
+
+	return []Finding{{
+		Severity:    "high",
+		Description: "Environment file is publicly accessible and may contain credentials",
+		URL:         resp.URL,
+		MatchedAt:   resp.URL,
+		Tags:        []string{"exposure", "config", "secrets"},
+	}}, nil
+}
+
+// looksLikeEnvFile 判断内容是否为 KEY=value 格式的环境变量文件
+func looksLikeEnvFile(body string) bool {
+	if strings.Contains(strings.ToLower(body), "<html") {
+		return false
+	}
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, ok := strings.Cut(line, "=")
+		if ok && key != "" && !strings.ContainsAny(key, " \t<>") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDirectoryListing 检查根路径是否开启目录列表
+func checkDirectoryListing(ctx context.Context, c *httpCheckClient, target string) ([]Finding, error) {
+	resp, err := c.get(ctx, target, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Body, "Index of /") {
+		return nil, nil
+	}
+
+	return []Finding{{
+		Severity:    "low",
+		Description: "Directory listing is enabled",
+		URL:         resp.URL,
+		MatchedAt:   resp.URL,
+		Tags:        []string{"exposure", "misconfig"},
+	}}, nil
+}
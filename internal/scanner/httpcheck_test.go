@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// TestHTTPCheckEngine_Run 测试内置 HTTP 检查的发现、检查项选择与请求头
+func TestHTTPCheckEngine_Run(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Scan") != "holehunter" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/.git/config":
+			fmt.Fprintln(w, "[core]\n\trepositoryformatversion = 0")
+		case "/.env":
+			// SPA 对任意路径返回首页，不应误报
+			fmt.Fprintln(w, "<html><body>app</body></html>")
+		case "/":
+			w.Header().Set("X-Powered-By", "PHP/7.4.3")
+			fmt.Fprintln(w, "<html><title>Index of /</title></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var findings []*NucleiOutput
+	var last ScanProgress
+	sink := FindingSink{
+		OnFinding: func(output *NucleiOutput) {
+			mu.Lock()
+			defer mu.Unlock()
+			findings = append(findings, output)
+		},
+		OnProgress: func(progress ScanProgress) { last = progress },
+	}
+
+	engine := NewHTTPCheckEngine()
+	req := ScanRequest{
+		TaskID:  1,
+		Targets: []ScanTarget{{ID: 1, URL: server.URL}},
+		Options: &models.ScanOptions{Headers: []string{"X-Scan: holehunter"}, RateLimit: 100},
+	}
+	runner, err := engine.NewRunner(req, sink)
+	if err != nil {
+		t.Fatalf("NewRunner() failed: %v", err)
+	}
+	if err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	got := make(map[string]*NucleiOutput)
+	for _, f := range findings {
+		got[f.TemplateID] = f
+	}
+	want := map[string]string{
+		"http-missing-security-headers": "info",
+		"http-version-disclosure":       "low",
+		"http-git-config-exposure":      "medium",
+		"http-directory-listing":        "low",
+	}
+	if len(got) != len(want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+	for id, severity := range want {
+		f := got[id]
+		if f == nil || f.Severity != severity || f.Info["severity"] != severity || f.Host != server.URL {
+			t.Errorf("finding %s = %+v, want severity %s", id, f, severity)
+		}
+	}
+	if last.Executed != len(httpChecks) || last.Progress != 100 {
+		t.Errorf("last progress = %+v, want all checks executed", last)
+	}
+
+	// 只执行选中的检查项
+	req.Templates = []string{"http-git-config-exposure"}
+	findings = nil
+	runner, err = engine.NewRunner(req, sink)
+	if err != nil {
+		t.Fatalf("NewRunner() failed: %v", err)
+	}
+	if err := runner.Run(context.Background()); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if len(findings) != 1 || findings[0].TemplateID != "http-git-config-exposure" {
+		t.Errorf("selected findings = %+v", findings)
+	}
+
+	req.Templates = []string{"unknown"}
+	if _, err := engine.NewRunner(req, sink); err == nil {
+		t.Error("NewRunner() should reject unknown checks")
+	}
+}
+
+// TestHTTPCheckEngine_Stop 测试停止时中断正在进行的请求
+func TestHTTPCheckEngine_Stop(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	runner, err := NewHTTPCheckEngine().NewRunner(ScanRequest{TaskID: 1, TargetURL: server.URL}, FindingSink{})
+	if err != nil {
+		t.Fatalf("NewRunner() failed: %v", err)
+	}
+	if err := runner.Suspend(); err != errSuspendUnsupported {
+		t.Errorf("Suspend() = %v, want errSuspendUnsupported", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- runner.Run(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+
+	if err := runner.Stop(); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Run() should return an error after Stop()")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after Stop()")
+	}
+}
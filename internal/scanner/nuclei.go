@@ -6,9 +6,11 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
 )
 
 // NucleiClient Nuclei 客户端
@@ -73,36 +75,73 @@ func (n *NucleiClient) GetVersion() string {
 	return strings.TrimSpace(string(output))
 }
 
-// BuildCommand 构建扫描命令
-func (n *NucleiClient) BuildCommand(targetURL, strategy string, templates []string, customDir string) (*exec.Cmd, error) {
+// BuildCommand 根据扫描请求构建扫描命令
+// TargetList 非空时通过 -l 从列表文件读取目标，忽略 TargetURL
+// ResumeFile 非空时通过 -resume 从断点继续，并在中断时写入该文件
+// SecretFile 非空时通过 -secret-file 传入认证凭据，避免凭据出现在命令行中
+// ExcludeFile 非空时通过 -exclude-id 排除文件中列出的模板 ID（每行一个）
+// ManifestFile 非空时通过 -id 只运行清单中的模板，策略中的严重程度、标签与模板 ID 过滤不再生效
+// Options 为已合并默认值的调优参数，为 nil 时使用 nuclei 默认值
+func (n *NucleiClient) BuildCommand(req *ScanRequest) (*exec.Cmd, error) {
 	if !n.IsAvailable() {
 		return nil, errors.Internal("nuclei binary not found", nil)
 	}
 
-	args := n.buildArgs(targetURL, strategy, templates, customDir)
+	args := n.buildArgs(req)
 	cmd := exec.Command(n.binaryPath, args...)
 	return cmd, nil
 }
 
 // buildArgs 构建命令参数
-func (n *NucleiClient) buildArgs(targetURL, strategy string, templates []string, customDir string) []string {
-	args := []string{
-		"-u", targetURL,
+func (n *NucleiClient) buildArgs(req *ScanRequest) []string {
+	strategy, templates := req.Strategy, req.Templates
+
+	// 多目标任务通过列表文件传入目标
+	args := []string{"-u", req.TargetURL}
+	if req.TargetList != "" {
+		args = []string{"-l", req.TargetList}
+	}
+	args = append(args,
 		"-jsonl",      // JSONL 格式输出（每行一个 JSON 对象）
 		"-stats-json", // 启用 JSON 格式的统计/进度输出
 		"-no-color",   // 禁用颜色输出
 		"-si", "3",    // 每 3 秒更新一次统计
+	)
+
+	// 断点文件：存在时从断点继续，收到 SIGINT 时 nuclei 会写入当前进度
+	if req.ResumeFile != "" {
+		args = append(args, "-resume", req.ResumeFile)
+	}
+
+	if req.SecretFile != "" {
+		args = append(args, "-secret-file", req.SecretFile)
 	}
 
+	// 用户禁用的模板
+	if req.ExcludeFile != "" {
+		args = append(args, "-exclude-id", req.ExcludeFile)
+	}
+
+	args = append(args, optionArgs(req.Options)...)
+
 	// 添加模板目录
 	if n.templatesDir != "" {
 		args = append(args, "-t", n.templatesDir)
 	}
 
 	// 添加自定义模板目录
-	hasCustomTemplates := n.hasCustomTemplates(customDir)
+	hasCustomTemplates := n.hasCustomTemplates(req.CustomDir)
 	if hasCustomTemplates {
-		args = append(args, "-t", customDir)
+		args = append(args, "-t", req.CustomDir)
+	}
+
+	// 模板清单已按策略解析，只保留 passive 模式参数
+	if req.ManifestFile != "" {
+		args = append(args, "-id", req.ManifestFile)
+		if strategy == "passive" {
+			args = append(args, "-passive")
+		}
+		return args
 	}
 
 	// 根据策略添加参数
@@ -148,6 +187,37 @@ func (n *NucleiClient) buildArgs(targetURL, strategy string, templates []string,
 	return args
 }
 
+// optionArgs 将调优参数转换为 nuclei 参数，未设置的字段不传
+func optionArgs(options *models.ScanOptions) []string {
+	if options == nil {
+		return nil
+	}
+
+	var args []string
+	if options.RateLimit > 0 {
+		args = append(args, "-rl", strconv.Itoa(options.RateLimit))
+	}
+	if options.Concurrency > 0 {
+		args = append(args, "-c", strconv.Itoa(options.Concurrency))
+	}
+	if options.Timeout > 0 {
+		args = append(args, "-timeout", strconv.Itoa(options.Timeout))
+	}
+	switch {
+	case options.Retries > 0:
+		args = append(args, "-retries", strconv.Itoa(options.Retries))
+	case options.Retries < 0:
+		args = append(args, "-retries", "0")
+	}
+	if options.Proxy != "" {
+		args = append(args, "-proxy", options.Proxy)
+	}
+	for _, header := range options.Headers {
+		args = append(args, "-H", header)
+	}
+	return args
+}
+
 // isSeverityStrategy 检查是否是 severity 策略
 func isSeverityStrategy(strategy string) bool {
 	if strategy == "" {
@@ -284,3 +354,43 @@ func findNucleiBinaryInDir(userDataDir string) string {
 
 	return ""
 }
+
+// NucleiEngine 基于 nuclei 模板的扫描引擎
+type NucleiEngine struct {
+	client *NucleiClient
+}
+
+// NewNucleiEngine 创建 nuclei 扫描引擎
+func NewNucleiEngine(client *NucleiClient) *NucleiEngine {
+	return &NucleiEngine{client: client}
+}
+
+// Name 引擎名称
+func (e *NucleiEngine) Name() string {
+	return EngineNuclei
+}
+
+// Description 引擎说明
+func (e *NucleiEngine) Description() string {
+	return "Nuclei 模板扫描，支持断点续扫、认证配置与调优参数"
+}
+
+// Available 检查 nuclei 是否可用
+func (e *NucleiEngine) Available() bool {
+	return e.client.IsAvailable()
+}
+
+// NewRunner 构建 nuclei 命令
+func (e *NucleiEngine) NewRunner(req ScanRequest, sink FindingSink) (Runner, error) {
+	cmd, err := e.client.BuildCommand(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置环境变量 - nuclei 需要这些来定位模板目录
+	cmd.Env = append(os.Environ(),
+		"NUCLEI_TEMPLATES_DIR="+e.client.templatesDir,
+	)
+
+	return NewScanProcess(req.TaskID, cmd, NewOutputParser(sink.OnFinding, sink.OnProgress)), nil
+}
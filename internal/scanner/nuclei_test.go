@@ -4,7 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/holehunter/holehunter/internal/models"
 )
 
 func TestNewNucleiClient(t *testing.T) {
@@ -97,7 +100,7 @@ func TestIsValidTemplateID(t *testing.T) {
 func TestBuildCommand(t *testing.T) {
 	t.Run("unavailable nuclei", func(t *testing.T) {
 		client := &NucleiClient{binaryPath: "", templatesDir: "/tmp"}
-		cmd, err := client.BuildCommand("https://example.com", "fast", nil, "")
+		cmd, err := client.BuildCommand(&ScanRequest{TargetURL: "https://example.com", Strategy: "fast"})
 
 		if err == nil {
 			t.Error("BuildCommand() should return error when nuclei binary is empty")
@@ -118,7 +121,7 @@ func TestBuildCommand(t *testing.T) {
 		file.Close()
 
 		client := &NucleiClient{binaryPath: fakeNuclei, templatesDir: tmpDir}
-		cmd, err := client.BuildCommand("https://example.com", "fast", nil, "")
+		cmd, err := client.BuildCommand(&ScanRequest{TargetURL: "https://example.com", Strategy: "fast"})
 
 		if err != nil {
 			t.Errorf("BuildCommand() unexpected error: %v", err)
@@ -192,12 +195,18 @@ func TestBuildArgs(t *testing.T) {
 	client := NewNucleiClient("/tmp/test")
 
 	tests := []struct {
-		name      string
-		targetURL string
-		strategy  string
-		templates []string
-		customDir string
-		checkFn   func(*testing.T, []string)
+		name         string
+		targetURL    string
+		targetList   string
+		strategy     string
+		templates    []string
+		customDir    string
+		resumeFile   string
+		secretFile   string
+		excludeFile  string
+		manifestFile string
+		options      *models.ScanOptions
+		checkFn      func(*testing.T, []string)
 	}{
 		{
 			name:      "quick strategy",
@@ -275,11 +284,154 @@ func TestBuildArgs(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:       "resume file",
+			targetURL:  "https://example.com",
+			strategy:   "deep",
+			resumeFile: "/tmp/test/resume/scan-1.cfg",
+			checkFn: func(t *testing.T, args []string) {
+				found := false
+				for i, arg := range args {
+					if arg == "-resume" && i+1 < len(args) && args[i+1] == "/tmp/test/resume/scan-1.cfg" {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Error("resume file should be passed with -resume")
+				}
+			},
+		},
+		{
+			name:       "target list",
+			targetURL:  "https://example.com",
+			targetList: "/tmp/test/targets/scan-1.txt",
+			strategy:   "deep",
+			checkFn: func(t *testing.T, args []string) {
+				if len(args) < 2 || args[0] != "-l" || args[1] != "/tmp/test/targets/scan-1.txt" {
+					t.Errorf("target list should be passed with -l, got %v", args)
+				}
+				for _, arg := range args {
+					if arg == "-u" {
+						t.Error("-u should be omitted with target list")
+					}
+				}
+			},
+		},
+		{
+			name:       "secret file",
+			targetURL:  "https://example.com",
+			strategy:   "deep",
+			secretFile: "/tmp/test/secrets/scan-1.yaml",
+			checkFn: func(t *testing.T, args []string) {
+				if !strings.Contains(strings.Join(args, " "), "-secret-file /tmp/test/secrets/scan-1.yaml") {
+					t.Errorf("secret file should be passed with -secret-file, got %v", args)
+				}
+			},
+		},
+		{
+			name:        "excluded templates",
+			targetURL:   "https://example.com",
+			strategy:    "deep",
+			excludeFile: "/tmp/test/template-snapshots/scan-1.exclude",
+			checkFn: func(t *testing.T, args []string) {
+				if !strings.Contains(strings.Join(args, " "), "-exclude-id /tmp/test/template-snapshots/scan-1.exclude") {
+					t.Errorf("disabled templates should be excluded with -exclude-id, got %v", args)
+				}
+			},
+		},
+		{
+			name:         "template manifest replaces strategy filters",
+			targetURL:    "https://example.com",
+			strategy:     "tags:cve",
+			manifestFile: "/tmp/test/template-snapshots/scan-1.manifest",
+			checkFn: func(t *testing.T, args []string) {
+				joined := strings.Join(args, " ")
+				if !strings.Contains(joined, "-id /tmp/test/template-snapshots/scan-1.manifest") {
+					t.Errorf("manifest should be passed with -id, got %v", args)
+				}
+				if strings.Contains(joined, "-tags") {
+					t.Errorf("strategy filters should not be added with a manifest, got %v", args)
+				}
+			},
+		},
+		{
+			name:         "template manifest keeps passive mode",
+			targetURL:    "https://example.com",
+			strategy:     "passive",
+			manifestFile: "/tmp/test/template-snapshots/scan-1.manifest",
+			checkFn: func(t *testing.T, args []string) {
+				if !strings.Contains(strings.Join(args, " "), "-passive") {
+					t.Errorf("passive mode should be kept with a manifest, got %v", args)
+				}
+			},
+		},
+		{
+			name:      "tuning options",
+			targetURL: "https://example.com",
+			strategy:  "deep",
+			options: &models.ScanOptions{
+				RateLimit:   10,
+				Concurrency: 5,
+				Timeout:     30,
+				Retries:     -1,
+				Proxy:       "socks5://127.0.0.1:1080",
+				Headers:     []string{"X-Scan: holehunter", "User-Agent: test"},
+			},
+			checkFn: func(t *testing.T, args []string) {
+				joined := strings.Join(args, " ")
+				for _, want := range []string{
+					"-rl 10", "-c 5", "-timeout 30", "-retries 0",
+					"-proxy socks5://127.0.0.1:1080", "-H X-Scan: holehunter", "-H User-Agent: test",
+				} {
+					if !strings.Contains(joined, want) {
+						t.Errorf("args should contain %q, got %v", want, args)
+					}
+				}
+			},
+		},
+		{
+			name:      "no tuning options",
+			targetURL: "https://example.com",
+			strategy:  "deep",
+			options:   &models.ScanOptions{},
+			checkFn: func(t *testing.T, args []string) {
+				for _, arg := range args {
+					switch arg {
+					case "-rl", "-c", "-timeout", "-retries", "-proxy", "-H":
+						t.Errorf("%s should be omitted when unset", arg)
+					}
+				}
+			},
+		},
+		{
+			name:      "no resume file",
+			targetURL: "https://example.com",
+			strategy:  "deep",
+			checkFn: func(t *testing.T, args []string) {
+				for _, arg := range args {
+					if arg == "-resume" {
+						t.Error("-resume should be omitted without resume file")
+					}
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			args := client.buildArgs(tt.targetURL, tt.strategy, tt.templates, tt.customDir)
+			args := client.buildArgs(&ScanRequest{
+				TargetURL:    tt.targetURL,
+				TargetList:   tt.targetList,
+				Strategy:     tt.strategy,
+				Templates:    tt.templates,
+				CustomDir:    tt.customDir,
+				ResumeFile:   tt.resumeFile,
+				SecretFile:   tt.secretFile,
+				ExcludeFile:  tt.excludeFile,
+				ManifestFile: tt.manifestFile,
+				Options:      tt.options,
+			})
 			if tt.checkFn != nil {
 				tt.checkFn(t, args)
 			}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,30 +24,67 @@ type Scanner interface {
 	Scan(ctx context.Context, req ScanRequest) error
 	// Stop 停止扫描
 	Stop(ctx context.Context, taskID int) error
+	// Pause 暂停扫描
+	Pause(ctx context.Context, taskID int) error
+	// Resume 恢复暂停的扫描
+	Resume(ctx context.Context, taskID int) error
+	// IsPaused 检查扫描是否处于挂起状态
+	IsPaused(taskID int) bool
 	// GetProgress 获取扫描进度
 	GetProgress(ctx context.Context, taskID int) (*models.ScanProgress, error)
 	// IsRunning 检查是否正在运行
 	IsRunning(taskID int) bool
 	// GetRunningCount 获取运行中的扫描数量
 	GetRunningCount() int
+	// HasCapacity 检查是否还有空闲的并发槽位
+	HasCapacity() bool
+	// GetStatus 获取 Nuclei 状态
+	GetStatus() models.NucleiStatus
+	// Engines 列出可选的扫描引擎
+	Engines() []models.ScanEngine
+	// HasEngine 检查扫描引擎是否已注册
+	HasEngine(name string) bool
 }
 
+var _ Scanner = (*Orchestrator)(nil)
+
 // ScanRequest 扫描请求
 type ScanRequest struct {
-	Context   context.Context
 	TaskID    int
 	Name      string
 	TargetID  int
 	TargetURL string
-	Strategy  string
-	Templates []string
-	CustomDir string
+	// Targets 多目标任务的全部目标，用于将发现归属到目标
+	Targets []ScanTarget
+	// TargetList 目标列表文件，非空时代替 TargetURL 传给 nuclei
+	TargetList string
+	Strategy   string
+	Templates  []string
+	CustomDir  string
+	// ResumeFile nuclei 断点文件，为空时不支持断点续扫
+	ResumeFile string
+	// ExcludeFile 禁用的模板 ID 列表文件，每行一个
+	ExcludeFile string
+	// ManifestFile 模板清单文件，每行一个模板 ID，非空时按清单选择模板而不是按策略
+	ManifestFile string
+	// TemplateCount 清单中的模板数，大于 0 时按模板数计算进度
+	TemplateCount int
+	// Options 已合并全局默认值的调优参数
+	Options *models.ScanOptions
+	// SecretFile nuclei 认证凭据文件，扫描结束后删除
+	SecretFile string
+	// Secrets 需要在日志中隐藏的凭据
+	Secrets []string
+	// Timeout 扫描最长运行时间，超时后终止进程，0 表示不限制
+	Timeout time.Duration
+	// Engine 扫描引擎名称，为空时使用 nuclei
+	Engine string
 }
 
 // Orchestrator 扫描编排器
 type Orchestrator struct {
 	nuclei        *NucleiClient
-	processMgr    *ScanProcessManager
+	engines       *EngineRegistry
 	eventBus      *event.Bus
 	logger        *logger.Logger
 	maxConcurrent int
@@ -53,6 +92,15 @@ type Orchestrator struct {
 
 	mu    sync.RWMutex
 	scans map[int]*ScanContext
+
+	// onFinished 扫描结束并释放并发槽位后调用
+	onFinished func(taskID int)
+
+	// redactors 各任务隐藏凭据用的替换器，taskID -> *strings.Replacer
+	redactors sync.Map
+
+	// releasePausedSlots 暂停的扫描是否让出并发槽位
+	releasePausedSlots bool
 }
 
 // ScanContext 扫描上下文
@@ -63,8 +111,24 @@ type ScanContext struct {
 	VulnCount  atomic.Int32
 	ProgressMu sync.RWMutex
 	Progress   *models.ScanProgress
-	StartTime  time.Time
-	metrics    *metrics.Metrics
+	// targetVulns 各目标的漏洞数量，由 ProgressMu 保护
+	targetVulns map[int]int
+	StartTime   time.Time
+	metrics     *metrics.Metrics
+	// stopped 用户主动停止，进程退出不视为失败
+	stopped atomic.Bool
+	// timedOut 运行时间超过上限
+	timedOut atomic.Bool
+
+	// pauseMu 保护暂停状态
+	pauseMu sync.Mutex
+	// pausedAt 挂起的时间，零值表示未挂起
+	pausedAt time.Time
+	// pausedTotal 累计挂起时长，不计入超时
+	pausedTotal time.Duration
+
+	// runner 扫描引擎创建的执行体
+	runner Runner
 }
 
 // NewOrchestrator 创建扫描编排器
@@ -76,9 +140,13 @@ func NewOrchestrator(
 	metrics *metrics.Metrics,
 	scanRepo *repo.ScanRepository,
 ) *Orchestrator {
+	engines := NewEngineRegistry()
+	engines.Register(NewNucleiEngine(nuclei))
+	engines.Register(NewHTTPCheckEngine())
+
 	return &Orchestrator{
 		nuclei:        nuclei,
-		processMgr:    NewScanProcessManager(),
+		engines:       engines,
 		eventBus:      eventBus,
 		logger:        logger,
 		maxConcurrent: maxConcurrent,
@@ -87,13 +155,29 @@ func NewOrchestrator(
 	}
 }
 
+// RegisterEngine 注册扫描引擎，同名引擎会被替换
+func (o *Orchestrator) RegisterEngine(engine Engine) {
+	o.engines.Register(engine)
+}
+
+// Engines 列出已注册的扫描引擎
+func (o *Orchestrator) Engines() []models.ScanEngine {
+	return o.engines.List()
+}
+
+// HasEngine 检查扫描引擎是否已注册
+func (o *Orchestrator) HasEngine(name string) bool {
+	_, ok := o.engines.Get(name)
+	return ok
+}
+
 // Scan 启动扫描
 func (o *Orchestrator) Scan(ctx context.Context, req ScanRequest) error {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
 	// 检查并发限制
-	if len(o.scans) >= o.maxConcurrent {
+	if o.activeCount() >= o.maxConcurrent {
 		return errors.Conflict("max concurrent scans reached")
 	}
 
@@ -102,44 +186,61 @@ func (o *Orchestrator) Scan(ctx context.Context, req ScanRequest) error {
 		return errors.Conflict("scan task already running")
 	}
 
-	// 检查扫描器是否可用
-	if !o.nuclei.IsAvailable() {
-		return errors.Internal("nuclei binary not found", nil)
+	// 检查扫描引擎是否可用
+	engine, ok := o.engines.Get(req.Engine)
+	if !ok {
+		return errors.InvalidInput(fmt.Sprintf("unknown scan engine: %s", req.Engine))
+	}
+	if !engine.Available() {
+		return errors.Internal(fmt.Sprintf("scan engine %s is not available", engine.Name()), nil)
+	}
+
+	if len(req.Targets) == 0 {
+		req.Targets = []ScanTarget{{ID: req.TargetID, URL: req.TargetURL}}
 	}
 
-	// 构建命令
-	cmd, err := o.nuclei.BuildCommand(req.TargetURL, req.Strategy, req.Templates, req.CustomDir)
+	// 构建执行体
+	runner, err := engine.NewRunner(req, FindingSink{
+		OnFinding:  o.onVulnerability(req.TaskID),
+		OnProgress: o.onProgress(req.TaskID),
+	})
 	if err != nil {
 		return errors.Internal("failed to build command", err)
 	}
 
-	// 设置环境变量 - nuclei 需要这些来定位模板目录
-	cmd.Env = append(os.Environ(),
-		"NUCLEI_TEMPLATES_DIR="+o.nuclei.templatesDir,
-	)
+	if redactor := newRedactor(req.Secrets); redactor != nil {
+		o.redactors.Store(req.TaskID, redactor)
+	}
 
-	o.logger.Info("Nuclei command: %s %s", cmd.Path, cmd.String())
-	o.logger.Info("Templates dir: %s, Strategy: %s", o.nuclei.templatesDir, req.Strategy)
+	if process, ok := runner.(*ScanProcess); ok {
+		o.logger.Info("Scan command: engine=%s, command=%s", engine.Name(), o.redact(req.TaskID, process.Cmd.String()))
+	}
+	o.logger.Info("Templates dir: %s, Engine: %s, Strategy: %s", o.nuclei.templatesDir, engine.Name(), req.Strategy)
 
 	// 创建扫描上下文
 	ctx, cancel := context.WithCancel(ctx)
 	scanContext := &ScanContext{
-		TaskID:     req.TaskID,
-		Request:    req,
-		CancelFunc: cancel,
-		StartTime:  time.Now(),
-		metrics:    metrics.Global,
+		TaskID:      req.TaskID,
+		Request:     req,
+		CancelFunc:  cancel,
+		StartTime:   time.Now(),
+		metrics:     metrics.Global,
+		targetVulns: make(map[int]int),
 		Progress: &models.ScanProgress{
 			TaskID: req.TaskID,
 			Status: "running",
 		},
+		runner: runner,
 	}
 
-	// 创建进程
-	process := NewScanProcess(req.TaskID, cmd)
-
-	// 添加到进程管理器
-	o.processMgr.Add(process)
+	// 外部进程记录 PID，便于应用异常退出后清理
+	if process, ok := runner.(*ScanProcess); ok && o.scanRepo != nil {
+		process.OnStarted = func(pid int) {
+			if err := o.scanRepo.UpdatePID(context.Background(), req.TaskID, pid); err != nil {
+				o.logger.Warn("Failed to record scan pid: task_id=%d, pid=%d, error=%v", req.TaskID, pid, err)
+			}
+		}
+	}
 
 	// 保存上下文
 	o.scans[req.TaskID] = scanContext
@@ -148,8 +249,9 @@ func (o *Orchestrator) Scan(ctx context.Context, req ScanRequest) error {
 	o.eventBus.PublishAsync(ctx, event.Event{
 		Type: event.EventScanStarted,
 		Data: map[string]interface{}{
-			"taskId":   req.TaskID,
-			"targetId": req.TargetID,
+			"taskId":    req.TaskID,
+			"targetId":  req.TargetID,
+			"targetIds": targetIDs(req.Targets),
 		},
 	})
 
@@ -157,29 +259,33 @@ func (o *Orchestrator) Scan(ctx context.Context, req ScanRequest) error {
 	metrics.Global.IncrementScanStarted()
 
 	// 记录扫描日志到数据库
-	o.addScanLog(ctx, req.TaskID, "info", fmt.Sprintf("Scan started: target=%s, strategy=%s", req.TargetURL, req.Strategy))
+	o.addScanLog(ctx, req.TaskID, "info", fmt.Sprintf("Scan started: target=%s, strategy=%s", describeTargets(req), req.Strategy))
+	if hasResumeFile(req.ResumeFile) {
+		o.addScanLog(ctx, req.TaskID, "info", "Resuming scan from checkpoint")
+	}
 
 	// 启动扫描
-	go o.runScan(scanContext, process)
+	go o.runScan(scanContext)
 
-	o.logger.Info("Scan started: task_id=%d, target=%s, strategy=%s", req.TaskID, req.TargetURL, req.Strategy)
+	o.logger.Info("Scan started: task_id=%d, target=%s, engine=%s, strategy=%s", req.TaskID, describeTargets(req), engine.Name(), req.Strategy)
 	return nil
 }
 
 // Stop 停止扫描
 func (o *Orchestrator) Stop(ctx context.Context, taskID int) error {
-	o.mu.Lock()
-	defer o.mu.Unlock()
-
+	// 等待进程退出期间不持有锁，避免阻塞输出解析回调
+	o.mu.RLock()
 	scanCtx, exists := o.scans[taskID]
+	o.mu.RUnlock()
 	if !exists {
 		return errors.NotFound("scan task not found")
 	}
 
+	scanCtx.stopped.Store(true)
 	scanCtx.CancelFunc()
 
-	if process, ok := o.processMgr.Get(taskID); ok {
-		if err := process.Stop(); err != nil {
+	if scanCtx.runner != nil {
+		if err := scanCtx.runner.Stop(); err != nil {
 			o.logger.Error("Failed to stop scan process: task_id=%d, error=%v", taskID, err)
 			return errors.Internal("failed to stop scan", err)
 		}
@@ -205,6 +311,124 @@ func (o *Orchestrator) Stop(ctx context.Context, taskID int) error {
 	return nil
 }
 
+// Pause 暂停扫描
+// 支持挂起进程的平台上挂起整个进程组，否则停止进程并保留断点文件，由调用方重新启动以恢复
+func (o *Orchestrator) Pause(ctx context.Context, taskID int) error {
+	o.mu.RLock()
+	scanCtx, exists := o.scans[taskID]
+	o.mu.RUnlock()
+	if !exists {
+		return errors.NotFound("scan task not found")
+	}
+	runner := scanCtx.runner
+
+	scanCtx.pauseMu.Lock()
+	if !scanCtx.pausedAt.IsZero() {
+		scanCtx.pauseMu.Unlock()
+		return errors.Conflict("scan task is already paused")
+	}
+	err := runner.Suspend()
+	if err == nil {
+		scanCtx.pausedAt = time.Now()
+	}
+	scanCtx.pauseMu.Unlock()
+
+	checkpoint := false
+	switch {
+	case err == errSuspendUnsupported:
+		// 按停止处理进程退出，保留断点文件
+		scanCtx.stopped.Store(true)
+		if err := runner.Stop(); err != nil {
+			o.logger.Error("Failed to stop scan process for pause: task_id=%d, error=%v", taskID, err)
+			return errors.Internal("failed to pause scan", err)
+		}
+		checkpoint = true
+	case err != nil:
+		o.logger.Error("Failed to suspend scan process: task_id=%d, error=%v", taskID, err)
+		return errors.Internal("failed to pause scan", err)
+	default:
+		scanCtx.setProgressStatus("paused")
+	}
+
+	o.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScanPaused,
+		Data: map[string]interface{}{
+			"taskId":     taskID,
+			"checkpoint": checkpoint,
+		},
+	})
+
+	if checkpoint {
+		o.addScanLog(ctx, taskID, "info", "Scan paused: process stopped, will continue from checkpoint on resume")
+	} else {
+		o.addScanLog(ctx, taskID, "info", "Scan paused")
+	}
+
+	o.logger.Info("Scan paused: task_id=%d, checkpoint=%t", taskID, checkpoint)
+	return nil
+}
+
+// Resume 恢复挂起的扫描
+// 暂停的扫描让出槽位时，需有空闲槽位才能恢复
+func (o *Orchestrator) Resume(ctx context.Context, taskID int) error {
+	// 持有锁检查槽位，避免与新启动的扫描争用
+	o.mu.Lock()
+	scanCtx, exists := o.scans[taskID]
+	if !exists {
+		o.mu.Unlock()
+		return errors.NotFound("scan task not found")
+	}
+	if !scanCtx.IsPaused() {
+		o.mu.Unlock()
+		return errors.Conflict("scan task is not paused")
+	}
+	if o.releasePausedSlots && o.activeCount() >= o.maxConcurrent {
+		o.mu.Unlock()
+		return errors.Conflict("max concurrent scans reached")
+	}
+	scanCtx.pauseMu.Lock()
+	err := scanCtx.runner.Continue()
+	var paused time.Duration
+	if err == nil {
+		paused = time.Since(scanCtx.pausedAt)
+		scanCtx.pausedTotal += paused
+		scanCtx.pausedAt = time.Time{}
+	}
+	scanCtx.pauseMu.Unlock()
+	o.mu.Unlock()
+
+	if err != nil {
+		o.logger.Error("Failed to resume scan process: task_id=%d, error=%v", taskID, err)
+		return errors.Internal("failed to resume scan", err)
+	}
+	scanCtx.setProgressStatus("running")
+
+	o.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScanResumed,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+		},
+	})
+
+	o.addScanLog(ctx, taskID, "info", fmt.Sprintf("Scan resumed: paused for %s", paused.Round(time.Second)))
+
+	o.logger.Info("Scan resumed: task_id=%d", taskID)
+	return nil
+}
+
+// IsPaused 检查扫描是否处于挂起状态
+func (o *Orchestrator) IsPaused(taskID int) bool {
+	o.mu.RLock()
+	scanCtx, exists := o.scans[taskID]
+	o.mu.RUnlock()
+	return exists && scanCtx.IsPaused()
+}
+
+// SetReleasePausedSlots 设置暂停的扫描是否让出并发槽位，需在启动扫描前设置
+func (o *Orchestrator) SetReleasePausedSlots(release bool) {
+	o.releasePausedSlots = release
+}
+
 // GetProgress 获取扫描进度
 func (o *Orchestrator) GetProgress(ctx context.Context, taskID int) (*models.ScanProgress, error) {
 	o.mu.RLock()
@@ -232,36 +456,76 @@ func (o *Orchestrator) GetRunningCount() int {
 	return len(o.scans)
 }
 
+// HasCapacity 检查是否还有空闲的并发槽位
+func (o *Orchestrator) HasCapacity() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.activeCount() < o.maxConcurrent
+}
+
+// activeCount 占用并发槽位的扫描数量，调用方需持有 mu
+func (o *Orchestrator) activeCount() int {
+	if !o.releasePausedSlots {
+		return len(o.scans)
+	}
+	count := 0
+	for _, scanCtx := range o.scans {
+		if !scanCtx.IsPaused() {
+			count++
+		}
+	}
+	return count
+}
+
+// SetFinishedHandler 设置扫描结束回调，需在启动扫描前设置
+func (o *Orchestrator) SetFinishedHandler(fn func(taskID int)) {
+	o.onFinished = fn
+}
+
 // runScan 运行扫描
-func (o *Orchestrator) runScan(scanCtx *ScanContext, process *ScanProcess) {
-	o.logger.Info("Running scan: task_id=%d, target=%s", scanCtx.TaskID, scanCtx.Request.TargetURL)
+func (o *Orchestrator) runScan(scanCtx *ScanContext) {
+	o.logger.Info("Running scan: task_id=%d, target=%s", scanCtx.TaskID, describeTargets(scanCtx.Request))
 
 	defer func() {
 		o.mu.Lock()
 		delete(o.scans, scanCtx.TaskID)
-		o.processMgr.Remove(scanCtx.TaskID)
 		o.mu.Unlock()
-	}()
 
-	// 创建输出解析器
-	parser := NewOutputParser(
-		o.onVulnerability(scanCtx.TaskID),
-		o.onProgress(scanCtx.TaskID),
-	)
+		o.redactors.Delete(scanCtx.TaskID)
+		o.removeSecretFile(scanCtx)
 
-	// 使用 scanCtx 的 context 而不是 Background
-	ctx := scanCtx.Request.Context
-	if ctx == nil {
-		ctx = context.Background()
+		if o.onFinished != nil {
+			o.onFinished(scanCtx.TaskID)
+		}
+	}()
+
+	// 进程的生命周期不跟随发起请求的 context，只受超时与 Stop 控制
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if scanCtx.Request.Timeout > 0 {
+		go o.watchTimeout(scanCtx, cancel, ctx.Done())
 	}
 
 	// 运行扫描
-	if err := process.Run(ctx, parser); err != nil {
+	if err := scanCtx.runner.Run(ctx); err != nil {
+		// 用户停止时保留断点文件，状态由调用方更新
+		if scanCtx.stopped.Load() {
+			o.logger.Info("Scan process exited after stop: task_id=%d, error=%v", scanCtx.TaskID, err)
+			return
+		}
+		// 超时终止时 nuclei 已写入断点文件，保留以便重新启动后继续
+		if scanCtx.timedOut.Load() {
+			o.handleScanTimeout(scanCtx)
+			return
+		}
 		o.logger.Error("Scan failed: task_id=%d, error=%v", scanCtx.TaskID, err)
 		o.handleScanError(scanCtx.TaskID, err)
 		return
 	}
 
+	// 扫描完成后断点文件不再需要
+	o.removeResumeFile(scanCtx)
+
 	// 扫描完成
 	vulnCount := int(scanCtx.VulnCount.Load())
 	o.onScanCompleted(scanCtx.TaskID, vulnCount)
@@ -274,27 +538,37 @@ func (o *Orchestrator) onVulnerability(taskID int) func(*NucleiOutput) {
 		scanCtx, exists := o.scans[taskID]
 		o.mu.RUnlock()
 
+		targetID := 0
 		if exists {
+			targetID = attributeTarget(scanCtx.Request.Targets, output)
 			count := scanCtx.VulnCount.Add(1)
-			o.logger.Debug("Vulnerability found: task_id=%d, count=%d, vuln=%s", taskID, count, output.Name)
+
+			scanCtx.ProgressMu.Lock()
+			scanCtx.targetVulns[targetID]++
+			scanCtx.ProgressMu.Unlock()
+
+			o.logger.Debug("Vulnerability found: task_id=%d, target_id=%d, count=%d, vuln=%s", taskID, targetID, count, output.Name)
 		}
 
-		// 使用 scanCtx 的 context（如果存在），否则使用 Background
+		// 扫描在后台运行，日志与事件不跟随发起请求的 context，调用方结束后到达的发现也能写入
 		ctx := context.Background()
-		if exists && scanCtx.Request.Context != nil {
-			ctx = scanCtx.Request.Context
-		}
 
 		// 记录漏洞发现日志到数据库
 		o.addScanLog(ctx, taskID, "info", fmt.Sprintf("Vulnerability found: %s [%s] at %s", output.Name, output.Severity, output.MatchedAt))
 
 		// 发布漏洞发现事件
+		data := map[string]interface{}{
+			"taskId": taskID,
+			"vuln":   output,
+		}
+		if targetID > 0 {
+			data["targetId"] = targetID
+		} else if exists && len(scanCtx.Request.Targets) > 1 {
+			o.logger.Warn("Failed to attribute vulnerability to target: task_id=%d, host=%s, matched_at=%s", taskID, output.Host, output.MatchedAt)
+		}
 		o.eventBus.PublishAsync(ctx, event.Event{
 			Type: event.EventVulnFound,
-			Data: map[string]interface{}{
-				"taskId": taskID,
-				"vuln":   output,
-			},
+			Data: data,
 		})
 
 		// 记录指标
@@ -317,6 +591,12 @@ func (o *Orchestrator) onProgress(taskID int) func(ScanProgress) {
 			return
 		}
 
+		// 有模板清单时按清单大小计算模板进度，stats-json 中的 total 为请求数而不是模板数
+		if count := scanCtx.Request.TemplateCount; count > 0 {
+			progress.TotalTemplates = count
+			progress.Executed = count * progress.Progress / 100
+		}
+
 		scanCtx.ProgressMu.Lock()
 		// 使用 scanCtx 中的实际漏洞计数，而不是 stats-json 中的值
 		actualVulnCount := int(scanCtx.VulnCount.Load())
@@ -328,6 +608,7 @@ func (o *Orchestrator) onProgress(taskID int) func(ScanProgress) {
 			Progress:        progress.Progress,
 			CurrentTemplate: progress.CurrentTemplate,
 			VulnCount:       actualVulnCount,
+			TargetVulns:     scanCtx.targetVulnsSnapshot(),
 		}
 		// 复制一份用于事件发布（避免锁内调用）
 		progressCopy := *scanCtx.Progress
@@ -343,11 +624,8 @@ func (o *Orchestrator) onProgress(taskID int) func(ScanProgress) {
 			}
 		}
 
-		// 使用 scanCtx 的 context（如果存在），否则使用 Background
+		// 扫描在后台运行，进度写入不跟随发起请求的 context
 		ctx := context.Background()
-		if scanCtx.Request.Context != nil {
-			ctx = scanCtx.Request.Context
-		}
 
 		// 发布进度事件
 		o.eventBus.PublishAsync(ctx, event.Event{
@@ -394,12 +672,19 @@ func (o *Orchestrator) onScanCompleted(taskID int, vulnCount int) {
 			o.logger.Info("Updated scan status to completed: task_id=%d", taskID)
 		}
 
-		// 更新最终进度为 100%
-		if err := o.scanRepo.UpdateProgress(ctx, taskID, models.ScanProgress{
+		// 更新最终进度为 100%，有模板清单时保留模板总数
+		final := models.ScanProgress{
 			TaskID:   taskID,
 			Status:   "completed",
 			Progress: 100,
-		}); err != nil {
+		}
+		o.mu.RLock()
+		if scanCtx, ok := o.scans[taskID]; ok && scanCtx.Request.TemplateCount > 0 {
+			final.TotalTemplates = scanCtx.Request.TemplateCount
+			final.Executed = scanCtx.Request.TemplateCount
+		}
+		o.mu.RUnlock()
+		if err := o.scanRepo.UpdateProgress(ctx, taskID, final); err != nil {
 			o.logger.Warn("Failed to update final progress: task_id=%d, error=%v", taskID, err)
 		}
 	}
@@ -471,6 +756,62 @@ func (o *Orchestrator) handleScanError(taskID int, err error) {
 	}
 }
 
+// watchTimeout 扫描运行时间超过上限时取消进程的 context，挂起期间不计入运行时间
+func (o *Orchestrator) watchTimeout(scanCtx *ScanContext, cancel context.CancelFunc, done <-chan struct{}) {
+	for {
+		remaining := scanCtx.Request.Timeout - scanCtx.activeDuration()
+		if remaining <= 0 {
+			scanCtx.timedOut.Store(true)
+			cancel()
+			return
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// handleScanTimeout 处理扫描超时
+func (o *Orchestrator) handleScanTimeout(scanCtx *ScanContext) {
+	taskID := scanCtx.TaskID
+	duration := time.Since(scanCtx.StartTime)
+	vulnCount := int(scanCtx.VulnCount.Load())
+	o.logger.Warn("Scan timed out: task_id=%d, timeout=%s", taskID, scanCtx.Request.Timeout)
+
+	ctx := context.Background()
+	if o.scanRepo != nil {
+		if err := o.scanRepo.UpdateFindingsCount(ctx, taskID, vulnCount); err != nil {
+			o.logger.Error("Failed to update scan findings_count: task_id=%d, error=%v", taskID, err)
+		}
+		if err := o.scanRepo.UpdateStatus(ctx, taskID, "timed_out"); err != nil {
+			o.logger.Error("Failed to update scan status to timed_out: task_id=%d, error=%v", taskID, err)
+		}
+	}
+
+	// 记录超时日志到数据库
+	o.addScanLog(ctx, taskID, "error", fmt.Sprintf("Scan timed out: exceeded max duration of %s, vuln_count=%d",
+		scanCtx.Request.Timeout, vulnCount))
+
+	o.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScanTimedOut,
+		Data: map[string]interface{}{
+			"taskId":    taskID,
+			"timeout":   int(scanCtx.Request.Timeout / time.Second),
+			"vulnCount": vulnCount,
+		},
+	})
+
+	// 记录指标
+	metrics.Global.IncrementScanFailed()
+	metrics.Global.RecordError("timeout")
+	metrics.Global.RecordScanDuration(duration)
+}
+
 // GetStatus 获取 Nuclei 状态
 func (o *Orchestrator) GetStatus() models.NucleiStatus {
 	return models.NucleiStatus{
@@ -480,11 +821,128 @@ func (o *Orchestrator) GetStatus() models.NucleiStatus {
 	}
 }
 
-// addScanLog 添加扫描日志到数据库
+// removeResumeFile 删除扫描任务的断点文件
+func (o *Orchestrator) removeResumeFile(scanCtx *ScanContext) {
+	path := scanCtx.Request.ResumeFile
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		o.logger.Warn("Failed to remove resume file: task_id=%d, path=%s, error=%v", scanCtx.TaskID, path, err)
+	}
+	if o.scanRepo != nil {
+		if err := o.scanRepo.UpdateResumeFile(context.Background(), scanCtx.TaskID, ""); err != nil {
+			o.logger.Warn("Failed to clear resume file: task_id=%d, error=%v", scanCtx.TaskID, err)
+		}
+	}
+}
+
+// targetVulnsSnapshot 复制多目标任务的各目标漏洞数量，单目标任务返回 nil
+// 调用方需持有 ProgressMu
+func (c *ScanContext) targetVulnsSnapshot() map[int]int {
+	if len(c.Request.Targets) <= 1 {
+		return nil
+	}
+	snapshot := make(map[int]int, len(c.Request.Targets))
+	for _, t := range c.Request.Targets {
+		snapshot[t.ID] = c.targetVulns[t.ID]
+	}
+	return snapshot
+}
+
+// IsPaused 检查扫描进程是否被挂起
+func (c *ScanContext) IsPaused() bool {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	return !c.pausedAt.IsZero()
+}
+
+// activeDuration 扫描实际运行时长，不含挂起时间
+func (c *ScanContext) activeDuration() time.Duration {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+	elapsed := time.Since(c.StartTime) - c.pausedTotal
+	if !c.pausedAt.IsZero() {
+		elapsed -= time.Since(c.pausedAt)
+	}
+	return elapsed
+}
+
+// setProgressStatus 更新实时进度中的状态
+func (c *ScanContext) setProgressStatus(status string) {
+	c.ProgressMu.Lock()
+	defer c.ProgressMu.Unlock()
+	progress := *c.Progress
+	progress.Status = status
+	c.Progress = &progress
+}
+
+// describeTargets 描述扫描目标，用于日志
+func describeTargets(req ScanRequest) string {
+	if len(req.Targets) > 1 {
+		return fmt.Sprintf("%d targets", len(req.Targets))
+	}
+	return req.TargetURL
+}
+
+// hasResumeFile 检查断点文件是否存在
+func hasResumeFile(path string) bool {
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// removeSecretFile 删除扫描任务的凭据文件，每次启动时重新生成
+func (o *Orchestrator) removeSecretFile(scanCtx *ScanContext) {
+	path := scanCtx.Request.SecretFile
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		o.logger.Warn("Failed to remove secret file: task_id=%d, error=%v", scanCtx.TaskID, err)
+	}
+}
+
+// minRedactLength 短于该长度的凭据不做替换，避免误伤普通日志内容
+const minRedactLength = 4
+
+// newRedactor 创建隐藏凭据的替换器，没有需要隐藏的内容时返回 nil
+func newRedactor(secrets []string) *strings.Replacer {
+	values := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		if len(s) >= minRedactLength {
+			values = append(values, s)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	// 较长的凭据优先替换，避免只替换其中一部分
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, len(values)*2)
+	for _, v := range values {
+		pairs = append(pairs, v, "******")
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// redact 隐藏日志内容中的凭据
+func (o *Orchestrator) redact(taskID int, message string) string {
+	if redactor, ok := o.redactors.Load(taskID); ok {
+		return redactor.(*strings.Replacer).Replace(message)
+	}
+	return message
+}
+
+// addScanLog 添加扫描日志到数据库，凭据会被隐藏
 func (o *Orchestrator) addScanLog(ctx context.Context, taskID int, level, message string) {
 	if o.scanRepo == nil {
 		return
 	}
+	message = o.redact(taskID, message)
 	if err := o.scanRepo.AddLog(ctx, taskID, level, message); err != nil {
 		o.logger.Warn("Failed to persist scan log: task_id=%d, error=%v", taskID, err)
 	}
@@ -43,7 +43,6 @@ func TestOrchestrator_Scan_UnavailableNuclei(t *testing.T) {
 
 	ctx := context.Background()
 	req := ScanRequest{
-		Context:   ctx,
 		TaskID:    1,
 		Name:      "test-scan",
 		TargetID:  1,
@@ -78,7 +77,6 @@ func TestOrchestrator_Scan_ConcurrentLimit(t *testing.T) {
 
 	ctx := context.Background()
 	req1 := ScanRequest{
-		Context:   ctx,
 		TaskID:    1,
 		Name:      "test-scan-1",
 		TargetID:  1,
@@ -198,7 +196,6 @@ func TestScanContext(t *testing.T) {
 	scanCtx := &ScanContext{
 		TaskID: 1,
 		Request: ScanRequest{
-			Context:  ctx,
 			TaskID:   1,
 			Name:     "test",
 			TargetID: 1,
@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// orphanShutdownTimeout 等待遗留进程响应中断信号的时间
+const orphanShutdownTimeout = 5 * time.Second
+
+// KillOrphanProcess 终止上次运行遗留的 nuclei 进程
+// 仅当 PID 对应的进程仍是 nuclei 时才终止，避免误杀复用了该 PID 的其他进程
+// 返回是否终止了进程
+func KillOrphanProcess(pid int) (bool, error) {
+	if pid <= 0 || pid == os.Getpid() {
+		return false, nil
+	}
+
+	name := processName(pid)
+	if !isNucleiProcess(name) {
+		return false, nil
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false, nil
+	}
+
+	// 暂停中的进程需先恢复，再发送中断信号让 nuclei 写入断点文件，超时后强制终止
+	_ = continueProcessGroup(process)
+	if err := process.Signal(os.Interrupt); err == nil {
+		deadline := time.Now().Add(orphanShutdownTimeout)
+		for time.Now().Before(deadline) {
+			if !isNucleiProcess(processName(pid)) {
+				return true, nil
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	if err := process.Kill(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isNucleiProcess 判断可执行文件名是否为 nuclei
+func isNucleiProcess(name string) bool {
+	name = strings.ToLower(filepath.Base(strings.TrimSpace(name)))
+	name = strings.TrimSuffix(name, ".exe")
+	return strings.HasPrefix(name, "nuclei")
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestKillOrphanProcess 测试终止遗留的 nuclei 进程
+func TestKillOrphanProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake nuclei script requires a POSIX shell")
+	}
+
+	script := filepath.Join(t.TempDir(), "nuclei")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nwhile :; do sleep 0.1; done\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	nuclei := exec.Command(script)
+	if err := nuclei.Start(); err != nil {
+		t.Fatalf("failed to start fake nuclei: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = nuclei.Wait()
+		close(done)
+	}()
+
+	other := exec.Command("sleep", "5")
+	if err := other.Start(); err != nil {
+		t.Fatalf("failed to start sleep: %v", err)
+	}
+	defer func() {
+		_ = other.Process.Kill()
+		_ = other.Wait()
+	}()
+
+	// 非 nuclei 进程不会被终止
+	if killed, err := KillOrphanProcess(other.Process.Pid); err != nil || killed {
+		t.Errorf("KillOrphanProcess(sleep) = %v, %v, want false, nil", killed, err)
+	}
+
+	killed, err := KillOrphanProcess(nuclei.Process.Pid)
+	if err != nil || !killed {
+		t.Fatalf("KillOrphanProcess(nuclei) = %v, %v, want true, nil", killed, err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake nuclei process was not killed")
+	}
+
+	// 进程已退出
+	if killed, _ := KillOrphanProcess(nuclei.Process.Pid); killed {
+		t.Error("KillOrphanProcess() should not kill an exited process")
+	}
+}
+
+// TestIsNucleiProcess 测试 nuclei 进程名识别
+func TestIsNucleiProcess(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"nuclei", true},
+		{"/Users/a/Library/HoleHunter/nuclei", true},
+		{"NUCLEI.EXE", true},
+		{"sleep", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isNucleiProcess(tt.name); got != tt.want {
+			t.Errorf("isNucleiProcess(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
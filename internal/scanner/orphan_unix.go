@@ -0,0 +1,24 @@
+//go:build !windows
+
+package scanner
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// processName 获取进程的可执行文件名，进程不存在时返回空
+func processName(pid int) string {
+	// Linux 优先读取 /proc
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm"); err == nil {
+		return strings.TrimSpace(string(data))
+	}
+
+	output, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
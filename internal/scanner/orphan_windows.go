@@ -0,0 +1,28 @@
+//go:build windows
+
+package scanner
+
+import (
+	"encoding/csv"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// processName 获取进程的映像名称，进程不存在时返回空
+func processName(pid int) string {
+	cmd := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH")
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	// 进程不存在时 tasklist 输出提示信息而不是 CSV
+	record, err := csv.NewReader(strings.NewReader(string(output))).Read()
+	if err != nil || len(record) < 2 || record[1] != strconv.Itoa(pid) {
+		return ""
+	}
+	return record[0]
+}
@@ -2,9 +2,10 @@ package scanner
 
 import (
 	"context"
+	"io"
+	"os"
 	"os/exec"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
@@ -14,13 +15,25 @@ const (
 	gracefulShutdownTimeout = 10 * time.Second
 )
 
-// ScanProcess 表示一个正在运行的扫描进程
+// errSuspendUnsupported 当前平台不支持挂起扫描进程
+var errSuspendUnsupported = errors.Internal("process suspension is not supported on this platform", nil)
+
+// StreamParser 解析扫描进程的标准输出与标准错误
+type StreamParser interface {
+	ParseStdout(reader io.Reader)
+	ParseStderr(reader io.Reader)
+}
+
+// ScanProcess 表示一个正在运行的扫描进程，外部命令类引擎的执行体
 type ScanProcess struct {
 	ID           int
 	Cmd          *exec.Cmd
+	parser       StreamParser
 	Progress     ScanProgress
 	ProgressMu   sync.RWMutex
 	CancelFunc   context.CancelFunc
+	OnStarted    func(pid int) // 进程启动后回调，用于记录 PID 以便异常退出后清理
+	exited       chan struct{} // 进程退出后关闭，只有 Run 调用 Cmd.Wait
 	startTime    time.Time
 	vulnCount    int
 	executed     int
@@ -29,13 +42,15 @@ type ScanProcess struct {
 }
 
 // NewScanProcess 创建新的扫描进程
-func NewScanProcess(id int, cmd *exec.Cmd) *ScanProcess {
+func NewScanProcess(id int, cmd *exec.Cmd, parser StreamParser) *ScanProcess {
 	// 调用平台特定的初始化
 	initProcessCmd(cmd)
 
 	return &ScanProcess{
 		ID:        id,
 		Cmd:       cmd,
+		parser:    parser,
+		exited:    make(chan struct{}),
 		startTime: time.Now(),
 		Progress: ScanProgress{
 			TaskID: id,
@@ -45,7 +60,7 @@ func NewScanProcess(id int, cmd *exec.Cmd) *ScanProcess {
 }
 
 // Run 执行扫描
-func (p *ScanProcess) Run(ctx context.Context, parser *OutputParser) error {
+func (p *ScanProcess) Run(ctx context.Context) error {
 	// 创建管道
 	stdout, err := p.Cmd.StdoutPipe()
 	if err != nil {
@@ -58,7 +73,11 @@ func (p *ScanProcess) Run(ctx context.Context, parser *OutputParser) error {
 
 	// 启动命令
 	if err := p.Cmd.Start(); err != nil {
-		return errors.Internal("failed to start nuclei", err)
+		return errors.Internal("failed to start scan process", err)
+	}
+	defer close(p.exited)
+	if p.OnStarted != nil {
+		p.OnStarted(p.Cmd.Process.Pid)
 	}
 
 	p.setProgress(ScanProgress{
@@ -73,11 +92,11 @@ func (p *ScanProcess) Run(ctx context.Context, parser *OutputParser) error {
 	// 启动解析 goroutine
 	go func() {
 		defer wg.Done()
-		parser.ParseStdout(stdout)
+		p.parser.ParseStdout(stdout)
 	}()
 	go func() {
 		defer wg.Done()
-		parser.ParseStderr(stderr)
+		p.parser.ParseStderr(stderr)
 	}()
 
 	// 等待解析完成的 goroutine
@@ -92,8 +111,9 @@ func (p *ScanProcess) Run(ctx context.Context, parser *OutputParser) error {
 	case <-doneParsing:
 		// 解析完成，等待命令结束
 	case <-ctx.Done():
-		// Context 取消，终止进程
-		_ = p.Cmd.Process.Kill()
+		// Context 取消（如超时），终止进程及其子进程
+		p.terminate(doneParsing)
+		_ = p.Cmd.Wait()
 		return ctx.Err()
 	}
 
@@ -117,16 +137,14 @@ func (p *ScanProcess) Stop() error {
 		return errors.Internal("process not started", nil)
 	}
 
-	// 首先尝试优雅终止（SIGTERM）
-	if err := p.Cmd.Process.Signal(syscall.SIGTERM); err == nil {
-		// 等待进程自然结束，最多等待 10 秒
-		done := make(chan error, 1)
-		go func() {
-			done <- p.Cmd.Wait()
-		}()
+	// 已挂起的进程需先恢复才能响应中断信号
+	_ = continueProcessGroup(p.Cmd.Process)
 
+	// 首先尝试优雅终止（SIGINT），nuclei 收到后会写入断点文件
+	if err := p.Cmd.Process.Signal(os.Interrupt); err == nil {
+		// 等待进程自然结束，最多等待 10 秒
 		select {
-		case <-done:
+		case <-p.exited:
 			// 进程已正常结束
 			p.setProgress(ScanProgress{
 				TaskID: p.ID,
@@ -138,8 +156,8 @@ func (p *ScanProcess) Stop() error {
 		}
 	}
 
-	// 强制杀死进程
-	if err := p.Cmd.Process.Kill(); err != nil {
+	// 强制杀死进程及其子进程
+	if err := killProcessGroup(p.Cmd.Process); err != nil {
 		return errors.Internal("failed to kill process", err)
 	}
 
@@ -151,6 +169,35 @@ func (p *ScanProcess) Stop() error {
 	return nil
 }
 
+// terminate 先中断进程让 nuclei 写入断点文件，done 关闭或等待超时后终止整个进程组
+func (p *ScanProcess) terminate(done <-chan struct{}) {
+	_ = continueProcessGroup(p.Cmd.Process)
+	if err := p.Cmd.Process.Signal(os.Interrupt); err == nil {
+		select {
+		case <-done:
+		case <-time.After(gracefulShutdownTimeout):
+		}
+	}
+	// nuclei 退出后仍可能有子进程残留，始终清理整个进程组
+	_ = killProcessGroup(p.Cmd.Process)
+}
+
+// Suspend 挂起扫描进程及其子进程
+func (p *ScanProcess) Suspend() error {
+	if p.Cmd.Process == nil {
+		return errors.Internal("process not started", nil)
+	}
+	return suspendProcessGroup(p.Cmd.Process)
+}
+
+// Continue 恢复被挂起的扫描进程
+func (p *ScanProcess) Continue() error {
+	if p.Cmd.Process == nil {
+		return errors.Internal("process not started", nil)
+	}
+	return continueProcessGroup(p.Cmd.Process)
+}
+
 // UpdateProgress 更新进度
 func (p *ScanProcess) UpdateProgress(progress ScanProgress) {
 	p.ProgressMu.Lock()
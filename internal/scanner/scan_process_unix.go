@@ -3,10 +3,38 @@
 package scanner
 
 import (
+	"os"
 	"os/exec"
+	"syscall"
 )
 
-// initProcessCmd Unix 平台的进程初始化（空实现）
+// initProcessCmd 让 nuclei 在独立的进程组中运行，便于终止时连同子进程一起清理
 func initProcessCmd(cmd *exec.Cmd) {
-	// Unix 平台不需要特殊处理
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup 强制终止进程及其所在进程组中的全部子进程
+func killProcessGroup(process *os.Process) error {
+	if err := syscall.Kill(-process.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return process.Kill()
+	}
+	return nil
+}
+
+// suspendProcessGroup 挂起进程组中的全部进程
+func suspendProcessGroup(process *os.Process) error {
+	return signalProcessGroup(process, syscall.SIGSTOP)
+}
+
+// continueProcessGroup 恢复被挂起的进程组
+func continueProcessGroup(process *os.Process) error {
+	return signalProcessGroup(process, syscall.SIGCONT)
+}
+
+// signalProcessGroup 向进程组发送信号，进程不是组长时（如旧版本启动的遗留进程）只发给该进程
+func signalProcessGroup(process *os.Process, sig syscall.Signal) error {
+	if err := syscall.Kill(-process.Pid, sig); err != nil {
+		return process.Signal(sig)
+	}
+	return nil
 }
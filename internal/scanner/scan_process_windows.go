@@ -3,7 +3,9 @@
 package scanner
 
 import (
+	"os"
 	"os/exec"
+	"strconv"
 	"syscall"
 )
 
@@ -14,3 +16,23 @@ func initProcessCmd(cmd *exec.Cmd) {
 		CreationFlags: 0x08000000, // CREATE_NO_WINDOW
 	}
 }
+
+// killProcessGroup 强制终止进程及其全部子进程
+func killProcessGroup(process *os.Process) error {
+	cmd := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(process.Pid))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	if err := cmd.Run(); err != nil {
+		return process.Kill()
+	}
+	return nil
+}
+
+// suspendProcessGroup Windows 没有可靠的进程组挂起方式，由调用方改用断点暂停
+func suspendProcessGroup(process *os.Process) error {
+	return errSuspendUnsupported
+}
+
+// continueProcessGroup Windows 上进程不会被挂起，无需恢复
+func continueProcessGroup(process *os.Process) error {
+	return nil
+}
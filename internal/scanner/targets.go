@@ -0,0 +1,105 @@
+package scanner
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ScanTarget 扫描目标，用于将 nuclei 输出归属到对应目标
+type ScanTarget struct {
+	ID  int
+	URL string
+}
+
+// attributeTarget 根据 nuclei 输出找到所属目标，无法确定时返回 0
+// 依次按原始输入、URL 前缀、主机与端口、主机名匹配
+func attributeTarget(targets []ScanTarget, output *NucleiOutput) int {
+	if len(targets) == 1 {
+		return targets[0].ID
+	}
+	if len(targets) == 0 || output == nil {
+		return 0
+	}
+
+	// nuclei 输出的 host 字段为输入列表中的原始目标
+	if host := strings.TrimSuffix(output.Host, "/"); host != "" {
+		for _, t := range targets {
+			if strings.TrimSuffix(t.URL, "/") == host {
+				return t.ID
+			}
+		}
+	}
+
+	candidates := []string{output.MatchedAt, output.URL, output.Host}
+
+	// 同一主机下的多个路径目标取最长前缀
+	best, bestLen := 0, 0
+	for _, c := range candidates {
+		for _, t := range targets {
+			prefix := strings.TrimSuffix(t.URL, "/")
+			if prefix != "" && strings.HasPrefix(c, prefix) && len(prefix) > bestLen {
+				best, bestLen = t.ID, len(prefix)
+			}
+		}
+	}
+	if best != 0 {
+		return best
+	}
+
+	for _, withPort := range []bool{true, false} {
+		for _, c := range candidates {
+			key := hostKey(c, withPort)
+			if key == "" {
+				continue
+			}
+			for _, t := range targets {
+				if hostKey(t.URL, withPort) == key {
+					return t.ID
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+// hostKey 返回地址的小写主机名，withPort 时附带端口（缺省端口按协议补全）
+func hostKey(raw string, withPort bool) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "//" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if !withPort {
+		return host
+	}
+
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			port = "443"
+		case "http":
+			port = "80"
+		}
+	}
+	return host + ":" + port
+}
+
+// targetIDs 返回目标 ID 列表
+func targetIDs(targets []ScanTarget) []int {
+	ids := make([]int, 0, len(targets))
+	for _, t := range targets {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
@@ -0,0 +1,42 @@
+package scanner
+
+import "testing"
+
+// TestAttributeTarget 测试将 nuclei 输出归属到目标
+func TestAttributeTarget(t *testing.T) {
+	targets := []ScanTarget{
+		{ID: 1, URL: "https://a.example.com"},
+		{ID: 2, URL: "https://b.example.com/"},
+		{ID: 3, URL: "https://b.example.com/app"},
+		{ID: 4, URL: "http://10.0.0.5:8080"},
+		{ID: 5, URL: "c.example.com"},
+	}
+
+	tests := []struct {
+		name   string
+		output NucleiOutput
+		want   int
+	}{
+		{"原始输入匹配", NucleiOutput{Host: "https://a.example.com", MatchedAt: "https://a.example.com/.git/config"}, 1},
+		{"忽略末尾斜杠", NucleiOutput{Host: "https://b.example.com", MatchedAt: "https://b.example.com/x"}, 2},
+		{"最长路径前缀", NucleiOutput{MatchedAt: "https://b.example.com/app/login"}, 3},
+		{"主机与端口", NucleiOutput{Host: "10.0.0.5:8080", MatchedAt: "10.0.0.5:8080"}, 4},
+		{"默认端口", NucleiOutput{Host: "A.EXAMPLE.COM:443"}, 1},
+		{"仅主机名", NucleiOutput{Host: "c.example.com:22"}, 5},
+		{"无法归属", NucleiOutput{Host: "https://other.example.com"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := attributeTarget(targets, &tt.output); got != tt.want {
+				t.Errorf("attributeTarget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// 单目标任务的输出总是归属到该目标
+	single := []ScanTarget{{ID: 9, URL: "https://a.example.com"}}
+	if got := attributeTarget(single, &NucleiOutput{Host: "https://redirect.example.com"}); got != 9 {
+		t.Errorf("attributeTarget() single = %d, want 9", got)
+	}
+}
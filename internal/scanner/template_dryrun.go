@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// dryRunOutput 试运行时 nuclei 的 JSON 输出，-matcher-status 下未匹配的请求也会输出
+type dryRunOutput struct {
+	TemplateID       string   `json:"template-id"`
+	MatcherName      string   `json:"matcher-name"`
+	MatcherStatus    bool     `json:"matcher-status"`
+	MatchedAt        string   `json:"matched-at"`
+	Extraction       []string `json:"extraction"`
+	ExtractedResults []string `json:"extracted-results"`
+	Request          string   `json:"request"`
+	Response         string   `json:"response"`
+}
+
+// RunTemplate 对单个目标运行一个模板，返回每次请求的原始请求、响应、匹配与提取结果
+// 结果只返回给调用方，不经过扫描流程，也不会保存为漏洞
+func (n *NucleiClient) RunTemplate(ctx context.Context, content []byte, targetURL string) (*models.TemplateTestResult, error) {
+	if !n.IsAvailable() {
+		return nil, errors.Internal("nuclei binary not found", nil)
+	}
+
+	path, cleanup, err := writeTempTemplate("holehunter-dryrun-*", content)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	args := []string{"-t", path, "-u", targetURL, "-jsonl", "-matcher-status", "-duc", "-no-color", "-silent"}
+	cmd := exec.CommandContext(ctx, n.binaryPath, args...)
+	cmd.Env = append(os.Environ(), "NUCLEI_TEMPLATES_DIR="+n.templatesDir)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, errors.Internal("template test timed out", ctx.Err())
+	}
+	if _, ok := runErr.(*exec.ExitError); runErr != nil && !ok {
+		return nil, errors.Internal("failed to run nuclei", runErr)
+	}
+
+	result := parseDryRunOutput(stdout.Bytes())
+	result.URL = targetURL
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.Diagnostics = parseValidateOutput(stderr.Bytes(), path)
+	if runErr != nil && len(result.Events) == 0 && !HasErrorDiagnostic(result.Diagnostics) {
+		result.Diagnostics = append(result.Diagnostics, models.TemplateDiagnostic{
+			Severity: DiagnosticError,
+			Message:  "nuclei exited with an error: " + runErr.Error(),
+			Source:   DiagnosticSourceNuclei,
+		})
+	}
+	if result.Diagnostics == nil {
+		result.Diagnostics = []models.TemplateDiagnostic{}
+	}
+	return result, nil
+}
+
+// parseDryRunOutput 解析试运行的 JSONL 输出，无法解析的行忽略
+func parseDryRunOutput(output []byte) *models.TemplateTestResult {
+	result := &models.TemplateTestResult{Events: []models.TemplateTestEvent{}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	// 原始响应可能很大
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var out dryRunOutput
+		if err := json.Unmarshal(line, &out); err != nil {
+			continue
+		}
+
+		if result.TemplateID == "" {
+			result.TemplateID = out.TemplateID
+		}
+		extracted := out.ExtractedResults
+		if len(extracted) == 0 {
+			extracted = out.Extraction
+		}
+		result.Events = append(result.Events, models.TemplateTestEvent{
+			Matched:     out.MatcherStatus,
+			MatcherName: out.MatcherName,
+			MatchedAt:   out.MatchedAt,
+			Extracted:   extracted,
+			Request:     out.Request,
+			Response:    out.Response,
+		})
+		if out.MatcherStatus {
+			result.Matched = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,65 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNucleiClient_RunTemplate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake nuclei script requires a POSIX shell")
+	}
+
+	// 假 nuclei：记录参数，输出一次未匹配与一次带提取结果的匹配
+	dir := t.TempDir()
+	script := filepath.Join(dir, "nuclei")
+	content := `#!/bin/sh
+echo "$@" > "` + filepath.Join(dir, "args") + `"
+echo '{"template-id":"dry-run","matcher-status":false,"matched-at":"http://127.0.0.1/a","request":"GET /a HTTP/1.1","response":"HTTP/1.1 404 Not Found"}'
+echo '{"template-id":"dry-run","matcher-name":"token","matcher-status":true,"matched-at":"http://127.0.0.1/b","extracted-results":["abc123"],"request":"GET /b HTTP/1.1","response":"HTTP/1.1 200 OK"}'
+echo 'not json'
+echo '[WRN] Could not resolve interactsh server' >&2
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	client := &NucleiClient{binaryPath: script, templatesDir: dir}
+
+	result, err := client.RunTemplate(context.Background(), []byte("id: dry-run\n"), "http://127.0.0.1")
+	if err != nil {
+		t.Fatalf("RunTemplate() failed: %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	for _, want := range []string{"-u http://127.0.0.1", "-jsonl", "-matcher-status"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("nuclei args = %q, want %s", args, want)
+		}
+	}
+
+	if result.TemplateID != "dry-run" || result.URL != "http://127.0.0.1" || !result.Matched {
+		t.Errorf("result = %+v, want a matched dry-run result", result)
+	}
+	if len(result.Events) != 2 {
+		t.Fatalf("events = %+v, want 2", result.Events)
+	}
+	if result.Events[0].Matched || result.Events[0].Response != "HTTP/1.1 404 Not Found" {
+		t.Errorf("first event = %+v, want an unmatched request with its response", result.Events[0])
+	}
+	if !result.Events[1].Matched || result.Events[1].MatcherName != "token" || len(result.Events[1].Extracted) != 1 || result.Events[1].Extracted[0] != "abc123" {
+		t.Errorf("second event = %+v, want a match with extraction", result.Events[1])
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Severity != DiagnosticWarning {
+		t.Errorf("diagnostics = %+v, want the nuclei warning", result.Diagnostics)
+	}
+
+	// 临时模板目录在运行后删除
+	path := strings.Fields(string(args))[1]
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("temporary template directory %s was not removed", filepath.Dir(path))
+	}
+}
@@ -0,0 +1,568 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// 模板校验问题的级别与来源
+const (
+	DiagnosticError   = "error"
+	DiagnosticWarning = "warning"
+
+	DiagnosticSourceSchema = "schema"
+	DiagnosticSourceNuclei = "nuclei"
+)
+
+var (
+	// templateIDPattern 模板 ID 格式，与 nuclei 的校验规则一致
+	templateIDPattern = regexp.MustCompile(`^([a-zA-Z0-9]+[-_])*[a-zA-Z0-9]+$`)
+	// variableNamePattern 变量与载荷名称格式
+	variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// placeholderPattern 字符串中的 {{...}} 表达式
+	placeholderPattern = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	// stringLiteralPattern DSL 中的字符串字面量，查找函数调用前先去掉
+	stringLiteralPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+	// functionCallPattern DSL 中的函数调用
+	functionCallPattern = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+	// identifierPattern DSL 中的标识符，用于检查载荷引用
+	identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	// yamlErrorLinePattern YAML 解析错误中的行号
+	yamlErrorLinePattern = regexp.MustCompile(`line (\d+)`)
+)
+
+// templateSeverities 模板允许的严重程度
+var templateSeverities = stringSet("info", "low", "medium", "high", "critical", "unknown")
+
+// protocolBlocks 协议块，requests 与 tcp 分别为 http 与 network 的旧名称
+var protocolBlocks = stringSet("http", "requests", "dns", "file", "network", "tcp", "headless", "ssl", "websocket", "whois", "code", "javascript")
+
+// topLevelFields 协议块以外的顶层字段
+var topLevelFields = stringSet("id", "info", "variables", "constants", "flow", "self-contained", "stop-at-first-match", "signature", "workflows")
+
+// httpMethods HTTP 请求方法
+var httpMethods = stringSet("GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH", "PURGE", "DEBUG")
+
+// attackTypes 载荷组合方式
+var attackTypes = stringSet("batteringram", "pitchfork", "clusterbomb")
+
+// matcherFields 匹配器类型及其必需字段
+var matcherFields = map[string]string{
+	"word":   "words",
+	"regex":  "regex",
+	"status": "status",
+	"size":   "size",
+	"binary": "binary",
+	"dsl":    "dsl",
+	"xpath":  "xpath",
+}
+
+// extractorFields 提取器类型及其必需字段
+var extractorFields = map[string]string{
+	"regex": "regex",
+	"kval":  "kval",
+	"json":  "json",
+	"xpath": "xpath",
+	"dsl":   "dsl",
+}
+
+// dslFunctions nuclei 支持的 DSL 辅助函数
+var dslFunctions = stringSet(
+	"aes_cbc", "aes_gcm", "base64", "base64_decode", "base64_py", "bin_to_dec", "compare_versions",
+	"concat", "contains", "contains_all", "contains_any", "date_time", "dec_to_hex", "deflate",
+	"ends_with", "equals_any", "generate_java_gadget", "generate_jwt", "gzip", "gzip_decode",
+	"hex_decode", "hex_encode", "hex_to_dec", "hmac", "html_escape", "html_unescape", "index",
+	"inflate", "ip_format", "jarm", "join", "json_minify", "json_prettify", "len", "line_ends_with",
+	"line_starts_with", "llm_prompt", "md5", "mmh3", "oct_to_dec", "padding", "print_debug",
+	"public_ip", "rand_base", "rand_char", "rand_int", "rand_ip", "rand_text_alpha",
+	"rand_text_alphanumeric", "rand_text_numeric", "regex", "regex_all", "regex_any",
+	"remove_bad_chars", "repeat", "replace", "replace_regex", "resolve", "reverse", "sha1",
+	"sha256", "sha512", "sort", "split", "starts_with", "substr", "to_lower", "to_number",
+	"to_string", "to_unix_time", "to_upper", "trim", "trim_left", "trim_prefix", "trim_right",
+	"trim_space", "trim_suffix", "uniq", "unix_time", "unpack", "url_decode", "url_encode",
+	"wait_for", "xor", "zlib", "zlib_decode",
+)
+
+// stringSet 创建字符串集合
+func stringSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// templateValidator 收集模板结构校验中发现的问题
+type templateValidator struct {
+	diagnostics []models.TemplateDiagnostic
+}
+
+// ValidateTemplateSchema 按 nuclei 模板结构校验内容，返回按位置排序的问题列表
+// 检查协议块、匹配器与提取器类型、matchers-condition、变量、DSL 函数名与载荷引用
+func ValidateTemplateSchema(content []byte) []models.TemplateDiagnostic {
+	v := &templateValidator{}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		line := 0
+		if m := yamlErrorLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		v.diagnostics = append(v.diagnostics, models.TemplateDiagnostic{
+			Line:     line,
+			Severity: DiagnosticError,
+			Message:  strings.TrimPrefix(err.Error(), "yaml: "),
+			Source:   DiagnosticSourceSchema,
+		})
+		return v.diagnostics
+	}
+	if len(doc.Content) == 0 {
+		v.errorf(nil, "template is empty")
+		return v.diagnostics
+	}
+
+	v.validateRoot(doc.Content[0])
+	sortDiagnostics(v.diagnostics)
+	return v.diagnostics
+}
+
+// validateRoot 校验顶层字段
+func (v *templateValidator) validateRoot(root *yaml.Node) {
+	if root.Kind != yaml.MappingNode {
+		v.errorf(root, "template must be a mapping")
+		return
+	}
+
+	fields := mappingFields(root)
+	if id, ok := fields["id"]; !ok {
+		v.errorf(root, "missing required field: id")
+	} else if id.Kind != yaml.ScalarNode || !templateIDPattern.MatchString(id.Value) {
+		v.errorf(id, "invalid template id %q: use letters, digits, '-' and '_'", id.Value)
+	}
+
+	if info, ok := fields["info"]; !ok {
+		v.errorf(root, "missing required field: info")
+	} else {
+		v.validateInfo(info)
+	}
+
+	if variables, ok := fields["variables"]; ok {
+		v.validateVariables(variables)
+	}
+	if flow, ok := fields["flow"]; ok && flow.Kind != yaml.ScalarNode {
+		v.errorf(flow, "flow must be a string")
+	}
+
+	protocols := 0
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		switch {
+		case protocolBlocks[key.Value]:
+			protocols++
+			v.validateProtocol(key.Value, value)
+		case !topLevelFields[key.Value]:
+			v.warnf(key, "unknown field: %s", key.Value)
+		}
+	}
+	if _, ok := fields["workflows"]; protocols == 0 && !ok {
+		v.errorf(root, "template has no protocol block (http, dns, network, ...)")
+	}
+}
+
+// validateInfo 校验 info 块
+func (v *templateValidator) validateInfo(info *yaml.Node) {
+	if info.Kind != yaml.MappingNode {
+		v.errorf(info, "info must be a mapping")
+		return
+	}
+	fields := mappingFields(info)
+	if name, ok := fields["name"]; !ok || strings.TrimSpace(name.Value) == "" {
+		v.errorf(info, "missing required field: info.name")
+	}
+	if _, ok := fields["author"]; !ok {
+		v.errorf(info, "missing required field: info.author")
+	}
+	severity, ok := fields["severity"]
+	if !ok {
+		v.errorf(info, "missing required field: info.severity")
+		return
+	}
+	if !templateSeverities[strings.ToLower(severity.Value)] {
+		v.errorf(severity, "invalid severity %q: use info, low, medium, high, critical or unknown", severity.Value)
+	}
+}
+
+// validateVariables 校验变量名并检查变量值中的 DSL 函数
+func (v *templateValidator) validateVariables(variables *yaml.Node) {
+	if variables.Kind != yaml.MappingNode {
+		v.errorf(variables, "variables must be a mapping")
+		return
+	}
+	for i := 0; i+1 < len(variables.Content); i += 2 {
+		key, value := variables.Content[i], variables.Content[i+1]
+		if !variableNamePattern.MatchString(key.Value) {
+			v.errorf(key, "invalid variable name %q", key.Value)
+		}
+		if value.Kind != yaml.ScalarNode {
+			v.errorf(value, "variable %s must be a string", key.Value)
+			continue
+		}
+		v.checkPlaceholders(value)
+	}
+}
+
+// validateProtocol 校验协议块中的每个请求
+func (v *templateValidator) validateProtocol(protocol string, block *yaml.Node) {
+	if block.Kind != yaml.SequenceNode {
+		v.errorf(block, "%s must be a list of requests", protocol)
+		return
+	}
+	for _, request := range block.Content {
+		if request.Kind != yaml.MappingNode {
+			v.errorf(request, "%s request must be a mapping", protocol)
+			continue
+		}
+		v.validateRequest(protocol, request)
+	}
+}
+
+// validateRequest 校验单个请求的载荷、匹配器与提取器
+func (v *templateValidator) validateRequest(protocol string, request *yaml.Node) {
+	fields := mappingFields(request)
+
+	if protocol == "http" || protocol == "requests" {
+		_, hasPath := fields["path"]
+		_, hasRaw := fields["raw"]
+		if !hasPath && !hasRaw {
+			v.errorf(request, "http request must define path or raw")
+		}
+		if method, ok := fields["method"]; ok && !httpMethods[strings.ToUpper(method.Value)] {
+			v.errorf(method, "invalid http method %q", method.Value)
+		}
+	}
+
+	if condition, ok := fields["matchers-condition"]; ok && condition.Value != "and" && condition.Value != "or" {
+		v.errorf(condition, "matchers-condition must be and or or, got %q", condition.Value)
+	}
+
+	if matchers, ok := fields["matchers"]; ok {
+		v.validateList(matchers, "matchers", v.validateMatcher)
+	}
+	if extractors, ok := fields["extractors"]; ok {
+		v.validateList(extractors, "extractors", v.validateExtractor)
+	}
+
+	// 请求中所有字符串里的 {{...}} 表达式
+	var scalars []*yaml.Node
+	collectScalars(request, &scalars)
+	referenced := make(map[string]bool)
+	for _, node := range scalars {
+		for _, expr := range v.checkPlaceholders(node) {
+			for _, name := range identifierPattern.FindAllString(stringLiteralPattern.ReplaceAllString(expr, ""), -1) {
+				referenced[name] = true
+			}
+		}
+	}
+
+	payloads, hasPayloads := fields["payloads"]
+	if attack, ok := fields["attack"]; ok {
+		if !attackTypes[attack.Value] {
+			v.errorf(attack, "invalid attack type %q: use batteringram, pitchfork or clusterbomb", attack.Value)
+		}
+		if !hasPayloads {
+			v.warnf(attack, "attack is set but the request has no payloads")
+		}
+	}
+	if !hasPayloads {
+		return
+	}
+	if payloads.Kind != yaml.MappingNode {
+		v.errorf(payloads, "payloads must be a mapping")
+		return
+	}
+	for i := 0; i+1 < len(payloads.Content); i += 2 {
+		key, value := payloads.Content[i], payloads.Content[i+1]
+		if !variableNamePattern.MatchString(key.Value) {
+			v.errorf(key, "invalid payload name %q", key.Value)
+		}
+		if value.Kind != yaml.SequenceNode && value.Kind != yaml.ScalarNode {
+			v.errorf(value, "payload %s must be a list or a file path", key.Value)
+		}
+		if !referenced[key.Value] {
+			v.warnf(key, "payload %s is never referenced as {{%s}}", key.Value, key.Value)
+		}
+	}
+}
+
+// validateList 校验匹配器或提取器列表
+func (v *templateValidator) validateList(list *yaml.Node, name string, validate func(*yaml.Node)) {
+	if list.Kind != yaml.SequenceNode {
+		v.errorf(list, "%s must be a list", name)
+		return
+	}
+	for _, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			v.errorf(item, "%s entry must be a mapping", name)
+			continue
+		}
+		validate(item)
+	}
+}
+
+// validateMatcher 校验匹配器类型、必需字段、条件与正则表达式
+func (v *templateValidator) validateMatcher(matcher *yaml.Node) {
+	fields := mappingFields(matcher)
+	typ, ok := fields["type"]
+	if !ok {
+		v.errorf(matcher, "matcher is missing type")
+		return
+	}
+	field, known := matcherFields[typ.Value]
+	if !known {
+		v.errorf(typ, "unknown matcher type %q", typ.Value)
+		return
+	}
+	if condition, ok := fields["condition"]; ok && condition.Value != "and" && condition.Value != "or" {
+		v.errorf(condition, "matcher condition must be and or or, got %q", condition.Value)
+	}
+
+	values, ok := fields[field]
+	if !ok || values.Kind != yaml.SequenceNode || len(values.Content) == 0 {
+		v.errorf(matcher, "%s matcher requires a non-empty %s list", typ.Value, field)
+		return
+	}
+	for _, value := range values.Content {
+		switch typ.Value {
+		case "regex":
+			v.checkRegex(value)
+		case "dsl":
+			v.checkDSL(value, value.Value, DiagnosticError)
+		case "status", "size":
+			if _, err := strconv.Atoi(value.Value); err != nil {
+				v.errorf(value, "%s matcher value %q is not a number", typ.Value, value.Value)
+			}
+		}
+	}
+}
+
+// validateExtractor 校验提取器类型、必需字段与正则表达式
+func (v *templateValidator) validateExtractor(extractor *yaml.Node) {
+	fields := mappingFields(extractor)
+	typ, ok := fields["type"]
+	if !ok {
+		v.errorf(extractor, "extractor is missing type")
+		return
+	}
+	field, known := extractorFields[typ.Value]
+	if !known {
+		v.errorf(typ, "unknown extractor type %q", typ.Value)
+		return
+	}
+
+	values, ok := fields[field]
+	if !ok || values.Kind != yaml.SequenceNode || len(values.Content) == 0 {
+		v.errorf(extractor, "%s extractor requires a non-empty %s list", typ.Value, field)
+		return
+	}
+	for _, value := range values.Content {
+		switch typ.Value {
+		case "regex":
+			v.checkRegex(value)
+		case "dsl":
+			v.checkDSL(value, value.Value, DiagnosticError)
+		}
+	}
+}
+
+// checkRegex 检查正则表达式能否编译
+func (v *templateValidator) checkRegex(node *yaml.Node) {
+	if _, err := regexp.Compile(node.Value); err != nil {
+		v.errorf(node, "invalid regex: %v", err)
+	}
+}
+
+// checkPlaceholders 检查字符串中 {{...}} 表达式的 DSL 函数，返回找到的表达式
+// 无法求值的表达式会被 nuclei 原样保留，也可能是有意匹配的字面内容，因此只给出警告
+func (v *templateValidator) checkPlaceholders(node *yaml.Node) []string {
+	var exprs []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(node.Value, -1) {
+		exprs = append(exprs, m[1])
+		v.checkDSL(node, m[1], DiagnosticWarning)
+	}
+	return exprs
+}
+
+// checkDSL 检查 DSL 表达式中调用的函数是否存在
+func (v *templateValidator) checkDSL(node *yaml.Node, expr, severity string) {
+	for _, m := range functionCallPattern.FindAllStringSubmatch(stringLiteralPattern.ReplaceAllString(expr, ""), -1) {
+		if !dslFunctions[m[1]] {
+			v.add(node, severity, "unknown DSL function: "+m[1])
+		}
+	}
+}
+
+// errorf 记录 error 级别的问题
+func (v *templateValidator) errorf(node *yaml.Node, format string, args ...interface{}) {
+	v.add(node, DiagnosticError, fmt.Sprintf(format, args...))
+}
+
+// warnf 记录 warning 级别的问题
+func (v *templateValidator) warnf(node *yaml.Node, format string, args ...interface{}) {
+	v.add(node, DiagnosticWarning, fmt.Sprintf(format, args...))
+}
+
+// add 记录问题，node 为 nil 时不带位置
+func (v *templateValidator) add(node *yaml.Node, severity, message string) {
+	d := models.TemplateDiagnostic{Severity: severity, Message: message, Source: DiagnosticSourceSchema}
+	if node != nil {
+		d.Line, d.Column = node.Line, node.Column
+	}
+	v.diagnostics = append(v.diagnostics, d)
+}
+
+// mappingFields 返回映射节点中字段名到值节点的映射
+func mappingFields(node *yaml.Node) map[string]*yaml.Node {
+	fields := make(map[string]*yaml.Node, len(node.Content)/2)
+	if node.Kind != yaml.MappingNode {
+		return fields
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		fields[node.Content[i].Value] = node.Content[i+1]
+	}
+	return fields
+}
+
+// collectScalars 收集节点下所有的字符串值，不包括映射的键
+func collectScalars(node *yaml.Node, out *[]*yaml.Node) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*out = append(*out, node)
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			collectScalars(node.Content[i], out)
+		}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			collectScalars(child, out)
+		}
+	}
+}
+
+// sortDiagnostics 按行列排序，同一位置保持发现顺序
+func sortDiagnostics(diagnostics []models.TemplateDiagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+}
+
+// ValidateTemplate 在临时目录中写入模板并运行 nuclei -validate，返回 nuclei 报告的问题
+func (n *NucleiClient) ValidateTemplate(ctx context.Context, content []byte) ([]models.TemplateDiagnostic, error) {
+	if !n.IsAvailable() {
+		return nil, errors.Internal("nuclei binary not found", nil)
+	}
+
+	path, cleanup, err := writeTempTemplate("holehunter-validate-*", content)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	cmd := exec.CommandContext(ctx, n.binaryPath, "-validate", "-t", path, "-duc", "-no-color")
+	cmd.Env = append(os.Environ(), "NUCLEI_TEMPLATES_DIR="+n.templatesDir)
+	output, runErr := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, errors.Internal("nuclei validation timed out", ctx.Err())
+	}
+	if _, ok := runErr.(*exec.ExitError); runErr != nil && !ok {
+		return nil, errors.Internal("failed to run nuclei validation", runErr)
+	}
+
+	diagnostics := parseValidateOutput(output, path)
+	if runErr != nil && !HasErrorDiagnostic(diagnostics) {
+		diagnostics = append(diagnostics, models.TemplateDiagnostic{
+			Severity: DiagnosticError,
+			Message:  "nuclei rejected the template: " + runErr.Error(),
+			Source:   DiagnosticSourceNuclei,
+		})
+	}
+	return diagnostics, nil
+}
+
+// writeTempTemplate 在临时目录中写入模板，返回模板路径与清理函数
+func writeTempTemplate(pattern string, content []byte) (string, func(), error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", nil, errors.Internal("failed to create temporary template directory", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	path := filepath.Join(dir, "template.yaml")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		cleanup()
+		return "", nil, errors.Internal("failed to write temporary template", err)
+	}
+	return path, cleanup, nil
+}
+
+// parseValidateOutput 解析 nuclei -validate 输出中的错误与警告，模板路径替换为 template
+// [FTL] 为汇总信息，只在没有具体错误时保留
+func parseValidateOutput(output []byte, path string) []models.TemplateDiagnostic {
+	var diagnostics []models.TemplateDiagnostic
+	var fatal []models.TemplateDiagnostic
+	for _, raw := range bytes.Split(output, []byte("\n")) {
+		line := strings.TrimSpace(string(raw))
+		var severity, level string
+		switch {
+		case strings.HasPrefix(line, "[ERR]"):
+			severity, level = DiagnosticError, "[ERR]"
+		case strings.HasPrefix(line, "[FTL]"):
+			severity, level = DiagnosticError, "[FTL]"
+		case strings.HasPrefix(line, "[WRN]"):
+			severity, level = DiagnosticWarning, "[WRN]"
+		default:
+			continue
+		}
+
+		message := strings.TrimSpace(strings.TrimPrefix(line, level))
+		message = strings.ReplaceAll(message, path, "template")
+		d := models.TemplateDiagnostic{Severity: severity, Message: message, Source: DiagnosticSourceNuclei}
+		if m := yamlErrorLinePattern.FindStringSubmatch(message); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+		}
+		if level == "[FTL]" {
+			fatal = append(fatal, d)
+			continue
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	if !HasErrorDiagnostic(diagnostics) {
+		diagnostics = append(diagnostics, fatal...)
+	}
+	return diagnostics
+}
+
+// HasErrorDiagnostic 检查是否存在 error 级别的问题
+func HasErrorDiagnostic(diagnostics []models.TemplateDiagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == DiagnosticError {
+			return true
+		}
+	}
+	return false
+}
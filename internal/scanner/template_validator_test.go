@@ -0,0 +1,210 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+const validTemplate = `id: test-template
+info:
+  name: Test Template
+  author: tester
+  severity: high
+variables:
+  marker: "{{rand_base(6)}}"
+http:
+  - method: POST
+    path:
+      - "{{BaseURL}}/login"
+    body: "user={{username}}&pass={{password}}"
+    attack: clusterbomb
+    payloads:
+      username:
+        - admin
+      password:
+        - admin
+    matchers-condition: and
+    matchers:
+      - type: status
+        status:
+          - 200
+      - type: dsl
+        dsl:
+          - "contains(to_lower(body), 'welcome')"
+    extractors:
+      - type: regex
+        regex:
+          - "token=([a-z0-9]+)"
+`
+
+func TestValidateTemplateSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // "line:severity:message 片段"
+	}{
+		{
+			name:    "valid",
+			content: validTemplate,
+		},
+		{
+			name:    "yaml syntax error",
+			content: "id: test\ninfo:\n  name: [broken\n",
+			want:    []string{"0:error:did not find expected"},
+		},
+		{
+			name: "missing info fields",
+			content: `id: bad id
+info:
+  name: Test
+  severity: urgent
+http:
+  - path:
+      - "{{BaseURL}}"
+`,
+			want: []string{
+				"1:error:invalid template id",
+				"3:error:missing required field: info.author",
+				"4:error:invalid severity \"urgent\"",
+			},
+		},
+		{
+			name: "no protocol block",
+			content: `id: test
+info:
+  name: Test
+  author: tester
+  severity: info
+requestz: []
+`,
+			want: []string{
+				"1:error:template has no protocol block",
+				"6:warning:unknown field: requestz",
+			},
+		},
+		{
+			name: "matchers and extractors",
+			content: `id: test
+info:
+  name: Test
+  author: tester
+  severity: info
+http:
+  - method: FETCH
+    path:
+      - "{{BaseURL}}"
+    matchers-condition: xor
+    matchers:
+      - type: words
+      - type: status
+        status:
+          - ok
+      - type: regex
+        regex:
+          - "(unclosed"
+      - type: dsl
+        dsl:
+          - "not_a_function(body)"
+    extractors:
+      - type: jq
+        jq:
+          - ".id"
+`,
+			want: []string{
+				"7:error:invalid http method \"FETCH\"",
+				"10:error:matchers-condition must be and or or",
+				"12:error:unknown matcher type \"words\"",
+				"15:error:status matcher value \"ok\" is not a number",
+				"18:error:invalid regex",
+				"21:error:unknown DSL function: not_a_function",
+				"23:error:unknown extractor type \"jq\"",
+			},
+		},
+		{
+			name: "payloads and placeholders",
+			content: `id: test
+info:
+  name: Test
+  author: tester
+  severity: info
+variables:
+  1bad: value
+http:
+  - path:
+      - "{{BaseURL}}/{{md6(user)}}"
+    attack: sniper
+    payloads:
+      user:
+        - admin
+      unused:
+        - x
+`,
+			want: []string{
+				"7:error:invalid variable name \"1bad\"",
+				"10:warning:unknown DSL function: md6",
+				"11:error:invalid attack type \"sniper\"",
+				"15:warning:payload unused is never referenced",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateTemplateSchema([]byte(tt.content))
+			if len(got) != len(tt.want) {
+				t.Fatalf("ValidateTemplateSchema() = %s, want %d diagnostics", formatDiagnostics(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				parts := strings.SplitN(want, ":", 3)
+				d := got[i]
+				if parts[0] != "0" && strconv.Itoa(d.Line) != parts[0] || d.Severity != parts[1] || !strings.Contains(d.Message, parts[2]) {
+					t.Errorf("diagnostic %d = %s, want %s", i, formatDiagnostics([]models.TemplateDiagnostic{d}), want)
+				}
+				if d.Source != DiagnosticSourceSchema {
+					t.Errorf("diagnostic %d source = %s, want schema", i, d.Source)
+				}
+			}
+		})
+	}
+}
+
+func TestParseValidateOutput(t *testing.T) {
+	path := "/tmp/holehunter-validate-1/template.yaml"
+
+	output := `[INF] Current nuclei version: v3.3.0
+[WRN] Found duplicate template ID during validation '` + path + `' => 'test'
+[ERR] Error occurred loading template ` + path + `: could not unmarshal template: yaml: line 7: did not find expected key
+[FTL] Could not validate templates: errors occurred during template validation
+`
+	got := parseValidateOutput([]byte(output), path)
+	if len(got) != 2 {
+		t.Fatalf("parseValidateOutput() = %s, want a warning and an error", formatDiagnostics(got))
+	}
+	if got[0].Severity != DiagnosticWarning || strings.Contains(got[0].Message, path) {
+		t.Errorf("warning = %+v, want the template path replaced", got[0])
+	}
+	if got[1].Severity != DiagnosticError || got[1].Line != 7 || got[1].Source != DiagnosticSourceNuclei {
+		t.Errorf("error = %+v, want a nuclei error on line 7", got[1])
+	}
+
+	// 只有 [FTL] 时保留汇总信息
+	got = parseValidateOutput([]byte("[FTL] Could not validate templates: no templates provided\n"), path)
+	if len(got) != 1 || got[0].Severity != DiagnosticError {
+		t.Errorf("parseValidateOutput() with only [FTL] = %s, want one error", formatDiagnostics(got))
+	}
+
+	if got := parseValidateOutput([]byte("[INF] All templates validated successfully\n"), path); len(got) != 0 {
+		t.Errorf("parseValidateOutput() on success = %s, want none", formatDiagnostics(got))
+	}
+}
+
+func formatDiagnostics(diagnostics []models.TemplateDiagnostic) string {
+	var parts []string
+	for _, d := range diagnostics {
+		parts = append(parts, strings.Join([]string{strconv.Itoa(d.Line), d.Severity, d.Message}, ":"))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,220 @@
+package subdomain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout 默认单次解析超时
+	DefaultTimeout = 2 * time.Second
+	// DefaultBatchSize 默认并发解析数
+	DefaultBatchSize = 50
+)
+
+// Result 单个子域名的解析结果
+type Result struct {
+	Subdomain string
+	IPs       []string
+	// CNAME 规范名称，子域名没有 CNAME 记录时为空
+	CNAME    string
+	Latency  time.Duration
+	Wildcard bool
+	Err      error
+}
+
+// Resolved 子域名是否解析成功
+func (r *Result) Resolved() bool {
+	return len(r.IPs) > 0
+}
+
+// Config 解析器配置
+type Config struct {
+	// Server DNS 服务器地址（host 或 host:port），为空时使用系统解析器
+	Server    string
+	Timeout   time.Duration
+	BatchSize int
+}
+
+// Resolver 子域名解析器
+type Resolver struct {
+	resolver  *net.Resolver
+	timeout   time.Duration
+	batchSize int
+}
+
+// NewResolver 创建子域名解析器
+func NewResolver(cfg Config) *Resolver {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+
+	resolver := net.DefaultResolver
+	if cfg.Server != "" {
+		server := cfg.Server
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		dialer := &net.Dialer{Timeout: cfg.Timeout}
+		resolver = &net.Resolver{
+			PreferGo: true,
+			// 忽略系统配置的 DNS 服务器，所有查询发往指定服务器
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return &Resolver{
+		resolver:  resolver,
+		timeout:   cfg.Timeout,
+		batchSize: cfg.BatchSize,
+	}
+}
+
+// Lookup 解析主机名，域名不存在时返回空列表而不是错误
+func (r *Resolver) Lookup(ctx context.Context, host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// 以 . 结尾避免追加系统配置的搜索域
+	addrs, err := r.resolver.LookupNetIP(ctx, "ip", strings.TrimSuffix(host, ".")+".")
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	seen := make(map[netip.Addr]bool, len(addrs))
+	ips := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = addr.Unmap()
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		ips = append(ips, addr.String())
+	}
+	sort.Strings(ips)
+	return ips, nil
+}
+
+// LookupCNAME 查询主机名的 CNAME，没有 CNAME 或查询失败时返回空
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) string {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	cname, err := r.resolver.LookupCNAME(ctx, host+".")
+	if err != nil {
+		return ""
+	}
+	cname = strings.ToLower(strings.TrimSuffix(cname, "."))
+	if cname == host {
+		return ""
+	}
+	return cname
+}
+
+// Brute 依次解析 word.domain，onResult 在调用者的 goroutine 中串行调用
+// wildcard 非空时标记与泛解析签名一致的结果
+// context 取消时返回 ctx.Err()
+func (r *Resolver) Brute(ctx context.Context, domain string, words []string, wildcard *Wildcard, onResult func(*Result)) error {
+	domain = NormalizeDomain(domain)
+	hosts := Candidates(domain, words)
+
+	jobs := make(chan string)
+	results := make(chan *Result)
+
+	// 生产者
+	go func() {
+		defer close(jobs)
+		for _, host := range hosts {
+			select {
+			case jobs <- host:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// 工作池
+	workers := r.batchSize
+	if workers > len(hosts) {
+		workers = len(hosts)
+	}
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				result := r.resolve(ctx, host)
+				result.Wildcard = wildcard.Match(result)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for result := range results {
+		onResult(result)
+	}
+
+	return ctx.Err()
+}
+
+// resolve 解析单个子域名
+func (r *Resolver) resolve(ctx context.Context, host string) *Result {
+	start := time.Now()
+	ips, err := r.Lookup(ctx, host)
+	result := &Result{Subdomain: host, IPs: ips, Latency: time.Since(start), Err: err}
+	if result.Resolved() {
+		result.CNAME = r.LookupCNAME(ctx, host)
+	}
+	return result
+}
+
+// Candidates 由字典生成待解析的子域名，忽略空行、注释与重复项
+func Candidates(domain string, words []string) []string {
+	domain = NormalizeDomain(domain)
+	seen := make(map[string]bool, len(words))
+	hosts := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.Trim(strings.ToLower(strings.TrimSpace(word)), ".")
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		host := word + "." + domain
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// NormalizeDomain 规范化域名：去除空白、末尾的点并转为小写
+func NormalizeDomain(domain string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+}
@@ -0,0 +1,92 @@
+package subdomain
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/testutil"
+)
+
+// TestResolver_Brute 测试子域名解析
+func TestResolver_Brute(t *testing.T) {
+	server := testutil.NewDNSServer(t, map[string][]string{
+		"www.example.test":  {"10.0.0.2", "10.0.0.1"},
+		"api.example.test":  {"10.0.0.3", "fd00::3"},
+		"mail.example.test": {"10.0.0.4"},
+	})
+
+	resolver := NewResolver(Config{Server: server.Addr, Timeout: time.Second, BatchSize: 2})
+
+	got := make(map[string][]string)
+	err := resolver.Brute(context.Background(), "Example.Test.", []string{"www", "API", "missing", "", "# comment", "www"}, nil, func(r *Result) {
+		if r.Err != nil {
+			t.Errorf("lookup %s failed: %v", r.Subdomain, r.Err)
+		}
+		got[r.Subdomain] = r.IPs
+	})
+	if err != nil {
+		t.Fatalf("Brute() failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"www.example.test":     {"10.0.0.1", "10.0.0.2"},
+		"api.example.test":     {"10.0.0.3", "fd00::3"},
+		"missing.example.test": nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Brute() = %v, want %v", got, want)
+	}
+}
+
+// TestResolver_BruteCancel 测试取消解析
+func TestResolver_BruteCancel(t *testing.T) {
+	server := testutil.NewDNSServer(t, nil)
+	resolver := NewResolver(Config{Server: server.Addr, Timeout: time.Second, BatchSize: 1})
+
+	words := make([]string, 1000)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var count int
+	err := resolver.Brute(ctx, "example.test", words, nil, func(r *Result) {
+		count++
+		if count == 5 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("Brute() error = %v, want context.Canceled", err)
+	}
+	if count >= len(words) {
+		t.Errorf("Brute() resolved %d words after cancel", count)
+	}
+}
+
+// TestResolver_Timeout 测试 DNS 服务器无响应时超时
+func TestResolver_Timeout(t *testing.T) {
+	// 未监听的端口不会返回任何响应
+	resolver := NewResolver(Config{Server: "192.0.2.1:53", Timeout: 200 * time.Millisecond})
+
+	start := time.Now()
+	ips, err := resolver.Lookup(context.Background(), "www.example.test")
+	if err == nil || len(ips) != 0 {
+		t.Errorf("Lookup() = %v, %v, want timeout error", ips, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Lookup() took %v, timeout not respected", elapsed)
+	}
+}
+
+// TestCandidates 测试生成候选子域名
+func TestCandidates(t *testing.T) {
+	got := Candidates(" Example.com ", []string{"WWW", " www ", ".dev.", "", "#x"})
+	want := []string{"www.example.com", "dev.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Candidates() = %v, want %v", got, want)
+	}
+}
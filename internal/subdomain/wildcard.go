@@ -0,0 +1,111 @@
+package subdomain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+)
+
+// DefaultWildcardProbes 检测泛解析时查询的随机子域名数量
+const DefaultWildcardProbes = 3
+
+// Wildcard 泛解析签名，即随机子域名解析得到的 IP 与 CNAME 集合
+type Wildcard struct {
+	IPs    []string
+	CNAMEs []string
+
+	ips    map[string]bool
+	cnames map[string]bool
+}
+
+// NewWildcard 根据 IP 与 CNAME 创建泛解析签名
+func NewWildcard(ips, cnames []string) *Wildcard {
+	w := &Wildcard{ips: make(map[string]bool), cnames: make(map[string]bool)}
+	for _, ip := range ips {
+		w.addIP(ip)
+	}
+	for _, cname := range cnames {
+		w.addCNAME(cname)
+	}
+	return w
+}
+
+func (w *Wildcard) addIP(ip string) {
+	if !w.ips[ip] {
+		w.ips[ip] = true
+		w.IPs = append(w.IPs, ip)
+		sort.Strings(w.IPs)
+	}
+}
+
+func (w *Wildcard) addCNAME(cname string) {
+	if cname != "" && !w.cnames[cname] {
+		w.cnames[cname] = true
+		w.CNAMEs = append(w.CNAMEs, cname)
+		sort.Strings(w.CNAMEs)
+	}
+}
+
+// Match 判断解析结果是否来自泛解析：CNAME 与签名一致，或全部 IP 都在签名中
+func (w *Wildcard) Match(r *Result) bool {
+	if w == nil || !r.Resolved() {
+		return false
+	}
+	if r.CNAME != "" && w.cnames[r.CNAME] {
+		return true
+	}
+	for _, ip := range r.IPs {
+		if !w.ips[ip] {
+			return false
+		}
+	}
+	return true
+}
+
+// DetectWildcard 查询域名下若干随机子域名以检测泛解析，未配置泛解析时返回 nil
+// 泛解析可能轮询返回不同 IP，因此合并所有探测结果作为签名
+func (r *Resolver) DetectWildcard(ctx context.Context, domain string, probes int) (*Wildcard, error) {
+	if probes <= 0 {
+		probes = DefaultWildcardProbes
+	}
+	domain = NormalizeDomain(domain)
+
+	var wildcard *Wildcard
+	var lastErr error
+	var answered int
+	for i := 0; i < probes; i++ {
+		result := r.resolve(ctx, randomLabel()+"."+domain)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if result.Err != nil {
+			lastErr = result.Err
+			continue
+		}
+		answered++
+		if !result.Resolved() {
+			continue
+		}
+		if wildcard == nil {
+			wildcard = NewWildcard(nil, nil)
+		}
+		for _, ip := range result.IPs {
+			wildcard.addIP(ip)
+		}
+		wildcard.addCNAME(result.CNAME)
+	}
+
+	// 所有探测都失败时无法判断是否存在泛解析
+	if answered == 0 {
+		return nil, lastErr
+	}
+	return wildcard, nil
+}
+
+// randomLabel 生成不太可能真实存在的随机标签
+func randomLabel() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "hh-" + hex.EncodeToString(b)
+}
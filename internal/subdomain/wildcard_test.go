@@ -0,0 +1,125 @@
+package subdomain
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/testutil"
+)
+
+// TestResolver_DetectWildcard 测试泛解析检测
+func TestResolver_DetectWildcard(t *testing.T) {
+	server := testutil.NewDNSServer(t, map[string][]string{
+		"*.wild.test":            {"10.1.1.1", "10.1.1.2"},
+		"*.cdn.test":             {"edge.cdn-provider.test"},
+		"edge.cdn-provider.test": {"10.2.2.2"},
+		"www.plain.test":         {"10.3.3.3"},
+	})
+	resolver := NewResolver(Config{Server: server.Addr, Timeout: time.Second})
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		domain     string
+		wantIPs    []string
+		wantCNAMEs []string
+	}{
+		{"IP 泛解析", "wild.test", []string{"10.1.1.1", "10.1.1.2"}, nil},
+		{"CNAME 泛解析", "cdn.test", []string{"10.2.2.2"}, []string{"edge.cdn-provider.test"}},
+		{"无泛解析", "plain.test", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wildcard, err := resolver.DetectWildcard(ctx, tt.domain, 0)
+			if err != nil {
+				t.Fatalf("DetectWildcard() failed: %v", err)
+			}
+			if tt.wantIPs == nil {
+				if wildcard != nil {
+					t.Errorf("DetectWildcard() = %+v, want nil", wildcard)
+				}
+				return
+			}
+			if wildcard == nil {
+				t.Fatal("DetectWildcard() = nil, want wildcard")
+			}
+			if !reflect.DeepEqual(wildcard.IPs, tt.wantIPs) || !reflect.DeepEqual(wildcard.CNAMEs, tt.wantCNAMEs) {
+				t.Errorf("DetectWildcard() = %v/%v, want %v/%v", wildcard.IPs, wildcard.CNAMEs, tt.wantIPs, tt.wantCNAMEs)
+			}
+		})
+	}
+
+	// DNS 服务器不可达时返回错误
+	unreachable := NewResolver(Config{Server: "192.0.2.1:53", Timeout: 100 * time.Millisecond})
+	if _, err := unreachable.DetectWildcard(ctx, "wild.test", 1); err == nil {
+		t.Error("DetectWildcard() should fail when no probe is answered")
+	}
+}
+
+// TestResolver_BruteWildcard 测试泛解析结果标记
+func TestResolver_BruteWildcard(t *testing.T) {
+	server := testutil.NewDNSServer(t, map[string][]string{
+		"*.wild.test":            {"10.1.1.1"},
+		"www.wild.test":          {"10.1.1.1"},
+		"mail.wild.test":         {"10.9.9.9"},
+		"cdn.wild.test":          {"edge.cdn-provider.test"},
+		"edge.cdn-provider.test": {"10.2.2.2"},
+	})
+	resolver := NewResolver(Config{Server: server.Addr, Timeout: time.Second})
+	ctx := context.Background()
+
+	wildcard, err := resolver.DetectWildcard(ctx, "wild.test", 2)
+	if err != nil || wildcard == nil {
+		t.Fatalf("DetectWildcard() = %v, %v", wildcard, err)
+	}
+
+	got := make(map[string]bool)
+	err = resolver.Brute(ctx, "wild.test", []string{"www", "mail", "cdn", "random"}, wildcard, func(r *Result) {
+		got[r.Subdomain] = r.Wildcard
+	})
+	if err != nil {
+		t.Fatalf("Brute() failed: %v", err)
+	}
+
+	want := map[string]bool{
+		"www.wild.test":    true,
+		"mail.wild.test":   false,
+		"cdn.wild.test":    false,
+		"random.wild.test": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Brute() wildcard flags = %v, want %v", got, want)
+	}
+}
+
+// TestWildcard_Match 测试泛解析签名匹配
+func TestWildcard_Match(t *testing.T) {
+	wildcard := NewWildcard([]string{"10.0.0.1", "10.0.0.2"}, []string{"wild.example.net"})
+
+	tests := []struct {
+		name   string
+		result *Result
+		want   bool
+	}{
+		{"IP 子集", &Result{IPs: []string{"10.0.0.2"}}, true},
+		{"包含其他 IP", &Result{IPs: []string{"10.0.0.1", "10.0.0.9"}}, false},
+		{"CNAME 一致", &Result{IPs: []string{"10.9.9.9"}, CNAME: "wild.example.net"}, true},
+		{"未解析", &Result{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wildcard.Match(tt.result); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var none *Wildcard
+	if none.Match(&Result{IPs: []string{"10.0.0.1"}}) {
+		t.Error("nil wildcard should not match")
+	}
+}
@@ -0,0 +1,449 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	neturl "net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/infrastructure/secret"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/scanner"
+)
+
+// loginTimeout 登录请求超时
+const loginTimeout = 30 * time.Second
+
+// AuthService 认证配置服务，凭据加密存储，扫描时生成 nuclei 凭据文件
+type AuthService struct {
+	repo       *repo.AuthProfileRepository
+	targetRepo *repo.TargetRepository
+	httpRepo   *repo.HTTPRequestRepository
+	box        *secret.Box
+	logger     *logger.Logger
+	client     *nethttp.Client
+}
+
+// NewAuthService 创建认证配置服务，box 为 nil 时无法保存或使用凭据
+func NewAuthService(
+	repo *repo.AuthProfileRepository,
+	targetRepo *repo.TargetRepository,
+	httpRepo *repo.HTTPRequestRepository,
+	box *secret.Box,
+	logger *logger.Logger,
+) *AuthService {
+	return &AuthService{
+		repo:       repo,
+		targetRepo: targetRepo,
+		httpRepo:   httpRepo,
+		box:        box,
+		logger:     logger,
+		client: &nethttp.Client{
+			Timeout: loginTimeout,
+			// 登录接口通常在重定向响应中设置 Cookie，不跟随重定向
+			CheckRedirect: func(req *nethttp.Request, via []*nethttp.Request) error {
+				return nethttp.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// GetByTargetID 获取目标的认证配置
+func (s *AuthService) GetByTargetID(ctx context.Context, targetID int) ([]*models.AuthProfile, error) {
+	if targetID <= 0 {
+		return nil, errors.InvalidInput("invalid target id")
+	}
+	return s.repo.GetByTargetID(ctx, targetID)
+}
+
+// GetByID 根据 ID 获取认证配置
+func (s *AuthService) GetByID(ctx context.Context, id int) (*models.AuthProfile, error) {
+	if id <= 0 {
+		return nil, errors.InvalidInput("invalid auth profile id")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create 创建认证配置
+func (s *AuthService) Create(ctx context.Context, req *models.AuthProfileRequest) (*models.AuthProfile, error) {
+	if req == nil {
+		return nil, errors.InvalidInput("request is required")
+	}
+	if _, err := s.targetRepo.GetByID(ctx, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	profile := &models.AuthProfile{TargetID: req.TargetID}
+	if err := s.apply(ctx, profile, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Auth profile created: profile_id=%d, target_id=%d, type=%s", profile.ID, profile.TargetID, profile.Type)
+	return s.repo.GetByID(ctx, profile.ID)
+}
+
+// Update 更新认证配置，未提供凭据时保留原有凭据
+func (s *AuthService) Update(ctx context.Context, id int, req *models.AuthProfileRequest) (*models.AuthProfile, error) {
+	if req == nil {
+		return nil, errors.InvalidInput("request is required")
+	}
+	profile, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, profile, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, profile); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Delete 删除认证配置
+func (s *AuthService) Delete(ctx context.Context, id int) error {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// apply 校验请求并写入配置，凭据加密后保存
+func (s *AuthService) apply(ctx context.Context, profile *models.AuthProfile, req *models.AuthProfileRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.InvalidInput("auth profile name is required")
+	}
+
+	sec := &models.AuthSecret{
+		Cookies:  strings.TrimSpace(req.Cookies),
+		Password: req.Password,
+		Token:    strings.TrimSpace(req.Token),
+	}
+	for key, value := range req.Headers {
+		header, err := normalizeHeader(key + ":" + value)
+		if err != nil {
+			return err
+		}
+		headerName, headerValue, _ := strings.Cut(header, ": ")
+		if sec.Headers == nil {
+			sec.Headers = make(map[string]string)
+		}
+		sec.Headers[headerName] = headerValue
+	}
+	provided := len(sec.Headers) > 0 || sec.Cookies != "" || sec.Password != "" || sec.Token != ""
+
+	// 类型未变且未提供新凭据时沿用原有凭据
+	keepSecret := !provided && profile.ID > 0 && profile.Type == req.Type && profile.HasSecret
+
+	profile.Username = ""
+	profile.LoginRequestID = nil
+	switch req.Type {
+	case models.AuthTypeHeader:
+		if !keepSecret && len(sec.Headers) == 0 {
+			return errors.InvalidInput("at least one header is required")
+		}
+	case models.AuthTypeCookie:
+		if !keepSecret {
+			if len(parseCookies(sec.Cookies)) == 0 {
+				return errors.InvalidInput("cookies are required in the form name=value; name2=value2")
+			}
+		}
+	case models.AuthTypeBasic:
+		profile.Username = strings.TrimSpace(req.Username)
+		if profile.Username == "" {
+			return errors.InvalidInput("username is required for basic auth")
+		}
+		if !keepSecret && sec.Password == "" {
+			return errors.InvalidInput("password is required for basic auth")
+		}
+	case models.AuthTypeBearer:
+		if !keepSecret && sec.Token == "" {
+			return errors.InvalidInput("token is required for bearer auth")
+		}
+	case models.AuthTypeLogin:
+		if req.LoginRequestID <= 0 {
+			return errors.InvalidInput("login request is required")
+		}
+		if _, err := s.httpRepo.GetByID(ctx, req.LoginRequestID); err != nil {
+			return err
+		}
+		id := req.LoginRequestID
+		profile.LoginRequestID = &id
+		// 登录类型在扫描时获取会话，不保存凭据
+		keepSecret, provided = false, false
+	default:
+		return errors.InvalidInput(fmt.Sprintf("invalid auth type: %s", req.Type))
+	}
+
+	profile.Name = name
+	profile.Type = req.Type
+	profile.Enabled = req.Enabled
+
+	switch {
+	case keepSecret:
+	case provided:
+		encrypted, err := s.seal(sec)
+		if err != nil {
+			return err
+		}
+		profile.EncryptedSecret = encrypted
+	default:
+		profile.EncryptedSecret = ""
+	}
+	return nil
+}
+
+// WriteSecretFile 为扫描目标生成 nuclei 凭据文件（-secret-file），返回需要在日志中隐藏的凭据
+// 没有启用的认证配置时不生成文件，返回 false
+func (s *AuthService) WriteSecretFile(ctx context.Context, targets []scanner.ScanTarget, path string) (bool, []string, error) {
+	file := nucleiSecretFile{ID: "holehunter-scan-auth"}
+	file.Info.Name = "HoleHunter scan authentication"
+	var secrets []string
+
+	for _, target := range targets {
+		profiles, err := s.repo.GetByTargetID(ctx, target.ID)
+		if err != nil {
+			return false, nil, err
+		}
+
+		domain := authDomain(target.URL)
+		for _, profile := range profiles {
+			if !profile.Enabled {
+				continue
+			}
+			if domain == "" {
+				return false, nil, errors.InvalidInput(fmt.Sprintf("cannot determine host of target %d for authentication", target.ID))
+			}
+
+			entry, values, err := s.secretEntry(ctx, profile)
+			if err != nil {
+				return false, nil, errors.Wrap(err, fmt.Sprintf("auth profile %q", profile.Name))
+			}
+			entry.Domains = []string{domain}
+			file.Static = append(file.Static, entry)
+			secrets = append(secrets, values...)
+		}
+	}
+
+	if len(file.Static) == 0 {
+		return false, nil, nil
+	}
+
+	data, err := yaml.Marshal(&file)
+	if err != nil {
+		return false, nil, errors.Internal("failed to marshal secret file", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return false, nil, errors.Internal("failed to write secret file", err)
+	}
+	return true, secrets, nil
+}
+
+// secretEntry 将认证配置转换为凭据文件条目，同时返回其中的敏感值
+func (s *AuthService) secretEntry(ctx context.Context, profile *models.AuthProfile) (nucleiSecret, []string, error) {
+	if profile.Type == models.AuthTypeLogin {
+		cookies, err := s.login(ctx, profile)
+		if err != nil {
+			return nucleiSecret{}, nil, err
+		}
+		entry := nucleiSecret{Type: "Cookie"}
+		var values []string
+		for _, c := range cookies {
+			entry.Cookies = append(entry.Cookies, nucleiKeyValue{Key: c.Name, Value: c.Value})
+			values = append(values, c.Value)
+		}
+		return entry, values, nil
+	}
+
+	sec, err := s.open(profile)
+	if err != nil {
+		return nucleiSecret{}, nil, err
+	}
+
+	switch profile.Type {
+	case models.AuthTypeHeader:
+		entry := nucleiSecret{Type: "Header"}
+		names := make([]string, 0, len(sec.Headers))
+		for name := range sec.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var values []string
+		for _, name := range names {
+			entry.Headers = append(entry.Headers, nucleiKeyValue{Key: name, Value: sec.Headers[name]})
+			values = append(values, sec.Headers[name])
+		}
+		return entry, values, nil
+	case models.AuthTypeCookie:
+		entry := nucleiSecret{Type: "Cookie"}
+		values := []string{sec.Cookies}
+		for _, kv := range parseCookies(sec.Cookies) {
+			entry.Cookies = append(entry.Cookies, kv)
+			values = append(values, kv.Value)
+		}
+		return entry, values, nil
+	case models.AuthTypeBasic:
+		return nucleiSecret{Type: "BasicAuth", Username: profile.Username, Password: sec.Password}, []string{sec.Password}, nil
+	case models.AuthTypeBearer:
+		return nucleiSecret{Type: "BearerToken", Token: sec.Token}, []string{sec.Token}, nil
+	default:
+		return nucleiSecret{}, nil, errors.InvalidInput(fmt.Sprintf("invalid auth type: %s", profile.Type))
+	}
+}
+
+// login 发送 HTTP 模块中保存的登录请求，返回响应设置的 Cookie
+func (s *AuthService) login(ctx context.Context, profile *models.AuthProfile) ([]*nethttp.Cookie, error) {
+	if profile.LoginRequestID == nil {
+		return nil, errors.InvalidInput("login request is required")
+	}
+	request, err := s.httpRepo.GetByID(ctx, *profile.LoginRequestID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get login request")
+	}
+	if !allowedMethods[request.Method] {
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid http method: %s", request.Method))
+	}
+
+	var body io.Reader
+	if request.Body != "" {
+		body = bytes.NewReader([]byte(request.Body))
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, request.Method, request.URL, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create login request")
+	}
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+	if request.ContentType != "" {
+		req.Header.Set("Content-Type", request.ContentType)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "login request failed")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBodySize))
+
+	if resp.StatusCode >= 400 {
+		return nil, errors.InvalidInput(fmt.Sprintf("login request returned status %d", resp.StatusCode))
+	}
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return nil, errors.InvalidInput("login request returned no cookies")
+	}
+	return cookies, nil
+}
+
+// seal 加密凭据
+func (s *AuthService) seal(sec *models.AuthSecret) (string, error) {
+	if s.box == nil {
+		return "", errors.Internal("secret storage is unavailable", nil)
+	}
+	data, err := json.Marshal(sec)
+	if err != nil {
+		return "", errors.Internal("failed to marshal auth secret", err)
+	}
+	encrypted, err := s.box.Seal(data)
+	if err != nil {
+		return "", errors.Internal("failed to encrypt auth secret", err)
+	}
+	return encrypted, nil
+}
+
+// open 解密认证配置的凭据
+func (s *AuthService) open(profile *models.AuthProfile) (*models.AuthSecret, error) {
+	if s.box == nil {
+		return nil, errors.Internal("secret storage is unavailable", nil)
+	}
+	if profile.EncryptedSecret == "" {
+		return nil, errors.InvalidInput("auth profile has no credentials")
+	}
+	data, err := s.box.Open(profile.EncryptedSecret)
+	if err != nil {
+		return nil, errors.Internal("failed to decrypt auth secret", err)
+	}
+	var sec models.AuthSecret
+	if err := json.Unmarshal(data, &sec); err != nil {
+		return nil, errors.Internal("failed to unmarshal auth secret", err)
+	}
+	return &sec, nil
+}
+
+// nucleiSecretFile nuclei 凭据文件格式
+type nucleiSecretFile struct {
+	ID   string `yaml:"id"`
+	Info struct {
+		Name string `yaml:"name"`
+	} `yaml:"info"`
+	Static []nucleiSecret `yaml:"static"`
+}
+
+// nucleiSecret nuclei 静态凭据条目
+type nucleiSecret struct {
+	Type     string           `yaml:"type"`
+	Domains  []string         `yaml:"domains"`
+	Headers  []nucleiKeyValue `yaml:"headers,omitempty"`
+	Cookies  []nucleiKeyValue `yaml:"cookies,omitempty"`
+	Username string           `yaml:"username,omitempty"`
+	Password string           `yaml:"password,omitempty"`
+	Token    string           `yaml:"token,omitempty"`
+}
+
+// nucleiKeyValue nuclei 凭据中的键值对
+type nucleiKeyValue struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// parseCookies 解析 "a=1; b=2" 格式的 Cookie
+func parseCookies(raw string) []nucleiKeyValue {
+	var cookies []nucleiKeyValue
+	for _, part := range strings.Split(raw, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		cookies = append(cookies, nucleiKeyValue{Key: name, Value: strings.TrimSpace(value)})
+	}
+	return cookies
+}
+
+// authDomain 返回凭据文件中目标的主机，默认端口省略，与 nuclei 的匹配规则一致
+func authDomain(rawURL string) string {
+	raw := strings.TrimSpace(rawURL)
+	if !strings.Contains(raw, "://") {
+		raw = "//" + raw
+	}
+	u, err := neturl.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return host + ":" + port
+	}
+	return host
+}
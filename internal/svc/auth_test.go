@@ -0,0 +1,267 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/infrastructure/secret"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/scanner"
+)
+
+// newTestAuthService 创建使用临时密钥的认证配置服务
+func newTestAuthService(t *testing.T, db *sql.DB) *AuthService {
+	t.Helper()
+	box, err := secret.LoadOrCreate(filepath.Join(t.TempDir(), "secret.key"))
+	if err != nil {
+		t.Fatalf("failed to create secret box: %v", err)
+	}
+	return NewAuthService(
+		repo.NewAuthProfileRepository(db),
+		repo.NewTargetRepository(db),
+		repo.NewHTTPRequestRepository(db),
+		box,
+		logger.New("error", ""),
+	)
+}
+
+// TestAuthService_Create 测试认证配置的校验与加密存储
+func TestAuthService_Create(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service := newTestAuthService(t, db)
+	ctx := context.Background()
+	target := &models.Target{Name: "app", URL: "https://app.example.com"}
+	if err := repo.NewTargetRepository(db).Create(ctx, target); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	invalid := []models.AuthProfileRequest{
+		{TargetID: target.ID, Type: models.AuthTypeBearer, Token: "abc"},
+		{TargetID: 999, Name: "x", Type: models.AuthTypeBearer, Token: "abc"},
+		{TargetID: target.ID, Name: "x", Type: "oauth"},
+		{TargetID: target.ID, Name: "x", Type: models.AuthTypeBearer},
+		{TargetID: target.ID, Name: "x", Type: models.AuthTypeBasic, Password: "pw"},
+		{TargetID: target.ID, Name: "x", Type: models.AuthTypeCookie, Cookies: "no-value"},
+		{TargetID: target.ID, Name: "x", Type: models.AuthTypeHeader, Headers: map[string]string{"Bad Name": "v"}},
+		{TargetID: target.ID, Name: "x", Type: models.AuthTypeLogin, LoginRequestID: 42},
+	}
+	for _, req := range invalid {
+		if _, err := service.Create(ctx, &req); err == nil {
+			t.Errorf("Create(%+v) should fail", req)
+		}
+	}
+
+	profile, err := service.Create(ctx, &models.AuthProfileRequest{
+		TargetID: target.ID, Name: "api token", Type: models.AuthTypeBearer, Token: "s3cr3t-token", Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if !profile.HasSecret {
+		t.Error("HasSecret should be true")
+	}
+
+	// 数据库中只保存密文
+	var stored string
+	if err := db.QueryRow("SELECT secret FROM auth_profiles WHERE id = ?", profile.ID).Scan(&stored); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, "s3cr3t-token") {
+		t.Errorf("stored secret = %q, want ciphertext", stored)
+	}
+
+	// 未提供凭据时保留原有凭据
+	profile, err = service.Update(ctx, profile.ID, &models.AuthProfileRequest{
+		Name: "renamed", Type: models.AuthTypeBearer, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	sec, err := service.open(profile)
+	if err != nil || sec.Token != "s3cr3t-token" || profile.Name != "renamed" {
+		t.Errorf("after update name=%s token=%v, %v", profile.Name, sec, err)
+	}
+
+	// 更换类型时必须提供新凭据
+	if _, err := service.Update(ctx, profile.ID, &models.AuthProfileRequest{Name: "x", Type: models.AuthTypeCookie}); err == nil {
+		t.Error("Update() changing type without credentials should fail")
+	}
+}
+
+// TestAuthService_WriteSecretFile 测试生成 nuclei 凭据文件
+func TestAuthService_WriteSecretFile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service := newTestAuthService(t, db)
+	ctx := context.Background()
+	targetRepo := repo.NewTargetRepository(db)
+
+	app := &models.Target{Name: "app", URL: "https://App.example.com/login"}
+	lab := &models.Target{Name: "lab", URL: "http://10.0.0.5:8080"}
+	plain := &models.Target{Name: "plain", URL: "https://plain.example.com"}
+	for _, target := range []*models.Target{app, lab, plain} {
+		if err := targetRepo.Create(ctx, target); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+	}
+
+	// 登录请求返回会话 Cookie
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(nethttp.StatusBadRequest)
+			return
+		}
+		nethttp.SetCookie(w, &nethttp.Cookie{Name: "session", Value: "login-session-value"})
+		w.Header().Set("Location", "/dashboard")
+		w.WriteHeader(nethttp.StatusFound)
+	}))
+	defer server.Close()
+	loginReq := &models.HttpRequest{Name: "login", Method: "POST", URL: server.URL + "/login", Body: `{"u":"a"}`, ContentType: "application/json"}
+	if err := repo.NewHTTPRequestRepository(db).Create(ctx, loginReq); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	requests := []models.AuthProfileRequest{
+		{TargetID: app.ID, Name: "headers", Type: models.AuthTypeHeader, Enabled: true, Headers: map[string]string{"X-Api-Key": "key-123456"}},
+		{TargetID: app.ID, Name: "cookies", Type: models.AuthTypeCookie, Enabled: true, Cookies: "sid=cookie-abcdef; theme=dark"},
+		{TargetID: app.ID, Name: "disabled", Type: models.AuthTypeBearer, Token: "disabled-token"},
+		{TargetID: lab.ID, Name: "basic", Type: models.AuthTypeBasic, Enabled: true, Username: "admin", Password: "lab-password"},
+		{TargetID: lab.ID, Name: "login", Type: models.AuthTypeLogin, Enabled: true, LoginRequestID: loginReq.ID},
+	}
+	for _, req := range requests {
+		if _, err := service.Create(ctx, &req); err != nil {
+			t.Fatalf("Create(%s) failed: %v", req.Name, err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "scan-1.yaml")
+
+	// 没有认证配置的目标不生成文件
+	written, _, err := service.WriteSecretFile(ctx, []scanner.ScanTarget{{ID: plain.ID, URL: plain.URL}}, path)
+	if err != nil || written {
+		t.Fatalf("WriteSecretFile() without profiles = %v, %v, want false", written, err)
+	}
+
+	written, secrets, err := service.WriteSecretFile(ctx, []scanner.ScanTarget{
+		{ID: app.ID, URL: app.URL}, {ID: lab.ID, URL: lab.URL}, {ID: plain.ID, URL: plain.URL},
+	}, path)
+	if err != nil || !written {
+		t.Fatalf("WriteSecretFile() = %v, %v", written, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("secret file not written: %v", err)
+	}
+	var file nucleiSecretFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatalf("invalid secret file: %v", err)
+	}
+
+	got := make(map[string]nucleiSecret)
+	for _, entry := range file.Static {
+		got[entry.Type+"@"+strings.Join(entry.Domains, ",")] = entry
+	}
+	if len(got) != 4 {
+		t.Fatalf("secret entries = %+v, want 4", file.Static)
+	}
+	if e := got["Header@app.example.com"]; len(e.Headers) != 1 || e.Headers[0] != (nucleiKeyValue{Key: "X-Api-Key", Value: "key-123456"}) {
+		t.Errorf("header entry = %+v", e)
+	}
+	if e := got["Cookie@app.example.com"]; len(e.Cookies) != 2 || e.Cookies[0] != (nucleiKeyValue{Key: "sid", Value: "cookie-abcdef"}) {
+		t.Errorf("cookie entry = %+v", e)
+	}
+	if e := got["BasicAuth@10.0.0.5:8080"]; e.Username != "admin" || e.Password != "lab-password" {
+		t.Errorf("basic entry = %+v", e)
+	}
+	if e := got["Cookie@10.0.0.5:8080"]; len(e.Cookies) != 1 || e.Cookies[0].Value != "login-session-value" {
+		t.Errorf("login entry = %+v", e)
+	}
+	if strings.Contains(string(data), "disabled-token") {
+		t.Error("disabled profile should be skipped")
+	}
+
+	for _, want := range []string{"key-123456", "cookie-abcdef", "lab-password", "login-session-value"} {
+		found := false
+		for _, s := range secrets {
+			found = found || s == want
+		}
+		if !found {
+			t.Errorf("secrets = %v, missing %q", secrets, want)
+		}
+	}
+}
+
+// TestScanService_AuthProfiles 测试扫描时传入凭据文件、日志隐藏凭据并在结束后删除凭据文件
+func TestScanService_AuthProfiles(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：记录参数与凭据文件内容，并输出一个匹配地址中带有令牌的漏洞
+	script := `echo "$@" > "$(dirname "$0")/args"
+while [ $# -gt 0 ]; do [ "$1" = "-secret-file" ] && cp "$2" "$(dirname "$0")/secrets.yaml"; shift; done
+echo '{"template-id":"token-leak","host":"https://example.com","matched-at":"https://example.com/?token=bearer-token-value","info":{"name":"Token Leak","severity":"high"}}'`
+	service, targetID := newQueueTestScanService(t, db, script)
+	auth := newTestAuthService(t, db)
+	service.SetAuthService(auth)
+	ctx := context.Background()
+
+	if _, err := auth.Create(ctx, &models.AuthProfileRequest{
+		TargetID: targetID, Name: "token", Type: models.AuthTypeBearer, Token: "bearer-token-value", Enabled: true,
+	}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	task, err := service.Create(ctx, &CreateScanRequest{Name: "auth scan", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	dataDir := filepath.Dir(service.resumeDir)
+	secretFile := filepath.Join(service.secretsDir, "scan-1.yaml")
+	args, _ := os.ReadFile(filepath.Join(dataDir, "args"))
+	if !strings.Contains(string(args), "-secret-file "+secretFile) {
+		t.Errorf("nuclei args = %q, want -secret-file", args)
+	}
+	if strings.Contains(string(args), "bearer-token-value") {
+		t.Error("credentials must not be passed on the command line")
+	}
+	if data, err := os.ReadFile(filepath.Join(dataDir, "secrets.yaml")); err != nil || !strings.Contains(string(data), "bearer-token-value") {
+		t.Errorf("secret file content = %q, %v", data, err)
+	}
+	if _, err := os.Stat(secretFile); !os.IsNotExist(err) {
+		t.Errorf("secret file should be removed after scan, stat error = %v", err)
+	}
+
+	logs, err := service.GetLogs(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetLogs() failed: %v", err)
+	}
+	redacted := false
+	for _, log := range logs {
+		if strings.Contains(log.Message, "bearer-token-value") {
+			t.Errorf("scan log leaks credential: %q", log.Message)
+		}
+		redacted = redacted || strings.Contains(log.Message, "token=******")
+	}
+	if !redacted {
+		t.Errorf("scan logs = %+v, want redacted vulnerability log", logs)
+	}
+}
@@ -2,36 +2,114 @@ package svc
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/holehunter/holehunter/internal/brute"
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
 	"github.com/holehunter/holehunter/internal/models"
 	"github.com/holehunter/holehunter/internal/repo"
 )
 
+const (
+	// maxStoredBruteBodySize 每条结果保存的响应体上限
+	maxStoredBruteBodySize = 4096
+	// bruteProgressInterval 进度事件的最小发布间隔
+	bruteProgressInterval = time.Second
+)
+
 // BruteService 暴力破解服务
 type BruteService struct {
-	repo *repo.BruteRepository
+	repo        *repo.BruteRepository
+	requestRepo *repo.HTTPRequestRepository
+	eventBus    *event.Bus
+	logger      *logger.Logger
+	runner      *taskRunner
 }
 
 // NewBruteService 创建暴力破解服务
-func NewBruteService(repo *repo.BruteRepository) *BruteService {
-	return &BruteService{repo: repo}
+func NewBruteService(repo *repo.BruteRepository, requestRepo *repo.HTTPRequestRepository, eventBus *event.Bus, logger *logger.Logger) *BruteService {
+	return &BruteService{
+		repo:        repo,
+		requestRepo: requestRepo,
+		eventBus:    eventBus,
+		logger:      logger,
+		runner:      newTaskRunner("brute", repo, logger),
+	}
 }
 
 // CreateTask 创建任务
-func (s *BruteService) CreateTask(ctx context.Context, name string, requestID int, bruteType string) (int, error) {
-	if name == "" {
+func (s *BruteService) CreateTask(ctx context.Context, req *models.CreateBruteTaskRequest) (int, error) {
+	if req == nil {
+		return 0, errors.InvalidInput("request is required")
+	}
+	if req.Name == "" {
 		return 0, errors.InvalidInput("name is required")
 	}
-	if bruteType == "" {
+	if req.Type == "" {
 		return 0, errors.InvalidInput("type is required")
 	}
+	if req.RequestID <= 0 {
+		return 0, errors.InvalidInput("request_id is required")
+	}
+
+	attackMode := req.AttackMode
+	if attackMode == "" {
+		attackMode = attackModeFromType(req.Type)
+	}
+	if !brute.IsValidAttackMode(attackMode) {
+		return 0, errors.InvalidInput(fmt.Sprintf("invalid attack mode: %s", attackMode))
+	}
+	if err := brute.ValidatePayloadSetCount(attackMode, len(req.PayloadSetIDs)); err != nil {
+		return 0, err
+	}
+
+	request, err := s.requestRepo.GetByID(ctx, req.RequestID)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get http request")
+	}
+	tmpl, err := brute.ParseRequest(request)
+	if err != nil {
+		return 0, err
+	}
+	if err := brute.ValidateAttack(attackMode, len(tmpl.Positions), len(req.PayloadSetIDs)); err != nil {
+		return 0, err
+	}
+	for _, setID := range req.PayloadSetIDs {
+		if _, err := s.repo.GetPayloadSetByID(ctx, setID); err != nil {
+			return 0, errors.Wrap(err, "failed to get payload set")
+		}
+	}
+	if _, err := brute.NewMatcher(req.MatchRules, req.MatchMode); err != nil {
+		return 0, err
+	}
+
+	matchMode := req.MatchMode
+	if matchMode == "" {
+		matchMode = brute.MatchAny
+	}
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = brute.DefaultConcurrency
+	}
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = int(brute.DefaultTimeout / time.Millisecond)
+	}
 
 	task := &models.BruteTask{
-		Name:      name,
-		RequestID: requestID,
-		Type:      bruteType,
-		Status:    "pending",
+		Name:          req.Name,
+		RequestID:     req.RequestID,
+		Type:          req.Type,
+		AttackMode:    attackMode,
+		PayloadSetIDs: req.PayloadSetIDs,
+		Concurrency:   concurrency,
+		Timeout:       timeout,
+		MatchRules:    req.MatchRules,
+		MatchMode:     matchMode,
+		Status:        "pending",
 	}
 
 	if err := s.repo.CreateTask(ctx, task); err != nil {
@@ -59,6 +137,9 @@ func (s *BruteService) DeleteTask(ctx context.Context, id int) error {
 	if id <= 0 {
 		return errors.InvalidInput("invalid task id")
 	}
+	if s.runner.isRunning(id) {
+		return errors.Conflict("cannot delete running brute task, stop it first")
+	}
 	return s.repo.DeleteTask(ctx, id)
 }
 
@@ -76,6 +157,9 @@ func (s *BruteService) CreatePayloadSet(ctx context.Context, name string, bruteT
 		Type:   bruteType,
 		Config: config,
 	}
+	if _, err := brute.NewPayloadGenerator(set); err != nil {
+		return 0, err
+	}
 
 	if err := s.repo.CreatePayloadSet(ctx, set); err != nil {
 		return 0, err
@@ -100,29 +184,285 @@ func (s *BruteService) StartBruteTask(ctx context.Context, taskID int) error {
 		return err
 	}
 
-	if task.Status != "pending" {
-		return errors.InvalidInput("task is not in pending status")
+	runCtx, err := s.runner.begin(taskID, task.Status)
+	if err != nil {
+		return err
 	}
 
-	// 更新任务状态为 running
-	if err := s.repo.UpdateTaskStatus(ctx, taskID, "running"); err != nil {
+	tmpl, src, matcher, err := s.prepareTask(ctx, task)
+	if err != nil {
+		s.runner.finish(taskID)
 		return err
 	}
 
-	// TODO: 实现实际的暴力破解执行逻辑
-	// 这里应该启动一个 goroutine 来执行暴力破解
-	// 参考端口扫描的实现方式
+	if err := s.repo.DeleteResultsByTaskID(ctx, taskID); err != nil {
+		s.runner.finish(taskID)
+		return errors.Wrap(err, "failed to clear previous results")
+	}
+	if err := s.repo.MarkTaskStarted(ctx, taskID, src.Total()); err != nil {
+		s.runner.finish(taskID)
+		return errors.Wrap(err, "failed to update brute task")
+	}
+
+	s.eventBus.PublishAsync(runCtx, event.Event{
+		Type: event.EventBruteStarted,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+			"total":  src.Total(),
+		},
+	})
 
+	go s.runTask(runCtx, task, tmpl, src, matcher)
+
+	s.logger.Info("Brute task started: task_id=%d, mode=%s, positions=%d, total=%d", taskID, task.AttackMode, len(tmpl.Positions), src.Total())
 	return nil
 }
 
+// prepareTask 解析任务的请求模板并构建载荷来源与匹配器
+func (s *BruteService) prepareTask(ctx context.Context, task *models.BruteTask) (*brute.RequestTemplate, brute.Source, *brute.Matcher, error) {
+	request, err := s.requestRepo.GetByID(ctx, task.RequestID)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "failed to get http request")
+	}
+	if !allowedMethods[request.Method] {
+		return nil, nil, nil, errors.InvalidInput(fmt.Sprintf("invalid http method: %s", request.Method))
+	}
+
+	tmpl, err := brute.ParseRequest(request)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	src, err := s.buildSource(ctx, task, tmpl)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	matcher, err := brute.NewMatcher(task.MatchRules, task.MatchMode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return tmpl, src, matcher, nil
+}
+
+// StopBruteTask 停止暴力破解任务
+func (s *BruteService) StopBruteTask(ctx context.Context, taskID int) error {
+	if taskID <= 0 {
+		return errors.InvalidInput("invalid task id")
+	}
+
+	return s.runner.stop(taskID)
+}
+
 // GetBruteTaskResults 获取暴力破解任务结果
 func (s *BruteService) GetBruteTaskResults(ctx context.Context, taskID int) ([]*models.BruteResult, error) {
 	if taskID <= 0 {
 		return nil, errors.InvalidInput("invalid task id")
 	}
+	return s.repo.GetResultsByTaskID(ctx, taskID)
+}
+
+// GetBruteTaskHits 获取命中规则的暴力破解结果
+func (s *BruteService) GetBruteTaskHits(ctx context.Context, taskID int) ([]*models.BruteResult, error) {
+	if taskID <= 0 {
+		return nil, errors.InvalidInput("invalid task id")
+	}
+	return s.repo.GetSuccessResultsByTaskID(ctx, taskID)
+}
+
+// buildSource 根据任务的攻击模式和载荷集构建尝试来源
+func (s *BruteService) buildSource(ctx context.Context, task *models.BruteTask, tmpl *brute.RequestTemplate) (brute.Source, error) {
+	attackMode := task.AttackMode
+	if attackMode == "" {
+		attackMode = attackModeFromType(task.Type)
+	}
+	if err := brute.ValidateAttack(attackMode, len(tmpl.Positions), len(task.PayloadSetIDs)); err != nil {
+		return nil, err
+	}
+
+	sets := make([]brute.Generator, 0, len(task.PayloadSetIDs))
+	for _, setID := range task.PayloadSetIDs {
+		set, err := s.repo.GetPayloadSetByID(ctx, setID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get payload set")
+		}
+		gen, err := brute.NewPayloadGenerator(set)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, gen)
+	}
+
+	return brute.NewSource(attackMode, tmpl, sets)
+}
+
+// attackModeFromType 兼容旧的任务类型，推断攻击模式
+func attackModeFromType(bruteType string) string {
+	switch bruteType {
+	case "multi-pitchfork":
+		return brute.AttackPitchfork
+	case "multi-cluster":
+		return brute.AttackClusterBomb
+	default:
+		return brute.AttackSniper
+	}
+}
+
+// runTask 执行暴力破解任务
+func (s *BruteService) runTask(ctx context.Context, task *models.BruteTask, tmpl *brute.RequestTemplate, src brute.Source, matcher *brute.Matcher) {
+	defer s.runner.finish(task.ID)
+
+	engine := brute.NewEngine(brute.Config{
+		Concurrency: task.Concurrency,
+		Timeout:     time.Duration(task.Timeout) * time.Millisecond,
+	})
+
+	// 结果持久化失败时中止任务
+	runCtx, abort := context.WithCancel(ctx)
+	defer abort()
+
+	dbCtx := context.Background()
+
+	// 长度差规则以默认值请求的响应为基线，基线请求失败时无法判定，任务直接失败
+	if matcher.NeedsBaseline() {
+		if err := matcher.SetBaseline(engine.Baseline(runCtx, tmpl)); err != nil {
+			if ctx.Err() != nil {
+				s.runner.markFinished(dbCtx, task.ID, "stopped")
+				return
+			}
+			s.failTask(dbCtx, task.ID, err)
+			return
+		}
+	}
+
+	total := src.Total()
+	var sent, success, failure int
+	var persistErr error
+	lastPublish := time.Now()
+
+	runErr := engine.Run(runCtx, tmpl, src, func(r *brute.Result) {
+		// 停止时被中断的请求不计入结果
+		if r.Err != nil && runCtx.Err() != nil {
+			return
+		}
+
+		result := s.toBruteResult(task.ID, r, matcher)
+		sent++
+		if result.Success {
+			success++
+		} else {
+			failure++
+		}
+
+		if err := s.repo.CreateResult(dbCtx, result); err != nil && persistErr == nil {
+			persistErr = err
+			abort()
+			return
+		}
+
+		if time.Since(lastPublish) >= bruteProgressInterval {
+			lastPublish = time.Now()
+			if err := s.repo.UpdateTaskCounters(dbCtx, task.ID, sent, success, failure); err != nil {
+				s.logger.Warn("Failed to update brute task counters: task_id=%d, error=%v", task.ID, err)
+			}
+			s.publishProgress(ctx, task.ID, total, sent, success, failure)
+		}
+	})
+
+	if err := s.repo.UpdateTaskCounters(dbCtx, task.ID, sent, success, failure); err != nil {
+		s.logger.Warn("Failed to update brute task counters: task_id=%d, error=%v", task.ID, err)
+	}
+	s.publishProgress(dbCtx, task.ID, total, sent, success, failure)
+
+	// 未被停止却中断时（结果持久化或载荷读取失败）视为失败
+	failErr := persistErr
+	if failErr == nil && runErr != nil && ctx.Err() == nil {
+		failErr = runErr
+	}
+
+	switch {
+	case failErr != nil:
+		s.failTask(dbCtx, task.ID, failErr)
+	default:
+		status := "completed"
+		if runErr != nil {
+			status = "stopped"
+		}
+		s.logger.Info("Brute task %s: task_id=%d, sent=%d, success=%d, failure=%d", status, task.ID, sent, success, failure)
+		s.runner.markFinished(dbCtx, task.ID, status)
+		s.eventBus.PublishAsync(dbCtx, event.Event{
+			Type: event.EventBruteCompleted,
+			Data: map[string]interface{}{
+				"taskId":       task.ID,
+				"status":       status,
+				"sent":         sent,
+				"successCount": success,
+				"failureCount": failure,
+			},
+		})
+	}
+}
+
+// failTask 将任务标记为失败并发布失败事件
+func (s *BruteService) failTask(ctx context.Context, taskID int, err error) {
+	s.logger.Error("Brute task failed: task_id=%d, error=%v", taskID, err)
+	s.runner.markFinished(ctx, taskID, "failed")
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventBruteFailed,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+			"error":  errors.SanitizeUserError(err),
+		},
+	})
+}
+
+// toBruteResult 将引擎结果转换为持久化模型，由匹配规则判定是否成功
+func (s *BruteService) toBruteResult(taskID int, r *brute.Result, matcher *brute.Matcher) *models.BruteResult {
+	result := &models.BruteResult{
+		TaskID:         taskID,
+		ParamName:      r.Attempt.ParamName,
+		Payload:        r.Attempt.Payload,
+		Status:         models.BruteResultFailed,
+		StatusCode:     r.StatusCode,
+		ResponseLength: r.Length,
+		ResponseTime:   r.Duration.Milliseconds(),
+	}
+
+	if r.Err != nil {
+		result.Error = errors.SanitizeUserError(r.Err)
+		return result
+	}
+
+	if rule, ok := matcher.Match(r); ok {
+		result.Status = models.BruteResultSuccess
+		result.Success = true
+		result.MatchedRule = rule
+	}
+
+	body := r.Body
+	if len(body) > maxStoredBruteBodySize {
+		body = body[:maxStoredBruteBodySize]
+	}
+	result.Body = string(body)
+	return result
+}
+
+// publishProgress 发布进度事件
+func (s *BruteService) publishProgress(ctx context.Context, taskID, total, sent, success, failure int) {
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventBruteProgress,
+		Data: map[string]interface{}{
+			"taskId":       taskID,
+			"total":        total,
+			"sent":         sent,
+			"successCount": success,
+			"failureCount": failure,
+		},
+	})
+}
 
-	// TODO: 从数据库或内存中获取结果
-	// 当前返回空数组，实际应该从 repo 获取
-	return []*models.BruteResult{}, nil
+// RecoverInterrupted 将上次退出时仍处于 running 状态的暴力破解任务标记为 stopped，使其可以重新启动
+// 应在启动任何任务之前调用
+func (s *BruteService) RecoverInterrupted(ctx context.Context) (int, error) {
+	return s.runner.recoverInterrupted(ctx)
 }
@@ -3,8 +3,16 @@ package svc
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
 	"github.com/holehunter/holehunter/internal/repo"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -14,8 +22,7 @@ func TestBruteService_StartBruteTask(t *testing.T) {
 	db := setupBruteTestDB(t)
 	defer db.Close()
 
-	bruteRepo := repo.NewBruteRepository(db)
-	service := NewBruteService(bruteRepo)
+	service := newTestBruteService(db)
 	ctx := context.Background()
 
 	// 测试无效 ID
@@ -36,8 +43,7 @@ func TestBruteService_GetBruteTaskResults(t *testing.T) {
 	db := setupBruteTestDB(t)
 	defer db.Close()
 
-	bruteRepo := repo.NewBruteRepository(db)
-	service := NewBruteService(bruteRepo)
+	service := newTestBruteService(db)
 	ctx := context.Background()
 
 	// 测试无效 ID
@@ -46,7 +52,7 @@ func TestBruteService_GetBruteTaskResults(t *testing.T) {
 		t.Error("GetBruteTaskResults(-1) should return error for invalid ID")
 	}
 
-	// 测试不存在的任务 - 当前实现返回空数组
+	// 测试不存在的任务 - 返回空数组
 	results, err := service.GetBruteTaskResults(ctx, 999)
 	if err != nil {
 		t.Errorf("GetBruteTaskResults(999) unexpected error: %v", err)
@@ -61,25 +67,60 @@ func TestBruteService_CreateTask(t *testing.T) {
 	db := setupBruteTestDB(t)
 	defer db.Close()
 
-	bruteRepo := repo.NewBruteRepository(db)
-	service := NewBruteService(bruteRepo)
+	service := newTestBruteService(db)
 	ctx := context.Background()
 
+	singleID := createTestBruteRequest(t, db, "http://example.com/login?password=§x§")
+	multiID := createTestBruteRequest(t, db, "http://example.com/login?user=§u§&password=§p§")
+	setA := createTestPayloadSet(t, service, "a")
+	setB := createTestPayloadSet(t, service, "b")
+
 	tests := []struct {
-		name      string
-		taskName  string
-		requestID int
-		bruteType string
-		wantErr   bool
+		name          string
+		taskName      string
+		requestID     int
+		bruteType     string
+		attackMode    string
+		payloadSetIDs []int
+		wantErr       bool
 	}{
-		{"正常创建", "Test Brute", 1, "form", false},
-		{"空名称", "", 1, "form", true},
-		{"空类型", "Test", 1, "", true},
+		{"正常创建", "Test Brute", singleID, "form", "", []int{setA}, false},
+		{"空名称", "", singleID, "form", "", []int{setA}, true},
+		{"空类型", "Test", singleID, "", "", []int{setA}, true},
+		{"缺少请求", "Test", 0, "form", "", []int{setA}, true},
+		{"请求不存在", "Test", 999, "form", "", []int{setA}, true},
+		{"缺少载荷集", "Test", singleID, "form", "", nil, true},
+		{"载荷集不存在", "Test", singleID, "form", "", []int{999}, true},
+		{"无效攻击模式", "Test", singleID, "form", "unknown", []int{setA}, true},
+		{"battering ram", "Test", multiID, "form", "battering_ram", []int{setA}, false},
+		{"sniper 多个载荷集", "Test", multiID, "form", "sniper", []int{setA, setB}, true},
+		{"pitchfork", "Test", multiID, "form", "pitchfork", []int{setA, setB}, false},
+		{"pitchfork 载荷集不足", "Test", multiID, "form", "pitchfork", []int{setA}, true},
+		{"cluster bomb 由类型推断", "Test", multiID, "multi-cluster", "", []int{setA, setB}, false},
+		{"cluster bomb 位置不足", "Test", singleID, "form", "cluster_bomb", []int{setA, setB}, true},
+	}
+
+	// 无效的匹配规则
+	_, err := service.CreateTask(ctx, &models.CreateBruteTaskRequest{
+		Name:          "Test",
+		RequestID:     singleID,
+		Type:          "form",
+		PayloadSetIDs: []int{setA},
+		MatchRules:    []models.BruteMatchRule{{Type: "body"}},
+	})
+	if err == nil {
+		t.Error("CreateTask() should reject a body rule without pattern")
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			id, err := service.CreateTask(ctx, tt.taskName, tt.requestID, tt.bruteType)
+			id, err := service.CreateTask(ctx, &models.CreateBruteTaskRequest{
+				Name:          tt.taskName,
+				RequestID:     tt.requestID,
+				Type:          tt.bruteType,
+				AttackMode:    tt.attackMode,
+				PayloadSetIDs: tt.payloadSetIDs,
+			})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateTask() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -90,17 +131,53 @@ func TestBruteService_CreateTask(t *testing.T) {
 	}
 }
 
+// TestBruteService_CreatePayloadSet 测试创建载荷集时校验配置
+func TestBruteService_CreatePayloadSet(t *testing.T) {
+	db := setupBruteTestDB(t)
+	defer db.Close()
+
+	service := newTestBruteService(db)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		setName string
+		setType string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{"字典", "words", "dictionary", map[string]interface{}{"payloads": []interface{}{"a"}}, false},
+		{"数字范围", "pins", "number", map[string]interface{}{"from": 0, "to": 9999, "format": "%04d"}, false},
+		{"空名称", "", "dictionary", map[string]interface{}{"payloads": []interface{}{"a"}}, true},
+		{"未知类型", "x", "unknown", nil, true},
+		{"缺少字符集", "x", "charset", map[string]interface{}{"max_length": 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreatePayloadSet(ctx, tt.setName, tt.setType, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreatePayloadSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestBruteService_GetTaskByID 测试获取任务
 func TestBruteService_GetTaskByID(t *testing.T) {
 	db := setupBruteTestDB(t)
 	defer db.Close()
 
-	bruteRepo := repo.NewBruteRepository(db)
-	service := NewBruteService(bruteRepo)
+	service := newTestBruteService(db)
 	ctx := context.Background()
 
 	// 先创建一个任务
-	taskID, _ := service.CreateTask(ctx, "Test Task", 1, "form")
+	taskID, _ := service.CreateTask(ctx, &models.CreateBruteTaskRequest{
+		Name:          "Test Task",
+		RequestID:     createTestBruteRequest(t, db, "http://example.com/?q=§x§"),
+		Type:          "form",
+		PayloadSetIDs: []int{createTestPayloadSet(t, service, "a")},
+	})
 
 	// 测试获取存在的任务
 	task, err := service.GetTaskByID(ctx, taskID)
@@ -118,6 +195,251 @@ func TestBruteService_GetTaskByID(t *testing.T) {
 	}
 }
 
+// TestBruteService_RunTask 测试暴力破解任务的完整执行
+func TestBruteService_RunTask(t *testing.T) {
+	db := setupBruteTestDB(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("password") == "secret" {
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, "invalid credentials")
+	}))
+	defer server.Close()
+
+	service := newTestBruteService(db)
+	ctx := context.Background()
+
+	httpRepo := repo.NewHTTPRequestRepository(db)
+	request := &models.HttpRequest{
+		Name:   "login",
+		Method: "GET",
+		URL:    server.URL + "/login?user=admin&password=§x§",
+	}
+	if err := httpRepo.Create(ctx, request); err != nil {
+		t.Fatalf("failed to create http request: %v", err)
+	}
+
+	setID, err := service.CreatePayloadSet(ctx, "passwords", "dictionary", map[string]interface{}{
+		"payloads": []interface{}{"123456", "secret", "admin"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePayloadSet() failed: %v", err)
+	}
+
+	taskID, err := service.CreateTask(ctx, &models.CreateBruteTaskRequest{
+		Name:          "login brute",
+		RequestID:     request.ID,
+		Type:          "form",
+		PayloadSetIDs: []int{setID},
+		Concurrency:   2,
+		MatchRules: []models.BruteMatchRule{
+			{Name: "redirect", Type: "status", Codes: []int{http.StatusFound}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+
+	if err := service.StartBruteTask(ctx, taskID); err != nil {
+		t.Fatalf("StartBruteTask() failed: %v", err)
+	}
+
+	task := waitBruteTask(t, service, taskID)
+	if task.Status != "completed" {
+		t.Fatalf("task status = %s, want completed", task.Status)
+	}
+	if task.TotalPayloads != 3 || task.SentPayloads != 3 {
+		t.Errorf("total/sent = %d/%d, want 3/3", task.TotalPayloads, task.SentPayloads)
+	}
+	if task.SuccessCount != 1 || task.FailureCount != 2 {
+		t.Errorf("success/failure = %d/%d, want 1/2", task.SuccessCount, task.FailureCount)
+	}
+
+	results, err := service.GetBruteTaskResults(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetBruteTaskResults() failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	codes := make(map[string]int)
+	for _, r := range results {
+		codes[r.Payload] = r.StatusCode
+		if r.ParamName != "url" {
+			t.Errorf("ParamName = %q, want url", r.ParamName)
+		}
+	}
+	if codes["secret"] != http.StatusFound {
+		t.Errorf("status for payload secret = %d, want 302", codes["secret"])
+	}
+	if codes["123456"] != http.StatusUnauthorized {
+		t.Errorf("status for payload 123456 = %d, want 401", codes["123456"])
+	}
+
+	hits, err := service.GetBruteTaskHits(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetBruteTaskHits() failed: %v", err)
+	}
+	if len(hits) != 1 || hits[0].Payload != "secret" || hits[0].MatchedRule != "redirect" || !hits[0].Success {
+		t.Errorf("hits = %+v, want single hit for payload secret", hits)
+	}
+
+	// 已完成的任务不能重新启动，结果保留
+	if err := service.StartBruteTask(ctx, taskID); err == nil || !strings.Contains(err.Error(), "is completed, cannot start") {
+		t.Errorf("StartBruteTask() for completed task = %v, want conflict", err)
+	}
+	if results, _ := service.GetBruteTaskResults(ctx, taskID); len(results) != 3 {
+		t.Errorf("results after rejected restart = %d, want 3", len(results))
+	}
+}
+
+// TestBruteService_RunTaskBaselineFailed 测试长度差规则的基线请求失败时任务失败且不发送载荷
+func TestBruteService_RunTaskBaselineFailed(t *testing.T) {
+	db := setupBruteTestDB(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL + "/?p=§x§"
+	server.Close()
+
+	service := newTestBruteService(db)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, &models.CreateBruteTaskRequest{
+		Name:          "baseline",
+		RequestID:     createTestBruteRequest(t, db, url),
+		Type:          "form",
+		PayloadSetIDs: []int{createTestPayloadSet(t, service, "a", "b")},
+		MatchMode:     "all",
+		MatchRules: []models.BruteMatchRule{
+			{Type: "status", Codes: []int{http.StatusOK}},
+			{Type: "length", MinDelta: 10},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+
+	if err := service.StartBruteTask(ctx, taskID); err != nil {
+		t.Fatalf("StartBruteTask() failed: %v", err)
+	}
+	task := waitBruteTask(t, service, taskID)
+	if task.Status != "failed" {
+		t.Errorf("task status = %s, want failed", task.Status)
+	}
+	if task.SentPayloads != 0 {
+		t.Errorf("sent payloads = %d, want 0", task.SentPayloads)
+	}
+	if task.MatchMode != "all" {
+		t.Errorf("match mode = %q, want all", task.MatchMode)
+	}
+}
+
+// TestBruteService_RecoverInterrupted 测试上次异常退出遗留的 running 任务可以重新启动
+func TestBruteService_RecoverInterrupted(t *testing.T) {
+	db := setupBruteTestDB(t)
+	defer db.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	service := newTestBruteService(db)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, &models.CreateBruteTaskRequest{
+		Name:          "stale",
+		RequestID:     createTestBruteRequest(t, db, server.URL+"/?p=§x§"),
+		Type:          "form",
+		PayloadSetIDs: []int{createTestPayloadSet(t, service, "a")},
+	})
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE brute_tasks SET status = 'running' WHERE id = ?", taskID); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// 数据库中为 running 但没有在运行的任务可以直接重新启动
+	if err := service.StartBruteTask(ctx, taskID); err != nil {
+		t.Fatalf("StartBruteTask() for stale running task failed: %v", err)
+	}
+	if task := waitBruteTask(t, service, taskID); task.Status != "completed" {
+		t.Errorf("task status = %s, want completed", task.Status)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE brute_tasks SET status = 'running' WHERE id = ?", taskID); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	n, err := service.RecoverInterrupted(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("RecoverInterrupted() = %d, %v, want 1", n, err)
+	}
+	task, err := service.GetTaskByID(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetTaskByID() failed: %v", err)
+	}
+	if task.Status != "stopped" {
+		t.Errorf("task status = %s, want stopped", task.Status)
+	}
+}
+
+// newTestBruteService 创建测试用暴力破解服务
+func newTestBruteService(db *sql.DB) *BruteService {
+	return NewBruteService(
+		repo.NewBruteRepository(db),
+		repo.NewHTTPRequestRepository(db),
+		event.NewBus(),
+		logger.New("error", ""),
+	)
+}
+
+// createTestBruteRequest 创建带载荷标记的测试请求
+func createTestBruteRequest(t *testing.T, db *sql.DB, url string) int {
+	t.Helper()
+	request := &models.HttpRequest{Name: "test", Method: "GET", URL: url}
+	if err := repo.NewHTTPRequestRepository(db).Create(context.Background(), request); err != nil {
+		t.Fatalf("failed to create http request: %v", err)
+	}
+	return request.ID
+}
+
+// createTestPayloadSet 创建测试载荷集
+func createTestPayloadSet(t *testing.T, service *BruteService, payloads ...string) int {
+	t.Helper()
+	items := make([]interface{}, len(payloads))
+	for i, p := range payloads {
+		items[i] = p
+	}
+	id, err := service.CreatePayloadSet(context.Background(), "test", "dictionary", map[string]interface{}{"payloads": items})
+	if err != nil {
+		t.Fatalf("CreatePayloadSet() failed: %v", err)
+	}
+	return id
+}
+
+// waitBruteTask 等待任务结束
+func waitBruteTask(t *testing.T, service *BruteService, taskID int) *models.BruteTask {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if !service.runner.isRunning(taskID) {
+			task, err := service.GetTaskByID(context.Background(), taskID)
+			if err != nil {
+				t.Fatalf("GetTaskByID() failed: %v", err)
+			}
+			return task
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("brute task %d did not finish in time", taskID)
+	return nil
+}
+
 // setupBruteTestDB 创建测试数据库
 func setupBruteTestDB(t *testing.T) *sql.DB {
 	t.Helper()
@@ -125,23 +447,44 @@ func setupBruteTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
+	// 内存数据库每个连接相互独立，任务 goroutine 需要共用同一连接
+	db.SetMaxOpenConns(1)
 
-	// 创建测试表结构
+	// 创建测试表结构（与迁移保持一致）
 	schema := `
+	CREATE TABLE http_requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		method TEXT NOT NULL,
+		url TEXT NOT NULL,
+		headers TEXT,
+		body TEXT,
+		content_type TEXT,
+		tags TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
 	CREATE TABLE brute_tasks (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT,
+		name TEXT NOT NULL,
 		request_id INTEGER,
-		type TEXT,
-		status TEXT,
+		type TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
 		total_payloads INTEGER DEFAULT 0,
 		sent_payloads INTEGER DEFAULT 0,
 		success_count INTEGER DEFAULT 0,
 		failure_count INTEGER DEFAULT 0,
-		started_at TEXT,
-		completed_at TEXT,
-		created_at TEXT,
-		updated_at TEXT
+		started_at DATETIME,
+		completed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		payload_set_ids TEXT DEFAULT '[]',
+		concurrency INTEGER DEFAULT 10,
+		timeout INTEGER DEFAULT 10000,
+		attack_mode TEXT DEFAULT 'sniper',
+		match_rules TEXT DEFAULT '[]',
+		match_mode TEXT DEFAULT 'any'
 	);
 
 	CREATE TABLE brute_payload_sets (
@@ -149,19 +492,22 @@ func setupBruteTestDB(t *testing.T) *sql.DB {
 		name TEXT NOT NULL,
 		type TEXT NOT NULL,
 		config TEXT,
-		created_at TEXT
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 	);
 
 	CREATE TABLE brute_results (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		task_id INTEGER NOT NULL,
-		payload TEXT,
-		result TEXT,
-		success BOOLEAN DEFAULT 0,
-		response_time INTEGER,
+		param_name TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		status_code INTEGER,
+		response_length INTEGER,
+		response_time INTEGER NOT NULL,
+		body TEXT,
 		error TEXT,
-		timestamp TEXT,
-		metadata TEXT
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		matched_rule TEXT
 	);
 	`
 
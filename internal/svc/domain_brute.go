@@ -2,30 +2,55 @@ package svc
 
 import (
 	"context"
+	"time"
 
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
 	"github.com/holehunter/holehunter/internal/models"
 	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/subdomain"
 )
 
+// domainBruteProgressInterval 进度事件的最小发布间隔
+const domainBruteProgressInterval = time.Second
+
 // DomainBruteService 域名暴力破解服务
 type DomainBruteService struct {
-	repo *repo.DomainBruteRepository
+	repo     *repo.DomainBruteRepository
+	eventBus *event.Bus
+	logger   *logger.Logger
+	config   *config.Config
+	runner   *taskRunner
 }
 
 // NewDomainBruteService 创建域名暴力破解服务
-func NewDomainBruteService(repo *repo.DomainBruteRepository) *DomainBruteService {
-	return &DomainBruteService{repo: repo}
+func NewDomainBruteService(repo *repo.DomainBruteRepository, eventBus *event.Bus, logger *logger.Logger, cfg *config.Config) *DomainBruteService {
+	return &DomainBruteService{
+		repo:     repo,
+		eventBus: eventBus,
+		logger:   logger,
+		config:   cfg,
+		runner:   newTaskRunner("domain brute", repo, logger),
+	}
 }
 
 // CreateTask 创建任务
 func (s *DomainBruteService) CreateTask(ctx context.Context, domain string, wordlist []string, timeout, batchSize int) (int, error) {
+	domain = subdomain.NormalizeDomain(domain)
 	if domain == "" {
 		return 0, errors.InvalidInput("domain is required")
 	}
-	if len(wordlist) == 0 {
+	if len(subdomain.Candidates(domain, wordlist)) == 0 {
 		return 0, errors.InvalidInput("wordlist is required")
 	}
+	if timeout <= 0 {
+		timeout = int(subdomain.DefaultTimeout / time.Millisecond)
+	}
+	if batchSize <= 0 {
+		batchSize = subdomain.DefaultBatchSize
+	}
 
 	task := &models.DomainBruteTask{
 		Domain:    domain,
@@ -67,3 +92,190 @@ func (s *DomainBruteService) GetResults(ctx context.Context, taskID int) ([]*mod
 func (s *DomainBruteService) CreateResult(ctx context.Context, result *models.DomainBruteResult) error {
 	return s.repo.CreateResult(ctx, result)
 }
+
+// StartTask 启动子域名爆破任务
+func (s *DomainBruteService) StartTask(ctx context.Context, taskID int) error {
+	if taskID <= 0 {
+		return errors.InvalidInput("invalid task id")
+	}
+
+	task, err := s.repo.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	runCtx, err := s.runner.begin(taskID, task.Status)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteResultsByTaskID(ctx, taskID); err != nil {
+		s.runner.finish(taskID)
+		return errors.Wrap(err, "failed to clear previous results")
+	}
+	if err := s.repo.MarkTaskStarted(ctx, taskID); err != nil {
+		s.runner.finish(taskID)
+		return errors.Wrap(err, "failed to update domain brute task")
+	}
+
+	total := len(subdomain.Candidates(task.Domain, task.Wordlist))
+	s.eventBus.PublishAsync(runCtx, event.Event{
+		Type: event.EventDomainBruteStarted,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+			"domain": task.Domain,
+			"total":  total,
+		},
+	})
+
+	go s.runTask(runCtx, task)
+
+	s.logger.Info("Domain brute task started: task_id=%d, domain=%s, words=%d", taskID, task.Domain, total)
+	return nil
+}
+
+// StopTask 停止子域名爆破任务
+func (s *DomainBruteService) StopTask(ctx context.Context, taskID int) error {
+	if taskID <= 0 {
+		return errors.InvalidInput("invalid task id")
+	}
+
+	return s.runner.stop(taskID)
+}
+
+// runTask 执行子域名爆破任务
+func (s *DomainBruteService) runTask(ctx context.Context, task *models.DomainBruteTask) {
+	defer s.runner.finish(task.ID)
+
+	resolver := subdomain.NewResolver(subdomain.Config{
+		Server:    s.resolverAddr(),
+		Timeout:   time.Duration(task.Timeout) * time.Millisecond,
+		BatchSize: task.BatchSize,
+	})
+
+	// 结果持久化失败时中止任务
+	runCtx, abort := context.WithCancel(ctx)
+	defer abort()
+
+	dbCtx := context.Background()
+	wildcard := s.detectWildcard(runCtx, resolver, task)
+
+	total := len(subdomain.Candidates(task.Domain, task.Wordlist))
+	var scanned, resolved, wildcards, errCount int
+	var persistErr error
+	lastPublish := time.Now()
+
+	runErr := resolver.Brute(runCtx, task.Domain, task.Wordlist, wildcard, func(r *subdomain.Result) {
+		scanned++
+		if r.Err != nil {
+			errCount++
+		}
+		if r.Resolved() {
+			// 泛解析命中的结果只做标记，不计入解析成功数
+			if r.Wildcard {
+				wildcards++
+			} else {
+				resolved++
+			}
+			result := &models.DomainBruteResult{
+				TaskID:    task.ID,
+				Subdomain: r.Subdomain,
+				Resolved:  true,
+				IPs:       r.IPs,
+				CNAME:     r.CNAME,
+				Latency:   int(r.Latency.Milliseconds()),
+				Wildcard:  r.Wildcard,
+			}
+			if err := s.repo.CreateResult(dbCtx, result); err != nil && persistErr == nil {
+				persistErr = err
+				abort()
+				return
+			}
+		}
+
+		if time.Since(lastPublish) >= domainBruteProgressInterval {
+			lastPublish = time.Now()
+			s.publishProgress(ctx, task.ID, total, scanned, resolved)
+		}
+	})
+
+	s.publishProgress(dbCtx, task.ID, total, scanned, resolved)
+
+	switch {
+	case persistErr != nil:
+		s.logger.Error("Domain brute task failed: task_id=%d, error=%v", task.ID, persistErr)
+		s.runner.markFinished(dbCtx, task.ID, "failed")
+		s.eventBus.PublishAsync(dbCtx, event.Event{
+			Type: event.EventDomainBruteFailed,
+			Data: map[string]interface{}{
+				"taskId": task.ID,
+				"error":  errors.SanitizeUserError(persistErr),
+			},
+		})
+	default:
+		status := "completed"
+		if runErr != nil {
+			status = "stopped"
+		}
+		s.logger.Info("Domain brute task %s: task_id=%d, scanned=%d, resolved=%d, wildcard=%d, errors=%d", status, task.ID, scanned, resolved, wildcards, errCount)
+		s.runner.markFinished(dbCtx, task.ID, status)
+		s.eventBus.PublishAsync(dbCtx, event.Event{
+			Type: event.EventDomainBruteCompleted,
+			Data: map[string]interface{}{
+				"taskId":   task.ID,
+				"status":   status,
+				"scanned":  scanned,
+				"resolved": resolved,
+				"wildcard": wildcards,
+				"errors":   errCount,
+			},
+		})
+	}
+}
+
+// detectWildcard 检测并保存域名的泛解析签名，检测失败时不过滤结果
+func (s *DomainBruteService) detectWildcard(ctx context.Context, resolver *subdomain.Resolver, task *models.DomainBruteTask) *subdomain.Wildcard {
+	wildcard, err := resolver.DetectWildcard(ctx, task.Domain, subdomain.DefaultWildcardProbes)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("Wildcard detection failed: task_id=%d, domain=%s, error=%v", task.ID, task.Domain, err)
+		}
+		return nil
+	}
+	if wildcard == nil {
+		return nil
+	}
+
+	s.logger.Info("Wildcard DNS detected: task_id=%d, domain=%s, ips=%v, cnames=%v", task.ID, task.Domain, wildcard.IPs, wildcard.CNAMEs)
+	signature := &models.DomainWildcard{IPs: wildcard.IPs, CNAMEs: wildcard.CNAMEs}
+	if err := s.repo.UpdateTaskWildcard(context.Background(), task.ID, signature); err != nil {
+		s.logger.Error("Failed to save wildcard signature: task_id=%d, error=%v", task.ID, err)
+	}
+	return wildcard
+}
+
+// resolverAddr 返回配置的 DNS 服务器地址
+func (s *DomainBruteService) resolverAddr() string {
+	if s.config == nil {
+		return ""
+	}
+	return s.config.DNSResolver
+}
+
+// publishProgress 发布进度事件
+func (s *DomainBruteService) publishProgress(ctx context.Context, taskID, total, scanned, resolved int) {
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventDomainBruteProgress,
+		Data: map[string]interface{}{
+			"taskId":   taskID,
+			"total":    total,
+			"scanned":  scanned,
+			"resolved": resolved,
+		},
+	})
+}
+
+// RecoverInterrupted 将上次退出时仍处于 running 状态的子域名爆破任务标记为 stopped，使其可以重新启动
+// 应在启动任何任务之前调用
+func (s *DomainBruteService) RecoverInterrupted(ctx context.Context) (int, error) {
+	return s.runner.recoverInterrupted(ctx)
+}
@@ -0,0 +1,260 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/testutil"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestDomainBruteService_CreateTask 测试创建子域名爆破任务
+func TestDomainBruteService_CreateTask(t *testing.T) {
+	db := setupDomainBruteTestDB(t)
+	defer db.Close()
+
+	service := newTestDomainBruteService(db, "")
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		domain   string
+		wordlist []string
+		wantErr  bool
+	}{
+		{"正常创建", "Example.COM.", []string{"www", "api"}, false},
+		{"空域名", " ", []string{"www"}, true},
+		{"空字典", "example.com", []string{"", "# comment"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := service.CreateTask(ctx, tt.domain, tt.wordlist, 0, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				task, err := service.GetTaskByID(ctx, id)
+				if err != nil {
+					t.Fatalf("GetTaskByID() failed: %v", err)
+				}
+				if task.Domain != "example.com" || task.Timeout != 2000 || task.BatchSize != 50 {
+					t.Errorf("task = %+v, want normalized domain and defaults", task)
+				}
+			}
+		})
+	}
+}
+
+// TestDomainBruteService_RunTask 测试子域名爆破任务的完整执行
+func TestDomainBruteService_RunTask(t *testing.T) {
+	db := setupDomainBruteTestDB(t)
+	defer db.Close()
+
+	dns := testutil.NewDNSServer(t, map[string][]string{
+		"www.example.test": {"10.0.0.1"},
+		"vpn.example.test": {"10.0.0.2", "10.0.0.3"},
+	})
+
+	service := newTestDomainBruteService(db, dns.Addr)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, "example.test", []string{"www", "vpn", "nope"}, 1000, 2)
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	if err := service.StartTask(ctx, taskID); err != nil {
+		t.Fatalf("StartTask() failed: %v", err)
+	}
+
+	task := waitDomainBruteTask(t, service, taskID)
+	if task.Status != "completed" {
+		t.Errorf("task status = %s, want completed", task.Status)
+	}
+	if task.StartedAt == "" || task.CompletedAt == "" {
+		t.Errorf("timestamps not set: started=%q completed=%q", task.StartedAt, task.CompletedAt)
+	}
+
+	results, err := service.GetResults(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetResults() failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("results = %d, want 2 resolved subdomains", len(results))
+	}
+	if results[0].Subdomain != "vpn.example.test" || !results[0].Resolved ||
+		!reflect.DeepEqual(results[0].IPs, []string{"10.0.0.2", "10.0.0.3"}) {
+		t.Errorf("result = %+v", results[0])
+	}
+
+	// 未运行的任务无法停止
+	if err := service.StopTask(ctx, taskID); err == nil {
+		t.Error("StopTask() should fail for finished task")
+	}
+
+	// 已完成的任务不能重新启动，结果保留
+	if err := service.StartTask(ctx, taskID); err == nil || !strings.Contains(err.Error(), "is completed, cannot start") {
+		t.Errorf("StartTask() for completed task = %v, want conflict", err)
+	}
+	if results, _ := service.GetResults(ctx, taskID); len(results) != 2 {
+		t.Errorf("results after rejected restart = %d, want 2", len(results))
+	}
+}
+
+// TestDomainBruteService_RecoverInterrupted 测试上次异常退出遗留的 running 任务可以重新启动
+func TestDomainBruteService_RecoverInterrupted(t *testing.T) {
+	db := setupDomainBruteTestDB(t)
+	defer db.Close()
+
+	dns := testutil.NewDNSServer(t, map[string][]string{"www.example.test": {"10.0.0.1"}})
+	service := newTestDomainBruteService(db, dns.Addr)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, "example.test", []string{"www"}, 1000, 1)
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE domain_brute_tasks SET status = 'running' WHERE id = ?", taskID); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// 数据库中为 running 但没有在运行的任务可以直接重新启动
+	if err := service.StartTask(ctx, taskID); err != nil {
+		t.Fatalf("StartTask() for stale running task failed: %v", err)
+	}
+	if task := waitDomainBruteTask(t, service, taskID); task.Status != "completed" {
+		t.Errorf("task status = %s, want completed", task.Status)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE domain_brute_tasks SET status = 'running' WHERE id = ?", taskID); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	n, err := service.RecoverInterrupted(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("RecoverInterrupted() = %d, %v, want 1", n, err)
+	}
+	task, err := service.GetTaskByID(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetTaskByID() failed: %v", err)
+	}
+	if task.Status != "stopped" {
+		t.Errorf("task status = %s, want stopped", task.Status)
+	}
+}
+
+// TestDomainBruteService_Wildcard 测试泛解析域名的结果标记
+func TestDomainBruteService_Wildcard(t *testing.T) {
+	db := setupDomainBruteTestDB(t)
+	defer db.Close()
+
+	dns := testutil.NewDNSServer(t, map[string][]string{
+		"*.wild.test":   {"10.1.1.1"},
+		"www.wild.test": {"10.2.2.2"},
+	})
+
+	service := newTestDomainBruteService(db, dns.Addr)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, "wild.test", []string{"www", "foo", "bar"}, 1000, 2)
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	if err := service.StartTask(ctx, taskID); err != nil {
+		t.Fatalf("StartTask() failed: %v", err)
+	}
+
+	task := waitDomainBruteTask(t, service, taskID)
+	if task.Wildcard == nil || !reflect.DeepEqual(task.Wildcard.IPs, []string{"10.1.1.1"}) {
+		t.Errorf("task wildcard = %+v, want signature 10.1.1.1", task.Wildcard)
+	}
+
+	results, err := service.GetResults(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetResults() failed: %v", err)
+	}
+	flags := make(map[string]bool)
+	for _, r := range results {
+		flags[r.Subdomain] = r.Wildcard
+	}
+	want := map[string]bool{"bar.wild.test": true, "foo.wild.test": true, "www.wild.test": false}
+	if !reflect.DeepEqual(flags, want) {
+		t.Errorf("wildcard flags = %v, want %v", flags, want)
+	}
+}
+
+// newTestDomainBruteService 创建测试用子域名爆破服务
+func newTestDomainBruteService(db *sql.DB, resolver string) *DomainBruteService {
+	cfg := &config.Config{DNSResolver: resolver}
+	return NewDomainBruteService(repo.NewDomainBruteRepository(db), event.NewBus(), logger.New("error", ""), cfg)
+}
+
+// waitDomainBruteTask 等待任务结束
+func waitDomainBruteTask(t *testing.T, service *DomainBruteService, taskID int) *models.DomainBruteTask {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if !service.runner.isRunning(taskID) {
+			task, err := service.GetTaskByID(context.Background(), taskID)
+			if err != nil {
+				t.Fatalf("GetTaskByID() failed: %v", err)
+			}
+			return task
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("domain brute task %d did not finish in time", taskID)
+	return nil
+}
+
+// setupDomainBruteTestDB 创建测试数据库
+func setupDomainBruteTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	// 内存数据库每个连接相互独立，任务 goroutine 需要共用同一连接
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE domain_brute_tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		domain TEXT NOT NULL,
+		wordlist TEXT,
+		timeout INTEGER DEFAULT 2000,
+		batch_size INTEGER DEFAULT 50,
+		status TEXT NOT NULL DEFAULT 'pending',
+		wildcard TEXT,
+		started_at DATETIME,
+		completed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE domain_brute_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		subdomain TEXT NOT NULL,
+		resolved INTEGER DEFAULT 0,
+		ips TEXT DEFAULT '[]',
+		cname TEXT,
+		latency INTEGER DEFAULT 0,
+		wildcard INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create test schema: %v", err)
+	}
+
+	return db
+}
@@ -2,44 +2,126 @@ package svc
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
 	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/portscan"
 	"github.com/holehunter/holehunter/internal/repo"
 )
 
+// portScanProgressInterval 进度事件的最小发布间隔
+const portScanProgressInterval = time.Second
+
 // PortScanService 端口扫描服务
 type PortScanService struct {
-	repo *repo.PortScanRepository
+	repo     *repo.PortScanRepository
+	eventBus *event.Bus
+	logger   *logger.Logger
+	config   *config.Config
+	runner   *taskRunner
 }
 
 // NewPortScanService 创建端口扫描服务
-func NewPortScanService(repo *repo.PortScanRepository) *PortScanService {
-	return &PortScanService{repo: repo}
+func NewPortScanService(repo *repo.PortScanRepository, eventBus *event.Bus, logger *logger.Logger, cfg *config.Config) *PortScanService {
+	return &PortScanService{
+		repo:     repo,
+		eventBus: eventBus,
+		logger:   logger,
+		config:   cfg,
+		runner:   newTaskRunner("port scan", repo, logger),
+	}
 }
 
 // CreateTask 创建扫描任务
 func (s *PortScanService) CreateTask(ctx context.Context, target string, ports []int, timeout, batchSize int) (int, error) {
+	task, err := newPortScanTask(target, ports, timeout, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.CreateTask(ctx, task); err != nil {
+		return 0, err
+	}
+
+	return task.ID, nil
+}
+
+// newPortScanTask 校验参数并创建待执行的任务，未指定的超时与批量大小使用默认值
+func newPortScanTask(target string, ports []int, timeout, batchSize int) (*models.PortScanTask, error) {
 	if target == "" {
-		return 0, errors.InvalidInput("target is required")
+		return nil, errors.InvalidInput("target is required")
 	}
 	if len(ports) == 0 {
-		return 0, errors.InvalidInput("ports are required")
+		return nil, errors.InvalidInput("ports are required")
+	}
+	for _, port := range ports {
+		if port < 1 || port > 65535 {
+			return nil, errors.InvalidInput(fmt.Sprintf("invalid port: %d", port))
+		}
+	}
+	if timeout <= 0 {
+		timeout = int(portscan.DefaultTimeout / time.Millisecond)
+	}
+	if batchSize <= 0 {
+		batchSize = portscan.DefaultBatchSize
 	}
 
-	task := &models.PortScanTask{
+	return &models.PortScanTask{
 		Target:    target,
 		Ports:     ports,
 		Timeout:   timeout,
 		BatchSize: batchSize,
 		Status:    "pending",
+	}, nil
+}
+
+// CreateTasks 根据目标表达式与端口表达式创建扫描任务，每个主机一个任务
+// 目标支持 CIDR、IP 范围与主机名列表，端口支持范围、列表、排除项与预置配置
+// 主机数与端口数的乘积超过 portscan.MaxProbes 时拒绝；所有任务在同一事务中创建，失败时不留下部分任务
+func (s *PortScanService) CreateTasks(ctx context.Context, targets, ports string, timeout, batchSize int) ([]int, error) {
+	hosts, err := portscan.ExpandTargets(targets)
+	if err != nil {
+		return nil, errors.InvalidInput(err.Error())
+	}
+	portList, err := portscan.ParsePorts(ports)
+	if err != nil {
+		return nil, errors.InvalidInput(err.Error())
 	}
 
-	if err := s.repo.CreateTask(ctx, task); err != nil {
-		return 0, err
+	if probes := len(hosts) * len(portList); probes > portscan.MaxProbes {
+		return nil, errors.InvalidInput(fmt.Sprintf("too many probes: %d hosts x %d ports exceeds the limit of %d", len(hosts), len(portList), portscan.MaxProbes))
 	}
 
-	return task.ID, nil
+	tasks := make([]*models.PortScanTask, 0, len(hosts))
+	for _, host := range hosts {
+		task, err := newPortScanTask(host, portList, timeout, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := s.repo.CreateTasks(ctx, tasks); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	s.logger.Info("Port scan tasks created: hosts=%d, ports=%d", len(hosts), len(portList))
+	return ids, nil
+}
+
+// GetPortProfiles 获取预置端口配置
+func (s *PortScanService) GetPortProfiles() map[string]string {
+	return portscan.Profiles()
 }
 
 // GetTaskByID 获取任务
@@ -67,3 +149,167 @@ func (s *PortScanService) GetResults(ctx context.Context, taskID int) ([]*models
 func (s *PortScanService) CreateResult(ctx context.Context, result *models.PortScanResult) error {
 	return s.repo.CreateResult(ctx, result)
 }
+
+// StartTask 启动端口扫描任务
+func (s *PortScanService) StartTask(ctx context.Context, taskID int) error {
+	if taskID <= 0 {
+		return errors.InvalidInput("invalid task id")
+	}
+
+	task, err := s.repo.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	runCtx, err := s.runner.begin(taskID, task.Status)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteResultsByTaskID(ctx, taskID); err != nil {
+		s.runner.finish(taskID)
+		return errors.Wrap(err, "failed to clear previous results")
+	}
+	if err := s.repo.MarkTaskStarted(ctx, taskID); err != nil {
+		s.runner.finish(taskID)
+		return errors.Wrap(err, "failed to update port scan task")
+	}
+
+	s.eventBus.PublishAsync(runCtx, event.Event{
+		Type: event.EventPortScanStarted,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+			"target": task.Target,
+			"total":  len(task.Ports),
+		},
+	})
+
+	go s.runTask(runCtx, task)
+
+	s.logger.Info("Port scan task started: task_id=%d, target=%s, ports=%d", taskID, task.Target, len(task.Ports))
+	return nil
+}
+
+// StopTask 停止端口扫描任务
+func (s *PortScanService) StopTask(ctx context.Context, taskID int) error {
+	if taskID <= 0 {
+		return errors.InvalidInput("invalid task id")
+	}
+
+	return s.runner.stop(taskID)
+}
+
+// runTask 执行端口扫描任务
+func (s *PortScanService) runTask(ctx context.Context, task *models.PortScanTask) {
+	defer s.runner.finish(task.ID)
+
+	timeout := time.Duration(task.Timeout) * time.Millisecond
+	scanner := portscan.NewScanner(portscan.Config{
+		Timeout:       timeout,
+		BatchSize:     task.BatchSize,
+		Fingerprinter: portscan.NewFingerprinter(s.loadSignatures(), timeout),
+	})
+
+	// 结果持久化失败时中止任务
+	runCtx, abort := context.WithCancel(ctx)
+	defer abort()
+
+	dbCtx := context.Background()
+	total := len(task.Ports)
+	var scanned, open int
+	var persistErr error
+	lastPublish := time.Now()
+
+	runErr := scanner.Scan(runCtx, task.Target, task.Ports, func(r *portscan.Result) {
+		scanned++
+		if r.Open() {
+			open++
+			result := &models.PortScanResult{
+				TaskID:  task.ID,
+				Port:    r.Port,
+				Status:  r.Status,
+				Service: r.Service,
+				Version: r.Version,
+				Banner:  r.Banner,
+				Latency: int(r.Latency.Milliseconds()),
+			}
+			if err := s.repo.CreateResult(dbCtx, result); err != nil && persistErr == nil {
+				persistErr = err
+				abort()
+				return
+			}
+		}
+
+		if time.Since(lastPublish) >= portScanProgressInterval {
+			lastPublish = time.Now()
+			s.publishProgress(ctx, task.ID, total, scanned, open)
+		}
+	})
+
+	s.publishProgress(dbCtx, task.ID, total, scanned, open)
+
+	switch {
+	case persistErr != nil:
+		s.logger.Error("Port scan task failed: task_id=%d, error=%v", task.ID, persistErr)
+		s.runner.markFinished(dbCtx, task.ID, "failed")
+		s.eventBus.PublishAsync(dbCtx, event.Event{
+			Type: event.EventPortScanFailed,
+			Data: map[string]interface{}{
+				"taskId": task.ID,
+				"error":  errors.SanitizeUserError(persistErr),
+			},
+		})
+	default:
+		status := "completed"
+		if runErr != nil {
+			status = "stopped"
+		}
+		s.logger.Info("Port scan task %s: task_id=%d, scanned=%d, open=%d", status, task.ID, scanned, open)
+		s.runner.markFinished(dbCtx, task.ID, status)
+		s.eventBus.PublishAsync(dbCtx, event.Event{
+			Type: event.EventPortScanCompleted,
+			Data: map[string]interface{}{
+				"taskId":  task.ID,
+				"status":  status,
+				"scanned": scanned,
+				"open":    open,
+			},
+		})
+	}
+}
+
+// loadSignatures 加载服务指纹库，自定义指纹库无效时只使用内置指纹库
+func (s *PortScanService) loadSignatures() *portscan.SignatureDB {
+	db := portscan.DefaultSignatureDB()
+	if s.config == nil || s.config.PortSignaturesFile == "" {
+		return db
+	}
+	if _, err := os.Stat(s.config.PortSignaturesFile); err != nil {
+		return db
+	}
+
+	custom, err := portscan.LoadSignatureDB(s.config.PortSignaturesFile)
+	if err != nil {
+		s.logger.Warn("Failed to load port signatures: file=%s, error=%v", s.config.PortSignaturesFile, err)
+		return db
+	}
+	return db.Merge(custom)
+}
+
+// publishProgress 发布进度事件
+func (s *PortScanService) publishProgress(ctx context.Context, taskID, total, scanned, open int) {
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventPortScanProgress,
+		Data: map[string]interface{}{
+			"taskId":  taskID,
+			"total":   total,
+			"scanned": scanned,
+			"open":    open,
+		},
+	})
+}
+
+// RecoverInterrupted 将上次退出时仍处于 running 状态的端口扫描任务标记为 stopped，使其可以重新启动
+// 应在启动任何任务之前调用
+func (s *PortScanService) RecoverInterrupted(ctx context.Context) (int, error) {
+	return s.runner.recoverInterrupted(ctx)
+}
@@ -0,0 +1,297 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestPortScanService_CreateTask 测试创建端口扫描任务
+func TestPortScanService_CreateTask(t *testing.T) {
+	db := setupPortScanTestDB(t)
+	defer db.Close()
+
+	service := newTestPortScanService(db)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		target  string
+		ports   []int
+		wantErr bool
+	}{
+		{"正常创建", "127.0.0.1", []int{80, 443}, false},
+		{"空目标", "", []int{80}, true},
+		{"空端口", "127.0.0.1", nil, true},
+		{"端口越界", "127.0.0.1", []int{0, 70000}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := service.CreateTask(ctx, tt.target, tt.ports, 0, 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				task, err := service.GetTaskByID(ctx, id)
+				if err != nil {
+					t.Fatalf("GetTaskByID() failed: %v", err)
+				}
+				if task.Timeout != 2000 || task.BatchSize != 50 {
+					t.Errorf("defaults = %d/%d, want 2000/50", task.Timeout, task.BatchSize)
+				}
+			}
+		})
+	}
+}
+
+// TestPortScanService_CreateTasks 测试按表达式创建多个主机的扫描任务
+func TestPortScanService_CreateTasks(t *testing.T) {
+	db := setupPortScanTestDB(t)
+	defer db.Close()
+
+	service := newTestPortScanService(db)
+	ctx := context.Background()
+
+	ids, err := service.CreateTasks(ctx, "10.0.0.1-3, example.com", "web,!8000-9443", 0, 0)
+	if err != nil {
+		t.Fatalf("CreateTasks() failed: %v", err)
+	}
+	if len(ids) != 4 {
+		t.Fatalf("CreateTasks() created %d tasks, want 4", len(ids))
+	}
+
+	task, err := service.GetTaskByID(ctx, ids[3])
+	if err != nil {
+		t.Fatalf("GetTaskByID() failed: %v", err)
+	}
+	if task.Target != "example.com" {
+		t.Errorf("target = %s, want example.com", task.Target)
+	}
+	for _, port := range task.Ports {
+		if port >= 8000 {
+			t.Errorf("excluded port %d should not be scanned", port)
+		}
+	}
+
+	if _, err := service.CreateTasks(ctx, "10.0.0.0/8", "80", 0, 0); err == nil {
+		t.Error("CreateTasks() should reject oversized CIDR")
+	}
+	if _, err := service.CreateTasks(ctx, "10.0.0.1", "80-", 0, 0); err == nil {
+		t.Error("CreateTasks() should reject invalid port spec")
+	}
+
+	// 主机数与端口数的乘积超过上限时不创建任何任务
+	if _, err := service.CreateTasks(ctx, "10.1.0.0/16", "all", 0, 0); err == nil {
+		t.Error("CreateTasks() should reject requests over the probe budget")
+	}
+	tasks, err := service.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTasks() failed: %v", err)
+	}
+	if len(tasks) != 4 {
+		t.Errorf("GetAllTasks() = %d tasks, want only the 4 created before", len(tasks))
+	}
+
+	// 插入失败时回滚已创建的任务
+	if _, err := db.ExecContext(ctx, `CREATE TRIGGER reject_host AFTER INSERT ON port_scan_tasks
+		WHEN NEW.target = '10.2.0.3' BEGIN SELECT RAISE(ABORT, 'rejected'); END`); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if ids, err := service.CreateTasks(ctx, "10.2.0.1-5", "80", 0, 0); err == nil || ids != nil {
+		t.Errorf("CreateTasks() = %v, %v, want an error and no ids", ids, err)
+	}
+	tasks, err = service.GetAllTasks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllTasks() failed: %v", err)
+	}
+	if len(tasks) != 4 {
+		t.Errorf("GetAllTasks() = %d tasks after a failed insert, want 4", len(tasks))
+	}
+}
+
+// TestPortScanService_RunTask 测试端口扫描任务的完整执行
+func TestPortScanService_RunTask(t *testing.T) {
+	db := setupPortScanTestDB(t)
+	defer db.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	openPort := ln.Addr().(*net.TCPAddr).Port
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	service := newTestPortScanService(db)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, "127.0.0.1", []int{closedPort, openPort}, 1000, 2)
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	if err := service.StartTask(ctx, taskID); err != nil {
+		t.Fatalf("StartTask() failed: %v", err)
+	}
+
+	task := waitPortScanTask(t, service, taskID)
+	if task.Status != "completed" {
+		t.Errorf("task status = %s, want completed", task.Status)
+	}
+	if task.StartedAt == "" || task.CompletedAt == "" {
+		t.Errorf("timestamps not set: started=%q completed=%q", task.StartedAt, task.CompletedAt)
+	}
+
+	results, err := service.GetResults(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetResults() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Port != openPort || results[0].Status != "open" {
+		t.Errorf("results = %+v, want only port %d open", results, openPort)
+	}
+
+	// 未运行的任务无法停止
+	if err := service.StopTask(ctx, taskID); err == nil {
+		t.Error("StopTask() should fail for finished task")
+	}
+
+	// 已完成的任务不能重新启动，结果保留
+	if err := service.StartTask(ctx, taskID); err == nil || !strings.Contains(err.Error(), "is completed, cannot start") {
+		t.Errorf("StartTask() for completed task = %v, want conflict", err)
+	}
+	if results, _ := service.GetResults(ctx, taskID); len(results) != 1 {
+		t.Errorf("results after rejected restart = %d, want 1", len(results))
+	}
+}
+
+// TestPortScanService_RecoverInterrupted 测试上次异常退出遗留的 running 任务可以重新启动
+func TestPortScanService_RecoverInterrupted(t *testing.T) {
+	db := setupPortScanTestDB(t)
+	defer db.Close()
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	service := newTestPortScanService(db)
+	ctx := context.Background()
+
+	taskID, err := service.CreateTask(ctx, "127.0.0.1", []int{closedPort}, 500, 1)
+	if err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE port_scan_tasks SET status = 'running' WHERE id = ?", taskID); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// 数据库中为 running 但没有在运行的任务可以直接重新启动
+	if err := service.StartTask(ctx, taskID); err != nil {
+		t.Fatalf("StartTask() for stale running task failed: %v", err)
+	}
+	if task := waitPortScanTask(t, service, taskID); task.Status != "completed" {
+		t.Errorf("task status = %s, want completed", task.Status)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE port_scan_tasks SET status = 'running' WHERE id = ?", taskID); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	n, err := service.RecoverInterrupted(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("RecoverInterrupted() = %d, %v, want 1", n, err)
+	}
+	task, err := service.GetTaskByID(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetTaskByID() failed: %v", err)
+	}
+	if task.Status != "stopped" {
+		t.Errorf("task status = %s, want stopped", task.Status)
+	}
+}
+
+// newTestPortScanService 创建测试用端口扫描服务
+func newTestPortScanService(db *sql.DB) *PortScanService {
+	return NewPortScanService(repo.NewPortScanRepository(db), event.NewBus(), logger.New("error", ""), &config.Config{})
+}
+
+// waitPortScanTask 等待任务结束
+func waitPortScanTask(t *testing.T, service *PortScanService, taskID int) *models.PortScanTask {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if !service.runner.isRunning(taskID) {
+			task, err := service.GetTaskByID(context.Background(), taskID)
+			if err != nil {
+				t.Fatalf("GetTaskByID() failed: %v", err)
+			}
+			return task
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("port scan task %d did not finish in time", taskID)
+	return nil
+}
+
+// setupPortScanTestDB 创建测试数据库
+func setupPortScanTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	// 内存数据库每个连接相互独立，任务 goroutine 需要共用同一连接
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE port_scan_tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target TEXT NOT NULL,
+		ports TEXT NOT NULL,
+		timeout INTEGER DEFAULT 2000,
+		batch_size INTEGER DEFAULT 50,
+		status TEXT NOT NULL DEFAULT 'pending',
+		started_at DATETIME,
+		completed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE port_scan_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		port INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'closed',
+		service TEXT,
+		version TEXT,
+		banner TEXT,
+		latency INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create test schema: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,363 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+)
+
+// 结果来源
+const (
+	PromoteSourcePortScan    = "port_scan"
+	PromoteSourceDomainBrute = "domain_brute"
+)
+
+// defaultPromoteStrategy 提升后自动扫描的默认策略
+const defaultPromoteStrategy = "quick"
+
+// PromotionService 将端口扫描与子域名爆破结果提升为扫描目标
+type PromotionService struct {
+	targetSvc       *TargetService
+	scanSvc         *ScanService
+	targetRepo      *repo.TargetRepository
+	portScanRepo    *repo.PortScanRepository
+	domainBruteRepo *repo.DomainBruteRepository
+	logger          *logger.Logger
+}
+
+// NewPromotionService 创建结果提升服务
+func NewPromotionService(
+	targetSvc *TargetService,
+	scanSvc *ScanService,
+	targetRepo *repo.TargetRepository,
+	portScanRepo *repo.PortScanRepository,
+	domainBruteRepo *repo.DomainBruteRepository,
+	logger *logger.Logger,
+) *PromotionService {
+	return &PromotionService{
+		targetSvc:       targetSvc,
+		scanSvc:         scanSvc,
+		targetRepo:      targetRepo,
+		portScanRepo:    portScanRepo,
+		domainBruteRepo: domainBruteRepo,
+		logger:          logger,
+	}
+}
+
+// promoteCandidate 待提升的结果
+type promoteCandidate struct {
+	resultID    int
+	name        string
+	url         string
+	description string
+	skipReason  string
+}
+
+// aliases 返回与 URL 等价的写法，用于去重
+func (c *promoteCandidate) aliases() []string {
+	urls := []string{c.url, c.url + "/"}
+	if scheme, host, _ := strings.Cut(c.url, "://"); !hasPort(host) {
+		explicit := c.url + ":" + defaultPort(scheme)
+		urls = append(urls, explicit, explicit+"/")
+	}
+	return urls
+}
+
+// PromoteTargets 将选中的结果创建为目标，已存在的 URL 会被跳过
+func (s *PromotionService) PromoteTargets(ctx context.Context, req *models.PromoteTargetsRequest) (*models.PromoteTargetsResult, error) {
+	if req == nil {
+		return nil, errors.InvalidInput("request is required")
+	}
+	if req.TaskID <= 0 {
+		return nil, errors.InvalidInput("invalid task id")
+	}
+
+	var candidates []*promoteCandidate
+	var err error
+	switch req.Source {
+	case PromoteSourcePortScan:
+		candidates, err = s.portScanCandidates(ctx, req)
+	case PromoteSourceDomainBrute:
+		candidates, err = s.domainBruteCandidates(ctx, req)
+	default:
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid source: %s", req.Source))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tags := promoteTags(req)
+	result := &models.PromoteTargetsResult{
+		Created: []*models.PromotedTarget{},
+		Skipped: []*models.PromoteSkipped{},
+	}
+	seen := make(map[string]bool)
+
+	for _, c := range candidates {
+		skip := func(reason string) {
+			result.Skipped = append(result.Skipped, &models.PromoteSkipped{ResultID: c.resultID, URL: c.url, Reason: reason})
+		}
+		if c.skipReason != "" {
+			skip(c.skipReason)
+			continue
+		}
+		if seen[c.url] {
+			skip("duplicate url in selection")
+			continue
+		}
+		seen[c.url] = true
+
+		exists, err := s.urlExists(ctx, c.aliases())
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			skip("target url already exists")
+			continue
+		}
+
+		target, err := s.targetSvc.Create(ctx, &CreateTargetRequest{
+			Name:        c.name,
+			URL:         c.url,
+			Description: c.description,
+			Tags:        tags,
+		})
+		if err != nil {
+			if errors.Is(err, errors.ErrCodeConflict) {
+				skip("target url already exists")
+				continue
+			}
+			return nil, err
+		}
+		result.Created = append(result.Created, &models.PromotedTarget{ResultID: c.resultID, Target: target})
+	}
+
+	if req.StartScan {
+		s.startScans(ctx, req, result.Created)
+	}
+
+	s.logger.Info("Targets promoted: source=%s, task_id=%d, created=%d, skipped=%d",
+		req.Source, req.TaskID, len(result.Created), len(result.Skipped))
+	return result, nil
+}
+
+// portScanCandidates 由端口扫描结果生成候选目标，只提升 HTTP/HTTPS 服务
+func (s *PromotionService) portScanCandidates(ctx context.Context, req *models.PromoteTargetsRequest) ([]*promoteCandidate, error) {
+	task, err := s.portScanRepo.GetTaskByID(ctx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.portScanRepo.GetResultsByTaskID(ctx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.PortScanResult, len(results))
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	selected, err := selectResults(req.ResultIDs, results, byID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*promoteCandidate, 0, len(selected))
+	for _, r := range selected {
+		c := &promoteCandidate{
+			resultID:    r.ID,
+			name:        net.JoinHostPort(task.Target, strconv.Itoa(r.Port)),
+			description: fmt.Sprintf("Promoted from port scan task #%d (%s)", task.ID, describeService(r)),
+		}
+		scheme := webScheme(r.Service, r.Port)
+		if scheme == "" {
+			c.skipReason = fmt.Sprintf("port %d is not a web service", r.Port)
+		} else {
+			c.url = targetURL(scheme, task.Target, r.Port)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// domainBruteCandidates 由子域名爆破结果生成候选目标
+// 未指定结果时跳过泛解析命中的子域名
+func (s *PromotionService) domainBruteCandidates(ctx context.Context, req *models.PromoteTargetsRequest) ([]*promoteCandidate, error) {
+	task, err := s.domainBruteRepo.GetTaskByID(ctx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.domainBruteRepo.GetResultsByTaskID(ctx, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*models.DomainBruteResult, len(results))
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	selected, err := selectResults(req.ResultIDs, results, byID)
+	if err != nil {
+		return nil, err
+	}
+
+	scheme := strings.ToLower(req.Scheme)
+	if scheme == "" {
+		scheme = "https"
+	}
+	if scheme != "http" && scheme != "https" {
+		return nil, errors.InvalidInput(fmt.Sprintf("invalid scheme: %s", req.Scheme))
+	}
+
+	candidates := make([]*promoteCandidate, 0, len(selected))
+	for _, r := range selected {
+		c := &promoteCandidate{
+			resultID:    r.ID,
+			name:        r.Subdomain,
+			url:         scheme + "://" + r.Subdomain,
+			description: fmt.Sprintf("Promoted from domain brute task #%d (%s)", task.ID, strings.Join(r.IPs, ", ")),
+		}
+		if !r.Resolved {
+			c.skipReason = "subdomain did not resolve"
+		} else if r.Wildcard && len(req.ResultIDs) == 0 {
+			c.skipReason = "wildcard dns result"
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// startScans 为新目标批量创建并启动扫描任务，单个失败不影响其他目标
+func (s *PromotionService) startScans(ctx context.Context, req *models.PromoteTargetsRequest, created []*models.PromotedTarget) {
+	strategy := req.ScanStrategy
+	if strategy == "" {
+		strategy = defaultPromoteStrategy
+	}
+
+	for _, p := range created {
+		task, err := s.scanSvc.Create(ctx, &CreateScanRequest{
+			Name:      "Scan " + p.Target.Name,
+			TargetID:  p.Target.ID,
+			Strategy:  strategy,
+			Templates: req.Templates,
+		})
+		if err != nil {
+			p.ScanError = errors.SanitizeUserError(err)
+			continue
+		}
+		p.ScanTaskID = task.ID
+
+		if err := s.scanSvc.Start(ctx, task.ID); err != nil {
+			s.logger.Warn("Failed to start scan for promoted target: target_id=%d, task_id=%d, error=%v", p.Target.ID, task.ID, err)
+			p.ScanError = errors.SanitizeUserError(err)
+		}
+	}
+}
+
+// urlExists 检查任一等价 URL 是否已存在
+func (s *PromotionService) urlExists(ctx context.Context, urls []string) (bool, error) {
+	for _, u := range urls {
+		exists, err := s.targetRepo.ExistsByURL(ctx, u, 0)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
+
+// selectResults 按 ID 选取结果，ids 为空时返回全部结果
+func selectResults[T any](ids []int, all []T, byID map[int]T) ([]T, error) {
+	if len(ids) == 0 {
+		return all, nil
+	}
+	selected := make([]T, 0, len(ids))
+	for _, id := range ids {
+		r, ok := byID[id]
+		if !ok {
+			return nil, errors.InvalidInput(fmt.Sprintf("result %d does not belong to the task", id))
+		}
+		selected = append(selected, r)
+	}
+	return selected, nil
+}
+
+// promoteTags 合并请求标签与来源任务标签
+func promoteTags(req *models.PromoteTargetsRequest) []string {
+	tags := []string{"promoted", fmt.Sprintf("%s:%d", req.Source, req.TaskID)}
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		duplicate := false
+		for _, existing := range tags {
+			if existing == tag {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// webScheme 根据识别的服务返回 URL 协议，非 Web 服务返回空
+// 服务名带 ? 表示仅按端口推测
+func webScheme(service string, port int) string {
+	service = strings.TrimSuffix(strings.ToLower(service), "?")
+	switch {
+	case service == "https" || service == "https-alt" || service == "ssl/http" || service == "ssl":
+		return "https"
+	case service == "http" || service == "http-proxy" || service == "elasticsearch":
+		return "http"
+	case service == "" && (port == 443 || port == 8443):
+		return "https"
+	case service == "" && (port == 80 || port == 8080):
+		return "http"
+	}
+	return ""
+}
+
+// targetURL 构造目标 URL，默认端口不写出
+func targetURL(scheme, host string, port int) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if strconv.Itoa(port) == defaultPort(scheme) {
+		return scheme + "://" + host
+	}
+	return scheme + "://" + host + ":" + strconv.Itoa(port)
+}
+
+// hasPort 判断 host 是否带端口，兼容 IPv6 地址
+func hasPort(host string) bool {
+	_, _, err := net.SplitHostPort(host)
+	return err == nil
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// describeService 描述端口上的服务
+func describeService(r *models.PortScanResult) string {
+	parts := []string{fmt.Sprintf("%d/tcp", r.Port)}
+	if r.Service != "" {
+		parts = append(parts, r.Service)
+	}
+	if r.Version != "" {
+		parts = append(parts, r.Version)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,301 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// TestPromotionService_PortScan 测试将端口扫描结果提升为目标
+func TestPromotionService_PortScan(t *testing.T) {
+	db := setupPromotionTestDB(t)
+	defer db.Close()
+
+	service, targetRepo := newTestPromotionService(t, db)
+	portScanRepo := repo.NewPortScanRepository(db)
+	ctx := context.Background()
+
+	task := &models.PortScanTask{Target: "10.0.0.5", Ports: []int{22, 80, 443, 8443}, Status: "completed"}
+	if err := portScanRepo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	results := []*models.PortScanResult{
+		{TaskID: task.ID, Port: 22, Status: "open", Service: "ssh", Version: "OpenSSH 9.6"},
+		{TaskID: task.ID, Port: 80, Status: "open", Service: "http", Version: "nginx 1.25.3"},
+		{TaskID: task.ID, Port: 443, Status: "open", Service: "https"},
+		{TaskID: task.ID, Port: 8443, Status: "open", Service: "https-alt?"},
+	}
+	for _, r := range results {
+		if err := portScanRepo.CreateResult(ctx, r); err != nil {
+			t.Fatalf("CreateResult() failed: %v", err)
+		}
+	}
+
+	// 已存在的目标（显式写出默认端口）
+	if err := targetRepo.Create(ctx, &models.Target{Name: "existing", URL: "https://10.0.0.5:443"}); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	got, err := service.PromoteTargets(ctx, &models.PromoteTargetsRequest{
+		Source: PromoteSourcePortScan,
+		TaskID: task.ID,
+		Tags:   []string{"internal", "promoted"},
+	})
+	if err != nil {
+		t.Fatalf("PromoteTargets() failed: %v", err)
+	}
+
+	urls := make(map[string]*models.Target)
+	for _, p := range got.Created {
+		urls[p.Target.URL] = p.Target
+	}
+	if len(urls) != 2 || urls["http://10.0.0.5"] == nil || urls["https://10.0.0.5:8443"] == nil {
+		t.Errorf("created = %v, want http://10.0.0.5 and https://10.0.0.5:8443", urls)
+	}
+	if target := urls["http://10.0.0.5"]; target != nil {
+		wantTags := []string{"promoted", "port_scan:1", "internal"}
+		if len(target.Tags) != len(wantTags) {
+			t.Errorf("tags = %v, want %v", target.Tags, wantTags)
+		}
+	}
+
+	reasons := make(map[int]string)
+	for _, s := range got.Skipped {
+		reasons[s.ResultID] = s.Reason
+	}
+	if reasons[results[0].ID] == "" || reasons[results[2].ID] != "target url already exists" {
+		t.Errorf("skipped = %v, want ssh and existing https target skipped", reasons)
+	}
+
+	// 再次提升时全部跳过
+	again, err := service.PromoteTargets(ctx, &models.PromoteTargetsRequest{
+		Source:    PromoteSourcePortScan,
+		TaskID:    task.ID,
+		ResultIDs: []int{results[1].ID},
+	})
+	if err != nil {
+		t.Fatalf("PromoteTargets() failed: %v", err)
+	}
+	if len(again.Created) != 0 || len(again.Skipped) != 1 {
+		t.Errorf("second promotion = %d created, %d skipped, want 0/1", len(again.Created), len(again.Skipped))
+	}
+
+	// 不属于该任务的结果
+	if _, err := service.PromoteTargets(ctx, &models.PromoteTargetsRequest{
+		Source:    PromoteSourcePortScan,
+		TaskID:    task.ID,
+		ResultIDs: []int{999},
+	}); err == nil {
+		t.Error("PromoteTargets() should reject unknown result id")
+	}
+}
+
+// TestPromotionService_DomainBrute 测试将子域名提升为目标并创建扫描任务
+func TestPromotionService_DomainBrute(t *testing.T) {
+	db := setupPromotionTestDB(t)
+	defer db.Close()
+
+	service, _ := newTestPromotionService(t, db)
+	domainRepo := repo.NewDomainBruteRepository(db)
+	ctx := context.Background()
+
+	task := &models.DomainBruteTask{Domain: "example.test", Wordlist: []string{"www", "api", "x"}, Status: "completed"}
+	if err := domainRepo.CreateTask(ctx, task); err != nil {
+		t.Fatalf("CreateTask() failed: %v", err)
+	}
+	results := []*models.DomainBruteResult{
+		{TaskID: task.ID, Subdomain: "www.example.test", Resolved: true, IPs: []string{"10.0.0.1"}},
+		{TaskID: task.ID, Subdomain: "api.example.test", Resolved: true, IPs: []string{"10.0.0.2"}},
+		{TaskID: task.ID, Subdomain: "x.example.test", Resolved: true, IPs: []string{"10.9.9.9"}, Wildcard: true},
+	}
+	for _, r := range results {
+		if err := domainRepo.CreateResult(ctx, r); err != nil {
+			t.Fatalf("CreateResult() failed: %v", err)
+		}
+	}
+
+	got, err := service.PromoteTargets(ctx, &models.PromoteTargetsRequest{
+		Source:    PromoteSourceDomainBrute,
+		TaskID:    task.ID,
+		StartScan: true,
+	})
+	if err != nil {
+		t.Fatalf("PromoteTargets() failed: %v", err)
+	}
+
+	if len(got.Created) != 2 || len(got.Skipped) != 1 || got.Skipped[0].Reason != "wildcard dns result" {
+		t.Fatalf("result = %d created, %+v skipped", len(got.Created), got.Skipped)
+	}
+	for _, p := range got.Created {
+		if p.Target.URL != "https://"+p.Target.Name {
+			t.Errorf("url = %s, want https scheme", p.Target.URL)
+		}
+		if p.ScanTaskID == 0 {
+			t.Errorf("scan task not created for %s: %s", p.Target.URL, p.ScanError)
+		}
+	}
+
+	if _, err := service.PromoteTargets(ctx, &models.PromoteTargetsRequest{
+		Source: PromoteSourceDomainBrute,
+		TaskID: task.ID,
+		Scheme: "ftp",
+	}); err == nil {
+		t.Error("PromoteTargets() should reject invalid scheme")
+	}
+}
+
+// TestWebScheme 测试根据服务推断 URL 协议
+func TestWebScheme(t *testing.T) {
+	tests := []struct {
+		service string
+		port    int
+		want    string
+	}{
+		{"http", 8000, "http"},
+		{"http-proxy?", 8080, "http"},
+		{"https", 9443, "https"},
+		{"ssl", 4443, "https"},
+		{"ssl/smtp", 465, ""},
+		{"ssh", 22, ""},
+		{"", 443, "https"},
+		{"", 3306, ""},
+	}
+
+	for _, tt := range tests {
+		if got := webScheme(tt.service, tt.port); got != tt.want {
+			t.Errorf("webScheme(%q, %d) = %q, want %q", tt.service, tt.port, got, tt.want)
+		}
+	}
+}
+
+// newTestPromotionService 创建测试用结果提升服务
+func newTestPromotionService(t *testing.T, db *sql.DB) (*PromotionService, *repo.TargetRepository) {
+	t.Helper()
+	eventBus := event.NewBus()
+	log := logger.New("error", "")
+	cfg := &config.Config{DataDir: t.TempDir(), TemplatesDir: t.TempDir(), MaxConcurrent: 1}
+
+	targetRepo := repo.NewTargetRepository(db)
+	scanRepo := repo.NewScanRepository(db, log)
+	targetSvc := NewTargetService(targetRepo, eventBus)
+	scanSvc := NewScanService(scanRepo, targetRepo, eventBus, log, cfg)
+
+	service := NewPromotionService(targetSvc, scanSvc, targetRepo,
+		repo.NewPortScanRepository(db), repo.NewDomainBruteRepository(db), log)
+	return service, targetRepo
+}
+
+// setupPromotionTestDB 创建测试数据库
+func setupPromotionTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	schema := `
+	CREATE TABLE targets (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		url TEXT NOT NULL,
+		description TEXT,
+		tags TEXT,
+		created_at TEXT,
+		updated_at TEXT
+	);
+
+	CREATE TABLE scan_tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT,
+		target_id INTEGER NOT NULL,
+		target_tag TEXT,
+		status TEXT NOT NULL,
+		strategy TEXT,
+		templates_used TEXT,
+		started_at TEXT,
+		completed_at TEXT,
+		total_templates INTEGER,
+		executed_templates INTEGER,
+		progress INTEGER DEFAULT 0,
+		current_template TEXT,
+		error TEXT,
+		findings_count INTEGER DEFAULT 0,
+		queue_position INTEGER,
+		pid INTEGER,
+		resume_file TEXT,
+		options TEXT,
+		engine TEXT NOT NULL DEFAULT 'nuclei',
+		created_at TEXT DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE scan_task_targets (
+		scan_id INTEGER NOT NULL,
+		target_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (scan_id, target_id)
+	);
+
+	CREATE TABLE port_scan_tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target TEXT NOT NULL,
+		ports TEXT NOT NULL,
+		timeout INTEGER DEFAULT 2000,
+		batch_size INTEGER DEFAULT 50,
+		status TEXT NOT NULL DEFAULT 'pending',
+		started_at DATETIME,
+		completed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE port_scan_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		port INTEGER NOT NULL,
+		status TEXT NOT NULL DEFAULT 'closed',
+		service TEXT,
+		version TEXT,
+		banner TEXT,
+		latency INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE domain_brute_tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		domain TEXT NOT NULL,
+		wordlist TEXT,
+		timeout INTEGER DEFAULT 2000,
+		batch_size INTEGER DEFAULT 50,
+		status TEXT NOT NULL DEFAULT 'pending',
+		wildcard TEXT,
+		started_at DATETIME,
+		completed_at DATETIME,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE domain_brute_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		subdomain TEXT NOT NULL,
+		resolved INTEGER DEFAULT 0,
+		ips TEXT DEFAULT '[]',
+		cname TEXT,
+		latency INTEGER DEFAULT 0,
+		wildcard INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("failed to create test schema: %v", err)
+	}
+
+	return db
+}
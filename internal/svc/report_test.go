@@ -196,6 +196,7 @@ func setupReportTestDB(t *testing.T) *sql.DB {
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		name TEXT,
 		target_id INTEGER NOT NULL,
+		target_tag TEXT,
 		status TEXT NOT NULL,
 		strategy TEXT,
 		templates_used TEXT,
@@ -206,12 +207,26 @@ func setupReportTestDB(t *testing.T) *sql.DB {
 		progress INTEGER DEFAULT 0,
 		current_template TEXT,
 		error TEXT,
+		findings_count INTEGER DEFAULT 0,
+		queue_position INTEGER,
+		pid INTEGER,
+		resume_file TEXT,
+		options TEXT,
+		engine TEXT NOT NULL DEFAULT 'nuclei',
 		created_at TEXT
 	);
 
+	CREATE TABLE scan_task_targets (
+		scan_id INTEGER NOT NULL,
+		target_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (scan_id, target_id)
+	);
+
 	CREATE TABLE vulnerabilities (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		task_id INTEGER NOT NULL,
+		target_id INTEGER,
 		template_id TEXT NOT NULL,
 		severity TEXT,
 		name TEXT,
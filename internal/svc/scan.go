@@ -3,6 +3,10 @@ package svc
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/holehunter/holehunter/internal/infrastructure/config"
 	"github.com/holehunter/holehunter/internal/infrastructure/errors"
@@ -19,8 +23,21 @@ import (
 type ScanService struct {
 	scanRepo   *repo.ScanRepository
 	targetRepo *repo.TargetRepository
-	scanner    *scanner.Orchestrator
+	scanner    scanner.Scanner
 	eventBus   *event.Bus
+	logger     *logger.Logger
+	resumeDir  string
+	targetsDir string
+	secretsDir string
+	// auth 认证配置服务，为 nil 时扫描不携带认证信息
+	auth *AuthService
+	// templates 自定义模板同步器，为 nil 时扫描不使用自定义模板
+	templates *TemplateMaterializer
+	// defaultOptions 全局调优默认值，任务级参数在启动时覆盖
+	defaultOptions models.ScanOptions
+
+	// queueMu 串行化扫描准入与出队，保证先检查槽位再启动
+	queueMu sync.Mutex
 }
 
 // NewScanService 创建扫描服务
@@ -33,13 +50,48 @@ func NewScanService(
 ) *ScanService {
 	nucleiClient := scanner.NewNucleiClientWithTemplates(cfg.DataDir, cfg.TemplatesDir)
 	orchestrator := scanner.NewOrchestrator(nucleiClient, eventBus, logger, cfg.MaxConcurrent, metrics.Global, scanRepo)
+	orchestrator.SetReleasePausedSlots(cfg.PauseReleasesSlot)
 
-	return &ScanService{
+	// 注册外部扫描引擎，无效的定义跳过
+	engines, err := scanner.LoadExternalEngines(cfg.EnginesDir)
+	if err != nil {
+		logger.Warn("Failed to load external scan engines: %v", err)
+	}
+	for _, engine := range engines {
+		orchestrator.RegisterEngine(engine)
+	}
+
+	s := &ScanService{
 		scanRepo:   scanRepo,
 		targetRepo: targetRepo,
 		scanner:    orchestrator,
 		eventBus:   eventBus,
+		logger:     logger,
+		resumeDir:  filepath.Join(cfg.DataDir, "resume"),
+		targetsDir: filepath.Join(cfg.DataDir, "targets"),
+		secretsDir: filepath.Join(cfg.DataDir, "secrets"),
+
+		defaultOptions: defaultScanOptions(cfg),
 	}
+	// 槽位释放后删除模板快照并启动队列中的下一个任务
+	orchestrator.SetFinishedHandler(func(taskID int) {
+		s.removeTemplateSnapshot(taskID)
+		if err := s.DispatchQueue(context.Background()); err != nil {
+			s.logger.Error("Failed to dispatch scan queue: %v", err)
+		}
+	})
+
+	return s
+}
+
+// SetAuthService 设置认证配置服务，需在启动扫描前设置
+func (s *ScanService) SetAuthService(auth *AuthService) {
+	s.auth = auth
+}
+
+// SetTemplateMaterializer 设置自定义模板同步器，需在启动扫描前设置
+func (s *ScanService) SetTemplateMaterializer(m *TemplateMaterializer) {
+	s.templates = m
 }
 
 // GetAll 获取所有扫描任务
@@ -64,36 +116,49 @@ func (s *ScanService) GetByTargetID(ctx context.Context, targetID int) ([]*model
 }
 
 // Create 创建扫描任务
+// 指定 TargetIDs 或 TargetTag 时创建多目标任务，目标按请求顺序去重
 func (s *ScanService) Create(ctx context.Context, req *CreateScanRequest) (*models.ScanTask, error) {
 	// 输入验证
 	if req.Name == "" {
 		return nil, errors.InvalidInput("scan task name is required")
 	}
-	if req.TargetID <= 0 {
-		return nil, errors.InvalidInput("invalid target id")
-	}
 	if req.Strategy == "" {
 		return nil, errors.InvalidInput("scan strategy is required")
 	}
 
-	// 验证目标存在
-	target, err := s.targetRepo.GetByID(ctx, req.TargetID)
+	targetIDs, err := s.resolveTargets(ctx, req)
 	if err != nil {
-		if errors.Is(err, errors.ErrCodeNotFound) {
-			return nil, errors.NotFound("target not found")
-		}
-		return nil, errors.Wrap(err, "failed to get target")
+		return nil, err
+	}
+
+	options, err := normalizeScanOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := strings.TrimSpace(req.Engine)
+	if engine == "" {
+		engine = models.DefaultScanEngine
+	}
+	// nuclei 始终注册，其他引擎需检查是否存在
+	if engine != models.DefaultScanEngine && !s.scanner.HasEngine(engine) {
+		return nil, errors.InvalidInput(fmt.Sprintf("unknown scan engine: %s", engine))
 	}
-	_ = target // 用于后续使用
 
 	// 创建扫描任务
 	task := &models.ScanTask{
 		Name:          &req.Name,
-		TargetID:      req.TargetID,
+		TargetID:      targetIDs[0],
+		TargetIDs:     targetIDs,
 		Status:        "pending",
 		Strategy:      req.Strategy,
 		TemplatesUsed: req.Templates,
 		Progress:      0,
+		Options:       options,
+		Engine:        engine,
+	}
+	if tag := strings.TrimSpace(req.TargetTag); tag != "" {
+		task.TargetTag = &tag
 	}
 
 	if err := s.scanRepo.Create(ctx, task); err != nil {
@@ -109,7 +174,56 @@ func (s *ScanService) Create(ctx context.Context, req *CreateScanRequest) (*mode
 	return createdTask, nil
 }
 
-// Start 启动扫描任务
+// resolveTargets 解析请求中的目标，合并单个目标、目标列表与标签选中的目标
+func (s *ScanService) resolveTargets(ctx context.Context, req *CreateScanRequest) ([]int, error) {
+	ids := make([]int, 0, len(req.TargetIDs)+1)
+	if req.TargetID != 0 {
+		ids = append(ids, req.TargetID)
+	}
+	ids = append(ids, req.TargetIDs...)
+
+	if tag := strings.TrimSpace(req.TargetTag); tag != "" {
+		tagged, err := s.targetRepo.GetByTag(ctx, tag)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get targets by tag")
+		}
+		if len(tagged) == 0 {
+			return nil, errors.InvalidInput(fmt.Sprintf("no targets with tag: %s", tag))
+		}
+		for _, t := range tagged {
+			ids = append(ids, t.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.InvalidInput("invalid target id")
+	}
+
+	resolved := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, errors.InvalidInput("invalid target id")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		// 验证目标存在
+		if _, err := s.targetRepo.GetByID(ctx, id); err != nil {
+			if errors.Is(err, errors.ErrCodeNotFound) {
+				return nil, errors.NotFound(fmt.Sprintf("target %d not found", id))
+			}
+			return nil, errors.Wrap(err, "failed to get target")
+		}
+		resolved = append(resolved, id)
+	}
+
+	return resolved, nil
+}
+
+// Start 启动扫描任务，并发已满或队列非空时任务进入队列
 func (s *ScanService) Start(ctx context.Context, taskID int) error {
 	// 获取任务
 	task, err := s.scanRepo.GetByID(ctx, taskID)
@@ -118,41 +232,387 @@ func (s *ScanService) Start(ctx context.Context, taskID int) error {
 	}
 
 	// 状态检查
-	if task.Status != "pending" && task.Status != "stopped" {
+	if task.Status != "pending" && task.Status != "stopped" && task.Status != "interrupted" && task.Status != "timed_out" {
 		return errors.Conflict(fmt.Sprintf("scan task is %s, cannot start", task.Status))
 	}
 
+	return s.startTask(ctx, task)
+}
+
+// startTask 启动任务或将其加入队列
+func (s *ScanService) startTask(ctx context.Context, task *models.ScanTask) error {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	// 已有任务排队时新任务排在其后，保证先进先出
+	counts, err := s.scanRepo.CountByStatus(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to count queued scans")
+	}
+	if counts["queued"] > 0 || !s.scanner.HasCapacity() {
+		return s.enqueue(ctx, task)
+	}
+
+	return s.launch(ctx, task)
+}
+
+// launch 启动扫描进程并更新状态
+func (s *ScanService) launch(ctx context.Context, task *models.ScanTask) error {
 	// 获取目标信息
-	target, err := s.targetRepo.GetByID(ctx, task.TargetID)
+	targets, err := s.scanTargets(ctx, task)
 	if err != nil {
-		return errors.Wrap(err, "failed to get target")
+		return err
+	}
+
+	targetList, err := s.targetList(task, targets)
+	if err != nil {
+		return err
+	}
+
+	// 断点续扫、认证凭据文件与模板设置只有 nuclei 支持
+	var resumeFile, secretFile, customDir, excludeFile, manifestFile string
+	var secrets []string
+	var templateCount int
+	if task.Engine == "" || task.Engine == models.DefaultScanEngine {
+		if customDir, excludeFile, err = s.templateSnapshot(ctx, task); err != nil {
+			return err
+		}
+		if manifestFile, templateCount, err = s.templateManifest(ctx, task); err != nil {
+			s.removeTemplateSnapshot(task.ID)
+			return err
+		}
+		if resumeFile, err = s.resumeFile(ctx, task); err != nil {
+			s.removeTemplateSnapshot(task.ID)
+			return err
+		}
+		if secretFile, secrets, err = s.secretFile(ctx, task, targets); err != nil {
+			s.removeTemplateSnapshot(task.ID)
+			return err
+		}
 	}
 
 	// 构建扫描请求
+	options := mergeScanOptions(s.defaultOptions, task.Options)
+	secrets = append(secrets, headerSecrets(options)...)
 	scanReq := scanner.ScanRequest{
-		Context:   ctx,
-		TaskID:    taskID,
-		Name:      utils.DerefString(task.Name),
-		TargetID:  task.TargetID,
-		TargetURL: target.URL,
-		Strategy:  task.Strategy,
-		Templates: task.TemplatesUsed,
+		TaskID:        task.ID,
+		Name:          utils.DerefString(task.Name),
+		TargetID:      task.TargetID,
+		TargetURL:     targets[0].URL,
+		Targets:       targets,
+		TargetList:    targetList,
+		Strategy:      task.Strategy,
+		Templates:     task.TemplatesUsed,
+		CustomDir:     customDir,
+		ResumeFile:    resumeFile,
+		ExcludeFile:   excludeFile,
+		ManifestFile:  manifestFile,
+		TemplateCount: templateCount,
+		Options:       options,
+		SecretFile:    secretFile,
+		Secrets:       secrets,
+		Timeout:       scanTimeout(options),
+		Engine:        task.Engine,
+	}
+
+	// 先更新状态，避免扫描过快结束时最终状态被 running 覆盖
+	if err := s.scanRepo.UpdateStatus(ctx, task.ID, "running"); err != nil {
+		return errors.Wrap(err, "failed to update scan status")
 	}
 
 	// 启动扫描
 	if err := s.scanner.Scan(ctx, scanReq); err != nil {
+		if secretFile != "" {
+			_ = os.Remove(secretFile)
+		}
+		s.removeTemplateSnapshot(task.ID)
+		if revertErr := s.scanRepo.UpdateStatus(ctx, task.ID, task.Status); revertErr != nil {
+			s.logger.Error("Failed to revert scan status: task_id=%d, error=%v", task.ID, revertErr)
+		}
 		return errors.ScanFailed("failed to start scan", err)
 	}
 
-	// 更新状态
-	if err := s.scanRepo.UpdateStatus(ctx, taskID, "running"); err != nil {
-		return errors.Wrap(err, "failed to update scan status")
+	return nil
+}
+
+// scanTargets 获取任务的全部目标，多目标任务按创建时的顺序返回
+func (s *ScanService) scanTargets(ctx context.Context, task *models.ScanTask) ([]scanner.ScanTarget, error) {
+	ids := task.TargetIDs
+	if len(ids) == 0 {
+		ids = []int{task.TargetID}
+	}
+
+	targets := make([]scanner.ScanTarget, 0, len(ids))
+	for _, id := range ids {
+		target, err := s.targetRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get target")
+		}
+		targets = append(targets, scanner.ScanTarget{ID: target.ID, URL: target.URL})
+	}
+	return targets, nil
+}
+
+// targetList 为多目标任务写入 nuclei 目标列表文件，单目标任务返回空
+// 每次启动按相同顺序重写，保证断点续扫时输入一致
+func (s *ScanService) targetList(task *models.ScanTask, targets []scanner.ScanTarget) (string, error) {
+	if len(targets) <= 1 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(s.targetsDir, 0755); err != nil {
+		return "", errors.Internal("failed to create target list directory", err)
+	}
+
+	var b strings.Builder
+	for _, t := range targets {
+		b.WriteString(t.URL)
+		b.WriteString("\n")
+	}
+
+	path := s.targetListPath(task.ID)
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", errors.Internal("failed to write target list", err)
+	}
+	return path, nil
+}
+
+// targetListPath 返回任务的目标列表文件路径
+func (s *ScanService) targetListPath(taskID int) string {
+	return filepath.Join(s.targetsDir, fmt.Sprintf("scan-%d.txt", taskID))
+}
+
+// resumeFile 返回任务的断点文件路径，首次启动时分配并记录
+// 停止或中断后再次启动时 nuclei 从该文件继续
+func (s *ScanService) resumeFile(ctx context.Context, task *models.ScanTask) (string, error) {
+	if path := utils.DerefString(task.ResumeFile); path != "" {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(s.resumeDir, 0755); err != nil {
+		return "", errors.Internal("failed to create resume directory", err)
+	}
+	path := filepath.Join(s.resumeDir, fmt.Sprintf("scan-%d.cfg", task.ID))
+	if err := s.scanRepo.UpdateResumeFile(ctx, task.ID, path); err != nil {
+		return "", errors.Wrap(err, "failed to save resume file")
+	}
+	return path, nil
+}
+
+// secretFile 为任务目标生成 nuclei 认证凭据文件，没有启用的认证配置时返回空
+func (s *ScanService) secretFile(ctx context.Context, task *models.ScanTask, targets []scanner.ScanTarget) (string, []string, error) {
+	if s.auth == nil {
+		return "", nil, nil
+	}
+
+	if err := os.MkdirAll(s.secretsDir, 0700); err != nil {
+		return "", nil, errors.Internal("failed to create secrets directory", err)
+	}
+	path := filepath.Join(s.secretsDir, fmt.Sprintf("scan-%d.yaml", task.ID))
+	written, secrets, err := s.auth.WriteSecretFile(ctx, targets, path)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to prepare scan authentication")
+	}
+	if !written {
+		return "", nil, nil
+	}
+	return path, secrets, nil
+}
+
+// templateSnapshot 为任务写入启用的自定义模板快照与禁用内置模板的排除列表，没有时分别返回空
+// 扫描使用快照而不是同步目录，运行期间编辑或禁用模板不影响本次扫描
+func (s *ScanService) templateSnapshot(ctx context.Context, task *models.ScanTask) (string, string, error) {
+	if s.templates == nil {
+		return "", "", nil
+	}
+	dir, err := s.templates.Snapshot(ctx, task.ID)
+	if err != nil {
+		return "", "", errors.Internal("failed to prepare custom templates", err)
+	}
+	excludeFile, err := s.templates.ExclusionFile(ctx, task.ID)
+	if err != nil {
+		s.removeTemplateSnapshot(task.ID)
+		return "", "", errors.Internal("failed to prepare template exclusions", err)
+	}
+	return dir, excludeFile, nil
+}
+
+// templateManifest 返回任务的模板清单文件与模板数
+// 首次启动时按策略解析清单并保存，之后重新启动沿用保存的清单，保证续扫与重跑运行相同的模板
+// 模板表为空或任务在引入清单前已启动过时返回空，按策略参数扫描
+func (s *ScanService) templateManifest(ctx context.Context, task *models.ScanTask) (string, int, error) {
+	if s.templates == nil {
+		return "", 0, nil
 	}
 
+	entries, err := s.scanRepo.GetTemplateManifest(ctx, task.ID)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "failed to get template manifest")
+	}
+	if len(entries) == 0 {
+		// 已有断点文件的任务按原参数续扫，避免输入变化导致断点失效
+		if utils.DerefString(task.ResumeFile) != "" {
+			return "", 0, nil
+		}
+		entries, err = s.templates.ResolveManifest(ctx, task.Strategy, task.TemplatesUsed)
+		if err != nil {
+			return "", 0, errors.Internal("failed to resolve template manifest", err)
+		}
+		if entries == nil {
+			s.logger.Warn("Template table is empty or stale, scanning by strategy without a manifest: task_id=%d", task.ID)
+			return "", 0, nil
+		}
+		if len(entries) == 0 {
+			return "", 0, errors.InvalidInput(fmt.Sprintf("scan strategy %s matches no enabled templates", task.Strategy))
+		}
+		if err := s.scanRepo.SaveTemplateManifest(ctx, task.ID, entries); err != nil {
+			return "", 0, errors.Wrap(err, "failed to save template manifest")
+		}
+	}
+
+	path, err := s.templates.ManifestFile(task.ID, entries)
+	if err != nil {
+		return "", 0, errors.Internal("failed to write template manifest", err)
+	}
+	return path, len(entries), nil
+}
+
+// removeTemplateSnapshot 删除任务的自定义模板快照
+func (s *ScanService) removeTemplateSnapshot(taskID int) {
+	if s.templates == nil {
+		return
+	}
+	if err := s.templates.RemoveSnapshot(taskID); err != nil {
+		s.logger.Warn("Failed to remove template snapshot: task_id=%d, error=%v", taskID, err)
+	}
+}
+
+// removeStaleSecretFiles 删除上次退出时遗留的凭据文件
+func (s *ScanService) removeStaleSecretFiles() {
+	entries, err := os.ReadDir(s.secretsDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		var taskID int
+		if _, err := fmt.Sscanf(entry.Name(), "scan-%d.yaml", &taskID); err == nil && s.scanner.IsRunning(taskID) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.secretsDir, entry.Name())); err != nil {
+			s.logger.Warn("Failed to remove stale secret file: file=%s, error=%v", entry.Name(), err)
+		}
+	}
+}
+
+// enqueue 将任务加入队列末尾
+func (s *ScanService) enqueue(ctx context.Context, task *models.ScanTask) error {
+	if err := s.scanRepo.Enqueue(ctx, task.ID); err != nil {
+		return err
+	}
+
+	queued, err := s.scanRepo.GetByID(ctx, task.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get queued scan task")
+	}
+
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScanQueued,
+		Data: map[string]interface{}{
+			"taskId":   task.ID,
+			"targetId": task.TargetID,
+			"position": utils.DerefInt(queued.QueuePosition),
+		},
+	})
+
+	s.logger.Info("Scan queued: task_id=%d, position=%d", task.ID, utils.DerefInt(queued.QueuePosition))
 	return nil
 }
 
-// Stop 停止扫描任务
+// DispatchQueue 按顺序启动排队中的任务，直到并发槽位用尽或队列为空
+func (s *ScanService) DispatchQueue(ctx context.Context) error {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	for s.scanner.HasCapacity() {
+		task, err := s.scanRepo.NextQueued(ctx)
+		if errors.Is(err, errors.ErrCodeNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 队列中的任务在后台启动，不能使用调用方的 context
+		if err := s.launch(context.Background(), task); err != nil {
+			s.failQueued(ctx, task.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// failQueued 将无法启动的排队任务标记为失败，避免阻塞队列
+func (s *ScanService) failQueued(ctx context.Context, taskID int, cause error) {
+	s.logger.Error("Failed to start queued scan: task_id=%d, error=%v", taskID, cause)
+
+	if err := s.scanRepo.UpdateStatus(ctx, taskID, "failed"); err != nil {
+		s.logger.Error("Failed to update scan status to failed: task_id=%d, error=%v", taskID, err)
+	}
+	if err := s.scanRepo.AddLog(ctx, taskID, "error", fmt.Sprintf("Scan failed: %s", errors.SanitizeUserError(cause))); err != nil {
+		s.logger.Warn("Failed to persist scan log: task_id=%d, error=%v", taskID, err)
+	}
+
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScanFailed,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+			"error":  errors.SanitizeUserError(cause),
+		},
+	})
+}
+
+// GetQueue 按排队顺序获取排队中的任务
+func (s *ScanService) GetQueue(ctx context.Context) ([]*models.ScanTask, error) {
+	return s.scanRepo.GetQueued(ctx)
+}
+
+// ReorderQueue 调整队列顺序，taskIDs 中的任务依次排在队首，其余任务保持原有顺序
+func (s *ScanService) ReorderQueue(ctx context.Context, taskIDs []int) error {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	queued, err := s.scanRepo.GetQueued(ctx)
+	if err != nil {
+		return err
+	}
+
+	inQueue := make(map[int]bool, len(queued))
+	for _, t := range queued {
+		inQueue[t.ID] = true
+	}
+
+	order := make([]int, 0, len(queued))
+	seen := make(map[int]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		if !inQueue[id] {
+			return errors.InvalidInput(fmt.Sprintf("scan task %d is not queued", id))
+		}
+		if seen[id] {
+			return errors.InvalidInput(fmt.Sprintf("duplicate scan task id: %d", id))
+		}
+		seen[id] = true
+		order = append(order, id)
+	}
+	for _, t := range queued {
+		if !seen[t.ID] {
+			order = append(order, t.ID)
+		}
+	}
+
+	return s.scanRepo.SetQueuePositions(ctx, order)
+}
+
+// Stop 停止扫描任务，排队中的任务直接移出队列
 func (s *ScanService) Stop(ctx context.Context, taskID int) error {
 	// 获取任务
 	task, err := s.scanRepo.GetByID(ctx, taskID)
@@ -160,11 +620,32 @@ func (s *ScanService) Stop(ctx context.Context, taskID int) error {
 		return errors.Wrap(err, "failed to get scan task")
 	}
 
+	if task.Status == "queued" {
+		return s.cancelQueued(ctx, taskID)
+	}
+
 	// 状态检查
-	if task.Status != "running" {
+	if task.Status != "running" && task.Status != "paused" {
 		return errors.Conflict("scan task is not running")
 	}
 
+	// 以断点方式暂停的任务没有运行中的进程，直接更新状态
+	if task.Status == "paused" && !s.scanner.IsRunning(taskID) {
+		if err := s.scanRepo.UpdateStatus(ctx, taskID, "stopped"); err != nil {
+			return errors.Wrap(err, "failed to update scan status")
+		}
+		return nil
+	}
+
+	// 编排器中没有该任务说明进程已不存在（例如应用异常退出），直接更新状态
+	if !s.scanner.IsRunning(taskID) {
+		s.logger.Warn("Stopping orphaned scan task: task_id=%d", taskID)
+		if err := s.scanRepo.UpdateStatus(ctx, taskID, "stopped"); err != nil {
+			return errors.Wrap(err, "failed to update scan status")
+		}
+		return nil
+	}
+
 	// 停止扫描
 	if err := s.scanner.Stop(ctx, taskID); err != nil {
 		return errors.Wrap(err, "failed to stop scanner")
@@ -178,6 +659,148 @@ func (s *ScanService) Stop(ctx context.Context, taskID int) error {
 	return nil
 }
 
+// cancelQueued 取消排队中的任务
+func (s *ScanService) cancelQueued(ctx context.Context, taskID int) error {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+
+	// 加锁后重新检查，任务可能已被出队启动
+	task, err := s.scanRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get scan task")
+	}
+	if task.Status != "queued" {
+		return errors.Conflict(fmt.Sprintf("scan task is %s, not queued", task.Status))
+	}
+
+	if err := s.scanRepo.UpdateStatus(ctx, taskID, "stopped"); err != nil {
+		return errors.Wrap(err, "failed to update scan status")
+	}
+
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScanStopped,
+		Data: map[string]interface{}{
+			"taskId": taskID,
+		},
+	})
+
+	s.logger.Info("Queued scan cancelled: task_id=%d", taskID)
+	return nil
+}
+
+// Pause 暂停运行中的扫描任务
+func (s *ScanService) Pause(ctx context.Context, taskID int) error {
+	task, err := s.scanRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get scan task")
+	}
+	if task.Status != "running" || !s.scanner.IsRunning(taskID) {
+		return errors.Conflict(fmt.Sprintf("scan task is %s, cannot pause", task.Status))
+	}
+
+	if err := s.scanner.Pause(ctx, taskID); err != nil {
+		return errors.Wrap(err, "failed to pause scanner")
+	}
+	if err := s.scanRepo.UpdateStatus(ctx, taskID, "paused"); err != nil {
+		return errors.Wrap(err, "failed to update scan status")
+	}
+
+	// 暂停的任务让出槽位时启动队列中的下一个任务
+	if err := s.DispatchQueue(context.Background()); err != nil {
+		s.logger.Error("Failed to dispatch scan queue: %v", err)
+	}
+	return nil
+}
+
+// resumePaused 恢复暂停的扫描任务，进程已停止时从断点重新启动
+func (s *ScanService) resumePaused(ctx context.Context, task *models.ScanTask) error {
+	if s.scanner.IsPaused(task.ID) {
+		if err := s.scanner.Resume(ctx, task.ID); err != nil {
+			return errors.Wrap(err, "failed to resume scanner")
+		}
+		if err := s.scanRepo.UpdateStatus(ctx, task.ID, "running"); err != nil {
+			return errors.Wrap(err, "failed to update scan status")
+		}
+		return nil
+	}
+
+	// 以断点方式暂停的进程可能仍在退出
+	if s.scanner.IsRunning(task.ID) {
+		return errors.Conflict("scan task is still pausing, try again later")
+	}
+	return s.startTask(ctx, task)
+}
+
+// RecoverInterrupted 将上次退出时仍处于 running 或 paused 状态的任务标记为 interrupted
+// 并终止遗留的 nuclei 进程、清理遗留的凭据文件与模板快照，应在启动任何扫描之前调用
+func (s *ScanService) RecoverInterrupted(ctx context.Context) (int, error) {
+	s.removeStaleSecretFiles()
+	if s.templates != nil {
+		if err := s.templates.RemoveStaleSnapshots(s.scanner.IsRunning); err != nil {
+			s.logger.Warn("Failed to remove stale template snapshots: %v", err)
+		}
+	}
+
+	tasks, err := s.scanRepo.GetByStatus(ctx, "running")
+	if err != nil {
+		return 0, err
+	}
+	paused, err := s.scanRepo.GetByStatus(ctx, "paused")
+	if err != nil {
+		return 0, err
+	}
+	tasks = append(tasks, paused...)
+
+	recovered := 0
+	for _, task := range tasks {
+		if s.scanner.IsRunning(task.ID) {
+			continue
+		}
+
+		message := "Scan interrupted: application exited while the scan was running"
+		if pid := utils.DerefInt(task.PID); pid > 0 {
+			killed, err := scanner.KillOrphanProcess(pid)
+			switch {
+			case err != nil:
+				s.logger.Warn("Failed to kill orphaned nuclei process: task_id=%d, pid=%d, error=%v", task.ID, pid, err)
+			case killed:
+				message += fmt.Sprintf(", killed leftover nuclei process pid=%d", pid)
+			}
+		}
+
+		if err := s.scanRepo.UpdateStatus(ctx, task.ID, "interrupted"); err != nil {
+			return recovered, errors.Wrap(err, "failed to mark scan interrupted")
+		}
+		if err := s.scanRepo.AddLog(ctx, task.ID, "warning", message); err != nil {
+			s.logger.Warn("Failed to persist scan log: task_id=%d, error=%v", task.ID, err)
+		}
+
+		s.logger.Warn("%s: task_id=%d", message, task.ID)
+		recovered++
+	}
+
+	return recovered, nil
+}
+
+// Resume 恢复暂停的扫描任务，或重新启动被中断的扫描任务
+func (s *ScanService) Resume(ctx context.Context, taskID int) error {
+	task, err := s.scanRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get scan task")
+	}
+	if task.Status == "paused" {
+		return s.resumePaused(ctx, task)
+	}
+	if task.Status != "interrupted" {
+		return errors.Conflict(fmt.Sprintf("scan task is %s, not paused or interrupted", task.Status))
+	}
+
+	if err := s.scanRepo.AddLog(ctx, taskID, "info", "Scan resumed after interruption"); err != nil {
+		s.logger.Warn("Failed to persist scan log: task_id=%d, error=%v", taskID, err)
+	}
+	return s.Start(ctx, taskID)
+}
+
 // GetProgress 获取扫描进度
 func (s *ScanService) GetProgress(ctx context.Context, taskID int) (*models.ScanProgress, error) {
 	// 先从 Scanner 获取实时进度
@@ -227,11 +850,69 @@ func (s *ScanService) Delete(ctx context.Context, id int) error {
 		return err
 	}
 
-	if task.Status == "running" {
-		return errors.Conflict("cannot delete running scan task, stop it first")
+	if task.Status == "running" || task.Status == "paused" {
+		return errors.Conflict(fmt.Sprintf("cannot delete %s scan task, stop it first", task.Status))
+	}
+
+	if err := s.scanRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if path := utils.DerefString(task.ResumeFile); path != "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to remove resume file: task_id=%d, path=%s, error=%v", id, path, err)
+		}
+	}
+	if len(task.TargetIDs) > 1 {
+		path := s.targetListPath(id)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			s.logger.Warn("Failed to remove target list: task_id=%d, path=%s, error=%v", id, path, err)
+		}
+	}
+	return nil
+}
+
+// GetTemplateManifest 获取扫描任务运行的模板清单
+func (s *ScanService) GetTemplateManifest(ctx context.Context, taskID int) ([]models.TemplateManifestEntry, error) {
+	if taskID <= 0 {
+		return nil, errors.InvalidInput("invalid scan task id")
+	}
+	if _, err := s.scanRepo.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return s.scanRepo.GetTemplateManifest(ctx, taskID)
+}
+
+// CompareTemplateManifests 比较两次扫描运行的模板，baseTaskID 为 0 时与同一目标上一次带清单的扫描比较
+func (s *ScanService) CompareTemplateManifests(ctx context.Context, taskID, baseTaskID int) (*models.TemplateManifestDiff, error) {
+	current, err := s.GetTemplateManifest(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(current) == 0 {
+		return nil, errors.NotFound(fmt.Sprintf("scan task %d has no template manifest", taskID))
+	}
+
+	if baseTaskID == 0 {
+		if baseTaskID, err = s.scanRepo.GetPreviousManifestScanID(ctx, taskID); err != nil {
+			return nil, err
+		}
+		if baseTaskID == 0 {
+			return nil, errors.NotFound(fmt.Sprintf("no previous scan with a template manifest for scan task %d", taskID))
+		}
+	}
+	base, err := s.GetTemplateManifest(ctx, baseTaskID)
+	if err != nil {
+		return nil, err
+	}
+	if len(base) == 0 {
+		return nil, errors.NotFound(fmt.Sprintf("scan task %d has no template manifest", baseTaskID))
 	}
 
-	return s.scanRepo.Delete(ctx, id)
+	diff := diffTemplateManifests(base, current)
+	diff.ScanID = taskID
+	diff.BaseScanID = baseTaskID
+	return diff, nil
 }
 
 // GetStats 获取扫描统计
@@ -242,15 +923,29 @@ func (s *ScanService) GetStats(ctx context.Context) (*ScanStats, error) {
 	}
 
 	return &ScanStats{
-		Total:     sumValues(counts),
-		Pending:   counts["pending"],
-		Running:   counts["running"],
-		Completed: counts["completed"],
-		Failed:    counts["failed"],
-		Stopped:   counts["stopped"],
+		Total:       sumValues(counts),
+		Pending:     counts["pending"],
+		Queued:      counts["queued"],
+		Running:     counts["running"],
+		Paused:      counts["paused"],
+		Completed:   counts["completed"],
+		Failed:      counts["failed"],
+		Stopped:     counts["stopped"],
+		Interrupted: counts["interrupted"],
+		TimedOut:    counts["timed_out"],
 	}, nil
 }
 
+// DefaultOptions 获取全局调优默认值
+func (s *ScanService) DefaultOptions() models.ScanOptions {
+	return *mergeScanOptions(s.defaultOptions, nil)
+}
+
+// Engines 列出可选的扫描引擎
+func (s *ScanService) Engines() []models.ScanEngine {
+	return s.scanner.Engines()
+}
+
 // GetNucleiStatus 获取 Nuclei 状态
 func (s *ScanService) GetNucleiStatus() *models.NucleiStatus {
 	status := s.scanner.GetStatus()
@@ -261,18 +956,26 @@ func (s *ScanService) GetNucleiStatus() *models.NucleiStatus {
 type CreateScanRequest struct {
 	Name      string
 	TargetID  int
+	TargetIDs []int  // 多目标任务的目标列表
+	TargetTag string // 扫描带有该标签的全部目标
 	Strategy  string
 	Templates []string
+	Options   *models.ScanOptions // 任务级调优参数，未设置的字段使用全局默认值
+	Engine    string              // 扫描引擎，为空时使用 nuclei
 }
 
 // ScanStats 扫描统计
 type ScanStats struct {
-	Total     int
-	Pending   int
-	Running   int
-	Completed int
-	Failed    int
-	Stopped   int
+	Total       int
+	Pending     int
+	Queued      int
+	Running     int
+	Paused      int
+	Completed   int
+	Failed      int
+	Stopped     int
+	Interrupted int
+	TimedOut    int
 }
 
 func sumValues(m map[string]int) int {
@@ -0,0 +1,176 @@
+package svc
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// 调优参数的取值上限，避免误填导致目标被压垮或扫描无法结束
+const (
+	maxScanRateLimit   = 10000
+	maxScanConcurrency = 500
+	maxScanTimeout     = 600
+	maxScanRetries     = 10
+	maxScanHeaders     = 50
+	maxScanDuration    = 7 * 24 * 3600
+)
+
+// headerNamePattern HTTP 请求头名称允许的字符
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// defaultScanOptions 从配置读取全局调优默认值
+func defaultScanOptions(cfg *config.Config) models.ScanOptions {
+	return models.ScanOptions{
+		RateLimit:   cfg.ScanRateLimit,
+		Concurrency: cfg.ScanConcurrency,
+		Timeout:     cfg.ScanRequestTimeout,
+		Retries:     cfg.ScanRetries,
+		Proxy:       cfg.ScanProxy,
+		MaxDuration: cfg.ScanTimeout,
+	}
+}
+
+// normalizeScanOptions 校验并规范化任务级调优参数，全部未设置时返回 nil
+func normalizeScanOptions(opts *models.ScanOptions) (*models.ScanOptions, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	if opts.RateLimit < 0 || opts.RateLimit > maxScanRateLimit {
+		return nil, errors.InvalidInput(fmt.Sprintf("rate limit must be between 0 (default) and %d", maxScanRateLimit))
+	}
+	if opts.Concurrency < 0 || opts.Concurrency > maxScanConcurrency {
+		return nil, errors.InvalidInput(fmt.Sprintf("concurrency must be between 0 (default) and %d", maxScanConcurrency))
+	}
+	if opts.Timeout < 0 || opts.Timeout > maxScanTimeout {
+		return nil, errors.InvalidInput(fmt.Sprintf("request timeout must be between 0 (default) and %d seconds", maxScanTimeout))
+	}
+	if opts.Retries < -1 || opts.Retries > maxScanRetries {
+		return nil, errors.InvalidInput(fmt.Sprintf("retries must be between -1 and %d", maxScanRetries))
+	}
+	if opts.MaxDuration < -1 || opts.MaxDuration > maxScanDuration {
+		return nil, errors.InvalidInput(fmt.Sprintf("max duration must be between -1 and %d seconds", maxScanDuration))
+	}
+
+	normalized := &models.ScanOptions{
+		RateLimit:   opts.RateLimit,
+		Concurrency: opts.Concurrency,
+		Timeout:     opts.Timeout,
+		Retries:     opts.Retries,
+		Proxy:       strings.TrimSpace(opts.Proxy),
+		MaxDuration: opts.MaxDuration,
+	}
+
+	if normalized.Proxy != "" {
+		if err := validateProxy(normalized.Proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opts.Headers) > maxScanHeaders {
+		return nil, errors.InvalidInput(fmt.Sprintf("at most %d headers are allowed", maxScanHeaders))
+	}
+	for _, header := range opts.Headers {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		h, err := normalizeHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		normalized.Headers = append(normalized.Headers, h)
+	}
+
+	if normalized.RateLimit == 0 && normalized.Concurrency == 0 && normalized.Timeout == 0 &&
+		normalized.Retries == 0 && normalized.Proxy == "" && len(normalized.Headers) == 0 &&
+		normalized.MaxDuration == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// mergeScanOptions 用任务级参数覆盖全局默认值
+func mergeScanOptions(defaults models.ScanOptions, overrides *models.ScanOptions) *models.ScanOptions {
+	merged := defaults
+	merged.Headers = append([]string(nil), defaults.Headers...)
+	if overrides == nil {
+		return &merged
+	}
+
+	if overrides.RateLimit != 0 {
+		merged.RateLimit = overrides.RateLimit
+	}
+	if overrides.Concurrency != 0 {
+		merged.Concurrency = overrides.Concurrency
+	}
+	if overrides.Timeout != 0 {
+		merged.Timeout = overrides.Timeout
+	}
+	if overrides.Retries != 0 {
+		merged.Retries = overrides.Retries
+	}
+	if overrides.Proxy != "" {
+		merged.Proxy = overrides.Proxy
+	}
+	if overrides.MaxDuration != 0 {
+		merged.MaxDuration = overrides.MaxDuration
+	}
+	merged.Headers = append(merged.Headers, overrides.Headers...)
+	return &merged
+}
+
+// headerSecrets 返回请求头的值，请求头可能携带凭据，扫描命令写入日志前需要隐藏
+func headerSecrets(options *models.ScanOptions) []string {
+	var values []string
+	for _, header := range options.Headers {
+		if _, value, ok := strings.Cut(header, ":"); ok {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// scanTimeout 将合并后的最长运行秒数转换为扫描超时，未设置或 -1 时不限制
+func scanTimeout(options *models.ScanOptions) time.Duration {
+	if options == nil || options.MaxDuration <= 0 {
+		return 0
+	}
+	return time.Duration(options.MaxDuration) * time.Second
+}
+
+// validateProxy 校验代理地址，仅支持 HTTP 与 SOCKS5 代理
+func validateProxy(proxy string) error {
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return errors.InvalidInput("invalid proxy url")
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "socks5":
+		return nil
+	default:
+		return errors.InvalidInput("proxy must use http, https or socks5 scheme")
+	}
+}
+
+// normalizeHeader 校验请求头并规范为 "Name: value" 格式
+func normalizeHeader(header string) (string, error) {
+	if strings.ContainsAny(header, "\r\n") {
+		return "", errors.InvalidInput("header must not contain line breaks")
+	}
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || !headerNamePattern.MatchString(name) {
+		// 请求头的值可能包含凭据，错误信息中只保留名称
+		return "", errors.InvalidInput(fmt.Sprintf("invalid header name: %q", name))
+	}
+	return name + ": " + strings.TrimSpace(value), nil
+}
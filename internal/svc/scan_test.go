@@ -3,13 +3,26 @@ package svc
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/holehunter/holehunter/internal/infrastructure/config"
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
 	"github.com/holehunter/holehunter/internal/infrastructure/event"
 	"github.com/holehunter/holehunter/internal/infrastructure/logger"
 	"github.com/holehunter/holehunter/internal/models"
 	"github.com/holehunter/holehunter/internal/repo"
 	"github.com/holehunter/holehunter/internal/scanner"
+	"github.com/holehunter/holehunter/internal/utils"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -479,6 +492,979 @@ func TestSumValues(t *testing.T) {
 	}
 }
 
+// TestScanService_Queue 测试并发已满时任务排队并在槽位释放后自动启动
+func TestScanService_Queue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, "sleep 0.5")
+	ctx := context.Background()
+
+	ids := make([]int, 3)
+	for i := range ids {
+		task, err := service.Create(ctx, &CreateScanRequest{Name: "queued scan", TargetID: targetID, Strategy: "quick"})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		ids[i] = task.ID
+		if err := service.Start(ctx, task.ID); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+	}
+
+	assertScanStatus(t, service, ids[0], "running")
+	assertScanStatus(t, service, ids[1], "queued")
+	assertScanStatus(t, service, ids[2], "queued")
+
+	// 排队中的任务不能重复启动
+	if err := service.Start(ctx, ids[1]); err == nil {
+		t.Error("Start() should fail for queued task")
+	}
+
+	// 第三个任务调到队首
+	if err := service.ReorderQueue(ctx, []int{ids[2]}); err != nil {
+		t.Fatalf("ReorderQueue() failed: %v", err)
+	}
+	queue, err := service.GetQueue(ctx)
+	if err != nil {
+		t.Fatalf("GetQueue() failed: %v", err)
+	}
+	if len(queue) != 2 || queue[0].ID != ids[2] || queue[1].ID != ids[1] {
+		t.Fatalf("queue order = %v, want [%d %d]", queueIDs(queue), ids[2], ids[1])
+	}
+	if err := service.ReorderQueue(ctx, []int{ids[0]}); err == nil {
+		t.Error("ReorderQueue() should reject task that is not queued")
+	}
+
+	// 取消排队
+	if err := service.Stop(ctx, ids[1]); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	assertScanStatus(t, service, ids[1], "stopped")
+
+	// 第一个任务结束后自动启动队首任务
+	waitScanStatus(t, service, ids[0], "completed")
+	waitScanStatus(t, service, ids[2], "running", "completed")
+	assertScanStatus(t, service, ids[1], "stopped")
+}
+
+// TestScanService_DispatchQueue 测试重启后恢复队列中的任务
+func TestScanService_DispatchQueue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, "exit 0")
+	ctx := context.Background()
+
+	// 模拟上次退出时遗留的排队任务
+	scanRepo := repo.NewScanRepository(db, logger.New("error", ""))
+	var ids []int
+	for i := 0; i < 2; i++ {
+		task := &models.ScanTask{TargetID: targetID, Status: "pending", Strategy: "quick"}
+		if err := scanRepo.Create(ctx, task); err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		if err := scanRepo.Enqueue(ctx, task.ID); err != nil {
+			t.Fatalf("Enqueue() failed: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+
+	if err := service.DispatchQueue(ctx); err != nil {
+		t.Fatalf("DispatchQueue() failed: %v", err)
+	}
+
+	for _, id := range ids {
+		waitScanStatus(t, service, id, "completed")
+	}
+}
+
+// TestScanService_RecoverInterrupted 测试启动时恢复异常退出遗留的运行中任务
+func TestScanService_RecoverInterrupted(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, "exit 0")
+	ctx := context.Background()
+	scanRepo := repo.NewScanRepository(db, logger.New("error", ""))
+
+	// 模拟上次运行遗留的 nuclei 进程
+	orphanScript := filepath.Join(t.TempDir(), "nuclei")
+	if err := os.WriteFile(orphanScript, []byte("#!/bin/sh\nwhile :; do sleep 0.1; done\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	leftover := exec.Command(orphanScript)
+	if err := leftover.Start(); err != nil {
+		t.Fatalf("failed to start leftover process: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		_ = leftover.Wait()
+		close(exited)
+	}()
+
+	task := &models.ScanTask{TargetID: targetID, Status: "pending", Strategy: "quick"}
+	if err := scanRepo.Create(ctx, task); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := scanRepo.UpdateStatus(ctx, task.ID, "running"); err != nil {
+		t.Fatalf("UpdateStatus() failed: %v", err)
+	}
+	if err := scanRepo.UpdatePID(ctx, task.ID, leftover.Process.Pid); err != nil {
+		t.Fatalf("UpdatePID() failed: %v", err)
+	}
+
+	n, err := service.RecoverInterrupted(ctx)
+	if err != nil {
+		t.Fatalf("RecoverInterrupted() failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("RecoverInterrupted() = %d, want 1", n)
+	}
+	assertScanStatus(t, service, task.ID, "interrupted")
+
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("leftover nuclei process was not killed")
+	}
+
+	logs, err := service.GetLogs(ctx, task.ID)
+	if err != nil || len(logs) != 1 || logs[0].Level != "warning" {
+		t.Fatalf("GetLogs() = %v, %v, want one warning entry", logs, err)
+	}
+
+	// 中断的任务不能直接停止，但可以恢复
+	if err := service.Stop(ctx, task.ID); err == nil {
+		t.Error("Stop() should fail for interrupted task")
+	}
+	if err := service.Resume(ctx, task.ID); err != nil {
+		t.Fatalf("Resume() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+	if err := service.Resume(ctx, task.ID); err == nil {
+		t.Error("Resume() should fail for completed task")
+	}
+}
+
+// TestScanService_StopOrphaned 测试停止编排器中不存在的运行中任务
+func TestScanService_StopOrphaned(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, "exit 0")
+	ctx := context.Background()
+	scanRepo := repo.NewScanRepository(db, logger.New("error", ""))
+
+	task := &models.ScanTask{TargetID: targetID, Status: "running", Strategy: "quick"}
+	if err := scanRepo.Create(ctx, task); err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	if err := service.Stop(ctx, task.ID); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	assertScanStatus(t, service, task.ID, "stopped")
+}
+
+// TestScanService_ResumeFile 测试停止后再次启动时从断点文件继续
+func TestScanService_ResumeFile(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：断点文件存在时记录续扫并结束，否则运行到收到 SIGINT 后写入断点文件
+	script := `while [ $# -gt 0 ]; do [ "$1" = "-resume" ] && resume="$2"; shift; done
+if [ -f "$resume" ]; then echo resumed >> "$resume.log"; exit 0; fi
+trap 'echo checkpoint > "$resume"; exit 1' INT
+while :; do sleep 0.1; done`
+	service, targetID := newQueueTestScanService(t, db, script)
+	ctx := context.Background()
+
+	task, err := service.Create(ctx, &CreateScanRequest{Name: "deep scan", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	task, _ = service.GetByID(ctx, task.ID)
+	resumeFile := utils.DerefString(task.ResumeFile)
+	if filepath.Base(resumeFile) != fmt.Sprintf("scan-%d.cfg", task.ID) {
+		t.Fatalf("ResumeFile = %q, want per-task file", resumeFile)
+	}
+
+	// 等待进程启动后停止
+	waitScanPID(t, service, task.ID)
+	if err := service.Stop(ctx, task.ID); err != nil {
+		t.Fatalf("Stop() failed: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for service.scanner.IsRunning(task.ID) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	assertScanStatus(t, service, task.ID, "stopped")
+	if _, err := os.Stat(resumeFile); err != nil {
+		t.Fatalf("resume file not written on stop: %v", err)
+	}
+
+	// 再次启动时从断点继续，完成后清理断点文件
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	if data, err := os.ReadFile(resumeFile + ".log"); err != nil || string(data) != "resumed\n" {
+		t.Errorf("restart did not pass resume file: %q, %v", data, err)
+	}
+	if _, err := os.Stat(resumeFile); !os.IsNotExist(err) {
+		t.Errorf("resume file should be removed after completion, stat error = %v", err)
+	}
+	task, _ = service.GetByID(ctx, task.ID)
+	if task.ResumeFile != nil {
+		t.Errorf("ResumeFile = %q, want cleared after completion", *task.ResumeFile)
+	}
+}
+
+// TestScanService_Options 测试任务级调优参数的校验、保存以及与全局默认值合并后传给 nuclei
+func TestScanService_Options(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, `echo "$@" > "$(dirname "$0")/args"`)
+	service.defaultOptions = models.ScanOptions{RateLimit: 150, Concurrency: 25, Timeout: 10, Retries: 1}
+	ctx := context.Background()
+
+	invalid := []*models.ScanOptions{
+		{RateLimit: -1},
+		{Concurrency: maxScanConcurrency + 1},
+		{Timeout: maxScanTimeout + 1},
+		{Retries: -2},
+		{MaxDuration: -2},
+		{Proxy: "ftp://proxy.local:21"},
+		{Proxy: "not a url"},
+		{Headers: []string{"X-Bad\r\nInjected: 1"}},
+		{Headers: []string{"no colon"}},
+	}
+	for _, opts := range invalid {
+		if _, err := service.Create(ctx, &CreateScanRequest{Name: "x", TargetID: targetID, Strategy: "deep", Options: opts}); err == nil {
+			t.Errorf("Create() with options %+v should fail", opts)
+		}
+	}
+	// 0 表示使用默认值，错误信息与实际校验范围一致
+	if _, err := service.Create(ctx, &CreateScanRequest{Name: "x", TargetID: targetID, Strategy: "deep", Options: &models.ScanOptions{RateLimit: -1}}); err == nil || !strings.Contains(err.Error(), "between 0 (default) and") {
+		t.Errorf("Create() with negative rate limit = %v, want range including 0", err)
+	}
+
+	// 全部未设置时不保存
+	task, err := service.Create(ctx, &CreateScanRequest{Name: "defaults", TargetID: targetID, Strategy: "deep", Options: &models.ScanOptions{}})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if task.Options != nil {
+		t.Errorf("Options = %+v, want nil", task.Options)
+	}
+
+	task, err = service.Create(ctx, &CreateScanRequest{
+		Name:     "gentle",
+		TargetID: targetID,
+		Strategy: "deep",
+		Options: &models.ScanOptions{
+			RateLimit: 5,
+			Retries:   -1,
+			Proxy:     " http://127.0.0.1:8080 ",
+			Headers:   []string{"X-Scanner :holehunter", ""},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	want := &models.ScanOptions{RateLimit: 5, Retries: -1, Proxy: "http://127.0.0.1:8080", Headers: []string{"X-Scanner: holehunter"}}
+	if !reflect.DeepEqual(task.Options, want) {
+		t.Fatalf("Options = %+v, want %+v", task.Options, want)
+	}
+
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	args, _ := os.ReadFile(filepath.Join(filepath.Dir(service.resumeDir), "args"))
+	for _, flag := range []string{"-rl 5 ", "-c 25 ", "-timeout 10 ", "-retries 0 ", "-proxy http://127.0.0.1:8080 ", "-H X-Scanner: holehunter"} {
+		if !strings.Contains(string(args), flag) {
+			t.Errorf("nuclei args = %q, want %q", args, flag)
+		}
+	}
+}
+
+// TestScanService_HeaderRedaction 测试请求头的值不会以明文写入应用日志
+func TestScanService_HeaderRedaction(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	base, targetID := newQueueTestScanService(t, db, "exit 0")
+	dataDir := filepath.Dir(base.resumeDir)
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	log := logger.New("info", logFile)
+	cfg := &config.Config{DataDir: dataDir, TemplatesDir: dataDir, MaxConcurrent: 1}
+	service := NewScanService(repo.NewScanRepository(db, log), repo.NewTargetRepository(db), event.NewBus(), log, cfg)
+	service.defaultOptions = models.ScanOptions{Headers: []string{"Cookie: session=default-cookie-value"}}
+	ctx := context.Background()
+
+	task, err := service.Create(ctx, &CreateScanRequest{
+		Name: "headers", TargetID: targetID, Strategy: "deep",
+		Options: &models.ScanOptions{Headers: []string{"Authorization: Bearer header-token-value"}},
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if !strings.Contains(string(data), "Scan command:") || !strings.Contains(string(data), "-H Authorization: ******") {
+		t.Errorf("app log = %q, want the scan command with redacted headers", data)
+	}
+	for _, secret := range []string{"header-token-value", "default-cookie-value"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("app log leaks header value %q", secret)
+		}
+	}
+}
+
+// TestScanService_DefaultTimeout 测试默认配置下扫描不限制运行时间，只有显式配置时才开启
+func TestScanService_DefaultTimeout(t *testing.T) {
+	t.Setenv("HH_DATA_DIR", t.TempDir())
+	t.Setenv("HH_SCAN_TIMEOUT", "")
+
+	if timeout := scanTimeout(mergeScanOptions(defaultScanOptions(config.Load()), nil)); timeout != 0 {
+		t.Errorf("default scan timeout = %s, want no limit", timeout)
+	}
+
+	t.Setenv("HH_SCAN_TIMEOUT", "7200")
+	if timeout := scanTimeout(mergeScanOptions(defaultScanOptions(config.Load()), nil)); timeout != 2*time.Hour {
+		t.Errorf("scan timeout with HH_SCAN_TIMEOUT = %s, want 2h", timeout)
+	}
+}
+
+// TestScanService_Timeout 测试扫描超时后终止整个进程组并标记为 timed_out
+func TestScanService_Timeout(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("process check requires /proc")
+	}
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：启动一个不响应中断的子进程后等待，模拟卡住的扫描
+	script := `sleep 30 >/dev/null 2>&1 &
+echo $! > "$(dirname "$0")/child"
+wait`
+	service, targetID := newQueueTestScanService(t, db, script)
+	service.defaultOptions = models.ScanOptions{MaxDuration: 3600}
+	ctx := context.Background()
+
+	task, err := service.Create(ctx, &CreateScanRequest{
+		Name: "stuck", TargetID: targetID, Strategy: "deep", Options: &models.ScanOptions{MaxDuration: 1},
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "timed_out")
+
+	// 子进程随进程组一起被终止
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(service.resumeDir), "child"))
+	if err != nil {
+		t.Fatalf("child pid not recorded: %v", err)
+	}
+	var child int
+	fmt.Sscanf(string(data), "%d", &child)
+	deadline := time.Now().Add(2 * time.Second)
+	for processAlive(child) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if processAlive(child) {
+		t.Errorf("child process %d still running after timeout", child)
+	}
+
+	logs, err := service.GetLogs(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetLogs() failed: %v", err)
+	}
+	found := false
+	for _, log := range logs {
+		found = found || (log.Level == "error" && strings.Contains(log.Message, "Scan timed out: exceeded max duration of 1s"))
+	}
+	if !found {
+		t.Errorf("scan logs = %+v, want timeout log", logs)
+	}
+
+	stats, err := service.GetStats(ctx)
+	if err != nil || stats.TimedOut != 1 || stats.Failed != 0 {
+		t.Errorf("GetStats() = %+v, %v, want 1 timed out", stats, err)
+	}
+}
+
+// processAlive 检查进程是否仍在运行，僵尸进程视为已退出
+func processAlive(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// 状态字段位于进程名的右括号之后
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+// TestScanService_PauseResume 测试暂停与恢复扫描，暂停期间进程不运行且不计入超时
+func TestScanService_PauseResume(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：约 0.6 秒内持续写入计数文件
+	script := `i=0
+while [ $i -lt 12 ]; do echo $i >> "$(dirname "$0")/ticks"; i=$((i+1)); sleep 0.05; done`
+	service, targetID := newQueueTestScanService(t, db, script)
+	ctx := context.Background()
+	ticks := filepath.Join(filepath.Dir(service.resumeDir), "ticks")
+
+	task, err := service.Create(ctx, &CreateScanRequest{
+		Name: "pausable", TargetID: targetID, Strategy: "deep", Options: &models.ScanOptions{MaxDuration: 1},
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	other, err := service.Create(ctx, &CreateScanRequest{Name: "waiting", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	if err := service.Pause(ctx, task.ID); err == nil {
+		t.Error("Pause() should fail for pending task")
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanPID(t, service, task.ID)
+
+	if err := service.Pause(ctx, task.ID); err != nil {
+		t.Fatalf("Pause() failed: %v", err)
+	}
+	assertScanStatus(t, service, task.ID, "paused")
+	if err := service.Pause(ctx, task.ID); err == nil {
+		t.Error("Pause() should fail for paused task")
+	}
+
+	// 默认暂停的任务继续占用槽位
+	if err := service.Start(ctx, other.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	assertScanStatus(t, service, other.ID, "queued")
+	if err := service.Delete(ctx, task.ID); err == nil {
+		t.Error("Delete() should fail for paused task")
+	}
+
+	// 挂起期间进程不再输出，且超过最长运行时间也不会超时
+	before, _ := os.ReadFile(ticks)
+	time.Sleep(1200 * time.Millisecond)
+	after, _ := os.ReadFile(ticks)
+	if len(after) != len(before) {
+		t.Errorf("process kept running while paused: %d -> %d bytes", len(before), len(after))
+	}
+	assertScanStatus(t, service, task.ID, "paused")
+
+	if err := service.Resume(ctx, task.ID); err != nil {
+		t.Fatalf("Resume() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+	waitScanStatus(t, service, other.ID, "running", "completed")
+
+	logs, err := service.GetLogs(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetLogs() failed: %v", err)
+	}
+	var messages []string
+	for _, log := range logs {
+		messages = append(messages, log.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	if !strings.Contains(joined, "Scan paused") || !strings.Contains(joined, "Scan resumed: paused for") {
+		t.Errorf("scan logs = %q, want pause and resume entries", messages)
+	}
+}
+
+// TestScanService_RecoverPaused 测试暂停后异常退出时，恢复流程终止遗留的挂起进程
+func TestScanService_RecoverPaused(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, "while :; do sleep 0.1; done")
+	ctx := context.Background()
+
+	task, err := service.Create(ctx, &CreateScanRequest{Name: "paused", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanPID(t, service, task.ID)
+	if err := service.Pause(ctx, task.ID); err != nil {
+		t.Fatalf("Pause() failed: %v", err)
+	}
+
+	paused, err := service.GetByID(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if paused.PID == nil {
+		t.Fatal("paused task lost its process id")
+	}
+
+	// 模拟应用异常退出后重新启动：新的服务实例不持有该进程
+	restarted, _ := newQueueTestScanService(t, db, "exit 0")
+	n, err := restarted.RecoverInterrupted(ctx)
+	if err != nil {
+		t.Fatalf("RecoverInterrupted() failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("RecoverInterrupted() = %d, want 1", n)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for service.scanner.IsRunning(task.ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("paused nuclei process was not killed")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	logs, err := restarted.GetLogs(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetLogs() failed: %v", err)
+	}
+	var killed bool
+	for _, log := range logs {
+		if strings.Contains(log.Message, fmt.Sprintf("killed leftover nuclei process pid=%d", *paused.PID)) {
+			killed = true
+		}
+	}
+	if !killed {
+		t.Errorf("scan logs = %+v, want the leftover process to be killed", logs)
+	}
+}
+
+// TestScanService_PauseReleasesSlot 测试暂停的任务让出槽位时队列中的任务可以启动
+func TestScanService_PauseReleasesSlot(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, targetID := newQueueTestScanService(t, db, "sleep 1")
+	service.scanner.(*scanner.Orchestrator).SetReleasePausedSlots(true)
+	ctx := context.Background()
+
+	var ids []int
+	for i := 0; i < 2; i++ {
+		task, err := service.Create(ctx, &CreateScanRequest{Name: "scan", TargetID: targetID, Strategy: "deep"})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		if err := service.Start(ctx, task.ID); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	assertScanStatus(t, service, ids[1], "queued")
+	waitScanPID(t, service, ids[0])
+
+	if err := service.Pause(ctx, ids[0]); err != nil {
+		t.Fatalf("Pause() failed: %v", err)
+	}
+	waitScanStatus(t, service, ids[1], "running")
+
+	// 没有空闲槽位时不能恢复
+	if err := service.Resume(ctx, ids[0]); !errors.Is(err, errors.ErrCodeConflict) {
+		t.Errorf("Resume() without free slot error = %v, want conflict", err)
+	}
+	assertScanStatus(t, service, ids[0], "paused")
+
+	waitScanStatus(t, service, ids[1], "completed")
+	if err := service.Resume(ctx, ids[0]); err != nil {
+		t.Fatalf("Resume() failed: %v", err)
+	}
+	waitScanStatus(t, service, ids[0], "completed")
+}
+
+// TestScanService_DetachedContext 测试发起扫描的 context 结束后，之后到达的发现仍写入日志并发布事件
+func TestScanService_DetachedContext(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	script := `sleep 0.3
+echo '{"template-id":"exposed-panel","host":"https://example.com","matched-at":"https://example.com/admin","info":{"name":"Exposed Panel","severity":"high"}}'`
+	service, targetID := newQueueTestScanService(t, db, script)
+
+	found := make(chan struct{}, 1)
+	service.eventBus.Subscribe(event.EventVulnFound, func(ctx context.Context, e event.Event) error {
+		if ctx.Err() == nil {
+			found <- struct{}{}
+		}
+		return nil
+	})
+
+	task, err := service.Create(context.Background(), &CreateScanRequest{Name: "detached", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	// 模拟应用关闭时 Wails 调用的 context 结束
+	cancel()
+	waitScanStatus(t, service, task.ID, "completed")
+
+	select {
+	case <-found:
+	case <-time.After(5 * time.Second):
+		t.Fatal("vulnerability event was not delivered with a live context")
+	}
+	logs, err := service.GetLogs(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("GetLogs() failed: %v", err)
+	}
+	logged := false
+	for _, log := range logs {
+		if strings.HasPrefix(log.Message, "Vulnerability found:") && strings.Contains(log.Message, "https://example.com/admin") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Errorf("scan logs = %+v, want the finding logged after the caller context ended", logs)
+	}
+}
+
+// TestScanService_MultiTarget 测试多目标扫描任务的创建、执行与漏洞归属
+func TestScanService_MultiTarget(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：记录参数，并为列表中的第二个目标输出一个漏洞
+	script := `echo "$@" > "$(dirname "$0")/args"
+echo '{"template-id":"exposed-panel","host":"https://b.example.com","matched-at":"https://b.example.com/admin","info":{"name":"Exposed Panel","severity":"high"}}'`
+	service, firstID := newQueueTestScanService(t, db, script)
+	ctx := context.Background()
+
+	targetRepo := repo.NewTargetRepository(db)
+	var tagged []int
+	for _, host := range []string{"a", "b"} {
+		target := &models.Target{Name: host, URL: "https://" + host + ".example.com", Tags: []string{"prod"}}
+		if err := targetRepo.Create(ctx, target); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		tagged = append(tagged, target.ID)
+	}
+	if err := targetRepo.Create(ctx, &models.Target{Name: "c", URL: "https://c.example.com", Tags: []string{"production"}}); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	found := make(chan map[string]interface{}, 1)
+	service.eventBus.Subscribe(event.EventVulnFound, func(_ context.Context, e event.Event) error {
+		found <- e.Data.(map[string]interface{})
+		return nil
+	})
+
+	// 标签目标与显式目标合并去重
+	task, err := service.Create(ctx, &CreateScanRequest{
+		Name:      "prod scan",
+		TargetIDs: []int{firstID, tagged[1]},
+		TargetTag: "prod",
+		Strategy:  "quick",
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	wantIDs := []int{firstID, tagged[1], tagged[0]}
+	if !reflect.DeepEqual(task.TargetIDs, wantIDs) || task.TargetID != firstID || utils.DerefString(task.TargetTag) != "prod" {
+		t.Fatalf("task targets = %v (primary %d, tag %v), want %v", task.TargetIDs, task.TargetID, task.TargetTag, wantIDs)
+	}
+
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	listFile := filepath.Join(service.targetsDir, fmt.Sprintf("scan-%d.txt", task.ID))
+	args, _ := os.ReadFile(filepath.Join(filepath.Dir(service.resumeDir), "args"))
+	if !strings.HasPrefix(string(args), "-l "+listFile+" ") {
+		t.Errorf("nuclei args = %q, want target list %s", args, listFile)
+	}
+	list, err := os.ReadFile(listFile)
+	if err != nil || string(list) != "https://example.com\nhttps://b.example.com\nhttps://a.example.com\n" {
+		t.Errorf("target list = %q, %v", list, err)
+	}
+
+	select {
+	case data := <-found:
+		if data["targetId"] != tagged[1] {
+			t.Errorf("vulnerability targetId = %v, want %d", data["targetId"], tagged[1])
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("vulnerability event not published")
+	}
+
+	// 每个目标的扫描历史都包含该任务
+	for _, id := range wantIDs {
+		tasks, err := service.GetByTargetID(ctx, id)
+		if err != nil {
+			t.Fatalf("GetByTargetID() failed: %v", err)
+		}
+		if len(tasks) != 1 || tasks[0].ID != task.ID {
+			t.Errorf("GetByTargetID(%d) = %v, want task %d", id, queueIDs(tasks), task.ID)
+		}
+	}
+
+	if _, err := service.Create(ctx, &CreateScanRequest{Name: "none", TargetTag: "staging", Strategy: "quick"}); err == nil {
+		t.Error("Create() should reject tag without targets")
+	}
+	if _, err := service.Create(ctx, &CreateScanRequest{Name: "missing", TargetIDs: []int{firstID, 999}, Strategy: "quick"}); err == nil {
+		t.Error("Create() should reject unknown target")
+	}
+
+	if err := service.Delete(ctx, task.ID); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := os.Stat(listFile); !os.IsNotExist(err) {
+		t.Errorf("target list should be removed with the task, stat error = %v", err)
+	}
+}
+
+// TestScanService_Engines 测试选择扫描引擎，内置与外部引擎的发现都经同一事件入库为漏洞
+func TestScanService_Engines(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	service, _ := newQueueTestScanService(t, db, "exit 0")
+	ctx := context.Background()
+	dataDir := filepath.Dir(service.resumeDir)
+
+	// 与应用一致：漏洞事件交给漏洞服务入库
+	vulnRepo := repo.NewVulnerabilityRepository(db)
+	vulnService := NewVulnerabilityService(vulnRepo, logger.New("error", ""))
+	service.eventBus.Subscribe(event.EventVulnFound, func(ctx context.Context, e event.Event) error {
+		return vulnService.HandleEventVulnFound(ctx, e.Data.(map[string]interface{}))
+	})
+
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Server", "nginx/1.18.0")
+		if r.URL.Path == "/.env" {
+			fmt.Fprintln(w, "DB_PASSWORD=secret")
+			return
+		}
+		if r.URL.Path != "/" {
+			nethttp.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", "default-src 'self'")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		fmt.Fprintln(w, "<html>ok</html>")
+	}))
+	defer server.Close()
+	target := &models.Target{Name: "local", URL: server.URL}
+	if err := repo.NewTargetRepository(db).Create(ctx, target); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	// 外部工具输出自定义格式的 JSONL
+	tool := `echo "not json"
+echo '{"rule":{"id":"weak-cipher","title":"Weak Cipher"},"level":"warning","location":"'"$2"'","labels":["tls"]}'`
+	if err := os.WriteFile(filepath.Join(dataDir, "lint"), []byte("#!/bin/sh\n"+tool+"\n"), 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	spec := `name: lint
+command: ./lint
+args: ["-u", "{{target}}"]
+output:
+  fields:
+    template_id: rule.id
+    name: rule.title
+    severity: level
+    matched_at: location
+    tags: labels
+  severity_map:
+    warning: medium
+`
+	enginesDir := filepath.Join(dataDir, "engines")
+	if err := os.MkdirAll(enginesDir, 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(enginesDir, "lint.yaml"), []byte(spec), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dataDir, "lint"), filepath.Join(enginesDir, "lint")); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	engines, err := scanner.LoadExternalEngines(enginesDir)
+	if err != nil || len(engines) != 1 {
+		t.Fatalf("LoadExternalEngines() = %d engines, %v", len(engines), err)
+	}
+	service.scanner.(*scanner.Orchestrator).RegisterEngine(engines[0])
+
+	names := make(map[string]bool)
+	for _, engine := range service.Engines() {
+		names[engine.Name] = engine.Available
+	}
+	if !names["nuclei"] || !names["httpcheck"] || !names["lint"] {
+		t.Errorf("Engines() = %+v, want nuclei, httpcheck and lint available", service.Engines())
+	}
+
+	if _, err := service.Create(ctx, &CreateScanRequest{Name: "bad", TargetID: target.ID, Strategy: "quick", Engine: "zap"}); err == nil {
+		t.Error("Create() should reject unknown engine")
+	}
+
+	// runScan 启动扫描并等待漏洞入库
+	runScan := func(engine string) []*models.Vulnerability {
+		t.Helper()
+		task, err := service.Create(ctx, &CreateScanRequest{Name: engine, TargetID: target.ID, Strategy: "quick", Engine: engine})
+		if err != nil {
+			t.Fatalf("Create(%s) failed: %v", engine, err)
+		}
+		if task.Engine != engine {
+			t.Errorf("task engine = %q, want %q", task.Engine, engine)
+		}
+		if err := service.Start(ctx, task.ID); err != nil {
+			t.Fatalf("Start(%s) failed: %v", engine, err)
+		}
+		waitScanStatus(t, service, task.ID, "completed")
+
+		task, _ = service.GetByID(ctx, task.ID)
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			vulns, err := vulnRepo.GetByTaskID(ctx, task.ID)
+			if err != nil {
+				t.Fatalf("GetByTaskID() failed: %v", err)
+			}
+			if len(vulns) >= utils.DerefInt(task.FindingsCount) || time.Now().After(deadline) {
+				return vulns
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+
+	got := make(map[string]*models.Vulnerability)
+	for _, vuln := range runScan("httpcheck") {
+		got[vuln.TemplateID] = vuln
+	}
+	if len(got) != 2 {
+		t.Fatalf("httpcheck vulnerabilities = %v, want env file and version disclosure", got)
+	}
+	if v := got["http-env-file-exposure"]; v == nil || v.Severity != "high" || v.MatchedAt != server.URL+"/.env" || v.TargetID == nil || *v.TargetID != target.ID {
+		t.Errorf("env file vulnerability = %+v", v)
+	}
+	if v := got["http-version-disclosure"]; v == nil || v.Severity != "low" || !strings.Contains(v.Description, "nginx/1.18.0") {
+		t.Errorf("version disclosure vulnerability = %+v", v)
+	}
+
+	vulns := runScan("lint")
+	if len(vulns) != 1 {
+		t.Fatalf("lint vulnerabilities = %d, want 1", len(vulns))
+	}
+	if v := vulns[0]; v.TemplateID != "weak-cipher" || v.Name != "Weak Cipher" || v.Severity != "medium" || v.MatchedAt != server.URL || len(v.Tags) != 1 || v.Tags[0] != "tls" {
+		t.Errorf("lint vulnerability = %+v", v)
+	}
+}
+
+// newQueueTestScanService 创建使用假 nuclei 的扫描服务，最大并发为 1
+func newQueueTestScanService(t *testing.T, db *sql.DB, script string) (*ScanService, int) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake nuclei script requires a POSIX shell")
+	}
+
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "nuclei"), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake nuclei: %v", err)
+	}
+
+	targetRepo := repo.NewTargetRepository(db)
+	target := &models.Target{Name: "Queue Target", URL: "https://example.com"}
+	if err := targetRepo.Create(context.Background(), target); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	log := logger.New("error", "")
+	cfg := &config.Config{DataDir: dataDir, TemplatesDir: dataDir, MaxConcurrent: 1}
+	service := NewScanService(repo.NewScanRepository(db, log), targetRepo, event.NewBus(), log, cfg)
+	return service, target.ID
+}
+
+// assertScanStatus 检查扫描任务状态
+func assertScanStatus(t *testing.T, service *ScanService, taskID int, want string) {
+	t.Helper()
+	task, err := service.GetByID(context.Background(), taskID)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if task.Status != want {
+		t.Errorf("task %d status = %s, want %s", taskID, task.Status, want)
+	}
+}
+
+// waitScanStatus 等待扫描任务进入指定状态之一
+func waitScanStatus(t *testing.T, service *ScanService, taskID int, want ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	var status string
+	for time.Now().Before(deadline) {
+		task, err := service.GetByID(context.Background(), taskID)
+		if err != nil {
+			t.Fatalf("GetByID() failed: %v", err)
+		}
+		status = task.Status
+		for _, w := range want {
+			if status == w {
+				return
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("task %d status = %s, want one of %v", taskID, status, want)
+}
+
+// waitScanPID 等待扫描进程启动并记录 PID
+func waitScanPID(t *testing.T, service *ScanService, taskID int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		task, err := service.GetByID(context.Background(), taskID)
+		if err != nil {
+			t.Fatalf("GetByID() failed: %v", err)
+		}
+		if task.PID != nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("task %d process did not start in time", taskID)
+}
+
+// queueIDs 返回队列中的任务 ID
+func queueIDs(tasks []*models.ScanTask) []int {
+	ids := make([]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
 // setupTestDB 创建测试数据库
 func setupTestDB(t *testing.T) *sql.DB {
 	t.Helper()
@@ -486,6 +1472,8 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("failed to open test database: %v", err)
 	}
+	// 内存数据库每个连接相互独立，扫描 goroutine 需要共用同一连接
+	db.SetMaxOpenConns(1)
 
 	// 创建测试表结构
 	schema := `
@@ -503,6 +1491,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		name TEXT,
 		target_id INTEGER NOT NULL,
+		target_tag TEXT,
 		status TEXT NOT NULL,
 		strategy TEXT,
 		templates_used TEXT,
@@ -513,12 +1502,43 @@ func setupTestDB(t *testing.T) *sql.DB {
 		progress INTEGER DEFAULT 0,
 		current_template TEXT,
 		error TEXT,
+		findings_count INTEGER DEFAULT 0,
+		queue_position INTEGER,
+		pid INTEGER,
+		resume_file TEXT,
+		options TEXT,
+		engine TEXT NOT NULL DEFAULT 'nuclei',
 		created_at TEXT
 	);
 
+	CREATE TABLE scan_task_targets (
+		scan_id INTEGER NOT NULL,
+		target_id INTEGER NOT NULL,
+		position INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (scan_id, target_id)
+	);
+
+	CREATE TABLE scan_template_manifests (
+		scan_id INTEGER NOT NULL,
+		source TEXT NOT NULL,
+		template_id TEXT NOT NULL,
+		content_hash TEXT NOT NULL,
+		PRIMARY KEY (scan_id, source, template_id)
+	);
+
+	CREATE TABLE scan_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		scan_id INTEGER NOT NULL,
+		level TEXT NOT NULL,
+		message TEXT NOT NULL,
+		timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
 	CREATE TABLE vulnerabilities (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		task_id INTEGER NOT NULL,
+		target_id INTEGER,
 		template_id TEXT NOT NULL,
 		severity TEXT,
 		name TEXT,
@@ -557,6 +1577,51 @@ func setupTestDB(t *testing.T) *sql.DB {
 		created_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at      TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
+
+	CREATE TABLE http_requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		method TEXT NOT NULL,
+		url TEXT NOT NULL,
+		headers TEXT,
+		body TEXT,
+		content_type TEXT,
+		tags TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE auth_profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		target_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		enabled BOOLEAN DEFAULT 1,
+		username TEXT,
+		login_request_id INTEGER,
+		secret TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE scan_schedules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		target_id INTEGER,
+		target_tag TEXT,
+		strategy TEXT NOT NULL,
+		templates TEXT,
+		cron TEXT,
+		interval_seconds INTEGER DEFAULT 0,
+		catch_up BOOLEAN DEFAULT 0,
+		enabled BOOLEAN DEFAULT 1,
+		last_run_at DATETIME,
+		next_run_at DATETIME,
+		last_task_id INTEGER,
+		last_error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
 	`
 
 	if _, err := db.Exec(schema); err != nil {
@@ -0,0 +1,476 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/cron"
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/utils"
+)
+
+const (
+	// scheduleTickInterval 检查到期计划的间隔
+	scheduleTickInterval = 30 * time.Second
+	// missedRunGrace 到期超过该时长才执行的计划视为错过（例如应用关闭期间）
+	missedRunGrace = 2 * time.Minute
+	// minScheduleInterval 固定间隔计划的最小间隔
+	minScheduleInterval = time.Minute
+)
+
+// ScheduleService 扫描计划服务，到期时创建并启动扫描任务
+type ScheduleService struct {
+	repo         *repo.ScheduleRepository
+	targetRepo   *repo.TargetRepository
+	scenarioRepo *repo.ScenarioRepository
+	scanSvc      *ScanService
+	eventBus     *event.Bus
+	logger       *logger.Logger
+	now          func() time.Time
+
+	// mu 串行化到期检查与手动运行
+	mu sync.Mutex
+
+	lifecycleMu sync.Mutex
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewScheduleService 创建扫描计划服务
+func NewScheduleService(
+	repo *repo.ScheduleRepository,
+	targetRepo *repo.TargetRepository,
+	scenarioRepo *repo.ScenarioRepository,
+	scanSvc *ScanService,
+	eventBus *event.Bus,
+	logger *logger.Logger,
+) *ScheduleService {
+	return &ScheduleService{
+		repo:         repo,
+		targetRepo:   targetRepo,
+		scenarioRepo: scenarioRepo,
+		scanSvc:      scanSvc,
+		eventBus:     eventBus,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// GetAll 获取所有扫描计划
+func (s *ScheduleService) GetAll(ctx context.Context) ([]*models.ScanSchedule, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetByID 根据 ID 获取扫描计划
+func (s *ScheduleService) GetByID(ctx context.Context, id int) (*models.ScanSchedule, error) {
+	if id <= 0 {
+		return nil, errors.InvalidInput("invalid schedule id")
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Create 创建扫描计划
+func (s *ScheduleService) Create(ctx context.Context, req *models.ScanScheduleRequest) (*models.ScanSchedule, error) {
+	schedule := &models.ScanSchedule{}
+	if err := s.apply(ctx, schedule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Scan schedule created: schedule_id=%d, next_run=%s", schedule.ID, utils.DerefString(schedule.NextRunAt))
+	return s.repo.GetByID(ctx, schedule.ID)
+}
+
+// Update 更新扫描计划，下次运行时间从当前时间重新计算
+func (s *ScheduleService) Update(ctx context.Context, id int, req *models.ScanScheduleRequest) (*models.ScanSchedule, error) {
+	schedule, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, schedule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// SetEnabled 启用或停用扫描计划，重新启用时不补跑停用期间的执行
+func (s *ScheduleService) SetEnabled(ctx context.Context, id int, enabled bool) error {
+	schedule, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	schedule.Enabled = enabled
+	schedule.NextRunAt = nil
+	if enabled {
+		if schedule.NextRunAt, err = s.firstRun(schedule); err != nil {
+			return err
+		}
+	}
+
+	return s.repo.Update(ctx, schedule)
+}
+
+// Delete 删除扫描计划，已创建的扫描任务保留
+func (s *ScheduleService) Delete(ctx context.Context, id int) error {
+	if id <= 0 {
+		return errors.InvalidInput("invalid schedule id")
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// RunNow 立即运行一次扫描计划，不影响下次运行时间
+func (s *ScheduleService) RunNow(ctx context.Context, id int) (*models.ScanTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	taskID, runErr := s.trigger(ctx, schedule, now)
+	next, _ := parseScheduleTime(schedule.NextRunAt)
+	if err := s.repo.RecordRun(ctx, id, now, taskID, errorReason(runErr), next); err != nil {
+		s.logger.Error("Failed to record schedule run: schedule_id=%d, error=%v", id, err)
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	return s.scanSvc.GetByID(ctx, taskID)
+}
+
+// Start 启动后台调度，立即检查一次到期计划
+func (s *ScheduleService) Start() {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.loop(ctx, s.done)
+
+	s.logger.Info("Scan scheduler started")
+}
+
+// Stop 停止后台调度并等待当前检查结束
+func (s *ScheduleService) Stop() {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	if s.cancel == nil {
+		return
+	}
+
+	s.cancel()
+	<-s.done
+	s.cancel = nil
+	s.done = nil
+}
+
+// loop 定期检查到期计划
+func (s *ScheduleService) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(scheduleTickInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunDue(ctx); err != nil {
+			s.logger.Error("Failed to run due schedules: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue 运行所有到期的计划，返回创建的扫描任务数
+// 到期过久的计划视为错过：开启补跑时只运行一次，否则跳过
+// 上一次运行的任务仍在排队或运行时跳过本次执行
+func (s *ScheduleService) RunDue(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.repo.GetEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	started := 0
+	for _, schedule := range schedules {
+		due, ok := parseScheduleTime(schedule.NextRunAt)
+		if !ok || due.After(now) {
+			continue
+		}
+		if s.runDue(ctx, schedule, due, now) {
+			started++
+		}
+	}
+
+	return started, nil
+}
+
+// runDue 处理单个到期计划，返回是否创建了扫描任务
+func (s *ScheduleService) runDue(ctx context.Context, schedule *models.ScanSchedule, due, now time.Time) bool {
+	next, err := nextRun(schedule, now, due)
+	if err != nil {
+		s.logger.Error("Failed to compute next schedule run: schedule_id=%d, error=%v", schedule.ID, err)
+	}
+
+	if now.Sub(due) > missedRunGrace {
+		if !schedule.CatchUp {
+			s.skip(ctx, schedule, next, fmt.Sprintf("missed run at %s skipped", due.Local().Format(time.RFC3339)))
+			return false
+		}
+		s.logger.Info("Catching up missed schedule run: schedule_id=%d, due=%s", schedule.ID, due.Local().Format(time.RFC3339))
+	}
+
+	if s.previousRunActive(ctx, schedule) {
+		s.skip(ctx, schedule, next, fmt.Sprintf("previous scan task %d is still active", utils.DerefInt(schedule.LastTaskID)))
+		return false
+	}
+
+	taskID, runErr := s.trigger(ctx, schedule, now)
+	if runErr != nil {
+		s.logger.Error("Scheduled scan failed to start: schedule_id=%d, error=%v", schedule.ID, runErr)
+	}
+	if err := s.repo.RecordRun(ctx, schedule.ID, now, taskID, errorReason(runErr), next); err != nil {
+		s.logger.Error("Failed to record schedule run: schedule_id=%d, error=%v", schedule.ID, err)
+	}
+	return taskID > 0
+}
+
+// trigger 为计划创建并启动扫描任务，并发已满时任务进入扫描队列
+func (s *ScheduleService) trigger(ctx context.Context, schedule *models.ScanSchedule, now time.Time) (int, error) {
+	templates := schedule.Templates
+	if groupID, ok := strings.CutPrefix(schedule.Strategy, "scenario:"); ok {
+		group, err := s.scenarioRepo.GetByID(ctx, groupID)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to get scenario group")
+		}
+		templates = group.TemplateIDs
+	}
+
+	task, err := s.scanSvc.Create(ctx, &CreateScanRequest{
+		Name:      fmt.Sprintf("%s %s", schedule.Name, now.Local().Format("2006-01-02 15:04")),
+		TargetID:  utils.DerefInt(schedule.TargetID),
+		TargetTag: utils.DerefString(schedule.TargetTag),
+		Strategy:  schedule.Strategy,
+		Templates: templates,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.scanSvc.Start(ctx, task.ID); err != nil {
+		return task.ID, err
+	}
+
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScheduleTriggered,
+		Data: map[string]interface{}{
+			"scheduleId": schedule.ID,
+			"taskId":     task.ID,
+		},
+	})
+
+	s.logger.Info("Scheduled scan started: schedule_id=%d, task_id=%d", schedule.ID, task.ID)
+	return task.ID, nil
+}
+
+// skip 跳过本次执行并记录原因
+func (s *ScheduleService) skip(ctx context.Context, schedule *models.ScanSchedule, next time.Time, reason string) {
+	s.logger.Warn("Scheduled scan skipped: schedule_id=%d, reason=%s", schedule.ID, reason)
+
+	if err := s.repo.SetNextRun(ctx, schedule.ID, next, reason); err != nil {
+		s.logger.Error("Failed to update schedule next run: schedule_id=%d, error=%v", schedule.ID, err)
+	}
+
+	s.eventBus.PublishAsync(ctx, event.Event{
+		Type: event.EventScheduleSkipped,
+		Data: map[string]interface{}{
+			"scheduleId": schedule.ID,
+			"reason":     reason,
+		},
+	})
+}
+
+// previousRunActive 检查上一次创建的任务是否仍在排队或运行
+func (s *ScheduleService) previousRunActive(ctx context.Context, schedule *models.ScanSchedule) bool {
+	if schedule.LastTaskID == nil {
+		return false
+	}
+	task, err := s.scanSvc.GetByID(ctx, *schedule.LastTaskID)
+	if err != nil {
+		return false
+	}
+	return task.Status == "queued" || task.Status == "running" || task.Status == "paused"
+}
+
+// apply 校验请求并写入计划，启用时计算下次运行时间
+func (s *ScheduleService) apply(ctx context.Context, schedule *models.ScanSchedule, req *models.ScanScheduleRequest) error {
+	if req == nil {
+		return errors.InvalidInput("request is required")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.InvalidInput("schedule name is required")
+	}
+	if req.Strategy == "" {
+		return errors.InvalidInput("scan strategy is required")
+	}
+
+	tag := strings.TrimSpace(req.TargetTag)
+	switch {
+	case req.TargetID > 0 && tag != "":
+		return errors.InvalidInput("specify either a target or a target tag, not both")
+	case req.TargetID > 0:
+		if _, err := s.targetRepo.GetByID(ctx, req.TargetID); err != nil {
+			return err
+		}
+	case tag == "":
+		return errors.InvalidInput("target or target tag is required")
+	}
+
+	if groupID, ok := strings.CutPrefix(req.Strategy, "scenario:"); ok {
+		if _, err := s.scenarioRepo.GetByID(ctx, groupID); err != nil {
+			return err
+		}
+	}
+
+	cronExpr := strings.TrimSpace(req.Cron)
+	switch {
+	case cronExpr != "" && req.IntervalSeconds > 0:
+		return errors.InvalidInput("specify either a cron expression or an interval, not both")
+	case cronExpr != "":
+		if _, err := cron.Parse(cronExpr); err != nil {
+			return errors.InvalidInput(fmt.Sprintf("invalid cron expression: %v", err))
+		}
+	case req.IntervalSeconds <= 0:
+		return errors.InvalidInput("cron expression or interval is required")
+	case time.Duration(req.IntervalSeconds)*time.Second < minScheduleInterval:
+		return errors.InvalidInput(fmt.Sprintf("interval must be at least %d seconds", int(minScheduleInterval.Seconds())))
+	}
+
+	schedule.Name = name
+	schedule.TargetID = nil
+	schedule.TargetTag = nil
+	if req.TargetID > 0 {
+		id := req.TargetID
+		schedule.TargetID = &id
+	} else {
+		schedule.TargetTag = &tag
+	}
+	schedule.Strategy = req.Strategy
+	schedule.Templates = req.Templates
+	schedule.Cron = cronExpr
+	schedule.IntervalSeconds = 0
+	if cronExpr == "" {
+		schedule.IntervalSeconds = req.IntervalSeconds
+	}
+	schedule.CatchUp = req.CatchUp
+	schedule.Enabled = req.Enabled
+
+	schedule.NextRunAt = nil
+	if schedule.Enabled {
+		next, err := s.firstRun(schedule)
+		if err != nil {
+			return err
+		}
+		schedule.NextRunAt = next
+	}
+	return nil
+}
+
+// firstRun 计算启用计划的首次运行时间，cron 表达式能解析但永远不会命中（如 2 月 30 日）时返回错误
+func (s *ScheduleService) firstRun(schedule *models.ScanSchedule) (*string, error) {
+	next, err := nextRun(schedule, s.now(), time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if next.IsZero() {
+		return nil, errors.InvalidInput("cron expression never matches")
+	}
+	return scheduleTime(next), nil
+}
+
+// nextRun 计算晚于 after 的下次运行时间
+// 固定间隔计划以 anchor（上次应运行的时间）为基准保持节奏，anchor 为零值时从 after 开始计算
+func nextRun(schedule *models.ScanSchedule, after, anchor time.Time) (time.Time, error) {
+	if schedule.Cron != "" {
+		expr, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			return time.Time{}, errors.InvalidInput(fmt.Sprintf("invalid cron expression: %v", err))
+		}
+		return expr.Next(after.Local()), nil
+	}
+
+	interval := time.Duration(schedule.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		return time.Time{}, errors.InvalidInput("invalid schedule interval")
+	}
+	if anchor.IsZero() {
+		return after.Add(interval), nil
+	}
+	next := anchor.Add(interval)
+	if !next.After(after) {
+		skipped := after.Sub(next)/interval + 1
+		next = next.Add(skipped * interval)
+	}
+	return next, nil
+}
+
+// scheduleTime 格式化计划时间用于存储，零值表示不再运行
+func scheduleTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.UTC().Format(repo.ScheduleTimeLayout)
+	return &s
+}
+
+// parseScheduleTime 解析存储的计划时间
+func parseScheduleTime(s *string) (time.Time, bool) {
+	if s == nil || *s == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(repo.ScheduleTimeLayout, *s, time.UTC)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// errorReason 返回可展示给用户的错误原因
+func errorReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	return errors.SanitizeUserError(err)
+}
@@ -0,0 +1,229 @@
+package svc
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/cron"
+	"github.com/holehunter/holehunter/internal/infrastructure/event"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/utils"
+)
+
+// newTestScheduleService 创建使用假 nuclei 与可控时钟的扫描计划服务
+func newTestScheduleService(t *testing.T, db *sql.DB, clock *time.Time) (*ScheduleService, *ScanService, int) {
+	t.Helper()
+	scanSvc, targetID := newQueueTestScanService(t, db, "exit 0")
+	service := NewScheduleService(
+		repo.NewScheduleRepository(db),
+		repo.NewTargetRepository(db),
+		repo.NewScenarioRepository(db),
+		scanSvc,
+		event.NewBus(),
+		logger.New("error", ""),
+	)
+	service.now = func() time.Time { return *clock }
+	return service, scanSvc, targetID
+}
+
+// TestScheduleService_Create 测试创建扫描计划时的校验
+func TestScheduleService_Create(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	clock := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	service, _, targetID := newTestScheduleService(t, db, &clock)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     models.ScanScheduleRequest
+		wantErr bool
+	}{
+		{"固定间隔", models.ScanScheduleRequest{Name: "hourly", TargetID: targetID, Strategy: "quick", IntervalSeconds: 3600, Enabled: true}, false},
+		{"cron 表达式", models.ScanScheduleRequest{Name: "nightly", TargetTag: "prod", Strategy: "quick", Cron: "0 2 * * *", Enabled: true}, false},
+		{"缺少名称", models.ScanScheduleRequest{TargetID: targetID, Strategy: "quick", IntervalSeconds: 3600}, true},
+		{"缺少目标", models.ScanScheduleRequest{Name: "x", Strategy: "quick", IntervalSeconds: 3600}, true},
+		{"目标与标签同时指定", models.ScanScheduleRequest{Name: "x", TargetID: targetID, TargetTag: "prod", Strategy: "quick", IntervalSeconds: 3600}, true},
+		{"目标不存在", models.ScanScheduleRequest{Name: "x", TargetID: 999, Strategy: "quick", IntervalSeconds: 3600}, true},
+		{"缺少周期", models.ScanScheduleRequest{Name: "x", TargetID: targetID, Strategy: "quick"}, true},
+		{"cron 与间隔同时指定", models.ScanScheduleRequest{Name: "x", TargetID: targetID, Strategy: "quick", Cron: "@daily", IntervalSeconds: 3600}, true},
+		{"无效 cron", models.ScanScheduleRequest{Name: "x", TargetID: targetID, Strategy: "quick", Cron: "0 25 * * *"}, true},
+		{"cron 永不命中", models.ScanScheduleRequest{Name: "x", TargetID: targetID, Strategy: "quick", Cron: "0 0 30 2 *", Enabled: true}, true},
+		{"间隔过短", models.ScanScheduleRequest{Name: "x", TargetID: targetID, Strategy: "quick", IntervalSeconds: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Create(ctx, &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	schedule, err := service.Create(ctx, &models.ScanScheduleRequest{
+		Name: "nightly", TargetID: targetID, Strategy: "quick", Cron: "30 2 * * *", Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	// cron 表达式按本地时区计算
+	expr, _ := cron.Parse("30 2 * * *")
+	assertNextRun(t, schedule, expr.Next(clock.Local()))
+
+	// 停用的计划没有下次运行时间
+	disabled, err := service.Create(ctx, &models.ScanScheduleRequest{
+		Name: "paused", TargetID: targetID, Strategy: "quick", IntervalSeconds: 3600,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if disabled.NextRunAt != nil {
+		t.Errorf("disabled schedule NextRunAt = %s, want nil", *disabled.NextRunAt)
+	}
+
+	// 永不命中的 cron 计划不能启用
+	never, err := service.Create(ctx, &models.ScanScheduleRequest{
+		Name: "never", TargetID: targetID, Strategy: "quick", Cron: "0 0 30 2 *",
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.SetEnabled(ctx, never.ID, true); err == nil || !strings.Contains(err.Error(), "never matches") {
+		t.Errorf("SetEnabled() = %v, want never matches error", err)
+	}
+}
+
+// TestScheduleService_RunDue 测试到期计划的运行、错过与补跑
+func TestScheduleService_RunDue(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := start
+	service, scanSvc, targetID := newTestScheduleService(t, db, &clock)
+	ctx := context.Background()
+
+	schedule, err := service.Create(ctx, &models.ScanScheduleRequest{
+		Name: "hourly", TargetID: targetID, Strategy: "quick", IntervalSeconds: 3600, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	// 未到期时不运行
+	if n, err := service.RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("RunDue() before due = %d, %v, want 0", n, err)
+	}
+
+	// 到期后创建任务，下次运行时间以应运行时间为基准
+	clock = start.Add(time.Hour + 30*time.Second)
+	if n, err := service.RunDue(ctx); err != nil || n != 1 {
+		t.Fatalf("RunDue() = %d, %v, want 1", n, err)
+	}
+	schedule, _ = service.GetByID(ctx, schedule.ID)
+	if schedule.LastTaskID == nil {
+		t.Fatal("LastTaskID should be set after run")
+	}
+	waitScanStatus(t, scanSvc, *schedule.LastTaskID, "completed")
+	assertNextRun(t, schedule, start.Add(2*time.Hour))
+
+	// 错过的执行在未开启补跑时跳过
+	lastTaskID := *schedule.LastTaskID
+	clock = start.Add(2*time.Hour + 10*time.Minute)
+	if n, err := service.RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("RunDue() missed = %d, %v, want 0", n, err)
+	}
+	schedule, _ = service.GetByID(ctx, schedule.ID)
+	if !strings.Contains(utils.DerefString(schedule.LastError), "missed") {
+		t.Errorf("LastError = %q, want missed run reason", utils.DerefString(schedule.LastError))
+	}
+	if utils.DerefInt(schedule.LastTaskID) != lastTaskID {
+		t.Errorf("LastTaskID = %d, want %d", utils.DerefInt(schedule.LastTaskID), lastTaskID)
+	}
+	assertNextRun(t, schedule, start.Add(3*time.Hour))
+
+	// 开启补跑后多次错过的执行只运行一次
+	schedule, err = service.Update(ctx, schedule.ID, &models.ScanScheduleRequest{
+		Name: "hourly", TargetID: targetID, Strategy: "quick", IntervalSeconds: 3600, CatchUp: true, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	due, _ := parseScheduleTime(schedule.NextRunAt)
+	clock = due.Add(5*time.Hour + 10*time.Minute)
+	if n, err := service.RunDue(ctx); err != nil || n != 1 {
+		t.Fatalf("RunDue() catch up = %d, %v, want 1", n, err)
+	}
+	schedule, _ = service.GetByID(ctx, schedule.ID)
+	if schedule.LastError != nil {
+		t.Errorf("LastError = %q, want nil", *schedule.LastError)
+	}
+	waitScanStatus(t, scanSvc, *schedule.LastTaskID, "completed")
+	assertNextRun(t, schedule, due.Add(6*time.Hour))
+
+	if n, err := service.RunDue(ctx); err != nil || n != 0 {
+		t.Errorf("RunDue() after catch up = %d, %v, want 0", n, err)
+	}
+}
+
+// TestScheduleService_SkipActive 测试上一次任务仍在运行时跳过本次执行
+func TestScheduleService_SkipActive(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clock := start
+	service, _, targetID := newTestScheduleService(t, db, &clock)
+	ctx := context.Background()
+
+	schedule, err := service.Create(ctx, &models.ScanScheduleRequest{
+		Name: "hourly", TargetID: targetID, Strategy: "quick", IntervalSeconds: 3600, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+
+	running := &models.ScanTask{TargetID: targetID, Status: "running", Strategy: "quick"}
+	if err := repo.NewScanRepository(db, logger.New("error", "")).Create(ctx, running); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	due, _ := parseScheduleTime(schedule.NextRunAt)
+	if err := service.repo.RecordRun(ctx, schedule.ID, start, running.ID, "", due); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	clock = due.Add(time.Minute)
+	if n, err := service.RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("RunDue() = %d, %v, want 0", n, err)
+	}
+
+	schedule, _ = service.GetByID(ctx, schedule.ID)
+	if !strings.Contains(utils.DerefString(schedule.LastError), "still active") {
+		t.Errorf("LastError = %q, want active task reason", utils.DerefString(schedule.LastError))
+	}
+	assertNextRun(t, schedule, due.Add(time.Hour))
+
+	tasks, err := service.scanSvc.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll() failed: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("task count = %d, want 1", len(tasks))
+	}
+}
+
+// assertNextRun 检查计划的下次运行时间
+func assertNextRun(t *testing.T, schedule *models.ScanSchedule, want time.Time) {
+	t.Helper()
+	next, ok := parseScheduleTime(schedule.NextRunAt)
+	if !ok || !next.Equal(want) {
+		t.Errorf("NextRunAt = %s, want %s", utils.DerefString(schedule.NextRunAt), want.Format(repo.ScheduleTimeLayout))
+	}
+}
@@ -0,0 +1,105 @@
+package svc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/infrastructure/logger"
+)
+
+// taskStore 后台任务需要的状态持久化操作
+type taskStore interface {
+	MarkTaskFinished(ctx context.Context, id int, status string) error
+	MarkRunningTasksStopped(ctx context.Context) (int, error)
+}
+
+// taskRunner 管理在后台 goroutine 中执行的任务：运行表、启动检查、取消与异常退出恢复
+type taskRunner struct {
+	// kind 任务名称，用于日志与错误信息，如 "port scan"
+	kind   string
+	store  taskStore
+	logger *logger.Logger
+
+	mu      sync.Mutex
+	running map[int]context.CancelFunc
+}
+
+// newTaskRunner 创建任务运行管理器
+func newTaskRunner(kind string, store taskStore, logger *logger.Logger) *taskRunner {
+	return &taskRunner{
+		kind:    kind,
+		store:   store,
+		logger:  logger,
+		running: make(map[int]context.CancelFunc),
+	}
+}
+
+// begin 检查任务能否启动并登记为运行中，返回任务的 context
+// 只有 pending、stopped 状态的任务可以启动；状态为 running 但不在运行表中的任务是上次异常退出遗留的，也允许重新启动
+// 任务生命周期独立于调用方的 context，结束时需调用 finish
+func (r *taskRunner) begin(taskID int, status string) (context.Context, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.running[taskID]; exists {
+		return nil, errors.Conflict(r.kind + " task already running")
+	}
+	if status != "pending" && status != "stopped" && status != "running" {
+		return nil, errors.Conflict(fmt.Sprintf("%s task is %s, cannot start", r.kind, status))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.running[taskID] = cancel
+	return ctx, nil
+}
+
+// finish 取消任务 context 并移出运行表
+func (r *taskRunner) finish(taskID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cancel, ok := r.running[taskID]; ok {
+		cancel()
+		delete(r.running, taskID)
+	}
+}
+
+// stop 取消运行中的任务，任务自行更新最终状态
+func (r *taskRunner) stop(taskID int) error {
+	r.mu.Lock()
+	cancel, exists := r.running[taskID]
+	r.mu.Unlock()
+
+	if !exists {
+		return errors.Conflict(r.kind + " task is not running")
+	}
+
+	cancel()
+	return nil
+}
+
+// isRunning 检查任务是否在运行
+func (r *taskRunner) isRunning(taskID int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.running[taskID]
+	return exists
+}
+
+// markFinished 更新任务最终状态
+func (r *taskRunner) markFinished(ctx context.Context, taskID int, status string) {
+	if err := r.store.MarkTaskFinished(ctx, taskID, status); err != nil {
+		r.logger.Error("Failed to update %s task status: task_id=%d, status=%s, error=%v", r.kind, taskID, status, err)
+	}
+}
+
+// recoverInterrupted 将上次退出时仍处于 running 状态的任务标记为 stopped，使其可以重新启动
+// 应在启动任何任务之前调用
+func (r *taskRunner) recoverInterrupted(ctx context.Context) (int, error) {
+	n, err := r.store.MarkRunningTasksStopped(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("failed to recover interrupted %s tasks", r.kind))
+	}
+	return n, nil
+}
@@ -3,16 +3,46 @@ package svc
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/scanner"
 	"gopkg.in/yaml.v3"
 )
 
 // TemplateService 模板服务
 type TemplateService struct {
 	repo TemplateRepository
+	// materializer 自定义模板同步器，为 nil 时不写入磁盘
+	materializer *TemplateMaterializer
+	// validator nuclei 模板校验，为 nil 或不可用时只做结构校验
+	validator TemplateValidator
+	// runner 模板试运行，为 nil 或不可用时无法试运行
+	runner TemplateRunner
 }
 
+// TemplateValidator 使用 nuclei 校验模板
+type TemplateValidator interface {
+	IsAvailable() bool
+	ValidateTemplate(ctx context.Context, content []byte) ([]models.TemplateDiagnostic, error)
+}
+
+// TemplateRunner 使用 nuclei 对单个目标试运行模板
+type TemplateRunner interface {
+	IsAvailable() bool
+	RunTemplate(ctx context.Context, content []byte, targetURL string) (*models.TemplateTestResult, error)
+}
+
+const (
+	// nucleiValidateTimeout nuclei -validate 的最长运行时间
+	nucleiValidateTimeout = 30 * time.Second
+	// templateTestTimeout 模板试运行的最长运行时间
+	templateTestTimeout = 2 * time.Minute
+)
+
 // TemplateRepository 模板仓储接口
 type TemplateRepository interface {
 	GetAll(ctx context.Context) ([]*models.Template, error)
@@ -30,6 +60,9 @@ type TemplateRepository interface {
 	GetAuthors(ctx context.Context) ([]string, error)
 	GetSeverities(ctx context.Context) ([]string, error)
 	GetAllCustom(ctx context.Context) ([]*models.Template, error)
+	GetEnabled(ctx context.Context) ([]*models.Template, error)
+	GetDisabledBuiltinIDs(ctx context.Context) ([]string, error)
+	SetBuiltinEnabled(ctx context.Context, req *models.TemplateBulkToggleRequest) (int, error)
 }
 
 // NewTemplateService 创建模板服务
@@ -37,6 +70,32 @@ func NewTemplateService(repo TemplateRepository) *TemplateService {
 	return &TemplateService{repo: repo}
 }
 
+// SetMaterializer 设置自定义模板同步器，自定义模板变更后同步到磁盘
+func (s *TemplateService) SetMaterializer(m *TemplateMaterializer) {
+	s.materializer = m
+}
+
+// SetValidator 设置 nuclei 模板校验
+func (s *TemplateService) SetValidator(v TemplateValidator) {
+	s.validator = v
+}
+
+// SetRunner 设置模板试运行
+func (s *TemplateService) SetRunner(r TemplateRunner) {
+	s.runner = r
+}
+
+// SyncCustomTemplates 将启用的自定义模板同步到磁盘
+func (s *TemplateService) SyncCustomTemplates(ctx context.Context) error {
+	if s.materializer == nil {
+		return nil
+	}
+	if err := s.materializer.Sync(ctx); err != nil {
+		return fmt.Errorf("failed to sync custom templates: %w", err)
+	}
+	return nil
+}
+
 // GetAll 获取所有模板
 func (s *TemplateService) GetAll(ctx context.Context) ([]*models.Template, error) {
 	return s.repo.GetAll(ctx)
@@ -129,7 +188,14 @@ func (s *TemplateService) CreateCustomTemplate(ctx context.Context, req *models.
 		Tags:       tags,
 	}
 
-	return s.repo.Create(ctx, template)
+	created, err := s.repo.Create(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SyncCustomTemplates(ctx); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 // UpdateCustomTemplate 更新自定义模板
@@ -175,7 +241,10 @@ func (s *TemplateService) UpdateCustomTemplate(ctx context.Context, id int, req
 		template.Enabled = *req.Enabled
 	}
 
-	return s.repo.Update(ctx, template)
+	if err := s.repo.Update(ctx, template); err != nil {
+		return err
+	}
+	return s.SyncCustomTemplates(ctx)
 }
 
 // DeleteCustomTemplate 删除自定义模板
@@ -190,7 +259,10 @@ func (s *TemplateService) DeleteCustomTemplate(ctx context.Context, id int) erro
 		return fmt.Errorf("only custom templates can be deleted")
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.SyncCustomTemplates(ctx)
 }
 
 // ToggleCustomTemplate 切换自定义模板启用状态
@@ -205,7 +277,25 @@ func (s *TemplateService) ToggleCustomTemplate(ctx context.Context, id int, enab
 		return fmt.Errorf("only custom templates can be toggled")
 	}
 
-	return s.repo.ToggleEnabled(ctx, id, enabled)
+	if err := s.repo.ToggleEnabled(ctx, id, enabled); err != nil {
+		return err
+	}
+	return s.SyncCustomTemplates(ctx)
+}
+
+// BulkToggleBuiltin 按分类、标签或作者批量启用或禁用内置模板，返回受影响的模板数
+// 禁用的内置模板在之后启动的扫描中被排除
+func (s *TemplateService) BulkToggleBuiltin(ctx context.Context, req *models.TemplateBulkToggleRequest) (int, error) {
+	if req == nil {
+		return 0, fmt.Errorf("bulk toggle request is required")
+	}
+	req.Category = strings.TrimSpace(req.Category)
+	req.Tag = strings.TrimSpace(req.Tag)
+	req.Author = strings.TrimSpace(req.Author)
+	if req.Category == "" && req.Tag == "" && req.Author == "" {
+		return 0, fmt.Errorf("category, tag or author is required")
+	}
+	return s.repo.SetBuiltinEnabled(ctx, req)
 }
 
 // GetStats 获取模板统计信息
@@ -251,32 +341,103 @@ func (s *TemplateService) GetCustomByID(ctx context.Context, id int) (*models.Te
 }
 
 // ValidateCustomTemplate 验证自定义模板
-func (s *TemplateService) ValidateCustomTemplate(ctx context.Context, content string) (bool, []string, error) {
-	var warnings []string
+// 先按 nuclei 模板结构校验，没有结构错误且 nuclei 可用时再运行 nuclei -validate
+func (s *TemplateService) ValidateCustomTemplate(ctx context.Context, content string) (*models.TemplateValidationResult, error) {
+	diagnostics := scanner.ValidateTemplateSchema([]byte(content))
+
+	result := &models.TemplateValidationResult{}
+	if !scanner.HasErrorDiagnostic(diagnostics) && s.validator != nil && s.validator.IsAvailable() {
+		validateCtx, cancel := context.WithTimeout(ctx, nucleiValidateTimeout)
+		nucleiDiagnostics, err := s.validator.ValidateTemplate(validateCtx, []byte(content))
+		cancel()
+		if err != nil {
+			// nuclei 无法运行时不影响结构校验的结果
+			diagnostics = append(diagnostics, models.TemplateDiagnostic{
+				Severity: scanner.DiagnosticWarning,
+				Message:  fmt.Sprintf("nuclei validation skipped: %v", err),
+				Source:   scanner.DiagnosticSourceNuclei,
+			})
+		} else {
+			diagnostics = append(diagnostics, nucleiDiagnostics...)
+			result.NucleiChecked = true
+		}
+	}
 
-	// YAML 格式验证
-	if err := s.validateYAML(content); err != nil {
-		return false, nil, err
+	result.Diagnostics = diagnostics
+	if result.Diagnostics == nil {
+		result.Diagnostics = []models.TemplateDiagnostic{}
 	}
+	result.Valid = !scanner.HasErrorDiagnostic(diagnostics)
+	result.Error = firstTemplateError(diagnostics)
+	return result, nil
+}
 
-	// 提取模板信息
-	info, err := s.extractTemplateInfo(content)
+// TestTemplate 对指定 URL 试运行单个模板（已保存的模板或草稿内容），返回原始请求响应、匹配与提取结果
+// 试运行结果不会保存为漏洞，用于编写模板时对本地测试服务或靶机反复验证
+func (s *TemplateService) TestTemplate(ctx context.Context, req *models.TemplateTestRequest) (*models.TemplateTestResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("template test request is required")
+	}
+	target := strings.TrimSpace(req.URL)
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid target url: %s", req.URL)
+	}
+
+	content, err := s.testTemplateContent(ctx, req)
 	if err != nil {
-		return false, nil, err
+		return nil, err
+	}
+	if msg := firstTemplateError(scanner.ValidateTemplateSchema(content)); msg != "" {
+		return nil, fmt.Errorf("invalid template: %s", msg)
 	}
+	if s.runner == nil || !s.runner.IsAvailable() {
+		return nil, fmt.Errorf("nuclei is not available")
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, templateTestTimeout)
+	defer cancel()
+	return s.runner.RunTemplate(runCtx, content, target)
+}
 
-	// 验证必需字段
-	if info.ID == "" {
-		warnings = append(warnings, "missing template id")
+// testTemplateContent 返回试运行的模板内容，草稿内容优先
+func (s *TemplateService) testTemplateContent(ctx context.Context, req *models.TemplateTestRequest) ([]byte, error) {
+	if strings.TrimSpace(req.Content) != "" {
+		return []byte(req.Content), nil
+	}
+	if req.ID <= 0 {
+		return nil, fmt.Errorf("template content or id is required")
+	}
+
+	template, err := s.repo.GetByID(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if template.Source == "custom" {
+		return []byte(template.Content), nil
 	}
-	if info.Name == "" {
-		warnings = append(warnings, "missing template name")
+	if template.Path == "" {
+		return nil, fmt.Errorf("template %s has no file", template.TemplateID)
 	}
-	if info.Severity == "" {
-		warnings = append(warnings, "missing severity")
+	content, err := os.ReadFile(template.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", template.TemplateID, err)
 	}
+	return content, nil
+}
 
-	return len(warnings) == 0, warnings, nil
+// firstTemplateError 返回第一个 error 级别问题的描述，没有时返回空
+func firstTemplateError(diagnostics []models.TemplateDiagnostic) string {
+	for _, d := range diagnostics {
+		if d.Severity != scanner.DiagnosticError {
+			continue
+		}
+		if d.Line > 0 {
+			return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+		}
+		return d.Message
+	}
+	return ""
 }
 
 // GetCustomStats 获取自定义模板统计
@@ -0,0 +1,251 @@
+package svc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/holehunter/holehunter/internal/models"
+)
+
+// quickSeverities quick 策略运行的严重程度
+var quickSeverities = []string{"critical", "high", "medium"}
+
+// ResolveManifest 按扫描策略从模板表中选出要运行的模板，并记录各模板内容的哈希
+// 模板表为空（内置模板尚未同步）或选中的模板文件都已不存在（模板目录变化后未重新同步）时返回 nil，
+// 调用方退回按策略参数扫描
+func (m *TemplateMaterializer) ResolveManifest(ctx context.Context, strategy string, templateIDs []string) ([]models.TemplateManifestEntry, error) {
+	templates, err := m.repo.GetEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enabled templates: %w", err)
+	}
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	match := strategyMatcher(strategy, templateIDs)
+	entries := make([]models.TemplateManifestEntry, 0, len(templates))
+	matched := 0
+	seen := make(map[string]bool, len(templates))
+	for _, t := range templates {
+		if !match(t) {
+			continue
+		}
+		matched++
+		id := nucleiTemplateID(t)
+		// 自定义模板 ID 可能重复，清单中只记录一次
+		if id == "" || seen[t.Source+"/"+id] {
+			continue
+		}
+		hash, ok := templateContentHash(t)
+		if !ok {
+			continue
+		}
+		seen[t.Source+"/"+id] = true
+		entries = append(entries, models.TemplateManifestEntry{
+			TemplateID:  id,
+			Source:      t.Source,
+			ContentHash: hash,
+		})
+	}
+	if matched > 0 && len(entries) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Source != entries[j].Source {
+			return entries[i].Source < entries[j].Source
+		}
+		return entries[i].TemplateID < entries[j].TemplateID
+	})
+	return entries, nil
+}
+
+// ManifestFile 将模板清单中的模板 ID 写入文件，每行一个，供 nuclei 按 ID 选择模板
+func (m *TemplateMaterializer) ManifestFile(taskID int, entries []models.TemplateManifestEntry) (string, error) {
+	seen := make(map[string]bool, len(entries))
+	var b strings.Builder
+	for _, e := range entries {
+		if seen[e.TemplateID] {
+			continue
+		}
+		seen[e.TemplateID] = true
+		b.WriteString(e.TemplateID)
+		b.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(m.snapshotDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template snapshot directory: %w", err)
+	}
+	path := m.manifestPath(taskID)
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return "", fmt.Errorf("failed to write template manifest: %w", err)
+	}
+	return path, nil
+}
+
+// manifestPath 返回扫描任务的模板清单文件
+func (m *TemplateMaterializer) manifestPath(taskID int) string {
+	return filepath.Join(m.snapshotDir, fmt.Sprintf("scan-%d.manifest", taskID))
+}
+
+// strategyMatcher 返回与 nuclei 命令参数一致的模板过滤条件
+func strategyMatcher(strategy string, templateIDs []string) func(*models.Template) bool {
+	all := func(*models.Template) bool { return true }
+
+	switch {
+	case strategy == "quick":
+		return severityMatcher(quickSeverities)
+	case strategy == "deep" || strategy == "passive":
+		return all
+	case strings.HasPrefix(strategy, "tags:"):
+		var tags []string
+		for _, tag := range strings.Split(strings.TrimPrefix(strategy, "tags:"), ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		return func(t *models.Template) bool {
+			for _, tag := range t.Tags {
+				if containsFold(tags, tag) {
+					return true
+				}
+			}
+			return false
+		}
+	case strings.HasPrefix(strategy, "scenario:"):
+		if len(templateIDs) == 0 {
+			return all
+		}
+		return idMatcher(templateIDs)
+	}
+
+	if severities, ok := parseSeverityStrategy(strategy); ok {
+		return severityMatcher(severities)
+	}
+	if len(templateIDs) > 0 {
+		return idMatcher(templateIDs)
+	}
+	return all
+}
+
+// parseSeverityStrategy 解析逗号分隔的严重程度策略，如 critical,high
+func parseSeverityStrategy(strategy string) ([]string, bool) {
+	if strategy == "" {
+		return nil, false
+	}
+	var severities []string
+	for _, part := range strings.Split(strategy, ",") {
+		part = strings.TrimSpace(part)
+		if !containsFold([]string{"critical", "high", "medium", "low", "info"}, part) {
+			return nil, false
+		}
+		severities = append(severities, part)
+	}
+	return severities, true
+}
+
+// severityMatcher 按严重程度过滤模板
+func severityMatcher(severities []string) func(*models.Template) bool {
+	return func(t *models.Template) bool {
+		return containsFold(severities, t.Severity)
+	}
+}
+
+// idMatcher 按模板 ID 过滤模板
+func idMatcher(ids []string) func(*models.Template) bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[strings.TrimSpace(id)] = true
+	}
+	return func(t *models.Template) bool {
+		return set[t.TemplateID]
+	}
+}
+
+// containsFold 忽略大小写检查列表中是否包含 value
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// nucleiTemplateID 返回 nuclei 识别的模板 ID
+// 自定义模板数据库中的 template_id 为生成值，以内容中的 id 为准
+func nucleiTemplateID(t *models.Template) string {
+	if t.Source != "custom" {
+		return t.TemplateID
+	}
+	var doc struct {
+		ID string `yaml:"id"`
+	}
+	if err := yaml.Unmarshal([]byte(t.Content), &doc); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(doc.ID)
+}
+
+// templateContentHash 计算模板内容的 SHA-256
+// 自定义模板使用数据库中的内容（与快照一致），内置模板读取模板文件；文件不存在的模板 nuclei 不会加载，返回 false
+func templateContentHash(t *models.Template) (string, bool) {
+	content := []byte(t.Content)
+	if t.Source == "custom" {
+		if strings.TrimSpace(t.Content) == "" {
+			return "", false
+		}
+	} else {
+		if t.Path == "" {
+			return "", false
+		}
+		data, err := os.ReadFile(t.Path)
+		if err != nil {
+			return "", false
+		}
+		content = data
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// diffTemplateManifests 比较两次扫描的模板清单
+func diffTemplateManifests(base, current []models.TemplateManifestEntry) *models.TemplateManifestDiff {
+	key := func(e models.TemplateManifestEntry) string { return e.Source + "/" + e.TemplateID }
+
+	baseHashes := make(map[string]string, len(base))
+	for _, e := range base {
+		baseHashes[key(e)] = e.ContentHash
+	}
+	currentKeys := make(map[string]bool, len(current))
+
+	diff := &models.TemplateManifestDiff{
+		Added:   []models.TemplateManifestEntry{},
+		Removed: []models.TemplateManifestEntry{},
+		Changed: []models.TemplateManifestEntry{},
+	}
+	for _, e := range current {
+		currentKeys[key(e)] = true
+		hash, ok := baseHashes[key(e)]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, e)
+		case hash != e.ContentHash:
+			diff.Changed = append(diff.Changed, e)
+		}
+	}
+	for _, e := range base {
+		if !currentKeys[key(e)] {
+			diff.Removed = append(diff.Removed, e)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,168 @@
+package svc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/holehunter/holehunter/internal/infrastructure/errors"
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+	"github.com/holehunter/holehunter/internal/utils"
+)
+
+// TestStrategyMatcher 测试按扫描策略选择模板
+func TestStrategyMatcher(t *testing.T) {
+	templates := []*models.Template{
+		{TemplateID: "cve-rce", Severity: "critical", Tags: []string{"cve", "rce"}},
+		{TemplateID: "panel", Severity: "info", Tags: []string{"panel"}},
+		{TemplateID: "sqli", Severity: "high", Tags: []string{"sqli"}},
+	}
+
+	tests := []struct {
+		strategy  string
+		templates []string
+		want      string
+	}{
+		{"deep", nil, "cve-rce,panel,sqli"},
+		{"quick", nil, "cve-rce,sqli"},
+		{"tags:RCE, panel", nil, "cve-rce,panel"},
+		{"high,info", nil, "panel,sqli"},
+		{"scenario:web", []string{"panel"}, "panel"},
+		{"custom", []string{"sqli", "missing"}, "sqli"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			match := strategyMatcher(tt.strategy, tt.templates)
+			var got []string
+			for _, tmpl := range templates {
+				if match(tmpl) {
+					got = append(got, tmpl.TemplateID)
+				}
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("strategyMatcher(%q) selected %v, want %s", tt.strategy, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestScanService_TemplateManifest 测试扫描前解析模板清单并传给 nuclei，以及两次扫描的清单比较
+func TestScanService_TemplateManifest(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：记录 -id 清单文件的内容
+	script := `dir=$(dirname "$0")
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-id" ]; then cat "$2" > "$dir/manifest-ids"; fi
+  shift
+done`
+	service, targetID := newQueueTestScanService(t, db, script)
+	dataDir := filepath.Dir(service.resumeDir)
+	ctx := context.Background()
+
+	repository := repo.NewTemplateRepository(db)
+	materializer := NewTemplateMaterializer(repository, filepath.Join(dataDir, "custom-templates"), filepath.Join(dataDir, "template-snapshots"))
+	templates := NewTemplateService(repository)
+	templates.SetMaterializer(materializer)
+	service.SetTemplateMaterializer(materializer)
+
+	builtinDir := t.TempDir()
+	writeBuiltin := func(name, content string) string {
+		path := filepath.Join(builtinDir, name+".yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		return path
+	}
+	builtins := []*models.Template{
+		{TemplateID: "cve-high", Name: "High", Severity: "high", Path: writeBuiltin("cve-high", "v1"), Enabled: true},
+		{TemplateID: "tech-info", Name: "Info", Severity: "info", Path: writeBuiltin("tech-info", "v1"), Enabled: true},
+		{TemplateID: "exposure-medium", Name: "Medium", Severity: "medium", Path: writeBuiltin("exposure-medium", "v1"), Enabled: true},
+		{TemplateID: "missing-file", Name: "Missing", Severity: "critical", Path: filepath.Join(builtinDir, "missing.yaml"), Enabled: true},
+	}
+	if _, err := repository.SyncBuiltin(ctx, builtins); err != nil {
+		t.Fatalf("SyncBuiltin() failed: %v", err)
+	}
+	if _, err := templates.CreateCustomTemplate(ctx, &models.CreateTemplateRequest{Content: customTemplateYAML("poc-high", "POC"), Enabled: true}); err != nil {
+		t.Fatalf("CreateCustomTemplate() failed: %v", err)
+	}
+
+	runScan := func(name string) int {
+		t.Helper()
+		task, err := service.Create(ctx, &CreateScanRequest{Name: name, TargetID: targetID, Strategy: "quick"})
+		if err != nil {
+			t.Fatalf("Create() failed: %v", err)
+		}
+		if err := service.Start(ctx, task.ID); err != nil {
+			t.Fatalf("Start() failed: %v", err)
+		}
+		waitScanStatus(t, service, task.ID, "completed")
+		return task.ID
+	}
+
+	first := runScan("first")
+	ids, _ := os.ReadFile(filepath.Join(dataDir, "manifest-ids"))
+	if string(ids) != "cve-high\nexposure-medium\npoc-high\n" {
+		t.Errorf("nuclei template ids = %q, want the templates selected by the quick strategy", ids)
+	}
+
+	manifest, err := service.GetTemplateManifest(ctx, first)
+	if err != nil {
+		t.Fatalf("GetTemplateManifest() failed: %v", err)
+	}
+	if len(manifest) != 3 || manifest[0].Source != "builtin" || manifest[0].TemplateID != "cve-high" || len(manifest[0].ContentHash) != 64 {
+		t.Errorf("manifest = %+v, want 3 entries with content hashes", manifest)
+	}
+	task, err := service.GetByID(ctx, first)
+	if err != nil {
+		t.Fatalf("GetByID() failed: %v", err)
+	}
+	if utils.DerefInt(task.TotalTemplates) != 3 {
+		t.Errorf("total templates = %d, want manifest size 3", utils.DerefInt(task.TotalTemplates))
+	}
+
+	// 修改一个模板、禁用一个模板后再次扫描
+	writeBuiltin("cve-high", "v2")
+	if _, err := db.ExecContext(ctx, "UPDATE templates SET enabled = 0 WHERE template_id = 'exposure-medium'"); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE templates SET severity = 'critical' WHERE template_id = 'tech-info'"); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	second := runScan("second")
+
+	diff, err := service.CompareTemplateManifests(ctx, second, 0)
+	if err != nil {
+		t.Fatalf("CompareTemplateManifests() failed: %v", err)
+	}
+	if diff.BaseScanID != first {
+		t.Errorf("base scan = %d, want previous scan %d", diff.BaseScanID, first)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].TemplateID != "tech-info" {
+		t.Errorf("added = %+v, want tech-info", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].TemplateID != "exposure-medium" {
+		t.Errorf("removed = %+v, want exposure-medium", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].TemplateID != "cve-high" {
+		t.Errorf("changed = %+v, want cve-high", diff.Changed)
+	}
+
+	if _, err := service.CompareTemplateManifests(ctx, first, 0); !errors.Is(err, errors.ErrCodeNotFound) {
+		t.Errorf("CompareTemplateManifests() for the first scan = %v, want not found", err)
+	}
+
+	// 策略没有匹配的模板时拒绝启动
+	empty, err := service.Create(ctx, &CreateScanRequest{Name: "empty", TargetID: targetID, Strategy: "tags:nothing"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, empty.ID); !errors.Is(err, errors.ErrCodeInvalidInput) {
+		t.Errorf("Start() with no matching templates = %v, want invalid input", err)
+	}
+	assertScanStatus(t, service, empty.ID, "pending")
+}
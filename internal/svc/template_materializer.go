@@ -0,0 +1,221 @@
+package svc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// TemplateMaterializer 将数据库中的模板设置写入磁盘供 nuclei 使用
+// dir 与启用的自定义模板保持同步；每次扫描另写一份快照、禁用内置模板的排除列表与模板清单，扫描期间编辑模板不影响正在运行的扫描
+type TemplateMaterializer struct {
+	repo        TemplateRepository
+	dir         string
+	snapshotDir string
+
+	// mu 串行化对模板目录的写入
+	mu sync.Mutex
+}
+
+// NewTemplateMaterializer 创建自定义模板同步器，dir 为同步目录，snapshotDir 存放各扫描的模板快照
+func NewTemplateMaterializer(repo TemplateRepository, dir, snapshotDir string) *TemplateMaterializer {
+	return &TemplateMaterializer{
+		repo:        repo,
+		dir:         dir,
+		snapshotDir: snapshotDir,
+	}
+}
+
+// Dir 返回同步目录
+func (m *TemplateMaterializer) Dir() string {
+	return m.dir
+}
+
+// Sync 将启用的自定义模板写入同步目录，并删除已禁用或已删除模板的文件
+func (m *TemplateMaterializer) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	files, err := m.enabledFiles(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create custom templates directory: %w", err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(m.dir, name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, content) {
+			continue
+		}
+		if err := writeFileAtomic(path, content); err != nil {
+			return fmt.Errorf("failed to write custom template %s: %w", name, err)
+		}
+	}
+
+	// 只清理由同步器生成的文件，用户手动放入的模板保持不变
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read custom templates directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !isMaterializedTemplate(entry.Name()) {
+			continue
+		}
+		if _, ok := files[entry.Name()]; ok {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove custom template %s: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Snapshot 为扫描任务写入启用的自定义模板快照，没有启用的模板时返回空
+func (m *TemplateMaterializer) Snapshot(ctx context.Context, taskID int) (string, error) {
+	files, err := m.enabledFiles(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	dir := m.snapshotPath(taskID)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", fmt.Errorf("failed to clear template snapshot: %w", err)
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template snapshot: %w", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to write template snapshot: %w", err)
+		}
+	}
+	return dir, nil
+}
+
+// ExclusionFile 为扫描任务写入禁用的内置模板 ID 列表，没有禁用的模板时返回空
+// 列表通过文件传给 nuclei，避免大量 ID 超出命令行长度限制
+func (m *TemplateMaterializer) ExclusionFile(ctx context.Context, taskID int) (string, error) {
+	path := m.exclusionPath(taskID)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to clear template exclusions: %w", err)
+	}
+
+	ids, err := m.repo.GetDisabledBuiltinIDs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get disabled templates: %w", err)
+	}
+	if len(ids) == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(m.snapshotDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create template snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(ids, "\n")+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write template exclusions: %w", err)
+	}
+	return path, nil
+}
+
+// RemoveSnapshot 删除扫描任务的模板快照、排除列表与模板清单文件
+func (m *TemplateMaterializer) RemoveSnapshot(taskID int) error {
+	for _, path := range []string{m.exclusionPath(taskID), m.manifestPath(taskID)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.RemoveAll(m.snapshotPath(taskID))
+}
+
+// RemoveStaleSnapshots 删除不再运行的扫描遗留的模板快照
+func (m *TemplateMaterializer) RemoveStaleSnapshots(isRunning func(taskID int) bool) error {
+	entries, err := os.ReadDir(m.snapshotDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		var taskID int
+		if _, err := fmt.Sscanf(entry.Name(), "scan-%d", &taskID); err == nil && isRunning(taskID) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(m.snapshotDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotPath 返回扫描任务的模板快照目录
+func (m *TemplateMaterializer) snapshotPath(taskID int) string {
+	return filepath.Join(m.snapshotDir, fmt.Sprintf("scan-%d", taskID))
+}
+
+// exclusionPath 返回扫描任务的排除列表文件
+func (m *TemplateMaterializer) exclusionPath(taskID int) string {
+	return filepath.Join(m.snapshotDir, fmt.Sprintf("scan-%d.exclude", taskID))
+}
+
+// enabledFiles 读取启用的自定义模板，返回文件名到内容的映射
+func (m *TemplateMaterializer) enabledFiles(ctx context.Context) (map[string][]byte, error) {
+	templates, err := m.repo.GetAllCustom(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom templates: %w", err)
+	}
+
+	files := make(map[string][]byte, len(templates))
+	for _, t := range templates {
+		if !t.Enabled || strings.TrimSpace(t.Content) == "" {
+			continue
+		}
+		files[materializedTemplateName(t.ID)] = []byte(t.Content)
+	}
+	return files, nil
+}
+
+// materializedTemplateName 自定义模板的文件名，按数据库 ID 命名避免模板 ID 冲突
+func materializedTemplateName(id int) string {
+	return fmt.Sprintf("custom-%d.yaml", id)
+}
+
+// isMaterializedTemplate 检查文件是否由同步器生成
+func isMaterializedTemplate(name string) bool {
+	var id int
+	n, err := fmt.Sscanf(name, "custom-%d.yaml", &id)
+	return err == nil && n == 1 && name == materializedTemplateName(id)
+}
+
+// writeFileAtomic 先写临时文件再重命名，读取方不会看到写了一半的文件
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
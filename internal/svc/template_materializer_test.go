@@ -0,0 +1,247 @@
+package svc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/holehunter/holehunter/internal/models"
+	"github.com/holehunter/holehunter/internal/repo"
+)
+
+// customTemplateYAML 生成最小的自定义模板内容
+func customTemplateYAML(id, name string) string {
+	return "id: " + id + "\ninfo:\n  name: " + name + "\n  severity: high\nhttp:\n  - method: GET\n    path:\n      - \"{{BaseURL}}\"\n"
+}
+
+// TestTemplateMaterializer_Sync 测试自定义模板变更后同步到磁盘
+func TestTemplateMaterializer_Sync(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	repository := repo.NewTemplateRepository(db)
+	dir := filepath.Join(t.TempDir(), "custom-templates")
+	materializer := NewTemplateMaterializer(repository, dir, filepath.Join(t.TempDir(), "snapshots"))
+	service := NewTemplateService(repository)
+	service.SetMaterializer(materializer)
+
+	// 用户手动放入的模板不受同步影响
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	manual := filepath.Join(dir, "manual.yaml")
+	if err := os.WriteFile(manual, []byte(customTemplateYAML("manual", "Manual")), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+
+	enabled, err := service.CreateCustomTemplate(ctx, &models.CreateTemplateRequest{Content: customTemplateYAML("poc-a", "PoC A"), Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateCustomTemplate() failed: %v", err)
+	}
+	disabled, err := service.CreateCustomTemplate(ctx, &models.CreateTemplateRequest{Content: customTemplateYAML("poc-b", "PoC B")})
+	if err != nil {
+		t.Fatalf("CreateCustomTemplate() failed: %v", err)
+	}
+
+	enabledFile := filepath.Join(dir, materializedTemplateName(enabled.ID))
+	disabledFile := filepath.Join(dir, materializedTemplateName(disabled.ID))
+	if data, err := os.ReadFile(enabledFile); err != nil || !strings.Contains(string(data), "poc-a") {
+		t.Errorf("enabled template file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(disabledFile); !os.IsNotExist(err) {
+		t.Errorf("disabled template should not be written, stat error = %v", err)
+	}
+
+	// 启用、修改、禁用与删除都反映到磁盘
+	if err := service.ToggleCustomTemplate(ctx, disabled.ID, true); err != nil {
+		t.Fatalf("ToggleCustomTemplate() failed: %v", err)
+	}
+	if _, err := os.Stat(disabledFile); err != nil {
+		t.Errorf("enabled template should be written: %v", err)
+	}
+	content := customTemplateYAML("poc-a", "PoC A v2")
+	if err := service.UpdateCustomTemplate(ctx, enabled.ID, &models.UpdateTemplateRequest{Content: &content}); err != nil {
+		t.Fatalf("UpdateCustomTemplate() failed: %v", err)
+	}
+	if data, _ := os.ReadFile(enabledFile); string(data) != content {
+		t.Errorf("updated template file = %q, want %q", data, content)
+	}
+	if err := service.ToggleCustomTemplate(ctx, enabled.ID, false); err != nil {
+		t.Fatalf("ToggleCustomTemplate() failed: %v", err)
+	}
+	if err := service.DeleteCustomTemplate(ctx, disabled.ID); err != nil {
+		t.Fatalf("DeleteCustomTemplate() failed: %v", err)
+	}
+	for _, path := range []string{enabledFile, disabledFile} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, stat error = %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(manual); err != nil {
+		t.Errorf("manual template should be kept: %v", err)
+	}
+}
+
+// TestScanService_CustomTemplates 测试扫描使用启用的自定义模板快照，运行期间修改模板不影响本次扫描
+func TestScanService_CustomTemplates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：记录快照目录中的模板，等待测试修改模板后再读取一次
+	script := `dir=$(dirname "$0")
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-t" ] && [ "$2" != "$dir" ]; then custom="$2"; fi
+  shift
+done
+echo "$custom" > "$dir/custom-dir"
+cat "$custom"/*.yaml > "$dir/before"
+i=0; while [ ! -f "$dir/go" ] && [ $i -lt 100 ]; do sleep 0.05; i=$((i+1)); done
+cat "$custom"/*.yaml > "$dir/after"`
+	service, targetID := newQueueTestScanService(t, db, script)
+	dataDir := filepath.Dir(service.resumeDir)
+	ctx := context.Background()
+
+	repository := repo.NewTemplateRepository(db)
+	materializer := NewTemplateMaterializer(repository, filepath.Join(dataDir, "custom-templates"), filepath.Join(dataDir, "template-snapshots"))
+	templates := NewTemplateService(repository)
+	templates.SetMaterializer(materializer)
+	service.SetTemplateMaterializer(materializer)
+
+	poc, err := templates.CreateCustomTemplate(ctx, &models.CreateTemplateRequest{Content: customTemplateYAML("poc-enabled", "Enabled"), Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateCustomTemplate() failed: %v", err)
+	}
+	if _, err := templates.CreateCustomTemplate(ctx, &models.CreateTemplateRequest{Content: customTemplateYAML("poc-disabled", "Disabled")}); err != nil {
+		t.Fatalf("CreateCustomTemplate() failed: %v", err)
+	}
+
+	task, err := service.Create(ctx, &CreateScanRequest{Name: "custom", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(filepath.Join(dataDir, "before")); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("fake nuclei did not read custom templates")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// 扫描运行期间修改模板
+	content := customTemplateYAML("poc-edited", "Edited")
+	if err := templates.UpdateCustomTemplate(ctx, poc.ID, &models.UpdateTemplateRequest{Content: &content}); err != nil {
+		t.Fatalf("UpdateCustomTemplate() failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "go"), nil, 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	customDir, _ := os.ReadFile(filepath.Join(dataDir, "custom-dir"))
+	if got := strings.TrimSpace(string(customDir)); got != materializer.snapshotPath(task.ID) {
+		t.Errorf("custom template dir = %q, want snapshot %q", got, materializer.snapshotPath(task.ID))
+	}
+	for _, name := range []string{"before", "after"} {
+		data, _ := os.ReadFile(filepath.Join(dataDir, name))
+		if !strings.Contains(string(data), "poc-enabled") || strings.Contains(string(data), "poc-disabled") || strings.Contains(string(data), "poc-edited") {
+			t.Errorf("%s templates = %q, want only the enabled template as of scan start", name, data)
+		}
+	}
+
+	// 扫描结束后删除快照，同步目录中为修改后的模板
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(materializer.snapshotPath(task.ID)); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("template snapshot should be removed after the scan")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if data, _ := os.ReadFile(filepath.Join(materializer.Dir(), materializedTemplateName(poc.ID))); string(data) != content {
+		t.Errorf("synced template = %q, want edited content", data)
+	}
+}
+
+// TestScanService_ExcludeDisabledTemplates 测试禁用的内置模板在扫描中被排除
+func TestScanService_ExcludeDisabledTemplates(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	// 假 nuclei：记录排除列表的路径与内容
+	script := `dir=$(dirname "$0")
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-exclude-id" ]; then echo "$2" > "$dir/exclude-path"; cat "$2" > "$dir/excluded"; fi
+  shift
+done`
+	service, targetID := newQueueTestScanService(t, db, script)
+	dataDir := filepath.Dir(service.resumeDir)
+	ctx := context.Background()
+
+	repository := repo.NewTemplateRepository(db)
+	materializer := NewTemplateMaterializer(repository, filepath.Join(dataDir, "custom-templates"), filepath.Join(dataDir, "template-snapshots"))
+	templates := NewTemplateService(repository)
+	templates.SetMaterializer(materializer)
+	service.SetTemplateMaterializer(materializer)
+
+	if _, err := repository.SyncBuiltin(ctx, []*models.Template{
+		{TemplateID: "cve-2024-0001", Name: "CVE 1", Severity: "high", Category: "http/cves/2024", Tags: []string{"cve"}, Enabled: true},
+		{TemplateID: "cve-2024-0002", Name: "CVE 2", Severity: "high", Category: "http/cves/2024", Tags: []string{"cve"}, Enabled: true},
+		{TemplateID: "tech-detect", Name: "Tech", Severity: "info", Category: "http/technologies", Tags: []string{"tech"}, Enabled: true},
+	}); err != nil {
+		t.Fatalf("SyncBuiltin() failed: %v", err)
+	}
+
+	if _, err := templates.BulkToggleBuiltin(ctx, &models.TemplateBulkToggleRequest{}); err == nil {
+		t.Error("BulkToggleBuiltin() without filters should fail")
+	}
+	count, err := templates.BulkToggleBuiltin(ctx, &models.TemplateBulkToggleRequest{Tag: " cve ", Enabled: false})
+	if err != nil {
+		t.Fatalf("BulkToggleBuiltin() failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("BulkToggleBuiltin() = %d, want 2", count)
+	}
+
+	task, err := service.Create(ctx, &CreateScanRequest{Name: "exclude", TargetID: targetID, Strategy: "deep"})
+	if err != nil {
+		t.Fatalf("Create() failed: %v", err)
+	}
+	if err := service.Start(ctx, task.ID); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	waitScanStatus(t, service, task.ID, "completed")
+
+	excludePath, _ := os.ReadFile(filepath.Join(dataDir, "exclude-path"))
+	if got := strings.TrimSpace(string(excludePath)); got != materializer.exclusionPath(task.ID) {
+		t.Errorf("exclude file = %q, want %q", got, materializer.exclusionPath(task.ID))
+	}
+	excluded, _ := os.ReadFile(filepath.Join(dataDir, "excluded"))
+	if string(excluded) != "cve-2024-0001\ncve-2024-0002\n" {
+		t.Errorf("excluded templates = %q, want the disabled builtin templates", excluded)
+	}
+
+	// 扫描结束后删除排除列表
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(materializer.exclusionPath(task.ID)); os.IsNotExist(err) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("exclude file should be removed after the scan")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -2,6 +2,12 @@ package svc
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/holehunter/holehunter/internal/models"
@@ -264,6 +270,203 @@ func TestTemplateService_GetPageByFilter(t *testing.T) {
 	}
 }
 
+func TestTemplateService_ValidateCustomTemplate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	service := NewTemplateService(repo.NewTemplateRepository(db))
+	ctx := context.Background()
+
+	content := `id: test-validate
+info:
+  name: Validate
+  author: tester
+  severity: low
+http:
+  - path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: word
+        words:
+          - ok
+`
+
+	// 未设置 nuclei 时只做结构校验
+	result, err := service.ValidateCustomTemplate(ctx, content)
+	if err != nil {
+		t.Fatalf("ValidateCustomTemplate failed: %v", err)
+	}
+	if !result.Valid || result.NucleiChecked || len(result.Diagnostics) != 0 {
+		t.Errorf("Expected valid result without nuclei check, got %+v", result)
+	}
+
+	// 结构错误带行号，且不再运行 nuclei
+	validator := &fakeTemplateValidator{available: true}
+	service.SetValidator(validator)
+	result, err = service.ValidateCustomTemplate(ctx, strings.Replace(content, "type: word", "type: words", 1))
+	if err != nil {
+		t.Fatalf("ValidateCustomTemplate failed: %v", err)
+	}
+	if result.Valid || !strings.HasPrefix(result.Error, "line 10: unknown matcher type") {
+		t.Errorf("Expected matcher type error on line 10, got %+v", result)
+	}
+	if validator.calls != 0 {
+		t.Errorf("Expected nuclei to be skipped on schema errors, got %d calls", validator.calls)
+	}
+
+	// nuclei 报告的错误
+	validator.diagnostics = []models.TemplateDiagnostic{{Line: 7, Severity: "error", Message: "could not compile request", Source: "nuclei"}}
+	result, err = service.ValidateCustomTemplate(ctx, content)
+	if err != nil {
+		t.Fatalf("ValidateCustomTemplate failed: %v", err)
+	}
+	if result.Valid || !result.NucleiChecked || result.Error != "line 7: could not compile request" {
+		t.Errorf("Expected nuclei error, got %+v", result)
+	}
+
+	// nuclei 运行失败时退回结构校验结果
+	validator.err = fmt.Errorf("exec failed")
+	result, err = service.ValidateCustomTemplate(ctx, content)
+	if err != nil {
+		t.Fatalf("ValidateCustomTemplate failed: %v", err)
+	}
+	if !result.Valid || result.NucleiChecked || len(result.Diagnostics) != 1 || result.Diagnostics[0].Severity != "warning" {
+		t.Errorf("Expected valid result with a warning, got %+v", result)
+	}
+}
+
+type fakeTemplateValidator struct {
+	available   bool
+	diagnostics []models.TemplateDiagnostic
+	err         error
+	calls       int
+}
+
+func (f *fakeTemplateValidator) IsAvailable() bool {
+	return f.available
+}
+
+func (f *fakeTemplateValidator) ValidateTemplate(ctx context.Context, content []byte) ([]models.TemplateDiagnostic, error) {
+	f.calls++
+	return f.diagnostics, f.err
+}
+
+func TestTemplateService_TestTemplate(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	repository := repo.NewTemplateRepository(db)
+	service := NewTemplateService(repository)
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	content := `id: test-dry-run
+info:
+  name: Dry Run
+  author: tester
+  severity: info
+http:
+  - path:
+      - "{{BaseURL}}"
+    matchers:
+      - type: status
+        status:
+          - 200
+`
+
+	// 未设置 nuclei 时无法试运行
+	if _, err := service.TestTemplate(ctx, &models.TemplateTestRequest{Content: content, URL: server.URL}); err == nil {
+		t.Error("Expected error without nuclei, got nil")
+	}
+
+	runner := &fakeTemplateRunner{}
+	service.SetRunner(runner)
+
+	invalid := []*models.TemplateTestRequest{
+		{Content: content, URL: "ftp://127.0.0.1"},
+		{Content: content, URL: "127.0.0.1:8080"},
+		{URL: server.URL},
+		{Content: strings.Replace(content, "type: status", "type: statuses", 1), URL: server.URL},
+	}
+	for _, req := range invalid {
+		if _, err := service.TestTemplate(ctx, req); err == nil {
+			t.Errorf("Expected error for %+v, got nil", req)
+		}
+	}
+	if len(runner.contents) != 0 {
+		t.Fatalf("Expected nuclei not to run for invalid requests, got %d runs", len(runner.contents))
+	}
+
+	// 草稿内容
+	result, err := service.TestTemplate(ctx, &models.TemplateTestRequest{Content: content, URL: " " + server.URL + " "})
+	if err != nil {
+		t.Fatalf("TestTemplate failed: %v", err)
+	}
+	if result.URL != server.URL || runner.contents[0] != content {
+		t.Errorf("Expected draft content to run against %s, got %+v", server.URL, result)
+	}
+
+	// 已保存的自定义模板与内置模板
+	custom, err := service.CreateCustomTemplate(ctx, &models.CreateTemplateRequest{Content: content, Enabled: true})
+	if err != nil {
+		t.Fatalf("CreateCustomTemplate failed: %v", err)
+	}
+	builtinPath := filepath.Join(t.TempDir(), "builtin.yaml")
+	builtinContent := strings.Replace(content, "test-dry-run", "builtin-dry-run", 1)
+	if err := os.WriteFile(builtinPath, []byte(builtinContent), 0644); err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+	if _, err := repository.SyncBuiltin(ctx, []*models.Template{{TemplateID: "builtin-dry-run", Name: "Builtin", Severity: "info", Path: builtinPath, Enabled: true}}); err != nil {
+		t.Fatalf("SyncBuiltin failed: %v", err)
+	}
+	builtin, err := repository.GetBySourceAndID(ctx, "builtin", "builtin-dry-run")
+	if err != nil {
+		t.Fatalf("GetBySourceAndID failed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		id   int
+		want string
+	}{
+		{custom.ID, content},
+		{builtin.ID, builtinContent},
+	} {
+		if _, err := service.TestTemplate(ctx, &models.TemplateTestRequest{ID: tt.id, URL: server.URL}); err != nil {
+			t.Fatalf("TestTemplate(%d) failed: %v", tt.id, err)
+		}
+		if got := runner.contents[len(runner.contents)-1]; got != tt.want {
+			t.Errorf("Expected template %d content %q, got %q", tt.id, tt.want, got)
+		}
+	}
+
+	// 试运行结果不保存为漏洞
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vulnerabilities").Scan(&count); err != nil {
+		t.Fatalf("count vulnerabilities failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no vulnerabilities, got %d", count)
+	}
+}
+
+type fakeTemplateRunner struct {
+	contents []string
+}
+
+func (f *fakeTemplateRunner) IsAvailable() bool {
+	return true
+}
+
+func (f *fakeTemplateRunner) RunTemplate(ctx context.Context, content []byte, targetURL string) (*models.TemplateTestResult, error) {
+	f.contents = append(f.contents, string(content))
+	return &models.TemplateTestResult{
+		URL:         targetURL,
+		Matched:     true,
+		Events:      []models.TemplateTestEvent{{Matched: true}},
+		Diagnostics: []models.TemplateDiagnostic{},
+	}, nil
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
@@ -147,6 +147,7 @@ func (s *VulnerabilityService) HandleEventVulnFound(ctx context.Context, data ma
 	}
 
 	vuln := s.buildVulnerabilityFromData(vulnData, taskID)
+	vuln.TargetID = extractTargetID(data)
 	return s.Create(ctx, vuln)
 }
 
@@ -196,6 +197,21 @@ func extractTaskID(data map[string]interface{}) (int, error) {
 	return taskID, nil
 }
 
+// extractTargetID 从事件数据中提取漏洞所属目标 ID，缺失时返回 nil
+func extractTargetID(data map[string]interface{}) *int {
+	var targetID int
+	switch v := data["targetId"].(type) {
+	case int:
+		targetID = v
+	case float64:
+		targetID = int(v)
+	}
+	if targetID <= 0 {
+		return nil
+	}
+	return &targetID
+}
+
 // buildVulnerabilityFromData 从 Nuclei 输出数据构建漏洞对象
 func (s *VulnerabilityService) buildVulnerabilityFromData(vulnData map[string]interface{}, taskID int) *models.Vulnerability {
 	// 调试：打印原始数据
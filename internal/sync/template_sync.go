@@ -132,13 +132,20 @@ func (s *TemplateSyncer) parseTemplateFile(filePath string) (*models.Template, e
 		template.Description = description
 	}
 
-	// 提取标签
-	if tagsArray, ok := infoMap["tags"].([]interface{}); ok {
-		for _, tag := range tagsArray {
+	// 提取标签，官方模板多为逗号分隔的字符串
+	switch tags := infoMap["tags"].(type) {
+	case []interface{}:
+		for _, tag := range tags {
 			if tagStr, ok := tag.(string); ok {
 				template.Tags = append(template.Tags, tagStr)
 			}
 		}
+	case string:
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				template.Tags = append(template.Tags, tag)
+			}
+		}
 	}
 
 	// 提取分类（从文件路径）
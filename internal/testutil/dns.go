@@ -0,0 +1,187 @@
+package testutil
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// DNSServer 进程内 DNS 服务器，支持 A/AAAA/CNAME 查询
+type DNSServer struct {
+	Addr string
+
+	conn    net.PacketConn
+	mu      sync.Mutex
+	records map[string][]string
+	queries int
+}
+
+// NewDNSServer 启动 DNS 服务器，records 为域名到 IP 列表的映射
+// 值不是 IP 时视为 CNAME 目标，域名支持 *.example.com 形式的泛解析记录，
+// 未命中的域名返回 NXDOMAIN
+func NewDNSServer(t *testing.T, records map[string][]string) *DNSServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen dns server: %v", err)
+	}
+
+	s := &DNSServer{
+		Addr:    conn.LocalAddr().String(),
+		conn:    conn,
+		records: make(map[string][]string),
+	}
+	for name, ips := range records {
+		s.records[normalizeDNSName(name)] = ips
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go s.serve()
+	return s
+}
+
+// SetRecord 设置域名记录
+func (s *DNSServer) SetRecord(name string, ips ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[normalizeDNSName(name)] = ips
+}
+
+// Queries 返回收到的查询数量
+func (s *DNSServer) Queries() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries
+}
+
+func (s *DNSServer) serve() {
+	buf := make([]byte, 1500)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.handle(buf[:n]); resp != nil {
+			_, _ = s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+func (s *DNSServer) handle(msg []byte) []byte {
+	if len(msg) < 12 {
+		return nil
+	}
+
+	// 解析问题部分
+	var labels []string
+	off := 12
+	for off < len(msg) && msg[off] != 0 {
+		l := int(msg[off])
+		if off+1+l > len(msg) {
+			return nil
+		}
+		labels = append(labels, string(msg[off+1:off+1+l]))
+		off += 1 + l
+	}
+	off++
+	if off+4 > len(msg) {
+		return nil
+	}
+	question := msg[12 : off+4]
+	qtype := binary.BigEndian.Uint16(msg[off:])
+
+	name := strings.Join(labels, ".")
+	values, found := s.lookup(name)
+
+	var answers [][]byte
+	owner := []byte{0xc0, 0x0c} // 指向问题中的域名
+	for depth := 0; found && depth < 8; depth++ {
+		target := ""
+		for _, value := range values {
+			if net.ParseIP(value) == nil {
+				target = value
+			}
+		}
+		if target != "" {
+			answers = append(answers, resourceRecord(owner, 5, encodeDNSName(target)))
+			if qtype == 5 {
+				break
+			}
+			owner = encodeDNSName(target)
+			values, _ = s.lookup(target)
+			continue
+		}
+
+		for _, value := range values {
+			ip := net.ParseIP(value)
+			if v4 := ip.To4(); v4 != nil && qtype == 1 {
+				answers = append(answers, resourceRecord(owner, 1, v4))
+			} else if v4 == nil && qtype == 28 {
+				answers = append(answers, resourceRecord(owner, 28, ip.To16()))
+			}
+		}
+		break
+	}
+
+	flags := uint16(0x8000) | binary.BigEndian.Uint16(msg[2:])&0x7900 | 0x0400 | 0x0080 // QR、AA、RA
+	if !found {
+		flags |= 3 // NXDOMAIN
+	}
+
+	resp := append([]byte{}, msg[:2]...)
+	resp = binary.BigEndian.AppendUint16(resp, flags)
+	resp = binary.BigEndian.AppendUint16(resp, 1)
+	resp = binary.BigEndian.AppendUint16(resp, uint16(len(answers)))
+	resp = binary.BigEndian.AppendUint16(resp, 0)
+	resp = binary.BigEndian.AppendUint16(resp, 0)
+	resp = append(resp, question...)
+	for _, rr := range answers {
+		resp = append(resp, rr...)
+	}
+	return resp
+}
+
+// resourceRecord 构造资源记录
+func resourceRecord(owner []byte, rtype uint16, rdata []byte) []byte {
+	rr := append([]byte{}, owner...)
+	rr = binary.BigEndian.AppendUint16(rr, rtype)
+	rr = binary.BigEndian.AppendUint16(rr, 1) // IN
+	rr = binary.BigEndian.AppendUint32(rr, 60)
+	rr = binary.BigEndian.AppendUint16(rr, uint16(len(rdata)))
+	return append(rr, rdata...)
+}
+
+// encodeDNSName 将域名编码为 DNS 报文格式
+func encodeDNSName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(normalizeDNSName(name), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// lookup 查找记录，精确匹配优先于泛解析
+func (s *DNSServer) lookup(name string) ([]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries++
+
+	name = normalizeDNSName(name)
+	if ips, ok := s.records[name]; ok {
+		return ips, true
+	}
+	for i := strings.IndexByte(name, '.'); i >= 0; i = strings.IndexByte(name, '.') {
+		name = name[i+1:]
+		if ips, ok := s.records["*."+name]; ok {
+			return ips, true
+		}
+	}
+	return nil, false
+}
+
+func normalizeDNSName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}